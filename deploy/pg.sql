@@ -62,6 +62,7 @@ INSERT INTO sys_enums (enum_type, enum_value, enum_name, enum_desc, is_default)
 ('register_source', 1, '邮箱注册', '通过邮箱注册的用户', TRUE),
 ('register_source', 2, '手机注册', '通过手机号注册的用户', FALSE),
 ('register_source', 3, '微信登录', '通过微信授权登录的用户', FALSE),
+('register_source', 4, '第三方登录', '通过GitHub、Google等第三方账号登录的用户', FALSE),
 -- 文章状态枚举
 ('article_status', 1, '草稿', '未完成的文章草稿', TRUE),
 ('article_status', 2, '待审核', '提交等待审核的文章', FALSE),
@@ -75,7 +76,16 @@ INSERT INTO sys_enums (enum_type, enum_value, enum_name, enum_desc, is_default)
 ('article_type', 1, '原创', '原创内容', TRUE),
 ('article_type', 2, '转载', '转载的内容', FALSE),
 ('article_type', 3, '翻译', '翻译的内容', FALSE),
-('article_type', 4, 'AI', 'AI生成的内容', FALSE);
+('article_type', 4, 'AI', 'AI生成的内容', FALSE),
+-- 文章审核操作枚举
+('review_action', 1, '提交审核', '作者提交文章等待审核', TRUE),
+('review_action', 2, '审核通过', '审核员通过并发布文章', FALSE),
+('review_action', 3, '审核驳回', '审核员驳回文章', FALSE),
+('review_action', 4, '撤回', '作者撤回审核或将文章转为草稿', FALSE),
+('review_action', 5, '直接发布', '有发布权限的用户直接发布文章', FALSE),
+('review_action', 6, '下线', '将已发布文章下线', FALSE),
+('review_action', 7, '定时发布', '调度器按定时发布时间发布文章', FALSE),
+('review_action', 8, '定时下线', '调度器按定时下线时间下线文章', FALSE);
 
 -- 用户表
 CREATE TABLE IF NOT EXISTS sys_users (
@@ -83,6 +93,7 @@ CREATE TABLE IF NOT EXISTS sys_users (
     username VARCHAR(30) NOT NULL UNIQUE,                 -- 用户名
     password_hash VARCHAR(100),                           -- 密码哈希
     email VARCHAR(100) UNIQUE,                            -- 邮箱
+    email_verified_at TIMESTAMPTZ,                        -- 邮箱验证时间
     mobile VARCHAR(20) UNIQUE,                            -- 手机号
     wechat_openid VARCHAR(50) UNIQUE,                     -- 微信开放ID
     wechat_unionid VARCHAR(50) UNIQUE,                    -- 微信统一ID
@@ -92,7 +103,7 @@ CREATE TABLE IF NOT EXISTS sys_users (
     gender SMALLINT DEFAULT 0,                            -- 性别(0未知,1男,2女)
     birthday DATE,                                        -- 生日
     status SMALLINT NOT NULL DEFAULT 1,                   -- 状态(1正常,2禁用,3未激活)
-    register_source SMALLINT NOT NULL DEFAULT 1,          -- 注册来源(1邮箱,2手机,3微信)
+    register_source SMALLINT NOT NULL DEFAULT 1,          -- 注册来源(1邮箱,2手机,3微信,4第三方)
     last_login TIMESTAMPTZ,                               -- 最后登录时间
     login_count INT NOT NULL DEFAULT 0,                   -- 登录次数
     created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),        -- 创建时间
@@ -104,6 +115,7 @@ COMMENT ON COLUMN sys_users.user_id IS '用户唯一标识';
 COMMENT ON COLUMN sys_users.username IS '用户登录名，4-30位字母数字下划线组合';
 COMMENT ON COLUMN sys_users.password_hash IS '使用pgcrypto加密的密码哈希';
 COMMENT ON COLUMN sys_users.email IS '用户邮箱，可用于登录';
+COMMENT ON COLUMN sys_users.email_verified_at IS '邮箱验证时间，为空表示未验证，修改邮箱后清空';
 COMMENT ON COLUMN sys_users.mobile IS '用户手机号，可用于登录';
 COMMENT ON COLUMN sys_users.wechat_openid IS '微信授权登录的OpenID';
 COMMENT ON COLUMN sys_users.wechat_unionid IS '跨应用微信UnionID';
@@ -113,7 +125,7 @@ COMMENT ON COLUMN sys_users.real_name IS '用户真实姓名';
 COMMENT ON COLUMN sys_users.gender IS '性别：0未知，1男，2女';
 COMMENT ON COLUMN sys_users.birthday IS '用户生日';
 COMMENT ON COLUMN sys_users.status IS '用户状态：1正常，2禁用，3未激活';
-COMMENT ON COLUMN sys_users.register_source IS '注册来源：1邮箱，2手机号，3微信';
+COMMENT ON COLUMN sys_users.register_source IS '注册来源：1邮箱，2手机号，3微信，4其他第三方账号';
 COMMENT ON COLUMN sys_users.last_login IS '最后登录时间';
 COMMENT ON COLUMN sys_users.login_count IS '累计登录次数';
 COMMENT ON COLUMN sys_users.created_at IS '账号创建时间';
@@ -126,26 +138,179 @@ CREATE INDEX idx_users_email ON sys_users(email) WHERE email IS NOT NULL;
 CREATE INDEX idx_users_login ON sys_users(last_login) WHERE last_login IS NOT NULL;
 CREATE INDEX idx_users_wechat ON sys_users(wechat_openid) WHERE wechat_openid IS NOT NULL;
 
+-- 应用密码表
+CREATE TABLE IF NOT EXISTS sys_app_passwords (
+    app_password_id SERIAL PRIMARY KEY,                   -- 应用密码ID
+    user_id INT NOT NULL,                                 -- 用户ID
+    app_name VARCHAR(50) NOT NULL,                        -- 应用名称
+    password_hash VARCHAR(100) NOT NULL,                  -- 密码哈希
+    last_used_at TIMESTAMPTZ,                             -- 最后使用时间
+    last_used_ip INET,                                    -- 最后使用IP
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),        -- 创建时间
+    FOREIGN KEY (user_id) REFERENCES sys_users(user_id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE sys_app_passwords IS '应用密码表，供桌面写作客户端等第三方应用通过XML-RPC登录';
+COMMENT ON COLUMN sys_app_passwords.app_password_id IS '应用密码唯一标识';
+COMMENT ON COLUMN sys_app_passwords.user_id IS '所属用户ID';
+COMMENT ON COLUMN sys_app_passwords.app_name IS '应用名称，便于用户区分和撤销';
+COMMENT ON COLUMN sys_app_passwords.password_hash IS 'bcrypt密码哈希，明文只在创建时返回一次';
+COMMENT ON COLUMN sys_app_passwords.last_used_at IS '最后一次成功认证的时间';
+COMMENT ON COLUMN sys_app_passwords.last_used_ip IS '最后一次成功认证的IP';
+COMMENT ON COLUMN sys_app_passwords.created_at IS '应用密码创建时间';
+
+-- 应用密码表索引
+CREATE INDEX idx_app_passwords_user ON sys_app_passwords(user_id);
+
+-- 个人访问令牌表
+CREATE TABLE IF NOT EXISTS sys_personal_access_tokens (
+    token_id SERIAL PRIMARY KEY,                          -- 令牌ID
+    user_id INT NOT NULL,                                 -- 用户ID
+    name VARCHAR(50) NOT NULL,                            -- 令牌名称
+    token_prefix VARCHAR(20) NOT NULL,                    -- 令牌前缀
+    token_hash CHAR(64) NOT NULL UNIQUE,                  -- 令牌哈希
+    scopes TEXT NOT NULL,                                 -- 权限范围
+    expires_at TIMESTAMPTZ NOT NULL,                      -- 过期时间
+    last_used_at TIMESTAMPTZ,                             -- 最后使用时间
+    last_used_ip INET,                                    -- 最后使用IP
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),        -- 创建时间
+    FOREIGN KEY (user_id) REFERENCES sys_users(user_id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE sys_personal_access_tokens IS '个人访问令牌表，供CI等自动化脚本以Bearer方式调用接口，只能访问权限范围内的接口';
+COMMENT ON COLUMN sys_personal_access_tokens.token_id IS '令牌唯一标识';
+COMMENT ON COLUMN sys_personal_access_tokens.user_id IS '所属用户ID';
+COMMENT ON COLUMN sys_personal_access_tokens.name IS '令牌名称，便于用户区分和撤销';
+COMMENT ON COLUMN sys_personal_access_tokens.token_prefix IS '令牌开头几位，便于用户辨认';
+COMMENT ON COLUMN sys_personal_access_tokens.token_hash IS '令牌的SHA-256哈希，明文只在创建时返回一次';
+COMMENT ON COLUMN sys_personal_access_tokens.scopes IS '令牌可使用的权限标识，以逗号分隔，不超出创建时账号拥有的权限';
+COMMENT ON COLUMN sys_personal_access_tokens.expires_at IS '令牌过期时间';
+COMMENT ON COLUMN sys_personal_access_tokens.last_used_at IS '最后一次认证成功的时间，每分钟最多更新一次';
+COMMENT ON COLUMN sys_personal_access_tokens.last_used_ip IS '最后一次认证成功的IP';
+COMMENT ON COLUMN sys_personal_access_tokens.created_at IS '令牌创建时间';
+
+-- 个人访问令牌表索引
+CREATE INDEX idx_personal_access_tokens_user ON sys_personal_access_tokens(user_id);
+
+-- 用户TOTP验证器表
+CREATE TABLE IF NOT EXISTS sys_user_totp (
+    user_id INT PRIMARY KEY,                              -- 用户ID
+    secret VARCHAR(255) NOT NULL,                         -- 加密后的密钥
+    enabled_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),        -- 启用时间
+    last_used_at TIMESTAMPTZ,                             -- 最后使用时间
+    FOREIGN KEY (user_id) REFERENCES sys_users(user_id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE sys_user_totp IS '用户绑定的TOTP验证器，启用后登录需要两步验证';
+COMMENT ON COLUMN sys_user_totp.user_id IS '所属用户ID';
+COMMENT ON COLUMN sys_user_totp.secret IS 'AES-GCM加密后的TOTP密钥';
+COMMENT ON COLUMN sys_user_totp.enabled_at IS '验证器绑定时间';
+COMMENT ON COLUMN sys_user_totp.last_used_at IS '最后一次验证通过的时间';
+
+-- 两步验证恢复码表
+CREATE TABLE IF NOT EXISTS sys_user_recovery_codes (
+    code_id SERIAL PRIMARY KEY,                           -- 恢复码ID
+    user_id INT NOT NULL,                                 -- 用户ID
+    code_hash CHAR(64) NOT NULL,                          -- 恢复码哈希
+    used_at TIMESTAMPTZ,                                  -- 使用时间
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),        -- 创建时间
+    FOREIGN KEY (user_id) REFERENCES sys_users(user_id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE sys_user_recovery_codes IS '两步验证恢复码，丢失验证器时代替动态口令登录，每个只能使用一次';
+COMMENT ON COLUMN sys_user_recovery_codes.code_id IS '恢复码唯一标识';
+COMMENT ON COLUMN sys_user_recovery_codes.user_id IS '所属用户ID';
+COMMENT ON COLUMN sys_user_recovery_codes.code_hash IS '恢复码的SHA-256哈希，明文只在生成时返回一次';
+COMMENT ON COLUMN sys_user_recovery_codes.used_at IS '使用时间，为空表示未使用';
+COMMENT ON COLUMN sys_user_recovery_codes.created_at IS '生成时间';
+
+-- 恢复码表索引
+CREATE INDEX idx_recovery_codes_user ON sys_user_recovery_codes(user_id, code_hash) WHERE used_at IS NULL;
+
+-- 通行密钥表
+CREATE TABLE IF NOT EXISTS sys_user_webauthn_credentials (
+    credential_id SERIAL PRIMARY KEY,                     -- 通行密钥ID
+    user_id INT NOT NULL,                                 -- 用户ID
+    raw_id BYTEA NOT NULL UNIQUE,                         -- 凭据ID
+    public_key BYTEA NOT NULL,                            -- 公钥
+    sign_count BIGINT NOT NULL DEFAULT 0,                 -- 签名计数器
+    aaguid VARCHAR(36),                                   -- 认证器型号标识
+    transports VARCHAR(100),                              -- 连接方式
+    backup_eligible BOOLEAN NOT NULL DEFAULT FALSE,       -- 是否可同步备份
+    backup_state BOOLEAN NOT NULL DEFAULT FALSE,          -- 是否已备份
+    name VARCHAR(50) NOT NULL,                            -- 名称
+    last_used_at TIMESTAMPTZ,                             -- 最后使用时间
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),        -- 创建时间
+    FOREIGN KEY (user_id) REFERENCES sys_users(user_id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE sys_user_webauthn_credentials IS '用户注册的通行密钥（WebAuthn凭据），用于免密码登录';
+COMMENT ON COLUMN sys_user_webauthn_credentials.credential_id IS '通行密钥唯一标识';
+COMMENT ON COLUMN sys_user_webauthn_credentials.user_id IS '所属用户ID';
+COMMENT ON COLUMN sys_user_webauthn_credentials.raw_id IS '认证器生成的凭据ID，登录时据此查找公钥';
+COMMENT ON COLUMN sys_user_webauthn_credentials.public_key IS 'COSE编码的公钥';
+COMMENT ON COLUMN sys_user_webauthn_credentials.sign_count IS '签名计数器，未递增时说明凭据可能被复制';
+COMMENT ON COLUMN sys_user_webauthn_credentials.aaguid IS '认证器型号标识(AAGUID)';
+COMMENT ON COLUMN sys_user_webauthn_credentials.transports IS '认证器支持的连接方式，逗号分隔，如 internal,hybrid';
+COMMENT ON COLUMN sys_user_webauthn_credentials.backup_eligible IS '是否为可在多设备间同步的通行密钥';
+COMMENT ON COLUMN sys_user_webauthn_credentials.backup_state IS '最近一次使用时是否已备份';
+COMMENT ON COLUMN sys_user_webauthn_credentials.name IS '用户设置的名称，便于区分设备';
+COMMENT ON COLUMN sys_user_webauthn_credentials.last_used_at IS '最后一次登录使用的时间';
+COMMENT ON COLUMN sys_user_webauthn_credentials.created_at IS '注册时间';
+
+-- 通行密钥表索引
+CREATE INDEX idx_webauthn_credentials_user ON sys_user_webauthn_credentials(user_id);
+
+-- 第三方账号关联表
+CREATE TABLE IF NOT EXISTS sys_user_oauth_accounts (
+    account_id SERIAL PRIMARY KEY,                        -- 关联ID
+    user_id INT NOT NULL,                                 -- 用户ID
+    provider VARCHAR(30) NOT NULL,                        -- 第三方登录服务标识
+    subject VARCHAR(255) NOT NULL,                        -- 第三方账号唯一标识
+    email VARCHAR(100),                                   -- 第三方账号邮箱
+    nickname VARCHAR(100),                                -- 第三方账号昵称
+    avatar VARCHAR(255),                                  -- 第三方账号头像
+    last_login_at TIMESTAMPTZ,                            -- 最后登录时间
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),        -- 关联时间
+    UNIQUE (provider, subject),
+    UNIQUE (user_id, provider),
+    FOREIGN KEY (user_id) REFERENCES sys_users(user_id) ON DELETE CASCADE
+);
+
+COMMENT ON TABLE sys_user_oauth_accounts IS '用户关联的第三方登录账号（GitHub、Google、OIDC等），微信账号保存在sys_users的wechat_openid/wechat_unionid字段';
+COMMENT ON COLUMN sys_user_oauth_accounts.account_id IS '关联唯一标识';
+COMMENT ON COLUMN sys_user_oauth_accounts.user_id IS '所属用户ID';
+COMMENT ON COLUMN sys_user_oauth_accounts.provider IS '配置文件中的第三方登录服务标识，如 github';
+COMMENT ON COLUMN sys_user_oauth_accounts.subject IS '第三方账号在该服务中的唯一标识（OIDC的sub、GitHub的用户ID）';
+COMMENT ON COLUMN sys_user_oauth_accounts.email IS '第三方账号的邮箱，仅供展示';
+COMMENT ON COLUMN sys_user_oauth_accounts.nickname IS '第三方账号的昵称，每次登录时更新';
+COMMENT ON COLUMN sys_user_oauth_accounts.avatar IS '第三方账号的头像地址，每次登录时更新';
+COMMENT ON COLUMN sys_user_oauth_accounts.last_login_at IS '最后一次使用该账号登录的时间';
+COMMENT ON COLUMN sys_user_oauth_accounts.created_at IS '关联时间';
+
 -- 登录日志表
 CREATE TABLE IF NOT EXISTS sys_login_logs (
-    log_id BIGSERIAL PRIMARY KEY,                         -- 日志ID
+    log_id BIGSERIAL,                                     -- 日志ID
     user_id INT,                                         -- 用户ID
     username VARCHAR(30),                                -- 登录用户名
-    login_type SMALLINT NOT NULL,                        -- 登录类型(1用户名,2邮箱,3手机,4微信)
+    login_type SMALLINT NOT NULL,                        -- 登录类型(1用户名,2邮箱,3手机,4微信,5通行密钥,6邮件链接,7第三方)
     login_status SMALLINT NOT NULL,                      -- 登录结果(1成功,2失败)
+    action SMALLINT NOT NULL DEFAULT 1,                  -- 操作(1登录,2刷新令牌,3退出登录)
     ip_address INET NOT NULL,                            -- 登录IP
     user_agent TEXT,                                      -- 用户代理
     device_info JSONB,                                   -- 设备信息
     login_time TIMESTAMPTZ NOT NULL DEFAULT NOW(),       -- 登录时间
-    fail_reason VARCHAR(100)                             -- 失败原因
+    fail_reason VARCHAR(100),                            -- 失败原因
+    PRIMARY KEY (log_id, login_time)
 ) PARTITION BY RANGE (login_time);
 
 COMMENT ON TABLE sys_login_logs IS '用户登录日志表';
 COMMENT ON COLUMN sys_login_logs.log_id IS '日志唯一标识';
 COMMENT ON COLUMN sys_login_logs.user_id IS '关联的用户ID';
 COMMENT ON COLUMN sys_login_logs.username IS '登录时使用的用户名';
-COMMENT ON COLUMN sys_login_logs.login_type IS '登录类型：1用户名密码，2邮箱，3手机号，4微信';
+COMMENT ON COLUMN sys_login_logs.login_type IS '登录类型：1用户名密码，2邮箱，3手机号，4微信，5通行密钥，6邮件登录链接，7第三方账号';
 COMMENT ON COLUMN sys_login_logs.login_status IS '登录状态：1成功，2失败';
+COMMENT ON COLUMN sys_login_logs.action IS '操作类型：1登录，2刷新令牌，3退出登录';
 COMMENT ON COLUMN sys_login_logs.ip_address IS '登录IP地址';
 COMMENT ON COLUMN sys_login_logs.user_agent IS '浏览器代理信息';
 COMMENT ON COLUMN sys_login_logs.device_info IS '登录设备信息JSON';
@@ -156,6 +321,9 @@ COMMENT ON COLUMN sys_login_logs.fail_reason IS '登录失败原因';
 CREATE TABLE sys_login_logs_2024 PARTITION OF sys_login_logs
     FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');
 
+-- 登录日志兜底分区，未创建年度分区的日志写入此分区
+CREATE TABLE sys_login_logs_default PARTITION OF sys_login_logs DEFAULT;
+
 -- 登录日志索引
 CREATE INDEX idx_login_logs_user ON sys_login_logs(user_id);
 CREATE INDEX idx_login_logs_time ON sys_login_logs USING BRIN(login_time);
@@ -171,6 +339,7 @@ CREATE TABLE IF NOT EXISTS sys_roles (
     role_desc VARCHAR(200),                               -- 角色描述
     is_default BOOLEAN NOT NULL DEFAULT FALSE,            -- 是否默认角色
     is_enabled BOOLEAN NOT NULL DEFAULT TRUE,             -- 是否启用
+    require_mfa BOOLEAN NOT NULL DEFAULT FALSE,           -- 是否要求两步验证
     created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),        -- 创建时间
     updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()         -- 更新时间
 );
@@ -183,6 +352,7 @@ COMMENT ON COLUMN sys_roles.role_sort IS '角色排序字段';
 COMMENT ON COLUMN sys_roles.role_desc IS '角色详细描述';
 COMMENT ON COLUMN sys_roles.is_default IS '是否为新用户默认角色';
 COMMENT ON COLUMN sys_roles.is_enabled IS '角色是否启用';
+COMMENT ON COLUMN sys_roles.require_mfa IS '拥有该角色的用户是否必须启用两步验证，未绑定验证器的用户登录时需先绑定';
 COMMENT ON COLUMN sys_roles.created_at IS '角色创建时间';
 COMMENT ON COLUMN sys_roles.updated_at IS '角色更新时间';
 
@@ -223,7 +393,7 @@ COMMENT ON COLUMN sys_permissions.perm_key IS '权限唯一标识符，用于程
 COMMENT ON COLUMN sys_permissions.perm_type IS '权限类型：1菜单，2按钮，3接口';
 COMMENT ON COLUMN sys_permissions.parent_id IS '父权限ID，构建权限树';
 COMMENT ON COLUMN sys_permissions.path IS '使用ltree存储的权限路径';
-COMMENT ON COLUMN sys_permissions.api_path IS 'API接口路径';
+COMMENT ON COLUMN sys_permissions.api_path IS 'API接口路径，仅作说明，接口权限按权限标识校验';
 COMMENT ON COLUMN sys_permissions.component IS '前端组件路径';
 COMMENT ON COLUMN sys_permissions.perms IS '权限标识字符串';
 COMMENT ON COLUMN sys_permissions.icon IS '图标样式或地址';
@@ -240,6 +410,34 @@ CREATE INDEX idx_permissions_enabled ON sys_permissions(is_enabled);
 CREATE INDEX idx_permissions_visible ON sys_permissions(is_visible);
 CREATE INDEX idx_permissions_path_gist ON sys_permissions USING GIST (path);
 
+-- 初始化文章发布权限（拥有该权限的用户可直接发布文章并审核他人文章）
+INSERT INTO sys_permissions (perm_name, perm_key, perm_type, perms, menu_sort, is_visible) VALUES
+('发布文章', 'content:article:publish', 2, 'content:article:publish', 0, FALSE);
+
+-- 初始化全站导出权限
+INSERT INTO sys_permissions (perm_name, perm_key, perm_type, perms, menu_sort, is_visible) VALUES
+('全站导出', 'system:site:export', 3, 'system:site:export', 0, FALSE);
+
+-- 初始化静态站点生成权限
+INSERT INTO sys_permissions (perm_name, perm_key, perm_type, perms, menu_sort, is_visible) VALUES
+('生成静态站点', 'system:site:static', 3, 'system:site:static', 0, FALSE);
+
+-- 初始化用户会话管理权限
+INSERT INTO sys_permissions (perm_name, perm_key, perm_type, perms, menu_sort, is_visible) VALUES
+('用户会话管理', 'system:user:session', 3, 'system:user:session', 0, FALSE);
+
+-- 初始化登录日志查询权限
+INSERT INTO sys_permissions (perm_name, perm_key, perm_type, perms, menu_sort, is_visible) VALUES
+('登录日志查询', 'system:log:login', 3, 'system:log:login', 0, FALSE);
+
+-- 初始化两步验证重置权限
+INSERT INTO sys_permissions (perm_name, perm_key, perm_type, perms, menu_sort, is_visible) VALUES
+('重置两步验证', 'system:user:mfa', 3, 'system:user:mfa', 0, FALSE);
+
+-- 初始化登录锁定解除权限
+INSERT INTO sys_permissions (perm_name, perm_key, perm_type, perms, menu_sort, is_visible) VALUES
+('解除登录锁定', 'system:user:unlock', 3, 'system:user:unlock', 0, FALSE);
+
 -- 用户角色关联表
 CREATE TABLE IF NOT EXISTS sys_user_roles (
     user_id INT NOT NULL,                                -- 用户ID
@@ -270,6 +468,36 @@ COMMENT ON COLUMN sys_role_permissions.role_id IS '关联的角色ID';
 COMMENT ON COLUMN sys_role_permissions.perm_id IS '关联的权限ID';
 COMMENT ON COLUMN sys_role_permissions.created_at IS '关联创建时间';
 
+-- 为管理员、内容编辑和审核员授予文章发布权限
+INSERT INTO sys_role_permissions (role_id, perm_id)
+SELECT r.role_id, p.perm_id FROM sys_roles r, sys_permissions p
+WHERE r.role_key IN ('admin', 'editor', 'reviewer') AND p.perm_key = 'content:article:publish';
+
+-- 为管理员授予全站导出权限
+INSERT INTO sys_role_permissions (role_id, perm_id)
+SELECT r.role_id, p.perm_id FROM sys_roles r, sys_permissions p
+WHERE r.role_key = 'admin' AND p.perm_key = 'system:site:export';
+
+INSERT INTO sys_role_permissions (role_id, perm_id)
+SELECT r.role_id, p.perm_id FROM sys_roles r, sys_permissions p
+WHERE r.role_key = 'admin' AND p.perm_key = 'system:site:static';
+
+INSERT INTO sys_role_permissions (role_id, perm_id)
+SELECT r.role_id, p.perm_id FROM sys_roles r, sys_permissions p
+WHERE r.role_key = 'admin' AND p.perm_key = 'system:user:session';
+
+INSERT INTO sys_role_permissions (role_id, perm_id)
+SELECT r.role_id, p.perm_id FROM sys_roles r, sys_permissions p
+WHERE r.role_key = 'admin' AND p.perm_key = 'system:log:login';
+
+INSERT INTO sys_role_permissions (role_id, perm_id)
+SELECT r.role_id, p.perm_id FROM sys_roles r, sys_permissions p
+WHERE r.role_key = 'admin' AND p.perm_key = 'system:user:unlock';
+
+INSERT INTO sys_role_permissions (role_id, perm_id)
+SELECT r.role_id, p.perm_id FROM sys_roles r, sys_permissions p
+WHERE r.role_key = 'admin' AND p.perm_key = 'system:user:mfa';
+
 -- 分类表
 CREATE TABLE IF NOT EXISTS cms_categories (
     category_id SERIAL PRIMARY KEY,                       -- 分类ID
@@ -368,6 +596,10 @@ CREATE TABLE IF NOT EXISTS cms_articles (
     source_url VARCHAR(255),                              -- 原文链接(转载/翻译)
     source_name VARCHAR(100),                             -- 来源名称
     publish_time TIMESTAMPTZ,                             -- 发布时间
+    schedule_time TIMESTAMPTZ,                            -- 定时发布时间
+    expire_time TIMESTAMPTZ,                              -- 定时下线时间
+    reject_reason VARCHAR(500),                           -- 审核驳回原因
+    search_vector TSVECTOR,                               -- 全文检索向量
     created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),        -- 创建时间
     updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),        -- 更新时间
     FOREIGN KEY (user_id) REFERENCES sys_users(user_id)
@@ -394,6 +626,10 @@ COMMENT ON COLUMN cms_articles.seo_description IS 'SEO优化描述';
 COMMENT ON COLUMN cms_articles.source_url IS '转载或翻译的原文链接';
 COMMENT ON COLUMN cms_articles.source_name IS '转载或翻译的来源名称';
 COMMENT ON COLUMN cms_articles.publish_time IS '文章发布时间';
+COMMENT ON COLUMN cms_articles.schedule_time IS '定时发布时间，到期后由调度器发布并清空';
+COMMENT ON COLUMN cms_articles.expire_time IS '定时下线时间，到期后由调度器下线并清空';
+COMMENT ON COLUMN cms_articles.reject_reason IS '最近一次审核驳回的原因，重新提交审核时清空';
+COMMENT ON COLUMN cms_articles.search_vector IS '全文检索向量，由服务端对标题(A)、摘要(B)和当前版本内容(C)中文分词后加权生成，文章保存时维护，词典变更后需重建';
 COMMENT ON COLUMN cms_articles.created_at IS '文章创建时间';
 COMMENT ON COLUMN cms_articles.updated_at IS '文章更新时间';
 
@@ -409,6 +645,11 @@ CREATE INDEX idx_articles_created ON cms_articles USING BRIN(created_at);
 CREATE INDEX idx_articles_published ON cms_articles(publish_time) WHERE status = 3;
 CREATE INDEX idx_articles_recommend ON cms_articles(is_recommend) WHERE is_recommend = TRUE;
 CREATE INDEX idx_articles_top ON cms_articles(is_top) WHERE is_top = TRUE;
+CREATE INDEX idx_articles_schedule ON cms_articles(schedule_time) WHERE schedule_time IS NOT NULL;
+CREATE INDEX idx_articles_expire ON cms_articles(expire_time) WHERE expire_time IS NOT NULL;
+CREATE INDEX idx_articles_pending ON cms_articles(updated_at) WHERE status = 2;
+CREATE INDEX idx_articles_search ON cms_articles USING GIN(search_vector);
+CREATE INDEX idx_articles_title_trgm ON cms_articles USING GIN(title gin_trgm_ops);
 
 -- 文章内容表
 CREATE TABLE IF NOT EXISTS cms_article_contents (
@@ -441,6 +682,7 @@ CREATE TABLE IF NOT EXISTS cms_article_contents_current PARTITION OF cms_article
 CREATE INDEX idx_article_contents_article ON cms_article_contents(article_id);
 CREATE INDEX idx_article_contents_current ON cms_article_contents(is_current) WHERE is_current = TRUE;
 CREATE INDEX idx_article_contents_time ON cms_article_contents USING BRIN(created_at);
+CREATE INDEX idx_article_contents_version ON cms_article_contents(article_id, version);
 
 -- 文章-分类关联表
 CREATE TABLE IF NOT EXISTS cms_article_categories (
@@ -474,6 +716,34 @@ COMMENT ON COLUMN cms_article_tags.article_id IS '关联的文章ID';
 COMMENT ON COLUMN cms_article_tags.tag_id IS '关联的标签ID';
 COMMENT ON COLUMN cms_article_tags.created_at IS '关联创建时间';
 
+-- 文章审核记录表
+CREATE TABLE IF NOT EXISTS cms_article_reviews (
+    review_id BIGSERIAL PRIMARY KEY,                      -- 记录ID
+    article_id BIGINT NOT NULL,                           -- 文章ID
+    action SMALLINT NOT NULL,                             -- 操作类型
+    from_status SMALLINT NOT NULL,                        -- 变更前状态
+    to_status SMALLINT NOT NULL,                          -- 变更后状态
+    operator_id INT,                                      -- 操作人ID
+    reason VARCHAR(500),                                  -- 原因/备注
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),        -- 创建时间
+    FOREIGN KEY (article_id) REFERENCES cms_articles(article_id) ON DELETE CASCADE,
+    FOREIGN KEY (operator_id) REFERENCES sys_users(user_id) ON DELETE SET NULL
+);
+
+COMMENT ON TABLE cms_article_reviews IS '文章审核记录表，记录文章每一次状态流转';
+COMMENT ON COLUMN cms_article_reviews.review_id IS '审核记录唯一标识';
+COMMENT ON COLUMN cms_article_reviews.article_id IS '关联的文章ID';
+COMMENT ON COLUMN cms_article_reviews.action IS '操作类型：1提交审核，2审核通过，3审核驳回，4撤回，5直接发布，6下线，7定时发布，8定时下线';
+COMMENT ON COLUMN cms_article_reviews.from_status IS '变更前的文章状态，0表示新建';
+COMMENT ON COLUMN cms_article_reviews.to_status IS '变更后的文章状态';
+COMMENT ON COLUMN cms_article_reviews.operator_id IS '操作人ID，调度器执行时为空';
+COMMENT ON COLUMN cms_article_reviews.reason IS '驳回原因或操作备注';
+COMMENT ON COLUMN cms_article_reviews.created_at IS '操作时间';
+
+-- 文章审核记录表索引
+CREATE INDEX idx_article_reviews_article ON cms_article_reviews(article_id, created_at DESC);
+CREATE INDEX idx_article_reviews_operator ON cms_article_reviews(operator_id);
+
 -- 系统配置表
 CREATE TABLE IF NOT EXISTS sys_configs (
     config_id SERIAL PRIMARY KEY,                         -- 配置ID
@@ -518,7 +788,24 @@ INSERT INTO sys_configs (config_name, config_key, config_value, value_type, conf
 ('ICP备案号', 'site_icp', '', 1, 'site', TRUE, TRUE, '网站ICP备案号'),
 ('公安备案号', 'site_police', '', 1, 'site', TRUE, TRUE, '网站公安备案号'),
 ('版权信息', 'site_copyright', '© 2024 My Blog', 1, 'site', TRUE, TRUE, '网站版权信息'),
-('每页文章数', 'article_page_size', '10', 2, 'article', TRUE, TRUE, '文章列表每页显示数量');
+('网站地址', 'site_url', '', 1, 'site', TRUE, TRUE, '网站访问地址，如https://blog.example.com，用于生成订阅源等处的绝对链接，为空时使用请求地址；邮件中的链接只使用该地址，未配置时无法发送验证和找回密码邮件'),
+('网站语言', 'site_language', 'zh-CN', 1, 'site', TRUE, TRUE, '网站内容语言'),
+('robots.txt规则', 'robots_txt', E'User-agent: *\nAllow: /\nDisallow: /admin/\nDisallow: /api/\n', 1, 'seo', FALSE, FALSE, '搜索引擎抓取规则，未包含Sitemap行时自动追加站点地图地址'),
+('每页文章数', 'article_page_size', '10', 2, 'article', TRUE, TRUE, '文章列表每页显示数量'),
+('登录失败锁定次数', 'login_max_failures', '5', 2, 'security', TRUE, FALSE, '失败计数窗口内同一用户名连续登录失败达到该次数后临时锁定账号'),
+('IP登录失败限制次数', 'login_ip_max_failures', '20', 2, 'security', TRUE, FALSE, '失败计数窗口内同一IP登录失败达到该次数后临时限制该IP登录'),
+('登录失败计数窗口', 'login_failure_window', '15', 2, 'security', TRUE, FALSE, '登录失败次数的统计时间范围（分钟），按滑动窗口计算'),
+('登录锁定时长', 'login_lockout_minutes', '30', 2, 'security', TRUE, FALSE, '账号或IP被锁定的时长（分钟），到期自动解锁'),
+('登录延迟起始次数', 'login_delay_after', '3', 2, 'security', TRUE, FALSE, '连续失败达到该次数后，每次再尝试前需等待的时间从1秒起逐次加倍（最多60秒），0表示不延迟'),
+('登录需验证邮箱', 'email_verify_required', 'false', 3, 'security', TRUE, FALSE, '开启后邮箱未验证的账号不能登录，需先点击注册时收到的验证邮件中的链接'),
+('第三方登录自动注册', 'oauth_auto_register', 'true', 3, 'security', TRUE, FALSE, '第三方账号未关联本站用户且邮箱无法匹配已验证的用户时，是否自动注册新用户；关闭后需先登录再在账号设置中关联'),
+('启用邮件服务', 'email_enabled', 'false', 3, 'email', TRUE, FALSE, '是否启用邮件发送，关闭时不发送验证邮件和找回密码邮件'),
+('SMTP服务器', 'email_host', '', 1, 'email', TRUE, FALSE, 'SMTP服务器地址，如smtp.example.com'),
+('SMTP端口', 'email_port', '465', 2, 'email', TRUE, FALSE, 'SMTP服务器端口，465使用SSL连接，其他端口在服务器支持时使用STARTTLS'),
+('SMTP用户名', 'email_username', '', 1, 'email', TRUE, FALSE, 'SMTP登录用户名，为空时不进行身份验证'),
+('SMTP密码', 'email_password', '', 1, 'email', TRUE, FALSE, 'SMTP登录密码或授权码'),
+('发件人地址', 'email_from', '', 1, 'email', TRUE, FALSE, '发件人邮箱地址'),
+('发件人名称', 'email_from_name', '', 1, 'email', TRUE, FALSE, '发件人显示名称，为空时使用网站名称');
 
 -- 操作日志表
 CREATE TABLE IF NOT EXISTS sys_operation_logs (
@@ -705,9 +992,11 @@ CREATE OR REPLACE FUNCTION article_status_change() RETURNS TRIGGER AS $$
 BEGIN
 -- 如果文章状态变更为已发布，且之前不是已发布状态
 IF NEW.status = 3 AND (OLD.status IS NULL OR OLD.status != 3) THEN
--- 设置发布时间
+-- 设置发布时间（保留定时发布写入的已到期时间）
+IF NEW.publish_time IS NULL OR NEW.publish_time > NOW() OR NEW.publish_time IS NOT DISTINCT FROM OLD.publish_time THEN
 NEW.publish_time = NOW();
 END IF;
+END IF;
 RETURN NEW;
 END;
 $$ LANGUAGE plpgsql;
@@ -0,0 +1,155 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/logger"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/resp"
+)
+
+// AccessTokenController 个人访问令牌控制器
+type AccessTokenController struct{}
+
+// NewAccessTokenController 创建个人访问令牌控制器实例
+func NewAccessTokenController() *AccessTokenController {
+	return &AccessTokenController{}
+}
+
+// ListAccessTokens 获取当前用户的个人访问令牌列表
+// @Summary 获取个人访问令牌列表
+// @Description 获取当前用户创建的个人访问令牌（名称、权限范围、过期时间和最后使用时间），不包含令牌明文
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} resp.Response{data=[]model.PersonalAccessToken} "返回令牌列表"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /api/v1/user/access-tokens [get]
+func (ac *AccessTokenController) ListAccessTokens(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	accessTokens, err := service.ListAccessTokens(userID.(int))
+	if err != nil {
+		logger.Error("获取访问令牌列表失败", "user_id", userID, "error", err)
+		resp.FailWithMsg(c, "获取访问令牌列表失败")
+		return
+	}
+
+	resp.OkWithData(c, accessTokens)
+}
+
+// CreateAccessToken 创建个人访问令牌
+// @Summary 创建个人访问令牌
+// @Description 为CI等自动化脚本创建访问令牌，以 Authorization: Bearer 方式调用接口，只能访问所选权限范围内的接口。
+// @Description 权限范围不能超出当前账号拥有的权限，令牌明文只在创建时返回一次。直接发布、审核和定时发布文章还需包含 content:article:publish，否则按无发布权限处理
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param data body model.PersonalAccessTokenCreateForm true "令牌信息"
+// @Success 200 {object} resp.Response{data=model.PersonalAccessTokenCreateResult} "创建成功"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "权限范围超出当前账号的权限"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /api/v1/user/access-tokens [post]
+func (ac *AccessTokenController) CreateAccessToken(c *gin.Context) {
+	var form model.PersonalAccessTokenCreateForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	result, err := service.CreateAccessToken(userID.(int), form)
+	if err != nil {
+		if errors.Is(err, service.ErrAccessTokenScope) {
+			resp.FailWithCode(c, http.StatusForbidden, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrAccessTokenLimit) {
+			resp.FailWithMsg(c, err.Error())
+			return
+		}
+		logger.Error("创建访问令牌失败", "user_id", userID, "error", err)
+		resp.FailWithMsg(c, "创建访问令牌失败，请稍后重试")
+		return
+	}
+
+	resp.OkWithData(c, result)
+}
+
+// DeleteAccessToken 删除个人访问令牌
+// @Summary 删除个人访问令牌
+// @Description 撤销当前用户的个人访问令牌，使用该令牌的请求立即失效
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "令牌ID"
+// @Success 200 {object} resp.Response "删除成功"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 404 {object} resp.Response "令牌不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /api/v1/user/access-tokens/{id} [delete]
+func (ac *AccessTokenController) DeleteAccessToken(c *gin.Context) {
+	tokenID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		resp.FailWithMsg(c, "无效的令牌ID")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := service.DeleteAccessToken(userID.(int), tokenID); err != nil {
+		if errors.Is(err, service.ErrAccessTokenNotFound) {
+			resp.FailWithCode(c, http.StatusNotFound, err.Error())
+			return
+		}
+		logger.Error("删除访问令牌失败", "user_id", userID, "token_id", tokenID, "error", err)
+		resp.FailWithMsg(c, "删除访问令牌失败，请稍后重试")
+		return
+	}
+
+	resp.OkWithMsg(c, "删除成功")
+}
+
+// tokenScopes 返回个人访问令牌的权限范围，使用登录令牌认证时返回nil，表示不受限
+func tokenScopes(c *gin.Context) []string {
+	scopes, exists := c.Get("token_scopes")
+	if !exists {
+		return nil
+	}
+	if list, ok := scopes.([]string); ok && list != nil {
+		return list
+	}
+	return []string{}
+}
+
+// RegisterRoutes 注册路由
+func (ac *AccessTokenController) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/access-tokens", ac.ListAccessTokens)
+	router.POST("/access-tokens", ac.CreateAccessToken)
+	router.DELETE("/access-tokens/:id", ac.DeleteAccessToken)
+}
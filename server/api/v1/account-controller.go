@@ -0,0 +1,310 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/logger"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/resp"
+)
+
+// AccountController 邮箱验证、找回密码和邮件登录控制器
+type AccountController struct{}
+
+// NewAccountController 创建邮箱验证、找回密码和邮件登录控制器实例
+func NewAccountController() *AccountController {
+	return &AccountController{}
+}
+
+// RequestVerificationEmail 重新发送验证邮件
+// @Summary 重新发送验证邮件
+// @Description 向填写的邮箱重新发送验证邮件，用于未验证邮箱无法登录的情况。为避免暴露邮箱是否注册，无论邮箱是否存在都返回相同结果；同一账号1分钟内只发送一次
+// @Tags 认证管理
+// @Accept json
+// @Produce json
+// @Param data body model.EmailForm true "邮箱"
+// @Success 200 {object} resp.Response 已受理
+// @Failure 400 {object} resp.Response 请求参数错误
+// @Router /api/v1/auth/email/verify/send [post]
+func (ac *AccountController) RequestVerificationEmail(c *gin.Context) {
+	var form model.EmailForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	if err := service.RequestVerificationEmail(form.Email); err != nil && !errors.Is(err, service.ErrAccountTokenTooFrequent) {
+		logger.Error("发送验证邮件失败", "email", form.Email, "error", err)
+	}
+
+	resp.OkWithMsg(c, "如果该邮箱已注册且尚未验证，验证邮件已发送，请查收")
+}
+
+// SendVerificationEmail 向当前用户的邮箱发送验证邮件
+// @Summary 发送验证邮件
+// @Description 向当前用户的邮箱发送验证邮件，此前发送的验证链接随即失效；1分钟内只能发送一次
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} resp.Response "发送成功"
+// @Failure 400 {object} resp.Response "邮箱已验证、未填写邮箱或邮件服务未启用"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 429 {object} resp.Response "发送过于频繁"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /api/v1/user/email/verify/send [post]
+func (ac *AccountController) SendVerificationEmail(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := service.SendVerificationEmail(userID.(int)); err != nil {
+		switch {
+		case errors.Is(err, service.ErrAccountTokenTooFrequent):
+			resp.FailWithCode(c, http.StatusTooManyRequests, err.Error())
+		case errors.Is(err, service.ErrEmailAlreadyVerified),
+			errors.Is(err, service.ErrEmailNotSet),
+			errors.Is(err, service.ErrMailDisabled):
+			resp.FailWithMsg(c, err.Error())
+		default:
+			logger.Error("发送验证邮件失败", "user_id", userID, "error", err)
+			resp.FailWithMsg(c, "发送验证邮件失败，请稍后重试")
+		}
+		return
+	}
+
+	resp.OkWithMsg(c, "验证邮件已发送，请查收")
+}
+
+// VerifyEmail 验证邮箱
+// @Summary 验证邮箱
+// @Description 提交验证邮件链接中的令牌完成邮箱验证，令牌24小时内有效且只能使用一次
+// @Tags 认证管理
+// @Accept json
+// @Produce json
+// @Param data body model.EmailVerifyForm true "验证令牌"
+// @Success 200 {object} resp.Response 验证成功
+// @Failure 400 {object} resp.Response 链接无效或已过期
+// @Failure 500 {object} resp.Response 服务器内部错误
+// @Router /api/v1/auth/email/verify [post]
+func (ac *AccountController) VerifyEmail(c *gin.Context) {
+	var form model.EmailVerifyForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	if err := service.VerifyEmail(form.Token); err != nil {
+		if errors.Is(err, service.ErrAccountTokenInvalid) {
+			resp.FailWithMsg(c, err.Error())
+			return
+		}
+		logger.Error("验证邮箱失败", "error", err)
+		resp.FailWithMsg(c, "验证失败，请稍后重试")
+		return
+	}
+
+	resp.OkWithMsg(c, "邮箱验证成功")
+}
+
+// ForgotPassword 找回密码
+// @Summary 找回密码
+// @Description 向填写的邮箱发送重置密码邮件，链接30分钟内有效。为避免暴露邮箱是否注册，无论邮箱是否存在都返回相同结果；同一账号1分钟内只发送一次
+// @Tags 认证管理
+// @Accept json
+// @Produce json
+// @Param data body model.EmailForm true "邮箱"
+// @Success 200 {object} resp.Response 已受理
+// @Failure 400 {object} resp.Response 请求参数错误
+// @Router /api/v1/auth/password/forgot [post]
+func (ac *AccountController) ForgotPassword(c *gin.Context) {
+	var form model.EmailForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	if err := service.RequestPasswordReset(form.Email); err != nil && !errors.Is(err, service.ErrAccountTokenTooFrequent) {
+		logger.Error("发送找回密码邮件失败", "email", form.Email, "error", err)
+	}
+
+	resp.OkWithMsg(c, "如果该邮箱已注册，重置密码邮件已发送，请查收")
+}
+
+// ResetPassword 重置密码
+// @Summary 重置密码
+// @Description 提交找回密码邮件链接中的令牌和新密码，令牌只能使用一次。重置后所有设备上的登录失效，账号的登录锁定同时解除
+// @Tags 认证管理
+// @Accept json
+// @Produce json
+// @Param data body model.PasswordResetForm true "令牌和新密码"
+// @Success 200 {object} resp.Response 重置成功
+// @Failure 400 {object} resp.Response 链接无效或已过期
+// @Failure 500 {object} resp.Response 服务器内部错误
+// @Router /api/v1/auth/password/reset [post]
+func (ac *AccountController) ResetPassword(c *gin.Context) {
+	var form model.PasswordResetForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	if err := service.ResetPasswordByToken(form.Token, form.NewPassword); err != nil {
+		if errors.Is(err, service.ErrAccountTokenInvalid) || errors.Is(err, service.ErrUserDisabled) {
+			resp.FailWithMsg(c, err.Error())
+			return
+		}
+		logger.Error("重置密码失败", "error", err)
+		resp.FailWithMsg(c, "重置密码失败，请稍后重试")
+		return
+	}
+
+	resp.OkWithMsg(c, "密码已重置，请使用新密码登录")
+}
+
+// RequestMagicLink 申请邮件登录链接
+// @Summary 申请邮件登录链接
+// @Description 向填写的邮箱发送一次性登录链接，链接15分钟内有效且只能使用一次。bind_device 为 true 时链接只能在申请的设备上使用，需在登录时提交相同的 device_id。
+// @Description 为避免暴露邮箱是否注册，无论邮箱是否存在都返回相同结果；同一账号1分钟内只发送一次，同一邮箱每小时最多申请5次
+// @Tags 认证管理
+// @Accept json
+// @Produce json
+// @Param data body model.MagicLinkForm true "邮箱和设备绑定"
+// @Success 200 {object} resp.Response 已受理
+// @Failure 400 {object} resp.Response 请求参数错误
+// @Failure 429 {object} resp.Response 申请过于频繁
+// @Router /api/v1/auth/magic-link [post]
+func (ac *AccountController) RequestMagicLink(c *gin.Context) {
+	var form model.MagicLinkForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	binding := ""
+	if form.BindDevice {
+		binding = service.DeviceFingerprint(c.Request.UserAgent(), form.DeviceID)
+	}
+	if err := service.RequestMagicLink(form.Email, binding); err != nil {
+		if errors.Is(err, service.ErrMagicLinkTooFrequent) {
+			resp.FailWithCode(c, http.StatusTooManyRequests, err.Error())
+			return
+		}
+		if !errors.Is(err, service.ErrAccountTokenTooFrequent) {
+			logger.Error("发送登录邮件失败", "email", form.Email, "error", err)
+		}
+	}
+
+	resp.OkWithMsg(c, "如果该邮箱已注册，登录链接已发送，请查收")
+}
+
+// ConsumeMagicLink 使用邮件登录链接登录
+// @Summary 邮件链接登录
+// @Description 提交登录邮件链接中的令牌登录，成功后签发与密码登录相同的令牌。申请时绑定了设备的链接需在同一浏览器中打开并提交相同的 device_id。
+// @Description 启用了两步验证或所属角色要求两步验证时不签发令牌，返回 mfa_required 和临时令牌，需调用 /auth/mfa/verify 或 /auth/mfa/enroll 完成登录
+// @Tags 认证管理
+// @Accept json
+// @Produce json
+// @Param data body model.MagicLinkLoginForm true "令牌和设备标识"
+// @Success 200 {object} resp.Response "登录成功，返回令牌和用户信息"
+// @Failure 400 {object} resp.Response "链接无效或已过期、账号已禁用"
+// @Failure 423 {object} resp.Response "账号已被锁定"
+// @Failure 429 {object} resp.Response "登录尝试过于频繁"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /api/v1/auth/magic-link/consume [post]
+func (ac *AccountController) ConsumeMagicLink(c *gin.Context) {
+	var form model.MagicLinkLoginForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	fingerprint := service.DeviceFingerprint(c.Request.UserAgent(), form.DeviceID)
+	result, user, err := service.ConsumeMagicLink(form.Token, fingerprint, sessionClient(c))
+	if err != nil {
+		var limitErr *service.LoginLimitError
+		switch {
+		case errors.As(err, &limitErr):
+			ac.recordLoginLog(c, user, "登录受限")
+			failLoginLimited(c, limitErr)
+		case errors.Is(err, service.ErrAccountTokenInvalid):
+			ac.recordLoginLog(c, user, "登录链接无效")
+			resp.FailWithMsg(c, err.Error())
+		case errors.Is(err, service.ErrUserDisabled):
+			ac.recordLoginLog(c, user, "账号状态异常")
+			resp.FailWithMsg(c, err.Error())
+		default:
+			logger.Error("邮件链接登录失败", "error", err)
+			resp.FailWithMsg(c, "登录失败，请稍后重试")
+		}
+		return
+	}
+
+	// 需要两步验证时只返回临时令牌，完成第二步后才签发令牌
+	if result.MFA != nil {
+		resp.OkWithData(c, gin.H{
+			"mfa_required": true,
+			"mfa":          result.MFA,
+		})
+		return
+	}
+
+	ac.recordLoginLog(c, user, "")
+	resp.OkWithData(c, gin.H{
+		"access_token":  result.Token,
+		"refresh_token": result.RefreshToken,
+		"token_type":    result.TokenType,
+		"expires_in":    result.ExpiresIn,
+		"user_info": gin.H{
+			"user_id":  user.UserID,
+			"username": user.Username,
+			"nickname": user.Nickname,
+			"avatar":   user.Avatar,
+			"email":    user.Email,
+		},
+	})
+}
+
+// recordLoginLog 记录邮件链接登录日志，failReason 为空表示登录成功；令牌无法对应到用户时不记录
+func (ac *AccountController) recordLoginLog(c *gin.Context, user *model.User, failReason string) {
+	if user == nil {
+		return
+	}
+	client := sessionClient(c)
+	loginLog := model.LoginLog{
+		UserID:      &user.UserID,
+		Username:    user.Username,
+		LoginType:   model.LoginTypeMagicLink,
+		LoginStatus: model.LoginStatusSuccess,
+		Action:      model.LoginActionLogin,
+		IPAddress:   client.IPAddress,
+		UserAgent:   client.UserAgent,
+		FailReason:  failReason,
+	}
+	if failReason != "" {
+		loginLog.LoginStatus = model.LoginStatusFailed
+	}
+
+	service.RecordLoginLog(loginLog)
+}
+
+// RegisterPublicRoutes 注册无需认证的路由
+func (ac *AccountController) RegisterPublicRoutes(router *gin.RouterGroup) {
+	router.POST("/email/verify/send", ac.RequestVerificationEmail)
+	router.POST("/email/verify", ac.VerifyEmail)
+	router.POST("/password/forgot", ac.ForgotPassword)
+	router.POST("/password/reset", ac.ResetPassword)
+	router.POST("/magic-link", ac.RequestMagicLink)
+	router.POST("/magic-link/consume", ac.ConsumeMagicLink)
+}
+
+// RegisterRoutes 注册用户路由
+func (ac *AccountController) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/email/verify/send", ac.SendVerificationEmail)
+}
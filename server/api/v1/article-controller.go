@@ -1,17 +1,25 @@
 package v1
 
 import (
+	"archive/zip"
+	"errors"
+	"io/fs"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/sunmoonstrand/go-react-blog/server/internal/logger"
 	"github.com/sunmoonstrand/go-react-blog/server/internal/middleware"
 	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
 	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/resp"
 )
 
+// articleImportMaxSize 导入压缩包的最大字节数
+const articleImportMaxSize = 256 << 20
+
 // ArticleController 文章控制器
 type ArticleController struct {
 	articleModel  *model.ArticleModel
@@ -30,45 +38,49 @@ func NewArticleController(articleModel *model.ArticleModel, categoryModel *model
 
 // CreateArticleRequest 创建文章请求结构
 type CreateArticleRequest struct {
-	Title         string `json:"title" binding:"required,min=2,max=200"`
-	ArticleKey    string `json:"article_key" binding:"required,min=2,max=200"`
-	Summary       string `json:"summary" binding:"max=500"`
-	Thumbnail     string `json:"thumbnail"`
-	Content       string `json:"content" binding:"required"`
-	ContentFormat uint8  `json:"content_format" binding:"required,oneof=1 2"`
-	Status        uint8  `json:"status" binding:"required,oneof=1 2 3 4"`
-	ArticleType   uint8  `json:"article_type" binding:"required,oneof=1 2 3 4"`
-	CategoryIDs   []uint `json:"category_ids" binding:"required,min=1"`
-	TagIDs        []uint `json:"tag_ids"`
-	IsTop         bool   `json:"is_top"`
-	IsRecommend   bool   `json:"is_recommend"`
-	AllowComment  bool   `json:"allow_comment"`
-	SEOTitle      string `json:"seo_title" binding:"max=100"`
-	SEOKeywords   string `json:"seo_keywords" binding:"max=200"`
-	SEODesc       string `json:"seo_description" binding:"max=300"`
-	SourceURL     string `json:"source_url" binding:"max=255"`
-	SourceName    string `json:"source_name" binding:"max=100"`
+	Title         string     `json:"title" binding:"required,min=2,max=200"`
+	ArticleKey    string     `json:"article_key" binding:"required,min=2,max=200"`
+	Summary       string     `json:"summary" binding:"max=500"`
+	Thumbnail     string     `json:"thumbnail"`
+	Content       string     `json:"content" binding:"required"`
+	ContentFormat uint8      `json:"content_format" binding:"required,oneof=1 2"`
+	Status        uint8      `json:"status" binding:"required,oneof=1 2 3 4"`
+	ArticleType   uint8      `json:"article_type" binding:"required,oneof=1 2 3 4"`
+	CategoryIDs   []uint     `json:"category_ids" binding:"required,min=1"`
+	TagIDs        []uint     `json:"tag_ids"`
+	IsTop         bool       `json:"is_top"`
+	IsRecommend   bool       `json:"is_recommend"`
+	AllowComment  bool       `json:"allow_comment"`
+	SEOTitle      string     `json:"seo_title" binding:"max=100"`
+	SEOKeywords   string     `json:"seo_keywords" binding:"max=200"`
+	SEODesc       string     `json:"seo_description" binding:"max=300"`
+	SourceURL     string     `json:"source_url" binding:"max=255"`
+	SourceName    string     `json:"source_name" binding:"max=100"`
+	ScheduleTime  *time.Time `json:"schedule_time"`
+	ExpireTime    *time.Time `json:"expire_time"`
 }
 
 // UpdateArticleRequest 更新文章请求结构
 type UpdateArticleRequest struct {
-	Title         string `json:"title" binding:"omitempty,min=2,max=200"`
-	Summary       string `json:"summary" binding:"max=500"`
-	Thumbnail     string `json:"thumbnail"`
-	Content       string `json:"content"`
-	ContentFormat uint8  `json:"content_format" binding:"omitempty,oneof=1 2"`
-	Status        uint8  `json:"status" binding:"omitempty,oneof=1 2 3 4"`
-	ArticleType   uint8  `json:"article_type" binding:"omitempty,oneof=1 2 3 4"`
-	CategoryIDs   []uint `json:"category_ids" binding:"omitempty,min=1"`
-	TagIDs        []uint `json:"tag_ids"`
-	IsTop         *bool  `json:"is_top"`
-	IsRecommend   *bool  `json:"is_recommend"`
-	AllowComment  *bool  `json:"allow_comment"`
-	SEOTitle      string `json:"seo_title" binding:"max=100"`
-	SEOKeywords   string `json:"seo_keywords" binding:"max=200"`
-	SEODesc       string `json:"seo_description" binding:"max=300"`
-	SourceURL     string `json:"source_url" binding:"max=255"`
-	SourceName    string `json:"source_name" binding:"max=100"`
+	Title         string     `json:"title" binding:"omitempty,min=2,max=200"`
+	Summary       string     `json:"summary" binding:"max=500"`
+	Thumbnail     string     `json:"thumbnail"`
+	Content       string     `json:"content"`
+	ContentFormat uint8      `json:"content_format" binding:"omitempty,oneof=1 2"`
+	Status        uint8      `json:"status" binding:"omitempty,oneof=1 2 3 4"`
+	ArticleType   uint8      `json:"article_type" binding:"omitempty,oneof=1 2 3 4"`
+	CategoryIDs   []uint     `json:"category_ids" binding:"omitempty,min=1"`
+	TagIDs        []uint     `json:"tag_ids"`
+	IsTop         *bool      `json:"is_top"`
+	IsRecommend   *bool      `json:"is_recommend"`
+	AllowComment  *bool      `json:"allow_comment"`
+	SEOTitle      string     `json:"seo_title" binding:"max=100"`
+	SEOKeywords   string     `json:"seo_keywords" binding:"max=200"`
+	SEODesc       string     `json:"seo_description" binding:"max=300"`
+	SourceURL     string     `json:"source_url" binding:"max=255"`
+	SourceName    string     `json:"source_name" binding:"max=100"`
+	ScheduleTime  *time.Time `json:"schedule_time"`
+	ExpireTime    *time.Time `json:"expire_time"`
 }
 
 // GetArticleList 获取文章列表
@@ -122,6 +134,7 @@ func (ac *ArticleController) GetArticleList(c *gin.Context) {
 // @Produce json
 // @Security ApiKeyAuth
 // @Param id path int true "文章ID"
+// @Param render query bool false "是否返回渲染后的HTML、目录、字数和阅读时长"
 // @Success 200 {object} resp.Response "返回文章详情"
 // @Failure 400 {object} resp.Response "请求参数错误"
 // @Failure 401 {object} resp.Response "未授权"
@@ -200,6 +213,20 @@ func (ac *ArticleController) GetArticleByID(c *gin.Context) {
 		"tags":            tags,
 	}
 
+	// 按需返回渲染结果
+	if c.Query("render") == "true" {
+		rendered, err := service.GetArticleRender(int(articleID))
+		if err != nil {
+			logger.Error("渲染文章内容失败", "article_id", articleID, "error", err)
+			resp.FailWithMsg(c, "获取文章详情失败")
+			return
+		}
+		respData["content_html"] = rendered.ContentHTML
+		respData["toc"] = rendered.TOC
+		respData["word_count"] = rendered.WordCount
+		respData["reading_time"] = rendered.ReadingTime
+	}
+
 	resp.OkWithData(c, respData)
 }
 
@@ -274,6 +301,23 @@ func (ac *ArticleController) CreateArticle(c *gin.Context) {
 		}
 	}
 
+	// 检查定时发布/下线时间
+	if err := service.ValidateArticleSchedule(req.ScheduleTime, req.ExpireTime); err != nil {
+		resp.FailWithMsg(c, err.Error())
+		return
+	}
+
+	// 没有发布权限的作者只能保存草稿或提交审核
+	if err := service.CheckArticleCreatable(userID.(int), tokenScopes(c), int8(req.Status), req.ScheduleTime, req.ExpireTime); err != nil {
+		if errors.Is(err, service.ErrArticlePublishDenied) {
+			resp.FailWithCode(c, http.StatusForbidden, err.Error())
+			return
+		}
+		logger.Error("检查文章发布权限失败", "user_id", userID, "error", err)
+		resp.FailWithMsg(c, "创建文章失败，请稍后重试")
+		return
+	}
+
 	// 创建文章
 	article := &model.Article{
 		UserID:         userID.(uint),
@@ -291,6 +335,8 @@ func (ac *ArticleController) CreateArticle(c *gin.Context) {
 		SEODescription: req.SEODesc,
 		SourceURL:      req.SourceURL,
 		SourceName:     req.SourceName,
+		ScheduleTime:   req.ScheduleTime,
+		ExpireTime:     req.ExpireTime,
 	}
 
 	// 创建文章内容
@@ -309,6 +355,20 @@ func (ac *ArticleController) CreateArticle(c *gin.Context) {
 		return
 	}
 
+	// 记录文章初始状态
+	if err := service.RecordArticleCreated(int64(articleID), int8(req.Status), userID.(int)); err != nil {
+		logger.Error("记录文章审核日志失败", "article_id", articleID, "error", err)
+	}
+
+	// 更新全文检索向量
+	if err := service.RefreshArticleSearchIndex(int64(articleID)); err != nil {
+		logger.Error("更新文章检索索引失败", "article_id", articleID, "error", err)
+	}
+
+	// 清除站点地图缓存，重新生成静态页面
+	service.InvalidateArticleSitemap(int64(articleID))
+	service.RebuildStaticArticle(int64(articleID), &model.StaticArticleRefs{})
+
 	resp.OkWithData(c, gin.H{
 		"article_id": articleID,
 		"message":    "创建文章成功",
@@ -378,6 +438,17 @@ func (ac *ArticleController) UpdateArticle(c *gin.Context) {
 		return
 	}
 
+	// 已发布或已排期的文章需要发布权限才能修改
+	if err := service.CheckArticleEditable(int(articleID), userID.(int), tokenScopes(c)); err != nil {
+		if errors.Is(err, service.ErrArticleLocked) {
+			resp.FailWithCode(c, http.StatusForbidden, err.Error())
+			return
+		}
+		logger.Error("检查文章是否可修改失败", "article_id", articleID, "error", err)
+		resp.FailWithMsg(c, "更新文章失败，请稍后重试")
+		return
+	}
+
 	// 检查分类是否存在
 	if len(req.CategoryIDs) > 0 {
 		for _, categoryID := range req.CategoryIDs {
@@ -421,9 +492,6 @@ func (ac *ArticleController) UpdateArticle(c *gin.Context) {
 	if req.Thumbnail != "" {
 		updates["thumbnail"] = req.Thumbnail
 	}
-	if req.Status != 0 {
-		updates["status"] = req.Status
-	}
 	if req.ArticleType != 0 {
 		updates["article_type"] = req.ArticleType
 	}
@@ -451,6 +519,28 @@ func (ac *ArticleController) UpdateArticle(c *gin.Context) {
 	if req.SourceName != "" {
 		updates["source_name"] = req.SourceName
 	}
+	if req.ScheduleTime != nil || req.ExpireTime != nil {
+		if err := service.ValidateArticleSchedule(req.ScheduleTime, req.ExpireTime); err != nil {
+			resp.FailWithMsg(c, err.Error())
+			return
+		}
+		// 设置定时任务需要发布权限
+		if err := service.CheckArticleCreatable(userID.(int), tokenScopes(c), 0, req.ScheduleTime, req.ExpireTime); err != nil {
+			if errors.Is(err, service.ErrArticlePublishDenied) {
+				resp.FailWithCode(c, http.StatusForbidden, err.Error())
+				return
+			}
+			logger.Error("检查文章发布权限失败", "user_id", userID, "error", err)
+			resp.FailWithMsg(c, "更新文章失败，请稍后重试")
+			return
+		}
+		if req.ScheduleTime != nil {
+			updates["schedule_time"] = *req.ScheduleTime
+		}
+		if req.ExpireTime != nil {
+			updates["expire_time"] = *req.ExpireTime
+		}
+	}
 
 	// 创建新的文章内容版本（如果内容有更新）
 	var newContent *model.ArticleContent
@@ -463,6 +553,9 @@ func (ac *ArticleController) UpdateArticle(c *gin.Context) {
 		}
 	}
 
+	// 记录变更前关联的静态页面
+	staticRefs := service.SnapshotStaticArticle(int64(articleID))
+
 	// 调用服务更新文章
 	err = ac.articleModel.UpdateArticle(uint(articleID), updates, newContent, req.CategoryIDs, req.TagIDs)
 	if err != nil {
@@ -471,6 +564,24 @@ func (ac *ArticleController) UpdateArticle(c *gin.Context) {
 		return
 	}
 
+	// 更新全文检索向量
+	if err := service.RefreshArticleSearchIndex(int64(articleID)); err != nil {
+		logger.Error("更新文章检索索引失败", "article_id", articleID, "error", err)
+	}
+
+	// 清除渲染缓存和站点地图缓存，重新生成静态页面
+	service.InvalidateArticleRender(int64(articleID))
+	service.InvalidateArticleSitemap(int64(articleID))
+	service.RebuildStaticArticle(int64(articleID), staticRefs)
+
+	// 状态变更走审核流程
+	if req.Status != 0 {
+		if err := service.ChangeArticleStatus(int(articleID), int8(req.Status), userID.(int), tokenScopes(c)); err != nil {
+			ac.handleReviewError(c, err, int(articleID), "文章已更新，但状态变更失败")
+			return
+		}
+	}
+
 	resp.OkWithMsg(c, "更新文章成功")
 }
 
@@ -530,6 +641,9 @@ func (ac *ArticleController) DeleteArticle(c *gin.Context) {
 		return
 	}
 
+	// 记录变更前关联的静态页面
+	staticRefs := service.SnapshotStaticArticle(int64(articleID))
+
 	// 调用服务删除文章
 	err = ac.articleModel.DeleteArticle(uint(articleID))
 	if err != nil {
@@ -538,6 +652,11 @@ func (ac *ArticleController) DeleteArticle(c *gin.Context) {
 		return
 	}
 
+	// 清除渲染缓存和站点地图缓存，重新生成静态页面
+	service.InvalidateArticleRender(int64(articleID))
+	service.InvalidateArticleSitemap(int64(articleID))
+	service.RebuildStaticArticle(int64(articleID), staticRefs)
+
 	resp.OkWithMsg(c, "删除文章成功")
 }
 
@@ -591,32 +710,9 @@ func (ac *ArticleController) ChangeArticleStatus(c *gin.Context) {
 		return
 	}
 
-	// 检查权限
-	isAdmin, _ := c.Get("is_admin")
-	isAuthor, err := ac.articleModel.CheckIsAuthor(uint(articleID), userID.(uint))
-	if err != nil {
-		logger.Error("检查文章作者失败", "article_id", articleID, "user_id", userID, "error", err)
-		resp.FailWithMsg(c, "修改文章状态失败，请稍后重试")
-		return
-	}
-
-	// 发布和下线操作需要管理员权限，作者只能将文章设为草稿或提交审核
-	if (status == 3 || status == 4) && !isAdmin.(bool) {
-		resp.FailWithCode(c, http.StatusForbidden, "只有管理员可以发布或下线文章")
-		return
-	}
-
-	// 作者只能修改自己的文章
-	if !isAdmin.(bool) && !isAuthor {
-		resp.FailWithCode(c, http.StatusForbidden, "只能修改自己的文章")
-		return
-	}
-
-	// 调用服务修改文章状态
-	err = ac.articleModel.UpdateArticleStatus(uint(articleID), uint8(status))
-	if err != nil {
-		logger.Error("修改文章状态失败", "article_id", articleID, "status", status, "error", err)
-		resp.FailWithMsg(c, "修改文章状态失败，请稍后重试")
+	// 按审核流程变更状态，没有发布权限的作者只能保存草稿或提交审核
+	if err := service.ChangeArticleStatus(int(articleID), int8(status), userID.(int), tokenScopes(c)); err != nil {
+		ac.handleReviewError(c, err, int(articleID), "修改文章状态失败，请稍后重试")
 		return
 	}
 
@@ -638,12 +734,13 @@ func (ac *ArticleController) ChangeArticleStatus(c *gin.Context) {
 
 // GetArticleContent 获取文章内容
 // @Summary 获取文章内容
-// @Description 获取文章内容
+// @Description 获取文章内容，render=true时同时返回过滤后的HTML、目录、字数和阅读时长
 // @Tags 文章管理
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param id path int true "文章ID"
+// @Param render query bool false "是否返回渲染结果"
 // @Success 200 {object} resp.Response "返回文章内容"
 // @Failure 400 {object} resp.Response "请求参数错误"
 // @Failure 401 {object} resp.Response "未授权"
@@ -679,14 +776,766 @@ func (ac *ArticleController) GetArticleContent(c *gin.Context) {
 		return
 	}
 
-	resp.OkWithData(c, gin.H{
+	respData := gin.H{
 		"content":        content.Content,
 		"content_format": content.ContentFormat,
 		"version":        content.Version,
 		"updated_at":     content.UpdatedAt,
+	}
+
+	// 按需返回渲染结果
+	if c.Query("render") == "true" {
+		rendered, err := service.GetArticleRender(int(articleID))
+		if err != nil {
+			logger.Error("渲染文章内容失败", "article_id", articleID, "error", err)
+			resp.FailWithMsg(c, "获取文章内容失败")
+			return
+		}
+		respData["content_html"] = rendered.ContentHTML
+		respData["toc"] = rendered.TOC
+		respData["word_count"] = rendered.WordCount
+		respData["reading_time"] = rendered.ReadingTime
+	}
+
+	resp.OkWithData(c, respData)
+}
+
+// ListArticleVersions 获取文章内容版本列表
+// @Summary 获取文章版本列表
+// @Description 获取文章所有内容版本（不含正文）
+// @Tags 文章管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "文章ID"
+// @Success 200 {object} resp.Response "返回版本列表"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 404 {object} resp.Response "文章不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/article/{id}/versions [get]
+func (ac *ArticleController) ListArticleVersions(c *gin.Context) {
+	// 获取路径参数
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		resp.FailWithMsg(c, "无效的文章ID")
+		return
+	}
+
+	versions, err := service.ListArticleVersions(articleID)
+	if err != nil {
+		if errors.Is(err, service.ErrArticleNotFound) {
+			resp.FailWithCode(c, http.StatusNotFound, "文章不存在")
+		} else {
+			logger.Error("获取文章版本列表失败", "article_id", articleID, "error", err)
+			resp.FailWithMsg(c, "获取文章版本列表失败")
+		}
+		return
+	}
+
+	resp.OkWithData(c, versions)
+}
+
+// GetArticleVersion 获取文章指定版本内容
+// @Summary 获取文章版本内容
+// @Description 获取文章指定版本的完整内容
+// @Tags 文章管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "文章ID"
+// @Param version path int true "版本号"
+// @Success 200 {object} resp.Response "返回版本内容"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 404 {object} resp.Response "版本不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/article/{id}/versions/{version} [get]
+func (ac *ArticleController) GetArticleVersion(c *gin.Context) {
+	// 获取路径参数
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		resp.FailWithMsg(c, "无效的文章ID")
+		return
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		resp.FailWithMsg(c, "无效的版本号")
+		return
+	}
+
+	content, err := service.GetArticleVersion(articleID, version)
+	if err != nil {
+		if errors.Is(err, service.ErrArticleVersionNotFound) {
+			resp.FailWithCode(c, http.StatusNotFound, "文章版本不存在")
+		} else {
+			logger.Error("获取文章版本失败", "article_id", articleID, "version", version, "error", err)
+			resp.FailWithMsg(c, "获取文章版本失败")
+		}
+		return
+	}
+
+	resp.OkWithData(c, content)
+}
+
+// DiffArticleVersions 比较文章两个版本
+// @Summary 比较文章版本
+// @Description 返回两个版本之间的行级差异
+// @Tags 文章管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "文章ID"
+// @Param from query int true "起始版本号"
+// @Param to query int true "目标版本号"
+// @Success 200 {object} resp.Response "返回差异结果"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 404 {object} resp.Response "版本不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/article/{id}/versions/diff [get]
+func (ac *ArticleController) DiffArticleVersions(c *gin.Context) {
+	// 获取路径参数
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		resp.FailWithMsg(c, "无效的文章ID")
+		return
+	}
+
+	// 获取版本参数
+	fromVersion, err := strconv.Atoi(c.Query("from"))
+	if err != nil || fromVersion < 1 {
+		resp.FailWithMsg(c, "无效的起始版本号")
+		return
+	}
+	toVersion, err := strconv.Atoi(c.Query("to"))
+	if err != nil || toVersion < 1 {
+		resp.FailWithMsg(c, "无效的目标版本号")
+		return
+	}
+
+	result, err := service.DiffArticleVersions(articleID, fromVersion, toVersion)
+	if err != nil {
+		if errors.Is(err, service.ErrArticleVersionNotFound) {
+			resp.FailWithCode(c, http.StatusNotFound, "文章版本不存在")
+		} else {
+			logger.Error("比较文章版本失败", "article_id", articleID, "from", fromVersion, "to", toVersion, "error", err)
+			resp.FailWithMsg(c, "比较文章版本失败")
+		}
+		return
+	}
+
+	resp.OkWithData(c, result)
+}
+
+// RestoreArticleVersion 恢复文章历史版本
+// @Summary 恢复文章版本
+// @Description 将指定历史版本复制为新的当前版本
+// @Tags 文章管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "文章ID"
+// @Param version path int true "版本号"
+// @Success 200 {object} resp.Response "恢复成功"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 404 {object} resp.Response "版本不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/article/{id}/versions/{version}/restore [post]
+func (ac *ArticleController) RestoreArticleVersion(c *gin.Context) {
+	// 获取路径参数
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		resp.FailWithMsg(c, "无效的文章ID")
+		return
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		resp.FailWithMsg(c, "无效的版本号")
+		return
+	}
+
+	// 获取当前用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	restored, err := service.RestoreArticleVersion(articleID, version, userID.(int))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrArticleNotFound):
+			resp.FailWithCode(c, http.StatusNotFound, "文章不存在")
+		case errors.Is(err, service.ErrArticleVersionNotFound):
+			resp.FailWithCode(c, http.StatusNotFound, "文章版本不存在")
+		case errors.Is(err, service.ErrArticleVersionCurrent):
+			resp.FailWithMsg(c, "该版本已是当前版本")
+		default:
+			logger.Error("恢复文章版本失败", "article_id", articleID, "version", version, "error", err)
+			resp.FailWithMsg(c, "恢复文章版本失败，请稍后重试")
+		}
+		return
+	}
+
+	resp.OkWithData(c, gin.H{
+		"version": restored.Version,
+		"message": "恢复文章版本成功",
 	})
 }
 
+// SetArticleSchedule 设置文章定时发布/下线
+// @Summary 设置文章定时发布/下线
+// @Description 设置文章的定时发布时间和定时下线时间，时间为空表示取消对应的定时任务
+// @Tags 文章管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "文章ID"
+// @Param data body model.ArticleScheduleForm true "定时信息"
+// @Success 200 {object} resp.Response "设置成功"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 404 {object} resp.Response "文章不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/article/{id}/schedule [put]
+func (ac *ArticleController) SetArticleSchedule(c *gin.Context) {
+	// 获取路径参数
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		resp.FailWithMsg(c, "无效的文章ID")
+		return
+	}
+
+	var form model.ArticleScheduleForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	// 获取当前用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := service.SetArticleSchedule(articleID, form, userID.(int), tokenScopes(c)); err != nil {
+		switch {
+		case errors.Is(err, service.ErrArticleNotFound):
+			resp.FailWithCode(c, http.StatusNotFound, "文章不存在")
+		case errors.Is(err, service.ErrArticlePublishDenied):
+			resp.FailWithCode(c, http.StatusForbidden, err.Error())
+		case errors.Is(err, service.ErrScheduleTimeInPast),
+			errors.Is(err, service.ErrExpireTimeInPast),
+			errors.Is(err, service.ErrExpireBeforePublish):
+			resp.FailWithMsg(c, err.Error())
+		default:
+			logger.Error("设置文章定时任务失败", "article_id", articleID, "error", err)
+			resp.FailWithMsg(c, err.Error())
+		}
+		return
+	}
+
+	resp.OkWithMsg(c, "设置文章定时任务成功")
+}
+
+// ListScheduledArticles 获取定时任务文章列表
+// @Summary 获取定时任务文章列表
+// @Description 分页获取设置了定时发布或定时下线的文章，按最近执行时间排序
+// @Tags 文章管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param page query int false "页码，默认1" default(1)
+// @Param page_size query int false "每页记录数，默认10" default(10)
+// @Success 200 {object} resp.Response{data=resp.PageResult} "返回文章列表"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/article/scheduled [get]
+func (ac *ArticleController) ListScheduledArticles(c *gin.Context) {
+	// 获取分页参数
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	result, err := service.ListScheduledArticles(page, pageSize)
+	if err != nil {
+		logger.Error("获取定时任务文章列表失败", "error", err)
+		resp.FailWithMsg(c, "获取定时任务文章列表失败")
+		return
+	}
+
+	resp.OkWithData(c, result)
+}
+
+// SubmitArticle 提交文章审核
+// @Summary 提交文章审核
+// @Description 作者将草稿或已下线的文章提交审核，重新提交时清空上次的驳回原因
+// @Tags 文章审核
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "文章ID"
+// @Success 200 {object} resp.Response "提交成功"
+// @Failure 400 {object} resp.Response "当前状态不允许提交"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 404 {object} resp.Response "文章不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/article/{id}/submit [post]
+func (ac *ArticleController) SubmitArticle(c *gin.Context) {
+	articleID, userID, ok := ac.reviewParams(c)
+	if !ok {
+		return
+	}
+
+	if err := service.SubmitArticle(articleID, userID, tokenScopes(c)); err != nil {
+		ac.handleReviewError(c, err, articleID, "提交审核失败，请稍后重试")
+		return
+	}
+
+	resp.OkWithMsg(c, "文章已提交审核")
+}
+
+// WithdrawArticle 撤回文章审核
+// @Summary 撤回文章审核
+// @Description 作者撤回待审核的文章，或将已下线的文章转为草稿
+// @Tags 文章审核
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "文章ID"
+// @Success 200 {object} resp.Response "撤回成功"
+// @Failure 400 {object} resp.Response "当前状态不允许撤回"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 404 {object} resp.Response "文章不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/article/{id}/withdraw [post]
+func (ac *ArticleController) WithdrawArticle(c *gin.Context) {
+	articleID, userID, ok := ac.reviewParams(c)
+	if !ok {
+		return
+	}
+
+	if err := service.WithdrawArticle(articleID, userID, tokenScopes(c)); err != nil {
+		ac.handleReviewError(c, err, articleID, "撤回文章失败，请稍后重试")
+		return
+	}
+
+	resp.OkWithMsg(c, "文章已撤回为草稿")
+}
+
+// ApproveArticle 审核通过文章
+// @Summary 审核通过文章
+// @Description 审核员通过待审核的文章并立即发布
+// @Tags 文章审核
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "文章ID"
+// @Param data body model.ArticleReviewForm false "审核备注"
+// @Success 200 {object} resp.Response "审核通过"
+// @Failure 400 {object} resp.Response "当前状态不允许审核"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 404 {object} resp.Response "文章不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/article/{id}/approve [post]
+func (ac *ArticleController) ApproveArticle(c *gin.Context) {
+	articleID, userID, ok := ac.reviewParams(c)
+	if !ok {
+		return
+	}
+
+	// 审核备注可选
+	var form model.ArticleReviewForm
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&form); err != nil {
+			resp.FailWithValidation(c, err)
+			return
+		}
+	}
+
+	if err := service.ApproveArticle(articleID, userID, tokenScopes(c), form.Reason); err != nil {
+		ac.handleReviewError(c, err, articleID, "审核文章失败，请稍后重试")
+		return
+	}
+
+	resp.OkWithMsg(c, "文章已审核通过并发布")
+}
+
+// RejectArticle 驳回文章
+// @Summary 驳回文章
+// @Description 审核员驳回待审核的文章，文章退回草稿并保存驳回原因
+// @Tags 文章审核
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "文章ID"
+// @Param data body model.ArticleReviewForm true "驳回原因"
+// @Success 200 {object} resp.Response "驳回成功"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 404 {object} resp.Response "文章不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/article/{id}/reject [post]
+func (ac *ArticleController) RejectArticle(c *gin.Context) {
+	articleID, userID, ok := ac.reviewParams(c)
+	if !ok {
+		return
+	}
+
+	var form model.ArticleReviewForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	if err := service.RejectArticle(articleID, userID, tokenScopes(c), form.Reason); err != nil {
+		ac.handleReviewError(c, err, articleID, "驳回文章失败，请稍后重试")
+		return
+	}
+
+	resp.OkWithMsg(c, "文章已驳回")
+}
+
+// GetReviewQueue 获取待审核文章队列
+// @Summary 获取待审核文章队列
+// @Description 分页获取待审核的文章，按提交时间先后排序
+// @Tags 文章审核
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param page query int false "页码，默认1" default(1)
+// @Param page_size query int false "每页记录数，默认10" default(10)
+// @Success 200 {object} resp.Response{data=resp.PageResult} "返回待审核文章列表"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/article/review/queue [get]
+func (ac *ArticleController) GetReviewQueue(c *gin.Context) {
+	// 获取分页参数
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	result, err := service.ListReviewQueue(page, pageSize)
+	if err != nil {
+		logger.Error("获取待审核文章列表失败", "error", err)
+		resp.FailWithMsg(c, "获取待审核文章列表失败")
+		return
+	}
+
+	resp.OkWithData(c, result)
+}
+
+// ListArticleReviews 获取文章审核记录
+// @Summary 获取文章审核记录
+// @Description 获取文章每一次状态流转的记录，按时间倒序
+// @Tags 文章审核
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "文章ID"
+// @Success 200 {object} resp.Response{data=[]model.ArticleReviewResponse} "返回审核记录"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 404 {object} resp.Response "文章不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/article/{id}/reviews [get]
+func (ac *ArticleController) ListArticleReviews(c *gin.Context) {
+	// 获取路径参数
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		resp.FailWithMsg(c, "无效的文章ID")
+		return
+	}
+
+	reviews, err := service.ListArticleReviews(articleID)
+	if err != nil {
+		if errors.Is(err, service.ErrArticleNotFound) {
+			resp.FailWithCode(c, http.StatusNotFound, "文章不存在")
+			return
+		}
+		logger.Error("获取文章审核记录失败", "article_id", articleID, "error", err)
+		resp.FailWithMsg(c, "获取文章审核记录失败")
+		return
+	}
+
+	resp.OkWithData(c, reviews)
+}
+
+// reviewParams 解析审核接口的文章ID和当前用户ID
+func (ac *ArticleController) reviewParams(c *gin.Context) (int, int, bool) {
+	articleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		resp.FailWithMsg(c, "无效的文章ID")
+		return 0, 0, false
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return 0, 0, false
+	}
+
+	return articleID, userID.(int), true
+}
+
+// handleReviewError 将审核流程错误转换为响应
+func (ac *ArticleController) handleReviewError(c *gin.Context, err error, articleID int, failMsg string) {
+	switch {
+	case errors.Is(err, service.ErrArticleNotFound):
+		resp.FailWithCode(c, http.StatusNotFound, "文章不存在")
+	case errors.Is(err, service.ErrArticleForbidden),
+		errors.Is(err, service.ErrArticlePublishDenied):
+		resp.FailWithCode(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrArticleStatusTransition),
+		errors.Is(err, service.ErrRejectReasonRequired):
+		resp.FailWithMsg(c, err.Error())
+	default:
+		logger.Error("文章审核操作失败", "article_id", articleID, "error", err)
+		resp.FailWithMsg(c, failMsg)
+	}
+}
+
+// SearchArticles 搜索文章
+// @Summary 搜索文章
+// @Description 按标题、摘要和正文全文搜索已发布文章，按相关度排序并返回高亮摘要，标题支持拼写容错
+// @Tags 文章
+// @Accept json
+// @Produce json
+// @Param keyword query string true "搜索关键词"
+// @Param page query int false "页码，默认1" default(1)
+// @Param page_size query int false "每页记录数，默认10" default(10)
+// @Param category_id query int false "分类ID筛选（包含子分类）"
+// @Param tag_id query int false "标签ID筛选"
+// @Param user_id query int false "作者ID筛选"
+// @Param article_type query int false "文章类型筛选"
+// @Param start_time query string false "发布开始日期，格式YYYY-MM-DD"
+// @Param end_time query string false "发布结束日期，格式YYYY-MM-DD"
+// @Success 200 {object} resp.Response{data=resp.PageResult} "返回搜索结果"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /api/v1/article/search [get]
+func (ac *ArticleController) SearchArticles(c *gin.Context) {
+	params := model.ArticleQueryParams{Page: 1, PageSize: 10}
+	if err := c.ShouldBindQuery(&params); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	result, err := service.SearchArticles(params)
+	if err != nil {
+		if errors.Is(err, service.ErrSearchKeywordEmpty) || errors.Is(err, service.ErrSearchTimeInvalid) {
+			resp.FailWithMsg(c, err.Error())
+			return
+		}
+		logger.Error("搜索文章失败", "keyword", params.Keyword, "error", err)
+		resp.FailWithMsg(c, "搜索文章失败，请稍后重试")
+		return
+	}
+
+	resp.OkWithData(c, result)
+}
+
+// RebuildSearchIndex 重建文章搜索索引
+// @Summary 重建文章搜索索引
+// @Description 使用当前分词词典重新生成全部文章的全文检索向量，修改用户词典后需要执行
+// @Tags 文章管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} resp.Response{data=map[string]int} "返回重建的文章数"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/article/search/reindex [post]
+func (ac *ArticleController) RebuildSearchIndex(c *gin.Context) {
+	count, err := service.RebuildArticleSearchIndex()
+	if err != nil {
+		logger.Error("重建文章搜索索引失败", "count", count, "error", err)
+		resp.FailWithMsg(c, "重建文章搜索索引失败")
+		return
+	}
+
+	resp.OkWithData(c, gin.H{"count": count})
+}
+
+// ImportArticles 导入Markdown文章
+// @Summary 导入Markdown文章
+// @Description 上传 Hexo、Hugo、Jekyll 的Markdown文章压缩包（zip），支持YAML、TOML、JSON前置元数据，自动创建分类和标签并上传引用的图片；dry_run为true时只检查文章标识冲突等问题，不写入数据
+// @Tags 文章管理
+// @Accept multipart/form-data
+// @Produce json
+// @Security ApiKeyAuth
+// @Param file formData file true "Markdown文章压缩包"
+// @Param dry_run formData bool false "只预检不导入"
+// @Param on_conflict formData string false "文章标识冲突时的处理方式：skip跳过，rename追加序号" Enums(skip, rename)
+// @Success 200 {object} resp.Response{data=model.ImportResult} "返回导入结果"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/article/import [post]
+func (ac *ArticleController) ImportArticles(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, articleImportMaxSize)
+
+	var opts model.ImportOptions
+	if err := c.ShouldBind(&opts); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		resp.FailWithMsg(c, "未找到上传文件")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		logger.Error("读取导入文件失败", "error", err)
+		resp.FailWithMsg(c, "读取导入文件失败")
+		return
+	}
+	defer file.Close()
+
+	// 获取当前用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	result, err := service.ImportMarkdownArchive(file, header.Size, userID.(int), tokenScopes(c), opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrImportArchiveInvalid),
+			errors.Is(err, service.ErrImportTooManyFiles),
+			errors.Is(err, service.ErrImportNoMarkdown):
+			resp.FailWithMsg(c, err.Error())
+		default:
+			logger.Error("导入文章失败", "error", err)
+			resp.FailWithMsg(c, "导入文章失败，请稍后重试")
+		}
+		return
+	}
+
+	resp.OkWithData(c, result)
+}
+
+// ImportWordPress 导入WordPress文章
+// @Summary 导入WordPress文章
+// @Description 上传 WordPress 导出的WXR文件（xml），导入文章、分类树、标签和评论，作者和评论者对应到已有用户或创建未激活的用户；可同时上传 wp-content/uploads 目录的压缩包（zip），附件和正文引用的上传文件将保存到本站；dry_run为true时只检查文章标识冲突等问题，不写入数据
+// @Tags 文章管理
+// @Accept multipart/form-data
+// @Produce json
+// @Security ApiKeyAuth
+// @Param file formData file true "WordPress导出文件"
+// @Param uploads formData file false "上传目录压缩包"
+// @Param author_map formData string false "作者对应关系，格式为 登录名=用户ID，多个以逗号分隔"
+// @Param dry_run formData bool false "只预检不导入"
+// @Param on_conflict formData string false "文章标识冲突时的处理方式：skip跳过，rename追加序号" Enums(skip, rename)
+// @Success 200 {object} resp.Response{data=model.ImportResult} "返回导入结果"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/article/import/wordpress [post]
+func (ac *ArticleController) ImportWordPress(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, articleImportMaxSize)
+
+	var opts model.ImportOptions
+	if err := c.ShouldBind(&opts); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+	authorMap, err := service.ParseImportAuthorMap(c.PostForm("author_map"))
+	if err != nil {
+		resp.FailWithMsg(c, err.Error())
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		resp.FailWithMsg(c, "未找到上传文件")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		logger.Error("读取导入文件失败", "error", err)
+		resp.FailWithMsg(c, "读取导入文件失败")
+		return
+	}
+	defer file.Close()
+
+	// 上传目录压缩包可选
+	var uploads fs.FS
+	if uploadsHeader, err := c.FormFile("uploads"); err == nil {
+		uploadsFile, err := uploadsHeader.Open()
+		if err != nil {
+			logger.Error("读取上传目录压缩包失败", "error", err)
+			resp.FailWithMsg(c, "读取上传目录压缩包失败")
+			return
+		}
+		defer uploadsFile.Close()
+		zr, err := zip.NewReader(uploadsFile, uploadsHeader.Size)
+		if err != nil {
+			resp.FailWithMsg(c, service.ErrImportArchiveInvalid.Error())
+			return
+		}
+		uploads = zr
+	}
+
+	// 获取当前用户ID
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	result, err := service.ImportWordPress(file, uploads, userID.(int), tokenScopes(c), opts, authorMap)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrWordPressExportInvalid),
+			errors.Is(err, service.ErrAuthorMapUserNotFound):
+			resp.FailWithMsg(c, err.Error())
+		default:
+			logger.Error("导入WordPress文章失败", "error", err)
+			resp.FailWithMsg(c, "导入文章失败，请稍后重试")
+		}
+		return
+	}
+
+	resp.OkWithData(c, result)
+}
+
+// RegisterPublicRoutes 注册公开路由
+func (ac *ArticleController) RegisterPublicRoutes(router *gin.RouterGroup) {
+	// 文章搜索
+	router.GET("/search", ac.SearchArticles)
+}
+
 // RegisterRoutes 注册路由
 func (ac *ArticleController) RegisterRoutes(router *gin.RouterGroup) {
 	articleGroup := router.Group("/articles")
@@ -713,3 +1562,34 @@ func (ac *ArticleController) RegisterRoutes(router *gin.RouterGroup) {
 		articleGroup.PUT("/:id/status", middleware.RequirePermission("content:article:edit"), ac.ChangeArticleStatus)
 	}
 }
+
+// RegisterAdminRoutes 注册后台管理路由
+func (ac *ArticleController) RegisterAdminRoutes(router *gin.RouterGroup) {
+	// 文章版本历史
+	versionGroup := router.Group("/:id/versions")
+	{
+		versionGroup.GET("", middleware.RequirePermission("content:article:info"), ac.ListArticleVersions)
+		versionGroup.GET("/diff", middleware.RequirePermission("content:article:info"), ac.DiffArticleVersions)
+		versionGroup.GET("/:version", middleware.RequirePermission("content:article:info"), ac.GetArticleVersion)
+		versionGroup.POST("/:version/restore", middleware.RequirePermission("content:article:edit"), ac.RestoreArticleVersion)
+	}
+
+	// 定时发布/下线
+	router.GET("/scheduled", middleware.RequirePermission("content:article:list"), ac.ListScheduledArticles)
+	router.PUT("/:id/schedule", middleware.RequirePermission("content:article:edit"), ac.SetArticleSchedule)
+
+	// 审核流程
+	router.GET("/review/queue", middleware.RequirePermission("content:article:publish"), ac.GetReviewQueue)
+	router.GET("/:id/reviews", middleware.RequirePermission("content:article:info"), ac.ListArticleReviews)
+	router.POST("/:id/submit", middleware.RequirePermission("content:article:edit"), ac.SubmitArticle)
+	router.POST("/:id/withdraw", middleware.RequirePermission("content:article:edit"), ac.WithdrawArticle)
+	router.POST("/:id/approve", middleware.RequirePermission("content:article:publish"), ac.ApproveArticle)
+	router.POST("/:id/reject", middleware.RequirePermission("content:article:publish"), ac.RejectArticle)
+
+	// 导入
+	router.POST("/import", middleware.RequirePermission("content:article:add"), ac.ImportArticles)
+	router.POST("/import/wordpress", middleware.RequirePermission("content:article:add"), ac.ImportWordPress)
+
+	// 搜索索引
+	router.POST("/search/reindex", middleware.RequirePermission("content:article:edit"), ac.RebuildSearchIndex)
+}
@@ -1,31 +1,30 @@
 package v1
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
 
-	"github.com/sunmoonstrand/go-react-blog/server/internal/auth"
 	"github.com/sunmoonstrand/go-react-blog/server/internal/logger"
 	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
 	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/resp"
 )
 
 // AuthController 认证控制器
 type AuthController struct {
-	userModel     *model.UserModel
-	loginLogModel *model.LoginLogModel
-	jwtService    *auth.JWTService
+	userModel *model.UserModel
 }
 
 // NewAuthController 创建认证控制器实例
-func NewAuthController(userModel *model.UserModel, loginLogModel *model.LoginLogModel, jwtService *auth.JWTService) *AuthController {
+func NewAuthController(userModel *model.UserModel) *AuthController {
 	return &AuthController{
-		userModel:     userModel,
-		loginLogModel: loginLogModel,
-		jwtService:    jwtService,
+		userModel: userModel,
 	}
 }
 
@@ -51,7 +50,9 @@ type RefreshTokenRequest struct {
 
 // Login 用户登录
 // @Summary 用户登录
-// @Description 使用用户名和密码登录系统
+// @Description 使用用户名和密码登录系统。同一用户名或IP连续登录失败后需要等待逐次加长的时间才能再次尝试，达到上限时账号或IP被临时锁定，
+// @Description 此时返回423（锁定）或429（尝试过于频繁），并通过 Retry-After 响应头给出可以再次尝试的秒数。
+// @Description 启用了两步验证或所属角色要求两步验证时不签发令牌，返回 mfa_required 和临时令牌，需调用 /auth/mfa/verify 或 /auth/mfa/enroll 完成登录
 // @Tags 认证管理
 // @Accept json
 // @Produce json
@@ -59,6 +60,9 @@ type RefreshTokenRequest struct {
 // @Success 200 {object} resp.Response 成功返回用户信息和token
 // @Failure 400 {object} resp.Response 请求参数错误
 // @Failure 401 {object} resp.Response 用户名或密码错误
+// @Failure 403 {object} resp.Response 系统要求验证邮箱，邮箱尚未验证
+// @Failure 423 {object} resp.Response 账号或IP已被临时锁定
+// @Failure 429 {object} resp.Response 登录尝试过于频繁
 // @Failure 500 {object} resp.Response 服务器内部错误
 // @Router /api/v1/auth/login [post]
 func (ac *AuthController) Login(c *gin.Context) {
@@ -68,18 +72,34 @@ func (ac *AuthController) Login(c *gin.Context) {
 		return
 	}
 
+	// 检查账号和IP是否因登录失败过多被限制
+	if err := service.CheckLoginAllowed(loginReq.Username, c.ClientIP()); err != nil {
+		var limitErr *service.LoginLimitError
+		if errors.As(err, &limitErr) {
+			ac.recordLoginLog(c, model.LoginActionLogin, nil, loginReq.Username, "登录受限")
+			failLoginLimited(c, limitErr)
+			return
+		}
+		// 防护检查出错时不阻止登录
+		logger.Error("检查登录限制失败", "username", loginReq.Username, "error", err)
+	}
+
 	// 获取用户信息
 	user, err := ac.userModel.GetUserByUsername(loginReq.Username)
 	if err != nil {
 		logger.Error("用户登录失败", "username", loginReq.Username, "error", err)
-		resp.FailWithMsg(c, "用户名或密码错误")
+		// 记录登录日志
+		ac.recordLoginLog(c, model.LoginActionLogin, nil, loginReq.Username, "用户不存在")
+		ac.failLogin(c, loginReq.Username)
 		return
 	}
 
+	userID := int(user.ID)
+
 	// 检查用户状态
 	if user.Status != 1 {
 		// 记录登录日志
-		go ac.recordLoginLog(user.ID, loginReq.Username, 2, 2, c, "账号状态异常")
+		ac.recordLoginLog(c, model.LoginActionLogin, &userID, loginReq.Username, "账号状态异常")
 		resp.FailWithMsg(c, "账号已被禁用或未激活")
 		return
 	}
@@ -87,13 +107,43 @@ func (ac *AuthController) Login(c *gin.Context) {
 	// 验证密码
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(loginReq.Password)); err != nil {
 		// 记录登录日志
-		go ac.recordLoginLog(user.ID, loginReq.Username, 1, 2, c, "密码错误")
-		resp.FailWithMsg(c, "用户名或密码错误")
+		ac.recordLoginLog(c, model.LoginActionLogin, &userID, loginReq.Username, "密码错误")
+		ac.failLogin(c, loginReq.Username)
+		return
+	}
+
+	// 系统要求验证邮箱时，未验证的账号不能登录
+	if err := service.CheckEmailVerified(userID); err != nil {
+		if errors.Is(err, service.ErrEmailNotVerified) {
+			ac.recordLoginLog(c, model.LoginActionLogin, &userID, loginReq.Username, "邮箱未验证")
+			resp.FailWithCode(c, http.StatusForbidden, err.Error())
+			return
+		}
+		logger.Error("检查邮箱验证状态失败", "user_id", userID, "error", err)
+		resp.FailWithMsg(c, "登录失败，请稍后重试")
+		return
+	}
+
+	// 需要两步验证时只返回临时令牌，完成第二步后才签发令牌
+	challenge, err := service.BeginMFA(userID, user.Username)
+	if err != nil {
+		logger.Error("检查两步验证失败", "user_id", userID, "error", err)
+		resp.FailWithMsg(c, "登录失败，请稍后重试")
+		return
+	}
+	if challenge != nil {
+		resp.OkWithData(c, gin.H{
+			"mfa_required": true,
+			"mfa":          challenge,
+		})
 		return
 	}
 
-	// 生成JWT令牌
-	token, refreshToken, err := ac.jwtService.GenerateTokens(user.ID, user.Username)
+	// 登录成功，清除失败记录
+	service.ResetLoginFailures(loginReq.Username)
+
+	// 签发访问令牌和刷新令牌
+	tokens, err := service.IssueTokens(userID, user.Username, sessionClient(c))
 	if err != nil {
 		logger.Error("生成JWT令牌失败", "user_id", user.ID, "error", err)
 		resp.FailWithMsg(c, "登录失败，请稍后重试")
@@ -104,13 +154,14 @@ func (ac *AuthController) Login(c *gin.Context) {
 	go ac.userModel.UpdateLoginInfo(user.ID)
 
 	// 记录登录日志
-	go ac.recordLoginLog(user.ID, user.Username, 1, 1, c, "")
+	ac.recordLoginLog(c, model.LoginActionLogin, &userID, user.Username, "")
 
 	// 返回登录成功信息
 	resp.OkWithData(c, gin.H{
-		"access_token":  token,
-		"refresh_token": refreshToken,
-		"token_type":    "Bearer",
+		"access_token":  tokens.Token,
+		"refresh_token": tokens.RefreshToken,
+		"token_type":    tokens.TokenType,
+		"expires_in":    tokens.ExpiresIn,
 		"user_info": gin.H{
 			"user_id":  user.ID,
 			"username": user.Username,
@@ -123,7 +174,7 @@ func (ac *AuthController) Login(c *gin.Context) {
 
 // Register 用户注册
 // @Summary 用户注册
-// @Description 注册新用户
+// @Description 注册新用户，注册后向填写的邮箱发送验证邮件
 // @Tags 认证管理
 // @Accept json
 // @Produce json
@@ -195,19 +246,26 @@ func (ac *AuthController) Register(c *gin.Context) {
 		// 这里不返回错误，因为用户已创建成功，角色分配失败可以后续修复
 	}
 
+	// 发送邮箱验证邮件，发送失败时用户可以稍后重新发送
+	go func(userID int) {
+		if err := service.SendVerificationEmail(userID); err != nil && !errors.Is(err, service.ErrMailDisabled) {
+			logger.Error("发送验证邮件失败", "user_id", userID, "error", err)
+		}
+	}(int(user.ID))
+
 	resp.OkWithMsg(c, "注册成功")
 }
 
 // RefreshToken 刷新令牌
 // @Summary 刷新令牌
-// @Description 使用refresh_token刷新访问令牌
+// @Description 使用refresh_token换取新的访问令牌和刷新令牌，旧刷新令牌随即失效；已使用过的刷新令牌再次提交时视为泄露，该次登录签发的全部令牌将被吊销
 // @Tags 认证管理
 // @Accept json
 // @Produce json
 // @Param data body RefreshTokenRequest true "刷新令牌"
 // @Success 200 {object} resp.Response 刷新成功，返回新的令牌
 // @Failure 400 {object} resp.Response 请求参数错误
-// @Failure 401 {object} resp.Response 刷新令牌无效或已过期
+// @Failure 401 {object} resp.Response 刷新令牌无效、已过期或已被使用
 // @Failure 500 {object} resp.Response 服务器内部错误
 // @Router /api/v1/auth/refresh [post]
 func (ac *AuthController) RefreshToken(c *gin.Context) {
@@ -217,31 +275,29 @@ func (ac *AuthController) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	// 验证刷新令牌
-	claims, err := ac.jwtService.ValidateRefreshToken(refreshReq.RefreshToken)
-	if err != nil {
-		resp.FailWithCode(c, http.StatusUnauthorized, "刷新令牌无效或已过期")
-		return
-	}
-
-	// 生成新的令牌
-	token, refreshToken, err := ac.jwtService.GenerateTokens(claims.UserID, claims.Username)
+	// 轮换刷新令牌
+	tokens, err := service.RotateRefreshToken(refreshReq.RefreshToken, sessionClient(c))
 	if err != nil {
-		logger.Error("生成新JWT令牌失败", "user_id", claims.UserID, "error", err)
+		if errors.Is(err, service.ErrRefreshTokenInvalid) || errors.Is(err, service.ErrRefreshTokenReused) {
+			resp.FailWithCode(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+		logger.Error("刷新令牌失败", "error", err)
 		resp.FailWithMsg(c, "刷新令牌失败，请重新登录")
 		return
 	}
 
 	resp.OkWithData(c, gin.H{
-		"access_token":  token,
-		"refresh_token": refreshToken,
-		"token_type":    "Bearer",
+		"access_token":  tokens.Token,
+		"refresh_token": tokens.RefreshToken,
+		"token_type":    tokens.TokenType,
+		"expires_in":    tokens.ExpiresIn,
 	})
 }
 
 // Logout 用户登出
 // @Summary 用户登出
-// @Description 用户退出登录
+// @Description 用户退出登录，当前访问令牌及同一次登录的刷新令牌立即失效
 // @Tags 认证管理
 // @Accept json
 // @Produce json
@@ -250,55 +306,249 @@ func (ac *AuthController) RefreshToken(c *gin.Context) {
 // @Failure 401 {object} resp.Response 未授权
 // @Router /api/v1/auth/logout [post]
 func (ac *AuthController) Logout(c *gin.Context) {
-	// 从JWT中获取用户信息
+	// 从JWT中获取令牌信息
+	claims, ok := c.Get("claims")
+	if !ok {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	// 吊销当前令牌
+	tokenClaims := claims.(*model.CustomClaims)
+	failReason := ""
+	if err := service.RevokeToken(tokenClaims); err != nil {
+		logger.Error("吊销令牌失败", "user_id", tokenClaims.UserID, "error", err)
+		// 这里不返回错误，因为即使吊销失败，前端也会清除令牌
+		failReason = "吊销令牌失败"
+	}
+
+	// 记录登录日志
+	ac.recordLoginLog(c, model.LoginActionLogout, &tokenClaims.UserID, tokenClaims.Username, failReason)
+
+	resp.OkWithMsg(c, "登出成功")
+}
+
+// LogoutAll 退出全部设备
+// @Summary 退出全部设备
+// @Description 吊销当前用户此前签发的全部访问令牌和刷新令牌，所有设备（包括当前设备）都需要重新登录
+// @Tags 认证管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} resp.Response 已退出全部设备
+// @Failure 401 {object} resp.Response 未授权
+// @Failure 500 {object} resp.Response 服务器内部错误
+// @Router /api/v1/auth/logout-all [post]
+func (ac *AuthController) LogoutAll(c *gin.Context) {
 	userID, ok := c.Get("user_id")
 	if !ok {
 		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
 		return
 	}
 
-	// 将当前令牌加入黑名单
-	token := c.GetHeader("Authorization")
-	if token != "" && len(token) > 7 && token[:7] == "Bearer " {
-		token = token[7:]
-		// 将令牌加入黑名单
-		if err := ac.jwtService.BlacklistToken(token); err != nil {
-			logger.Error("将令牌加入黑名单失败", "user_id", userID, "error", err)
-			// 这里不返回错误，因为即使黑名单失败，前端也会清除令牌
+	if err := service.RevokeAllTokens(userID.(int)); err != nil {
+		logger.Error("退出全部设备失败", "user_id", userID, "error", err)
+		resp.FailWithMsg(c, "退出全部设备失败，请稍后重试")
+		return
+	}
+
+	resp.OkWithMsg(c, "已退出全部设备")
+}
+
+// VerifyMFA 登录第二步
+// @Summary 两步验证登录
+// @Description 使用登录返回的临时令牌和验证器动态口令（或一次性恢复码）完成登录，通过后签发访问令牌和刷新令牌。
+// @Description 每个临时令牌最多输错5次，输错同样计入登录失败次数
+// @Tags 认证管理
+// @Accept json
+// @Produce json
+// @Param data body model.MFALoginForm true "临时令牌和验证码"
+// @Success 200 {object} resp.Response{data=model.LoginResult} 登录成功，返回令牌
+// @Failure 400 {object} resp.Response 请求参数错误或验证码错误
+// @Failure 401 {object} resp.Response 临时令牌无效或已过期
+// @Failure 423 {object} resp.Response 账号或IP已被临时锁定
+// @Failure 500 {object} resp.Response 服务器内部错误
+// @Router /api/v1/auth/mfa/verify [post]
+func (ac *AuthController) VerifyMFA(c *gin.Context) {
+	var form model.MFALoginForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	tokens, claims, err := service.CompleteMFALogin(form.MFAToken, form.Code, sessionClient(c))
+	if err != nil {
+		if claims != nil {
+			ac.recordLoginLog(c, model.LoginActionLogin, &claims.UserID, claims.Username, "两步验证失败")
 		}
+		ac.failMFA(c, err)
+		return
 	}
 
-	resp.OkWithMsg(c, "登出成功")
+	ac.recordLoginLog(c, model.LoginActionLogin, &claims.UserID, claims.Username, "")
+	resp.OkWithData(c, gin.H{
+		"access_token":  tokens.Token,
+		"refresh_token": tokens.RefreshToken,
+		"token_type":    tokens.TokenType,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+// StartMFAEnrollment 登录时开始绑定验证器
+// @Summary 登录时绑定验证器
+// @Description 所属角色要求两步验证但尚未绑定时，使用登录返回的临时令牌获取验证器密钥和otpauth URI，10分钟内有效
+// @Tags 认证管理
+// @Accept json
+// @Produce json
+// @Param data body model.MFATokenForm true "临时令牌"
+// @Success 200 {object} resp.Response{data=model.TOTPEnrollment} 返回密钥和otpauth URI
+// @Failure 400 {object} resp.Response 请求参数错误
+// @Failure 401 {object} resp.Response 临时令牌无效或已过期
+// @Failure 500 {object} resp.Response 服务器内部错误
+// @Router /api/v1/auth/mfa/enroll [post]
+func (ac *AuthController) StartMFAEnrollment(c *gin.Context) {
+	var form model.MFATokenForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	enrollment, err := service.StartMFAEnrollment(form.MFAToken)
+	if err != nil {
+		ac.failMFA(c, err)
+		return
+	}
+
+	resp.OkWithData(c, enrollment)
+}
+
+// CompleteMFAEnrollment 登录时完成验证器绑定
+// @Summary 登录时确认绑定验证器
+// @Description 输入验证器生成的第一个动态口令完成绑定并登录，返回令牌和一次性恢复码，恢复码只显示这一次
+// @Tags 认证管理
+// @Accept json
+// @Produce json
+// @Param data body model.MFALoginForm true "临时令牌和验证码"
+// @Success 200 {object} resp.Response{data=model.MFAEnrollResult} 返回恢复码和令牌
+// @Failure 400 {object} resp.Response 请求参数错误或验证码错误
+// @Failure 401 {object} resp.Response 临时令牌无效或已过期
+// @Failure 500 {object} resp.Response 服务器内部错误
+// @Router /api/v1/auth/mfa/enroll/confirm [post]
+func (ac *AuthController) CompleteMFAEnrollment(c *gin.Context) {
+	var form model.MFALoginForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	result, claims, err := service.CompleteMFAEnrollment(form.MFAToken, form.Code, sessionClient(c))
+	if err != nil {
+		ac.failMFA(c, err)
+		return
+	}
+
+	ac.recordLoginLog(c, model.LoginActionLogin, &claims.UserID, claims.Username, "")
+	resp.OkWithData(c, result)
+}
+
+// failMFA 返回两步验证失败的结果
+func (ac *AuthController) failMFA(c *gin.Context, err error) {
+	var limitErr *service.LoginLimitError
+	switch {
+	case errors.As(err, &limitErr):
+		failLoginLimited(c, limitErr)
+	case errors.Is(err, service.ErrMFATokenInvalid):
+		resp.FailWithCode(c, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, service.ErrMFACodeInvalid),
+		errors.Is(err, service.ErrMFAEnrollExpired),
+		errors.Is(err, service.ErrMFAAlreadyEnabled),
+		errors.Is(err, service.ErrMFANotEnabled):
+		resp.FailWithMsg(c, err.Error())
+	default:
+		logger.Error("两步验证失败", "error", err)
+		resp.FailWithMsg(c, "验证失败，请稍后重试")
+	}
+}
+
+// failLogin 记录登录失败并返回结果，达到失败上限时提示账号已被锁定
+func (ac *AuthController) failLogin(c *gin.Context, username string) {
+	remaining, err := service.RecordLoginFailure(username, c.ClientIP())
+	if err != nil {
+		var limitErr *service.LoginLimitError
+		if errors.As(err, &limitErr) {
+			failLoginLimited(c, limitErr)
+			return
+		}
+		logger.Error("记录登录失败次数失败", "username", username, "error", err)
+	}
+
+	if err == nil && remaining <= 2 {
+		resp.FailWithMsg(c, fmt.Sprintf("用户名或密码错误，再失败%d次账号将被临时锁定", remaining))
+		return
+	}
+	resp.FailWithMsg(c, "用户名或密码错误")
 }
 
-// recordLoginLog 记录登录日志
-func (ac *AuthController) recordLoginLog(userID uint, username string, loginType, loginStatus uint8, c *gin.Context, failReason string) {
-	clientIP := c.ClientIP()
-	userAgent := c.GetHeader("User-Agent")
+// failLoginLimited 返回登录受限结果：账号或IP被锁定时返回423，尝试过于频繁时返回429，并设置 Retry-After 响应头
+func failLoginLimited(c *gin.Context, limitErr *service.LoginLimitError) {
+	c.Header("Retry-After", strconv.Itoa(limitErr.RetrySeconds()))
+	if errors.Is(limitErr, service.ErrLoginTooFrequent) {
+		resp.FailWithCode(c, http.StatusTooManyRequests, limitErr.Error())
+		return
+	}
+	resp.FailWithCode(c, http.StatusLocked, limitErr.Error())
+}
 
-	loginLog := &model.LoginLog{
+// recordLoginLog 异步记录登录日志，failReason 为空时记为成功
+func (ac *AuthController) recordLoginLog(c *gin.Context, action int8, userID *int, username string, failReason string) {
+	client := sessionClient(c)
+	loginLog := model.LoginLog{
 		UserID:      userID,
 		Username:    username,
-		LoginType:   loginType,
-		LoginStatus: loginStatus,
-		IPAddress:   clientIP,
-		UserAgent:   userAgent,
+		LoginType:   model.LoginTypeUsername,
+		LoginStatus: model.LoginStatusSuccess,
+		Action:      action,
+		IPAddress:   client.IPAddress,
+		UserAgent:   client.UserAgent,
 		LoginTime:   time.Now(),
 		FailReason:  failReason,
 	}
+	if failReason != "" {
+		loginLog.LoginStatus = model.LoginStatusFailed
+	}
 
-	if err := ac.loginLogModel.CreateLoginLog(loginLog); err != nil {
-		logger.Error("记录登录日志失败", "user_id", userID, "error", err)
+	service.RecordLoginLog(loginLog)
+}
+
+// sessionClient 获取请求的客户端信息
+func sessionClient(c *gin.Context) model.SessionClient {
+	return model.SessionClient{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.GetHeader("User-Agent"),
 	}
 }
 
-// RegisterRoutes 注册路由
+// RegisterPublicRoutes 注册前台公开路由，两步验证接口使用登录返回的临时令牌认证
+func (ac *AuthController) RegisterPublicRoutes(router *gin.RouterGroup) {
+	router.POST("/login", ac.Login)
+	router.POST("/register", ac.Register)
+	router.POST("/refresh", ac.RefreshToken)
+	router.POST("/mfa/verify", ac.VerifyMFA)
+	router.POST("/mfa/enroll", ac.StartMFAEnrollment)
+	router.POST("/mfa/enroll/confirm", ac.CompleteMFAEnrollment)
+}
+
+// RegisterAdminPublicRoutes 注册后台公开路由，后台不开放注册
+func (ac *AuthController) RegisterAdminPublicRoutes(router *gin.RouterGroup) {
+	router.POST("/login", ac.Login)
+	router.POST("/refresh", ac.RefreshToken)
+	router.POST("/mfa/verify", ac.VerifyMFA)
+	router.POST("/mfa/enroll", ac.StartMFAEnrollment)
+	router.POST("/mfa/enroll/confirm", ac.CompleteMFAEnrollment)
+}
+
+// RegisterRoutes 注册需要登录的路由
 func (ac *AuthController) RegisterRoutes(router *gin.RouterGroup) {
-	authGroup := router.Group("/auth")
-	{
-		authGroup.POST("/login", ac.Login)
-		authGroup.POST("/register", ac.Register)
-		authGroup.POST("/refresh", ac.RefreshToken)
-		authGroup.POST("/logout", ac.Logout)
-	}
+	router.POST("/logout", ac.Logout)
+	router.POST("/logout-all", ac.LogoutAll)
 }
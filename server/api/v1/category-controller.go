@@ -9,6 +9,7 @@ import (
 	"github.com/sunmoonstrand/go-react-blog/server/internal/logger"
 	"github.com/sunmoonstrand/go-react-blog/server/internal/middleware"
 	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
 	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/resp"
 )
 
@@ -261,6 +262,9 @@ func (cc *CategoryController) CreateCategory(c *gin.Context) {
 		return
 	}
 
+	// 清除站点地图缓存
+	service.InvalidateSitemap(model.SitemapTypeCategory)
+
 	resp.OkWithData(c, gin.H{
 		"category_id": category.ID,
 		"message":     "创建分类成功",
@@ -395,6 +399,9 @@ func (cc *CategoryController) UpdateCategory(c *gin.Context) {
 		return
 	}
 
+	// 清除站点地图缓存
+	service.InvalidateSitemap(model.SitemapTypeCategory)
+
 	resp.OkWithMsg(c, "更新分类成功")
 }
 
@@ -464,6 +471,9 @@ func (cc *CategoryController) DeleteCategory(c *gin.Context) {
 		return
 	}
 
+	// 清除站点地图缓存
+	service.InvalidateSitemap(model.SitemapTypeCategory)
+
 	resp.OkWithMsg(c, "删除分类成功")
 }
 
@@ -501,6 +511,9 @@ func (cc *CategoryController) BatchDeleteCategories(c *gin.Context) {
 		return
 	}
 
+	// 清除站点地图缓存
+	service.InvalidateSitemap(model.SitemapTypeCategory)
+
 	resp.OkWithData(c, gin.H{
 		"success": result.Success,
 		"failed":  result.Failed,
@@ -0,0 +1,72 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/config"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/middleware"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/resp"
+)
+
+// ExportController 全站导出控制器
+type ExportController struct {
+	upload config.UploadConfig
+}
+
+// NewExportController 创建全站导出控制器实例
+func NewExportController(upload config.UploadConfig) *ExportController {
+	return &ExportController{upload: upload}
+}
+
+// ExportSite 导出全站内容
+// @Summary 导出全站内容
+// @Description 以zip压缩包流式导出全站内容：文章为带前置元数据的Markdown，分类、标签、系统配置和评论为JSON，并包含文章引用的上传文件；指定limit时分段导出，以manifest.json中的last_article_id作为after_id继续导出下一段
+// @Tags 系统管理
+// @Produce application/zip
+// @Security ApiKeyAuth
+// @Param include_versions query bool false "是否导出文章历史版本"
+// @Param after_id query int false "从该文章ID之后继续导出"
+// @Param limit query int false "本次最多导出的文章数，0为不限制"
+// @Param skip_files query bool false "是否跳过上传文件"
+// @Success 200 {file} file "导出压缩包"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/export [get]
+func (ec *ExportController) ExportSite(c *gin.Context) {
+	var opts model.ExportOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	filename := "blog-export-" + time.Now().Format("20060102-150405")
+	if opts.AfterID > 0 {
+		filename += fmt.Sprintf("-after-%d", opts.AfterID)
+	}
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, filename))
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if _, err := service.ExportSite(c.Writer, ec.upload, opts); err != nil {
+		// 已开始输出时无法再返回错误信息，客户端会收到不完整的压缩包
+		if !c.Writer.Written() {
+			c.Header("Content-Type", "")
+			c.Header("Content-Disposition", "")
+			resp.FailWithMsg(c, "导出失败，请稍后重试")
+		}
+	}
+}
+
+// RegisterRoutes 注册路由
+func (ec *ExportController) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("", middleware.RequirePermission("system:site:export"), ec.ExportSite)
+}
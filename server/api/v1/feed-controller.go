@@ -0,0 +1,197 @@
+package v1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/logger"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/feed"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/resp"
+)
+
+// feedCacheMaxAge 订阅源允许客户端缓存的时间（秒）
+const feedCacheMaxAge = "300"
+
+// FeedController 订阅源控制器
+type FeedController struct{}
+
+// NewFeedController 创建订阅源控制器实例
+func NewFeedController() *FeedController {
+	return &FeedController{}
+}
+
+// RSS 获取RSS 2.0订阅源
+// @Summary 获取RSS订阅源
+// @Description 获取全站、分类（含子分类）、标签或作者的RSS 2.0订阅源，支持If-None-Match/If-Modified-Since条件请求
+// @Tags 订阅源
+// @Produce xml
+// @Param key path string false "分类标识、标签标识或作者用户名"
+// @Param full query bool false "是否输出全文，默认只输出摘要"
+// @Success 200 {string} string "RSS文档"
+// @Success 304 {string} string "未修改"
+// @Failure 404 {object} resp.Response "分类、标签或作者不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /feed.xml [get]
+// @Router /category/{key}/feed.xml [get]
+// @Router /tag/{key}/feed.xml [get]
+// @Router /author/{key}/feed.xml [get]
+func (fc *FeedController) RSS(c *gin.Context) {
+	fc.serveFeed(c, feed.RSS, feed.ContentTypeRSS)
+}
+
+// Atom 获取Atom订阅源
+// @Summary 获取Atom订阅源
+// @Description 获取全站、分类（含子分类）、标签或作者的Atom 1.0订阅源，支持If-None-Match/If-Modified-Since条件请求
+// @Tags 订阅源
+// @Produce xml
+// @Param key path string false "分类标识、标签标识或作者用户名"
+// @Param full query bool false "是否输出全文，默认只输出摘要"
+// @Success 200 {string} string "Atom文档"
+// @Success 304 {string} string "未修改"
+// @Failure 404 {object} resp.Response "分类、标签或作者不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /atom.xml [get]
+// @Router /category/{key}/atom.xml [get]
+// @Router /tag/{key}/atom.xml [get]
+// @Router /author/{key}/atom.xml [get]
+func (fc *FeedController) Atom(c *gin.Context) {
+	fc.serveFeed(c, feed.Atom, feed.ContentTypeAtom)
+}
+
+// JSONFeed 获取JSON Feed订阅源
+// @Summary 获取JSON Feed订阅源
+// @Description 获取全站、分类（含子分类）、标签或作者的JSON Feed 1.1订阅源，支持If-None-Match/If-Modified-Since条件请求
+// @Tags 订阅源
+// @Produce json
+// @Param key path string false "分类标识、标签标识或作者用户名"
+// @Param full query bool false "是否输出全文，默认只输出摘要"
+// @Success 200 {string} string "JSON Feed文档"
+// @Success 304 {string} string "未修改"
+// @Failure 404 {object} resp.Response "分类、标签或作者不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /feed.json [get]
+// @Router /category/{key}/feed.json [get]
+// @Router /tag/{key}/feed.json [get]
+// @Router /author/{key}/feed.json [get]
+func (fc *FeedController) JSONFeed(c *gin.Context) {
+	fc.serveFeed(c, feed.JSON, feed.ContentTypeJSON)
+}
+
+// serveFeed 生成订阅源并处理条件请求
+func (fc *FeedController) serveFeed(c *gin.Context, encode func(*feed.Feed) ([]byte, error), contentType string) {
+	query := model.FeedQuery{
+		Scope:       feedScope(c.FullPath()),
+		Key:         c.Param("key"),
+		FullContent: c.Query("full") == "true" || c.Query("full") == "1",
+		BaseURL:     requestBaseURL(c),
+		FeedPath:    c.Request.URL.Path,
+	}
+	if query.FullContent {
+		query.FeedPath += "?full=true"
+	}
+
+	f, err := service.BuildFeed(query)
+	if err != nil {
+		if errors.Is(err, service.ErrFeedNotFound) {
+			resp.FailWithCode(c, http.StatusNotFound, err.Error())
+			return
+		}
+		logger.Error("生成订阅源失败", "path", c.Request.URL.Path, "error", err)
+		resp.FailWithCode(c, http.StatusInternalServerError, "生成订阅源失败")
+		return
+	}
+
+	body, err := encode(f)
+	if err != nil {
+		logger.Error("序列化订阅源失败", "path", c.Request.URL.Path, "error", err)
+		resp.FailWithCode(c, http.StatusInternalServerError, "生成订阅源失败")
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	if checkNotModified(c, `W/"`+hex.EncodeToString(sum[:8])+`"`, f.Updated, feedCacheMaxAge) {
+		return
+	}
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// RegisterRoutes 注册路由，订阅源挂载在站点根路径下
+func (fc *FeedController) RegisterRoutes(router gin.IRouter) {
+	// 全站
+	router.GET("/feed.xml", fc.RSS)
+	router.GET("/atom.xml", fc.Atom)
+	router.GET("/feed.json", fc.JSONFeed)
+
+	// 分类、标签、作者
+	for _, prefix := range []string{"/category/:key", "/tag/:key", "/author/:key"} {
+		router.GET(prefix+"/feed.xml", fc.RSS)
+		router.GET(prefix+"/atom.xml", fc.Atom)
+		router.GET(prefix+"/feed.json", fc.JSONFeed)
+	}
+}
+
+// feedScope 根据路由判断订阅源范围
+func feedScope(fullPath string) string {
+	switch {
+	case strings.HasPrefix(fullPath, "/category/"):
+		return model.FeedScopeCategory
+	case strings.HasPrefix(fullPath, "/tag/"):
+		return model.FeedScopeTag
+	case strings.HasPrefix(fullPath, "/author/"):
+		return model.FeedScopeAuthor
+	}
+	return model.FeedScopeSite
+}
+
+// requestBaseURL 根据请求推断站点地址，支持反向代理传递的协议和主机头
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = strings.TrimSpace(strings.Split(proto, ",")[0])
+	}
+	host := c.Request.Host
+	if forwarded := c.GetHeader("X-Forwarded-Host"); forwarded != "" {
+		host = strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	return scheme + "://" + host
+}
+
+// checkNotModified 设置缓存相关响应头，客户端缓存仍有效时返回304并返回true
+func checkNotModified(c *gin.Context, etag string, lastModified time.Time, maxAge string) bool {
+	lastModified = lastModified.UTC().Truncate(time.Second)
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age="+maxAge)
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+
+	// If-None-Match 优先于 If-Modified-Since
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				c.Status(http.StatusNotModified)
+				return true
+			}
+		}
+		return false
+	}
+	if since := c.GetHeader("If-Modified-Since"); since != "" && !lastModified.IsZero() {
+		if t, err := http.ParseTime(since); err == nil && !lastModified.After(t) {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
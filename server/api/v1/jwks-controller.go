@@ -0,0 +1,39 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
+)
+
+// jwksCacheMaxAge 公钥集允许客户端缓存的时间（秒）。新密钥在开始签名前就已公布，
+// 验证方遇到未知 kid 时应重新获取
+const jwksCacheMaxAge = "900"
+
+// JWKSController 令牌公钥集控制器
+type JWKSController struct{}
+
+// NewJWKSController 创建令牌公钥集控制器实例
+func NewJWKSController() *JWKSController {
+	return &JWKSController{}
+}
+
+// JWKS 获取令牌验证公钥
+// @Summary 获取令牌验证公钥
+// @Description 返回验证访问令牌签名的公钥集（RFC 7517），其他服务按令牌头中的 kid 选择公钥验证本站签发的令牌。
+// @Description 包括尚未开始签名的新密钥和仍在验证期内的旧密钥；使用 HS256 共享密钥签名时返回空集合
+// @Tags 认证管理
+// @Produce json
+// @Success 200 {object} jwt.JSONWebKeySet "公钥集"
+// @Router /.well-known/jwks.json [get]
+func (jc *JWKSController) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age="+jwksCacheMaxAge)
+	c.JSON(http.StatusOK, service.TokenKeys().JWKS())
+}
+
+// RegisterRoutes 注册路由，公钥集挂载在站点根路径下
+func (jc *JWKSController) RegisterRoutes(router gin.IRouter) {
+	router.GET("/.well-known/jwks.json", jc.JWKS)
+}
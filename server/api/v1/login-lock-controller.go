@@ -0,0 +1,98 @@
+package v1
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/logger"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/middleware"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/resp"
+)
+
+// LoginLockController 登录锁定管理控制器
+type LoginLockController struct{}
+
+// NewLoginLockController 创建登录锁定管理控制器实例
+func NewLoginLockController() *LoginLockController {
+	return &LoginLockController{}
+}
+
+// UnlockUser 解除用户的登录锁定
+// @Summary 解除用户登录锁定
+// @Description 管理员解除用户因连续登录失败导致的临时锁定，并清空该用户名的失败计数
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} resp.Response "解锁成功"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 404 {object} resp.Response "用户不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/user/{id}/lockout [delete]
+func (lc *LoginLockController) UnlockUser(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID <= 0 {
+		resp.FailWithMsg(c, "无效的用户ID")
+		return
+	}
+
+	if err := service.UnlockLoginUser(userID); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			resp.FailWithCode(c, http.StatusNotFound, err.Error())
+			return
+		}
+		logger.Error("解除用户登录锁定失败", "user_id", userID, "error", err)
+		resp.FailWithMsg(c, "解锁失败，请稍后重试")
+		return
+	}
+
+	resp.OkWithMsg(c, "解锁成功")
+}
+
+// UnlockIP 解除IP的登录限制
+// @Summary 解除IP登录限制
+// @Description 管理员解除IP因登录失败过多导致的临时限制，并清空该IP的失败计数
+// @Tags 系统管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param ip path string true "IP地址"
+// @Success 200 {object} resp.Response "解锁成功"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/login-lock/ip/{ip} [delete]
+func (lc *LoginLockController) UnlockIP(c *gin.Context) {
+	ip := net.ParseIP(c.Param("ip"))
+	if ip == nil {
+		resp.FailWithMsg(c, "无效的IP地址")
+		return
+	}
+
+	if err := service.UnlockLoginIP(ip.String()); err != nil {
+		logger.Error("解除IP登录限制失败", "ip", ip.String(), "error", err)
+		resp.FailWithMsg(c, "解锁失败，请稍后重试")
+		return
+	}
+
+	resp.OkWithMsg(c, "解锁成功")
+}
+
+// RegisterAdminUserRoutes 注册后台用户管理中的登录锁定路由
+func (lc *LoginLockController) RegisterAdminUserRoutes(router *gin.RouterGroup) {
+	router.DELETE("/:id/lockout", middleware.RequirePermission("system:user:unlock"), lc.UnlockUser)
+}
+
+// RegisterAdminRoutes 注册后台登录锁定管理路由
+func (lc *LoginLockController) RegisterAdminRoutes(router *gin.RouterGroup) {
+	router.DELETE("/ip/:ip", middleware.RequirePermission("system:user:unlock"), lc.UnlockIP)
+}
@@ -0,0 +1,192 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/logger"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/middleware"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/resp"
+)
+
+// LoginLogController 登录日志控制器
+type LoginLogController struct{}
+
+// NewLoginLogController 创建登录日志控制器实例
+func NewLoginLogController() *LoginLogController {
+	return &LoginLogController{}
+}
+
+// ListLoginLogs 获取登录日志列表
+// @Summary 获取登录日志列表
+// @Description 分页查询登录、刷新令牌和退出登录的日志，可按用户、IP地址或网段、结果、操作和时间筛选
+// @Tags 系统管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param user_id query int false "用户ID"
+// @Param username query string false "用户名"
+// @Param ip_address query string false "IP地址或网段，如 192.168.1.0/24"
+// @Param login_type query int false "登录类型：1用户名密码，2邮箱，3手机号，4微信，5通行密钥，6邮件登录链接，7第三方账号"
+// @Param login_status query int false "结果：1成功，2失败"
+// @Param action query int false "操作：1登录，2刷新令牌，3退出登录"
+// @Param start_time query string false "开始时间（RFC3339）"
+// @Param end_time query string false "结束时间（RFC3339）"
+// @Param page query int true "页码"
+// @Param page_size query int true "每页数量"
+// @Success 200 {object} resp.Response{data=model.PageResult{list=[]model.LoginLog}} "返回登录日志列表"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/login-log [get]
+func (lc *LoginLogController) ListLoginLogs(c *gin.Context) {
+	var params model.LoginLogQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	result, err := service.ListLoginLogs(params)
+	if err != nil {
+		if errors.Is(err, service.ErrLoginLogIPInvalid) {
+			resp.FailWithMsg(c, err.Error())
+			return
+		}
+		logger.Error("获取登录日志失败", "error", err)
+		resp.FailWithMsg(c, "获取登录日志失败")
+		return
+	}
+
+	resp.OkWithData(c, result)
+}
+
+// ExportLoginLogs 导出登录日志
+// @Summary 导出登录日志
+// @Description 以CSV导出符合筛选条件的登录日志，按时间倒序，单次最多导出10万条
+// @Tags 系统管理
+// @Produce text/csv
+// @Security ApiKeyAuth
+// @Param user_id query int false "用户ID"
+// @Param username query string false "用户名"
+// @Param ip_address query string false "IP地址或网段"
+// @Param login_type query int false "登录类型"
+// @Param login_status query int false "结果：1成功，2失败"
+// @Param action query int false "操作：1登录，2刷新令牌，3退出登录"
+// @Param start_time query string false "开始时间（RFC3339）"
+// @Param end_time query string false "结束时间（RFC3339）"
+// @Success 200 {file} file "CSV文件"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/login-log/export [get]
+func (lc *LoginLogController) ExportLoginLogs(c *gin.Context) {
+	var filter model.LoginLogFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="login-logs-`+time.Now().Format("20060102-150405")+`.csv"`)
+	c.Status(http.StatusOK)
+
+	if _, err := service.ExportLoginLogs(c.Writer, filter); err != nil {
+		if errors.Is(err, service.ErrLoginLogIPInvalid) {
+			c.Header("Content-Type", "")
+			c.Header("Content-Disposition", "")
+			resp.FailWithMsg(c, err.Error())
+			return
+		}
+		logger.Error("导出登录日志失败", "error", err)
+		// 已开始输出时无法再返回错误信息，客户端会收到不完整的文件
+		if !c.Writer.Written() {
+			c.Header("Content-Type", "")
+			c.Header("Content-Disposition", "")
+			resp.FailWithMsg(c, "导出失败，请稍后重试")
+		}
+	}
+}
+
+// ListMyRecentLogins 获取当前用户最近的登录记录
+// @Summary 获取最近登录记录
+// @Description 获取当前用户最近的登录记录（含失败的尝试），用于发现异常登录
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param limit query int false "条数，默认10，最多50"
+// @Success 200 {object} resp.Response{data=[]model.LoginLog} "返回登录记录"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /api/v1/user/logins [get]
+func (lc *LoginLogController) ListMyRecentLogins(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	lc.listRecentLogins(c, userID.(int))
+}
+
+// ListUserRecentLogins 获取指定用户最近的登录记录
+// @Summary 获取用户最近登录记录
+// @Description 管理员获取指定用户最近的登录记录（含失败的尝试）
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Param limit query int false "条数，默认10，最多50"
+// @Success 200 {object} resp.Response{data=[]model.LoginLog} "返回登录记录"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/user/{id}/logins [get]
+func (lc *LoginLogController) ListUserRecentLogins(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID <= 0 {
+		resp.FailWithMsg(c, "无效的用户ID")
+		return
+	}
+
+	lc.listRecentLogins(c, userID)
+}
+
+// listRecentLogins 返回用户最近的登录记录
+func (lc *LoginLogController) listRecentLogins(c *gin.Context, userID int) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	logs, err := service.ListRecentLogins(userID, limit)
+	if err != nil {
+		logger.Error("获取最近登录记录失败", "user_id", userID, "error", err)
+		resp.FailWithMsg(c, "获取登录记录失败")
+		return
+	}
+
+	resp.OkWithData(c, logs)
+}
+
+// RegisterRoutes 注册用户路由
+func (lc *LoginLogController) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/logins", lc.ListMyRecentLogins)
+}
+
+// RegisterAdminRoutes 注册后台登录日志路由
+func (lc *LoginLogController) RegisterAdminRoutes(router *gin.RouterGroup) {
+	router.GET("", middleware.RequirePermission("system:log:login"), lc.ListLoginLogs)
+	router.GET("/export", middleware.RequirePermission("system:log:login"), lc.ExportLoginLogs)
+}
+
+// RegisterAdminUserRoutes 注册后台用户管理中的登录记录路由
+func (lc *LoginLogController) RegisterAdminUserRoutes(router *gin.RouterGroup) {
+	router.GET("/:id/logins", middleware.RequirePermission("system:log:login"), lc.ListUserRecentLogins)
+}
@@ -0,0 +1,552 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/logger"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/resp"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/xmlrpc"
+)
+
+// MetaWeblog接口参数
+const (
+	// metaWeblogMaxBodySize 请求体大小上限，包含base64编码的媒体文件
+	metaWeblogMaxBodySize = 32 << 20
+	// metaWeblogBlogID 站点只有一个博客，固定ID
+	metaWeblogBlogID = "1"
+	// metaWeblogPath XML-RPC接口地址
+	metaWeblogPath = "/xmlrpc"
+)
+
+// metaWeblogMethod XML-RPC方法处理函数
+type metaWeblogMethod func(c *gin.Context, params []interface{}) (interface{}, error)
+
+// MetaWeblogController MetaWeblog（XML-RPC）控制器，供桌面写作客户端发布文章
+type MetaWeblogController struct {
+	methods map[string]metaWeblogMethod
+}
+
+// NewMetaWeblogController 创建MetaWeblog控制器实例
+func NewMetaWeblogController() *MetaWeblogController {
+	mc := &MetaWeblogController{}
+	mc.methods = map[string]metaWeblogMethod{
+		"blogger.getUsersBlogs":     mc.getUsersBlogs,
+		"metaWeblog.getUsersBlogs":  mc.getUsersBlogs,
+		"metaWeblog.newPost":        mc.newPost,
+		"metaWeblog.editPost":       mc.editPost,
+		"metaWeblog.getPost":        mc.getPost,
+		"metaWeblog.getRecentPosts": mc.getRecentPosts,
+		"metaWeblog.getCategories":  mc.getCategories,
+		"metaWeblog.newMediaObject": mc.newMediaObject,
+		"blogger.deletePost":        mc.deletePost,
+		"metaWeblog.deletePost":     mc.deletePost,
+		"system.listMethods":        mc.listMethods,
+	}
+	return mc
+}
+
+// XMLRPC 处理XML-RPC调用
+// @Summary MetaWeblog XML-RPC接口
+// @Description 支持blogger.getUsersBlogs、metaWeblog.newPost/editPost/getPost/getRecentPosts/getCategories/newMediaObject、blogger.deletePost，使用用户名和登录密码或应用密码认证，已启用或所属角色要求两步验证的用户只能使用应用密码
+// @Tags MetaWeblog
+// @Accept xml
+// @Produce xml
+// @Success 200 {string} string "XML-RPC响应，出错时返回fault"
+// @Router /xmlrpc [post]
+func (mc *MetaWeblogController) XMLRPC(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, metaWeblogMaxBodySize)
+	method, params, err := xmlrpc.Decode(c.Request.Body)
+	if err != nil {
+		mc.writeFault(c, xmlrpc.NewFault(xmlrpc.FaultParse, "请求格式错误"))
+		return
+	}
+
+	handler, ok := mc.methods[method]
+	if !ok {
+		mc.writeFault(c, xmlrpc.NewFault(xmlrpc.FaultMethodNotFound, "不支持的方法: "+method))
+		return
+	}
+
+	result, err := handler(c, params)
+	if err != nil {
+		mc.writeFault(c, mc.toFault(method, err))
+		return
+	}
+
+	body, err := xmlrpc.EncodeResponse(result)
+	if err != nil {
+		logger.Error("序列化XML-RPC响应失败", "method", method, "error", err)
+		mc.writeFault(c, xmlrpc.NewFault(xmlrpc.FaultInternal, "服务器内部错误"))
+		return
+	}
+	c.Data(http.StatusOK, xmlrpc.ContentType, body)
+}
+
+// RSD 获取RSD文档，写作客户端据此自动发现XML-RPC接口
+// @Summary 获取RSD文档
+// @Description 获取Really Simple Discovery文档，用于写作客户端自动发现MetaWeblog接口地址
+// @Tags MetaWeblog
+// @Produce xml
+// @Success 200 {string} string "RSD文档"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /rsd.xml [get]
+func (mc *MetaWeblogController) RSD(c *gin.Context) {
+	baseURL, err := service.SiteBaseURL(requestBaseURL(c))
+	if err != nil {
+		logger.Error("获取站点地址失败", "error", err)
+		resp.FailWithCode(c, http.StatusInternalServerError, "获取站点信息失败")
+		return
+	}
+
+	base := html.EscapeString(baseURL)
+	apiLink := base + metaWeblogPath
+	body := `<?xml version="1.0" encoding="UTF-8"?>
+<rsd version="1.0" xmlns="http://archipelago.phrasewise.com/rsd">
+  <service>
+    <engineName>go-react-blog</engineName>
+    <homePageLink>` + base + `/</homePageLink>
+    <apis>
+      <api name="MetaWeblog" blogID="` + metaWeblogBlogID + `" preferred="true" apiLink="` + apiLink + `" />
+      <api name="Blogger" blogID="` + metaWeblogBlogID + `" preferred="false" apiLink="` + apiLink + `" />
+    </apis>
+  </service>
+</rsd>
+`
+	c.Data(http.StatusOK, "application/rsd+xml; charset=utf-8", []byte(body))
+}
+
+// RegisterRoutes 注册路由，XML-RPC接口和RSD文档挂载在站点根路径下
+func (mc *MetaWeblogController) RegisterRoutes(router gin.IRouter) {
+	router.POST(metaWeblogPath, mc.XMLRPC)
+	router.GET("/rsd.xml", mc.RSD)
+}
+
+// getUsersBlogs blogger.getUsersBlogs(appKey, username, password)
+func (mc *MetaWeblogController) getUsersBlogs(c *gin.Context, params []interface{}) (interface{}, error) {
+	userID, err := mc.authenticate(c, params, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	site, err := service.GetSiteInfo()
+	if err != nil {
+		return nil, err
+	}
+	baseURL, err := service.SiteBaseURL(requestBaseURL(c))
+	if err != nil {
+		return nil, err
+	}
+	isAdmin, err := service.HasPermission(userID, service.PermArticlePublish)
+	if err != nil {
+		return nil, err
+	}
+
+	return []map[string]interface{}{{
+		"blogid":   metaWeblogBlogID,
+		"blogName": site.Name,
+		"url":      baseURL + "/",
+		"xmlrpc":   baseURL + metaWeblogPath,
+		"isAdmin":  isAdmin,
+	}}, nil
+}
+
+// newPost metaWeblog.newPost(blogid, username, password, struct, publish)
+func (mc *MetaWeblogController) newPost(c *gin.Context, params []interface{}) (interface{}, error) {
+	userID, err := mc.authenticate(c, params, 1)
+	if err != nil {
+		return nil, err
+	}
+	if err := mc.requirePermission(userID, "content:article:add"); err != nil {
+		return nil, err
+	}
+	content, err := paramStruct(params, 3)
+	if err != nil {
+		return nil, err
+	}
+	post := metaWeblogPostFromStruct(content)
+	if strings.TrimSpace(post.Title) == "" {
+		return nil, xmlrpc.NewFault(xmlrpc.FaultBadRequest, "文章标题不能为空")
+	}
+
+	articleID, err := service.MetaWeblogNewPost(userID, post, paramBool(params, 4))
+	if err != nil {
+		return nil, err
+	}
+	return strconv.Itoa(articleID), nil
+}
+
+// editPost metaWeblog.editPost(postid, username, password, struct, publish)
+func (mc *MetaWeblogController) editPost(c *gin.Context, params []interface{}) (interface{}, error) {
+	userID, err := mc.authenticate(c, params, 1)
+	if err != nil {
+		return nil, err
+	}
+	articleID, err := paramInt(params, 0)
+	if err != nil {
+		return nil, err
+	}
+	content, err := paramStruct(params, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.MetaWeblogEditPost(userID, articleID, metaWeblogPostFromStruct(content), paramBool(params, 4)); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+// getPost metaWeblog.getPost(postid, username, password)
+func (mc *MetaWeblogController) getPost(c *gin.Context, params []interface{}) (interface{}, error) {
+	userID, err := mc.authenticate(c, params, 1)
+	if err != nil {
+		return nil, err
+	}
+	articleID, err := paramInt(params, 0)
+	if err != nil {
+		return nil, err
+	}
+	baseURL, err := service.SiteBaseURL(requestBaseURL(c))
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := service.MetaWeblogGetPost(userID, articleID, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return metaWeblogPostToStruct(post), nil
+}
+
+// getRecentPosts metaWeblog.getRecentPosts(blogid, username, password, numberOfPosts)
+func (mc *MetaWeblogController) getRecentPosts(c *gin.Context, params []interface{}) (interface{}, error) {
+	userID, err := mc.authenticate(c, params, 1)
+	if err != nil {
+		return nil, err
+	}
+	limit := 0
+	if len(params) > 3 {
+		if limit, err = paramInt(params, 3); err != nil {
+			return nil, err
+		}
+	}
+	baseURL, err := service.SiteBaseURL(requestBaseURL(c))
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := service.MetaWeblogRecentPosts(userID, limit, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]map[string]interface{}, 0, len(posts))
+	for i := range posts {
+		result = append(result, metaWeblogPostToStruct(&posts[i]))
+	}
+	return result, nil
+}
+
+// getCategories metaWeblog.getCategories(blogid, username, password)
+func (mc *MetaWeblogController) getCategories(c *gin.Context, params []interface{}) (interface{}, error) {
+	if _, err := mc.authenticate(c, params, 1); err != nil {
+		return nil, err
+	}
+	baseURL, err := service.SiteBaseURL(requestBaseURL(c))
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := service.MetaWeblogCategories(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	// 多数客户端以 description 作为分类显示名称
+	result := make([]map[string]interface{}, 0, len(categories))
+	for _, category := range categories {
+		result = append(result, map[string]interface{}{
+			"categoryId":   strconv.Itoa(category.CategoryID),
+			"title":        category.Title,
+			"description":  category.Title,
+			"categoryName": category.Title,
+			"htmlUrl":      category.HTMLURL,
+			"rssUrl":       category.RSSURL,
+		})
+	}
+	return result, nil
+}
+
+// newMediaObject metaWeblog.newMediaObject(blogid, username, password, struct{name, type, bits})
+func (mc *MetaWeblogController) newMediaObject(c *gin.Context, params []interface{}) (interface{}, error) {
+	userID, err := mc.authenticate(c, params, 1)
+	if err != nil {
+		return nil, err
+	}
+	if err := mc.requirePermission(userID, "content:file:upload"); err != nil {
+		return nil, err
+	}
+	media, err := paramStruct(params, 3)
+	if err != nil {
+		return nil, err
+	}
+	name, _ := media["name"].(string)
+	mimeType, _ := media["type"].(string)
+	bits, _ := media["bits"].([]byte)
+	if name == "" || len(bits) == 0 {
+		return nil, xmlrpc.NewFault(xmlrpc.FaultBadRequest, "文件名或文件内容为空")
+	}
+
+	result, err := service.MetaWeblogNewMediaObject(userID, name, mimeType, bits)
+	if err != nil {
+		return nil, err
+	}
+	baseURL, err := service.SiteBaseURL(requestBaseURL(c))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"id":   strconv.FormatInt(result.FileID, 10),
+		"file": result.FileName,
+		"url":  service.SiteAbsURL(baseURL, result.URL),
+		"type": mimeType,
+	}, nil
+}
+
+// deletePost blogger.deletePost(appKey, postid, username, password, publish)
+func (mc *MetaWeblogController) deletePost(c *gin.Context, params []interface{}) (interface{}, error) {
+	userID, err := mc.authenticate(c, params, 2)
+	if err != nil {
+		return nil, err
+	}
+	articleID, err := paramInt(params, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.MetaWeblogDeletePost(userID, articleID); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+// listMethods system.listMethods()
+func (mc *MetaWeblogController) listMethods(c *gin.Context, params []interface{}) (interface{}, error) {
+	methods := make([]string, 0, len(mc.methods))
+	for method := range mc.methods {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods, nil
+}
+
+// authenticate 使用参数中的用户名和密码（登录密码或应用密码）认证，userIndex为用户名的位置，密码紧随其后
+func (mc *MetaWeblogController) authenticate(c *gin.Context, params []interface{}, userIndex int) (int, error) {
+	username, err := paramString(params, userIndex)
+	if err != nil {
+		return 0, err
+	}
+	password, err := paramString(params, userIndex+1)
+	if err != nil {
+		return 0, err
+	}
+
+	user, err := service.AuthenticateUser(username, password, c.ClientIP())
+	if err != nil {
+		return 0, err
+	}
+	return user.UserID, nil
+}
+
+// requirePermission 检查用户是否拥有指定权限
+func (mc *MetaWeblogController) requirePermission(userID int, permKey string) error {
+	ok, err := service.HasPermission(userID, permKey)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return xmlrpc.NewFault(xmlrpc.FaultUnauthorized, "无权限执行该操作")
+	}
+	return nil
+}
+
+// toFault 将业务错误转换为XML-RPC错误
+func (mc *MetaWeblogController) toFault(method string, err error) *xmlrpc.Fault {
+	var fault *xmlrpc.Fault
+	var limitErr *service.LoginLimitError
+	switch {
+	case errors.As(err, &fault):
+		return fault
+	case errors.As(err, &limitErr):
+		return xmlrpc.NewFault(xmlrpc.FaultForbidden, limitErr.Error())
+	case errors.Is(err, service.ErrInvalidCredentials),
+		errors.Is(err, service.ErrAppPasswordRequired),
+		errors.Is(err, service.ErrEmailNotVerified):
+		return xmlrpc.NewFault(xmlrpc.FaultForbidden, err.Error())
+	case errors.Is(err, service.ErrArticleNotFound):
+		return xmlrpc.NewFault(xmlrpc.FaultNotFound, "文章不存在")
+	case errors.Is(err, service.ErrArticleForbidden),
+		errors.Is(err, service.ErrArticlePublishDenied),
+		errors.Is(err, service.ErrArticleLocked):
+		return xmlrpc.NewFault(xmlrpc.FaultUnauthorized, err.Error())
+	case errors.Is(err, service.ErrNoCategory),
+		errors.Is(err, service.ErrMediaTypeNotAllowed),
+		errors.Is(err, service.ErrArticleStatusTransition):
+		return xmlrpc.NewFault(xmlrpc.FaultBadRequest, err.Error())
+	}
+	logger.Error("XML-RPC调用失败", "method", method, "error", err)
+	return xmlrpc.NewFault(xmlrpc.FaultInternal, "服务器内部错误")
+}
+
+// writeFault 输出XML-RPC错误，按协议要求HTTP状态码仍为200
+func (mc *MetaWeblogController) writeFault(c *gin.Context, fault *xmlrpc.Fault) {
+	c.Data(http.StatusOK, xmlrpc.ContentType, xmlrpc.EncodeFault(fault))
+}
+
+// metaWeblogPostFromStruct 将MetaWeblog文章结构转换为文章
+func metaWeblogPostFromStruct(content map[string]interface{}) model.MetaWeblogPost {
+	post := model.MetaWeblogPost{}
+	post.Title, _ = content["title"].(string)
+	post.Description, _ = content["description"].(string)
+	post.Excerpt, _ = content["mt_excerpt"].(string)
+	post.Slug, _ = content["wp_slug"].(string)
+
+	// 部分客户端将“更多”之后的内容单独放在 mt_text_more 中
+	if more, _ := content["mt_text_more"].(string); strings.TrimSpace(more) != "" {
+		post.Description += "\n<!--more-->\n" + more
+	}
+
+	switch keywords := content["mt_keywords"].(type) {
+	case string:
+		post.Keywords = keywords
+	case []interface{}:
+		names := make([]string, 0, len(keywords))
+		for _, keyword := range keywords {
+			if name, ok := keyword.(string); ok {
+				names = append(names, name)
+			}
+		}
+		post.Keywords = strings.Join(names, ",")
+	}
+
+	if categories, ok := content["categories"].([]interface{}); ok {
+		for _, category := range categories {
+			if name, ok := category.(string); ok && name != "" {
+				post.Categories = append(post.Categories, name)
+			}
+		}
+	}
+
+	// mt_allow_comments：1开放，0或2关闭
+	switch allow := content["mt_allow_comments"].(type) {
+	case int:
+		allowComments := allow == 1
+		post.AllowComments = &allowComments
+	case bool:
+		post.AllowComments = &allow
+	case string:
+		if allow != "" {
+			allowComments := allow == "1" || allow == "open"
+			post.AllowComments = &allowComments
+		}
+	}
+	return post
+}
+
+// metaWeblogPostToStruct 将文章转换为MetaWeblog文章结构
+func metaWeblogPostToStruct(post *model.MetaWeblogPost) map[string]interface{} {
+	postStatus := "draft"
+	switch post.Status {
+	case model.ArticleStatusPublished:
+		postStatus = "publish"
+	case model.ArticleStatusPending:
+		postStatus = "pending"
+	}
+	allowComments := 0
+	if post.AllowComments != nil && *post.AllowComments {
+		allowComments = 1
+	}
+	categories := post.Categories
+	if categories == nil {
+		categories = []string{}
+	}
+
+	return map[string]interface{}{
+		"postid":            strconv.FormatInt(post.ArticleID, 10),
+		"userid":            strconv.Itoa(post.UserID),
+		"title":             post.Title,
+		"description":       post.Description,
+		"link":              post.Link,
+		"permaLink":         post.Link,
+		"categories":        categories,
+		"mt_keywords":       post.Keywords,
+		"mt_excerpt":        post.Excerpt,
+		"mt_allow_comments": allowComments,
+		"wp_slug":           post.Slug,
+		"post_status":       postStatus,
+		"dateCreated":       post.DateCreated,
+	}
+}
+
+// paramString 获取字符串参数
+func paramString(params []interface{}, i int) (string, error) {
+	if i >= len(params) {
+		return "", xmlrpc.NewFault(xmlrpc.FaultBadRequest, fmt.Sprintf("缺少第%d个参数", i+1))
+	}
+	switch v := params[i].(type) {
+	case string:
+		return v, nil
+	case int:
+		return strconv.Itoa(v), nil
+	}
+	return "", xmlrpc.NewFault(xmlrpc.FaultBadRequest, fmt.Sprintf("第%d个参数应为字符串", i+1))
+}
+
+// paramInt 获取整数参数，兼容以字符串传递的ID
+func paramInt(params []interface{}, i int) (int, error) {
+	if i < len(params) {
+		switch v := params[i].(type) {
+		case int:
+			return v, nil
+		case string:
+			if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+				return n, nil
+			}
+		}
+	}
+	return 0, xmlrpc.NewFault(xmlrpc.FaultBadRequest, fmt.Sprintf("第%d个参数应为整数", i+1))
+}
+
+// paramBool 获取布尔参数，缺省为false
+func paramBool(params []interface{}, i int) bool {
+	if i >= len(params) {
+		return false
+	}
+	switch v := params[i].(type) {
+	case bool:
+		return v
+	case int:
+		return v != 0
+	case string:
+		return v == "1" || v == "true"
+	}
+	return false
+}
+
+// paramStruct 获取结构体参数
+func paramStruct(params []interface{}, i int) (map[string]interface{}, error) {
+	if i < len(params) {
+		if v, ok := params[i].(map[string]interface{}); ok {
+			return v, nil
+		}
+	}
+	return nil, xmlrpc.NewFault(xmlrpc.FaultBadRequest, fmt.Sprintf("第%d个参数应为结构体", i+1))
+}
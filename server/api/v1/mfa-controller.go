@@ -0,0 +1,249 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/logger"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/middleware"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/resp"
+)
+
+// MFAController 两步验证控制器
+type MFAController struct{}
+
+// NewMFAController 创建两步验证控制器实例
+func NewMFAController() *MFAController {
+	return &MFAController{}
+}
+
+// GetStatus 获取当前用户的两步验证状态
+// @Summary 获取两步验证状态
+// @Description 获取当前用户是否已绑定验证器、剩余恢复码数量，以及所属角色是否要求两步验证
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} resp.Response{data=model.MFAStatus} "返回两步验证状态"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /api/v1/user/mfa [get]
+func (mc *MFAController) GetStatus(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	status, err := service.GetMFAStatus(userID.(int))
+	if err != nil {
+		logger.Error("获取两步验证状态失败", "user_id", userID, "error", err)
+		resp.FailWithMsg(c, "获取两步验证状态失败")
+		return
+	}
+
+	resp.OkWithData(c, status)
+}
+
+// StartTOTP 开始绑定验证器
+// @Summary 绑定验证器
+// @Description 生成验证器密钥和otpauth URI，用身份验证器应用扫码后在10分钟内提交动态口令完成绑定
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} resp.Response{data=model.TOTPEnrollment} "返回密钥和otpauth URI"
+// @Failure 400 {object} resp.Response "已启用两步验证"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /api/v1/user/mfa/totp [post]
+func (mc *MFAController) StartTOTP(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	enrollment, err := service.StartTOTPEnrollment(userID.(int), c.GetString("username"))
+	if err != nil {
+		mc.fail(c, userID.(int), err)
+		return
+	}
+
+	resp.OkWithData(c, enrollment)
+}
+
+// ConfirmTOTP 确认绑定验证器
+// @Summary 确认绑定验证器
+// @Description 提交验证器生成的第一个动态口令，通过后启用两步验证并返回一次性恢复码，恢复码只显示这一次
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param data body model.MFACodeForm true "动态口令"
+// @Success 200 {object} resp.Response{data=model.MFAEnrollResult} "返回恢复码"
+// @Failure 400 {object} resp.Response "验证码错误或绑定已超时"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /api/v1/user/mfa/totp/confirm [post]
+func (mc *MFAController) ConfirmTOTP(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var form model.MFACodeForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	codes, err := service.ConfirmTOTPEnrollment(userID.(int), form.Code)
+	if err != nil {
+		mc.fail(c, userID.(int), err)
+		return
+	}
+
+	resp.OkWithData(c, model.MFAEnrollResult{RecoveryCodes: codes})
+}
+
+// DisableTOTP 关闭两步验证
+// @Summary 关闭两步验证
+// @Description 提交动态口令或恢复码验证后解除验证器绑定并作废全部恢复码；所属角色要求两步验证时不能关闭
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param data body model.MFACodeForm true "动态口令或恢复码"
+// @Success 200 {object} resp.Response "已关闭"
+// @Failure 400 {object} resp.Response "验证码错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "角色要求两步验证"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /api/v1/user/mfa/totp [delete]
+func (mc *MFAController) DisableTOTP(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var form model.MFACodeForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	if err := service.DisableTOTP(userID.(int), form.Code); err != nil {
+		mc.fail(c, userID.(int), err)
+		return
+	}
+
+	resp.OkWithMsg(c, "已关闭两步验证")
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+// @Summary 重新生成恢复码
+// @Description 提交验证器动态口令后重新生成一组恢复码，旧恢复码全部作废，新恢复码只显示这一次
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param data body model.MFACodeForm true "动态口令"
+// @Success 200 {object} resp.Response{data=model.MFAEnrollResult} "返回新的恢复码"
+// @Failure 400 {object} resp.Response "验证码错误或未启用两步验证"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /api/v1/user/mfa/recovery-codes [post]
+func (mc *MFAController) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var form model.MFACodeForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	codes, err := service.RegenerateRecoveryCodes(userID.(int), form.Code)
+	if err != nil {
+		mc.fail(c, userID.(int), err)
+		return
+	}
+
+	resp.OkWithData(c, model.MFAEnrollResult{RecoveryCodes: codes})
+}
+
+// ResetUserMFA 重置用户的两步验证
+// @Summary 重置用户两步验证
+// @Description 管理员解除用户的验证器绑定并作废恢复码，用于用户丢失验证器的情况；所属角色要求两步验证的用户下次登录时需重新绑定
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} resp.Response "重置成功"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 404 {object} resp.Response "用户不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/user/{id}/mfa [delete]
+func (mc *MFAController) ResetUserMFA(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID <= 0 {
+		resp.FailWithMsg(c, "无效的用户ID")
+		return
+	}
+
+	if err := service.ResetMFA(userID); err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			resp.FailWithCode(c, http.StatusNotFound, err.Error())
+			return
+		}
+		logger.Error("重置两步验证失败", "user_id", userID, "error", err)
+		resp.FailWithMsg(c, "重置失败，请稍后重试")
+		return
+	}
+
+	resp.OkWithMsg(c, "重置成功")
+}
+
+// fail 返回两步验证操作失败的结果
+func (mc *MFAController) fail(c *gin.Context, userID int, err error) {
+	switch {
+	case errors.Is(err, service.ErrMFARequired):
+		resp.FailWithCode(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrMFACodeInvalid),
+		errors.Is(err, service.ErrMFAEnrollExpired),
+		errors.Is(err, service.ErrMFAAlreadyEnabled),
+		errors.Is(err, service.ErrMFANotEnabled):
+		resp.FailWithMsg(c, err.Error())
+	default:
+		logger.Error("两步验证操作失败", "user_id", userID, "error", err)
+		resp.FailWithMsg(c, "操作失败，请稍后重试")
+	}
+}
+
+// RegisterRoutes 注册用户路由
+func (mc *MFAController) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/mfa", mc.GetStatus)
+	router.POST("/mfa/totp", mc.StartTOTP)
+	router.POST("/mfa/totp/confirm", mc.ConfirmTOTP)
+	router.DELETE("/mfa/totp", mc.DisableTOTP)
+	router.POST("/mfa/recovery-codes", mc.RegenerateRecoveryCodes)
+}
+
+// RegisterAdminUserRoutes 注册后台用户管理中的两步验证路由
+func (mc *MFAController) RegisterAdminUserRoutes(router *gin.RouterGroup) {
+	router.DELETE("/:id/mfa", middleware.RequirePermission("system:user:mfa"), mc.ResetUserMFA)
+}
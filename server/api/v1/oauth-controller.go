@@ -0,0 +1,334 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/logger"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/oauth"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/resp"
+)
+
+// 第三方授权的 state 同时保存在 HttpOnly Cookie 中，回调时比对，防止他人的回调地址把当前浏览器登录到其他账号
+const (
+	oauthStateCookie     = "oauth_state"
+	oauthStateCookiePath = "/api/v1"
+)
+
+// OAuthController 第三方登录控制器
+type OAuthController struct{}
+
+// NewOAuthController 创建第三方登录控制器实例
+func NewOAuthController() *OAuthController {
+	return &OAuthController{}
+}
+
+// ListProviders 获取可用的第三方登录方式
+// @Summary 获取第三方登录方式
+// @Description 返回服务端已配置的第三方登录服务，用于显示登录按钮
+// @Tags 认证管理
+// @Produce json
+// @Success 200 {object} resp.Response{data=[]model.OAuthProvider} 第三方登录方式
+// @Router /api/v1/auth/oauth/providers [get]
+func (oc *OAuthController) ListProviders(c *gin.Context) {
+	resp.OkWithData(c, service.ListOAuthProviders())
+}
+
+// Authorize 发起第三方登录
+// @Summary 发起第三方登录
+// @Description 返回第三方服务的授权地址，同时将 state 写入 HttpOnly Cookie，前端保存返回的 state 后跳转。授权完成后第三方服务跳转到 网站地址/oauth/callback/{provider}，
+// @Description 前端比对地址中的 state 与保存的是否一致，再在同一浏览器中调用 /auth/oauth/{provider}/callback 完成登录。需在10分钟内完成
+// @Tags 认证管理
+// @Produce json
+// @Param provider path string true "第三方登录服务标识"
+// @Success 200 {object} resp.Response{data=model.OAuthAuthorization} 授权地址
+// @Failure 400 {object} resp.Response 不支持该登录方式或未配置网站地址
+// @Failure 500 {object} resp.Response 服务器内部错误
+// @Router /api/v1/auth/oauth/{provider}/authorize [post]
+func (oc *OAuthController) Authorize(c *gin.Context) {
+	oc.authorize(c, 0)
+}
+
+// Callback 完成第三方登录
+// @Summary 完成第三方登录
+// @Description 提交回调地址中的 code 和 state，state 须与发起授权时写入 Cookie 的一致，校验通过后签发与密码登录相同的令牌。第三方账号未关联本站用户时，
+// @Description 按第三方服务已验证的邮箱关联到邮箱同样已验证的用户，找不到时自动注册（系统配置 oauth_auto_register 关闭时返回错误）。
+// @Description 启用了两步验证或所属角色要求两步验证时不签发令牌，返回 mfa_required 和临时令牌，需调用 /auth/mfa/verify 或 /auth/mfa/enroll 完成登录
+// @Tags 认证管理
+// @Accept json
+// @Produce json
+// @Param provider path string true "第三方登录服务标识"
+// @Param data body model.OAuthCallbackForm true "回调参数"
+// @Success 200 {object} resp.Response "登录成功，返回令牌和用户信息"
+// @Failure 400 {object} resp.Response "授权已超时、第三方账号验证失败、账号已禁用或邮箱冲突"
+// @Failure 403 {object} resp.Response "邮箱未验证"
+// @Failure 423 {object} resp.Response "账号已被锁定"
+// @Failure 429 {object} resp.Response "登录尝试过于频繁"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /api/v1/auth/oauth/{provider}/callback [post]
+func (oc *OAuthController) Callback(c *gin.Context) {
+	var form model.OAuthCallbackForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	provider := c.Param("provider")
+	if !oc.checkState(c, form.State) {
+		resp.FailWithMsg(c, service.ErrOAuthStateInvalid.Error())
+		return
+	}
+
+	result, user, err := service.FinishOAuthLogin(provider, form.Code, form.State, sessionClient(c))
+	if err != nil {
+		var limitErr *service.LoginLimitError
+		switch {
+		case errors.As(err, &limitErr):
+			oc.recordLoginLog(c, provider, user, "登录受限")
+			failLoginLimited(c, limitErr)
+		case errors.Is(err, service.ErrUserDisabled):
+			oc.recordLoginLog(c, provider, user, "账号状态异常")
+			resp.FailWithMsg(c, err.Error())
+		case errors.Is(err, service.ErrEmailNotVerified):
+			oc.recordLoginLog(c, provider, user, "邮箱未验证")
+			resp.FailWithCode(c, http.StatusForbidden, err.Error())
+		case errors.Is(err, service.ErrOAuthProviderNotFound),
+			errors.Is(err, service.ErrOAuthStateInvalid),
+			errors.Is(err, service.ErrOAuthFailed),
+			errors.Is(err, service.ErrOAuthAccountNotLinked),
+			errors.Is(err, service.ErrOAuthEmailConflict),
+			errors.Is(err, service.ErrOAuthProviderLinked),
+			errors.Is(err, service.ErrSiteURLNotConfigured):
+			resp.FailWithMsg(c, err.Error())
+		default:
+			logger.Error("第三方登录失败", "provider", provider, "error", err)
+			resp.FailWithMsg(c, "登录失败，请稍后重试")
+		}
+		return
+	}
+
+	// 需要两步验证时只返回临时令牌，完成第二步后才签发令牌
+	if result.MFA != nil {
+		resp.OkWithData(c, gin.H{
+			"mfa_required": true,
+			"mfa":          result.MFA,
+		})
+		return
+	}
+
+	oc.recordLoginLog(c, provider, user, "")
+	resp.OkWithData(c, gin.H{
+		"access_token":  result.Token,
+		"refresh_token": result.RefreshToken,
+		"token_type":    result.TokenType,
+		"expires_in":    result.ExpiresIn,
+		"user_info": gin.H{
+			"user_id":  user.UserID,
+			"username": user.Username,
+			"nickname": user.Nickname,
+			"avatar":   user.Avatar,
+			"email":    user.Email,
+		},
+	})
+}
+
+// ListAccounts 获取已关联的第三方账号
+// @Summary 获取已关联的第三方账号
+// @Description 返回当前用户已关联的第三方账号，已从配置中移除的服务不返回
+// @Tags 用户管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} resp.Response{data=[]model.OAuthAccount} 已关联的第三方账号
+// @Failure 401 {object} resp.Response 未授权
+// @Failure 500 {object} resp.Response 服务器内部错误
+// @Router /api/v1/user/oauth/accounts [get]
+func (oc *OAuthController) ListAccounts(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	accounts, err := service.ListOAuthAccounts(userID.(int))
+	if err != nil {
+		logger.Error("获取第三方账号失败", "user_id", userID, "error", err)
+		resp.FailWithMsg(c, "获取第三方账号失败")
+		return
+	}
+
+	resp.OkWithData(c, accounts)
+}
+
+// AuthorizeLink 发起关联第三方账号
+// @Summary 发起关联第三方账号
+// @Description 返回第三方服务的授权地址，流程与第三方登录相同，回调后调用 /user/oauth/{provider}/link 完成关联。只能由发起关联的用户完成
+// @Tags 用户管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param provider path string true "第三方登录服务标识"
+// @Success 200 {object} resp.Response{data=model.OAuthAuthorization} 授权地址
+// @Failure 400 {object} resp.Response 不支持该登录方式或未配置网站地址
+// @Failure 401 {object} resp.Response 未授权
+// @Failure 500 {object} resp.Response 服务器内部错误
+// @Router /api/v1/user/oauth/{provider}/authorize [post]
+func (oc *OAuthController) AuthorizeLink(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+	oc.authorize(c, userID.(int))
+}
+
+// Link 完成关联第三方账号
+// @Summary 关联第三方账号
+// @Description 提交回调地址中的 code 和 state，state 须与发起关联时写入 Cookie 的一致，将第三方账号关联到当前用户，之后可以使用该账号登录。每个第三方服务只能关联一个账号
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param provider path string true "第三方登录服务标识"
+// @Param data body model.OAuthCallbackForm true "回调参数"
+// @Success 200 {object} resp.Response 关联成功
+// @Failure 400 {object} resp.Response 授权已超时、第三方账号验证失败或已关联其他用户
+// @Failure 401 {object} resp.Response 未授权
+// @Failure 500 {object} resp.Response 服务器内部错误
+// @Router /api/v1/user/oauth/{provider}/link [post]
+func (oc *OAuthController) Link(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var form model.OAuthCallbackForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	if !oc.checkState(c, form.State) {
+		resp.FailWithMsg(c, service.ErrOAuthStateInvalid.Error())
+		return
+	}
+
+	if err := service.LinkOAuthAccount(userID.(int), c.Param("provider"), form.Code, form.State); err != nil {
+		oc.fail(c, userID.(int), err)
+		return
+	}
+
+	resp.OkWithMsg(c, "关联成功")
+}
+
+// Unlink 解除关联第三方账号
+// @Summary 解除关联第三方账号
+// @Description 解除当前用户关联的第三方账号。未设置密码且没有其他第三方账号或通行密钥时不能解除，以免无法登录
+// @Tags 用户管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Param provider path string true "第三方登录服务标识"
+// @Success 200 {object} resp.Response 解除成功
+// @Failure 400 {object} resp.Response 未关联或这是唯一的登录方式
+// @Failure 401 {object} resp.Response 未授权
+// @Failure 500 {object} resp.Response 服务器内部错误
+// @Router /api/v1/user/oauth/accounts/{provider} [delete]
+func (oc *OAuthController) Unlink(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := service.UnlinkOAuthAccount(userID.(int), c.Param("provider")); err != nil {
+		oc.fail(c, userID.(int), err)
+		return
+	}
+
+	resp.OkWithMsg(c, "已解除关联")
+}
+
+// authorize 生成授权地址，userID 为0时用于登录，否则用于为该用户关联账号
+func (oc *OAuthController) authorize(c *gin.Context, userID int) {
+	authorization, err := service.BeginOAuth(c.Param("provider"), userID)
+	if err != nil {
+		oc.fail(c, userID, err)
+		return
+	}
+
+	oc.setStateCookie(c, authorization.State, authorization.ExpiresIn)
+	resp.OkWithData(c, authorization)
+}
+
+// checkState 比对回调参数中的 state 与 Cookie 中保存的 state，比对后清除 Cookie，state 只能使用一次
+func (oc *OAuthController) checkState(c *gin.Context, state string) bool {
+	expected, _ := c.Cookie(oauthStateCookie)
+	oc.setStateCookie(c, "", -1)
+	return oauth.VerifyState(expected, state)
+}
+
+// setStateCookie 写入 state Cookie，maxAge 小于0时删除
+func (oc *OAuthController) setStateCookie(c *gin.Context, state string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, state, maxAge, oauthStateCookiePath, "", secure, true)
+}
+
+// fail 将第三方账号相关的业务错误返回给前端，其他错误记录日志后返回通用提示
+func (oc *OAuthController) fail(c *gin.Context, userID int, err error) {
+	switch {
+	case errors.Is(err, service.ErrOAuthProviderNotFound),
+		errors.Is(err, service.ErrOAuthStateInvalid),
+		errors.Is(err, service.ErrOAuthFailed),
+		errors.Is(err, service.ErrOAuthAccountLinked),
+		errors.Is(err, service.ErrOAuthProviderLinked),
+		errors.Is(err, service.ErrOAuthNotLinked),
+		errors.Is(err, service.ErrOAuthLastLoginMethod),
+		errors.Is(err, service.ErrSiteURLNotConfigured):
+		resp.FailWithMsg(c, err.Error())
+	default:
+		logger.Error("第三方账号操作失败", "user_id", userID, "provider", c.Param("provider"), "error", err)
+		resp.FailWithMsg(c, "操作失败，请稍后重试")
+	}
+}
+
+// recordLoginLog 记录第三方登录日志，failReason 为空表示登录成功；无法对应到用户时不记录
+func (oc *OAuthController) recordLoginLog(c *gin.Context, provider string, user *model.User, failReason string) {
+	if user == nil {
+		return
+	}
+	client := sessionClient(c)
+	loginLog := model.LoginLog{
+		UserID:      &user.UserID,
+		Username:    user.Username,
+		LoginType:   service.OAuthLoginType(provider),
+		LoginStatus: model.LoginStatusSuccess,
+		Action:      model.LoginActionLogin,
+		IPAddress:   client.IPAddress,
+		UserAgent:   client.UserAgent,
+		FailReason:  failReason,
+	}
+	if failReason != "" {
+		loginLog.LoginStatus = model.LoginStatusFailed
+	}
+
+	service.RecordLoginLog(loginLog)
+}
+
+// RegisterPublicRoutes 注册无需认证的登录路由
+func (oc *OAuthController) RegisterPublicRoutes(router *gin.RouterGroup) {
+	router.GET("/oauth/providers", oc.ListProviders)
+	router.POST("/oauth/:provider/authorize", oc.Authorize)
+	router.POST("/oauth/:provider/callback", oc.Callback)
+}
+
+// RegisterRoutes 注册用户的第三方账号管理路由
+func (oc *OAuthController) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/oauth/accounts", oc.ListAccounts)
+	router.DELETE("/oauth/accounts/:provider", oc.Unlink)
+	router.POST("/oauth/:provider/authorize", oc.AuthorizeLink)
+	router.POST("/oauth/:provider/link", oc.Link)
+}
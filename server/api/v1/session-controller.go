@@ -0,0 +1,197 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/logger"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/middleware"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/resp"
+)
+
+// SessionController 登录会话控制器
+type SessionController struct{}
+
+// NewSessionController 创建登录会话控制器实例
+func NewSessionController() *SessionController {
+	return &SessionController{}
+}
+
+// ListSessions 获取当前用户的登录会话
+// @Summary 获取登录会话列表
+// @Description 获取当前用户的有效登录会话（设备、IP、浏览器、登录时间和最近访问时间），当前请求所用的会话标记为current
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} resp.Response{data=[]model.Session} "返回会话列表"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /api/v1/user/sessions [get]
+func (sc *SessionController) ListSessions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	sessions, err := service.ListSessions(userID.(int), currentSessionID(c))
+	if err != nil {
+		logger.Error("获取登录会话失败", "user_id", userID, "error", err)
+		resp.FailWithMsg(c, "获取登录会话失败")
+		return
+	}
+
+	resp.OkWithData(c, sessions)
+}
+
+// RevokeSession 移除当前用户的登录会话
+// @Summary 移除登录会话
+// @Description 移除当前用户的指定登录会话，该设备上的登录立即失效；移除当前会话等同于退出登录
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param session_id path string true "会话ID"
+// @Success 200 {object} resp.Response "移除成功"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 404 {object} resp.Response "会话不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /api/v1/user/sessions/{session_id} [delete]
+func (sc *SessionController) RevokeSession(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	sc.revokeSession(c, userID.(int), c.Param("session_id"))
+}
+
+// ListUserSessions 获取指定用户的登录会话
+// @Summary 获取用户登录会话列表
+// @Description 管理员获取指定用户的有效登录会话
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} resp.Response{data=[]model.Session} "返回会话列表"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/user/{id}/sessions [get]
+func (sc *SessionController) ListUserSessions(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID <= 0 {
+		resp.FailWithMsg(c, "无效的用户ID")
+		return
+	}
+
+	sessions, err := service.ListSessions(userID, currentSessionID(c))
+	if err != nil {
+		logger.Error("获取登录会话失败", "user_id", userID, "error", err)
+		resp.FailWithMsg(c, "获取登录会话失败")
+		return
+	}
+
+	resp.OkWithData(c, sessions)
+}
+
+// RevokeUserSession 移除指定用户的登录会话
+// @Summary 移除用户登录会话
+// @Description 管理员移除指定用户的登录会话，该设备上的登录立即失效
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Param session_id path string true "会话ID"
+// @Success 200 {object} resp.Response "移除成功"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 404 {object} resp.Response "会话不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/user/{id}/sessions/{session_id} [delete]
+func (sc *SessionController) RevokeUserSession(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID <= 0 {
+		resp.FailWithMsg(c, "无效的用户ID")
+		return
+	}
+
+	sc.revokeSession(c, userID, c.Param("session_id"))
+}
+
+// RevokeUserSessions 移除指定用户的全部登录会话
+// @Summary 移除用户全部登录会话
+// @Description 管理员吊销指定用户此前签发的全部令牌，该用户在所有设备上都需要重新登录
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} resp.Response "移除成功"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/user/{id}/sessions [delete]
+func (sc *SessionController) RevokeUserSessions(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || userID <= 0 {
+		resp.FailWithMsg(c, "无效的用户ID")
+		return
+	}
+
+	if err := service.RevokeAllTokens(userID); err != nil {
+		logger.Error("移除用户全部会话失败", "user_id", userID, "error", err)
+		resp.FailWithMsg(c, "移除会话失败，请稍后重试")
+		return
+	}
+
+	resp.OkWithMsg(c, "已移除该用户的全部会话")
+}
+
+// revokeSession 移除会话并返回结果
+func (sc *SessionController) revokeSession(c *gin.Context, userID int, sessionID string) {
+	if err := service.RevokeSession(userID, sessionID); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			resp.FailWithCode(c, http.StatusNotFound, err.Error())
+			return
+		}
+		logger.Error("移除登录会话失败", "user_id", userID, "session_id", sessionID, "error", err)
+		resp.FailWithMsg(c, "移除会话失败，请稍后重试")
+		return
+	}
+
+	resp.OkWithMsg(c, "移除会话成功")
+}
+
+// currentSessionID 当前请求所用令牌的会话ID
+func currentSessionID(c *gin.Context) string {
+	if claims, ok := c.Get("claims"); ok {
+		return claims.(*model.CustomClaims).FamilyID
+	}
+	return ""
+}
+
+// RegisterRoutes 注册路由
+func (sc *SessionController) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/sessions", sc.ListSessions)
+	router.DELETE("/sessions/:session_id", sc.RevokeSession)
+}
+
+// RegisterAdminRoutes 注册后台管理路由
+func (sc *SessionController) RegisterAdminRoutes(router *gin.RouterGroup) {
+	router.GET("/:id/sessions", middleware.RequirePermission("system:user:session"), sc.ListUserSessions)
+	router.DELETE("/:id/sessions", middleware.RequirePermission("system:user:session"), sc.RevokeUserSessions)
+	router.DELETE("/:id/sessions/:session_id", middleware.RequirePermission("system:user:session"), sc.RevokeUserSession)
+}
@@ -0,0 +1,159 @@
+package v1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/logger"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/resp"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/sitemap"
+)
+
+// sitemapCacheMaxAge 站点地图和robots.txt允许客户端缓存的时间（秒）
+const sitemapCacheMaxAge = "3600"
+
+// SitemapController 站点地图控制器
+type SitemapController struct{}
+
+// NewSitemapController 创建站点地图控制器实例
+func NewSitemapController() *SitemapController {
+	return &SitemapController{}
+}
+
+// SitemapIndex 获取站点地图索引
+// @Summary 获取站点地图索引
+// @Description 获取站点地图索引，列出文章、分类、标签的分页站点地图，支持If-None-Match/If-Modified-Since条件请求
+// @Tags 站点地图
+// @Produce xml
+// @Success 200 {string} string "站点地图索引"
+// @Success 304 {string} string "未修改"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /sitemap.xml [get]
+func (sc *SitemapController) SitemapIndex(c *gin.Context) {
+	sitemaps, err := service.BuildSitemapIndex(requestBaseURL(c))
+	if err != nil {
+		logger.Error("生成站点地图索引失败", "error", err)
+		resp.FailWithCode(c, http.StatusInternalServerError, "生成站点地图失败")
+		return
+	}
+
+	var lastModified time.Time
+	for _, s := range sitemaps {
+		if s.LastMod.After(lastModified) {
+			lastModified = s.LastMod
+		}
+	}
+
+	body, err := sitemap.Index(sitemaps)
+	if err != nil {
+		logger.Error("序列化站点地图索引失败", "error", err)
+		resp.FailWithCode(c, http.StatusInternalServerError, "生成站点地图失败")
+		return
+	}
+	sc.serve(c, body, lastModified, sitemap.ContentType)
+}
+
+// Sitemap 获取分页站点地图
+// @Summary 获取分页站点地图
+// @Description 获取指定类型的分页站点地图，类型为article、category或tag，页码从1开始，文件名如article-1.xml
+// @Tags 站点地图
+// @Produce xml
+// @Param name path string true "站点地图文件名"
+// @Success 200 {string} string "站点地图"
+// @Success 304 {string} string "未修改"
+// @Failure 404 {object} resp.Response "站点地图不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /sitemaps/{name} [get]
+func (sc *SitemapController) Sitemap(c *gin.Context) {
+	sitemapType, page, ok := parseSitemapName(c.Param("name"))
+	if !ok {
+		resp.FailWithCode(c, http.StatusNotFound, service.ErrSitemapNotFound.Error())
+		return
+	}
+
+	urls, err := service.BuildSitemap(sitemapType, page, requestBaseURL(c))
+	if err != nil {
+		if errors.Is(err, service.ErrSitemapNotFound) {
+			resp.FailWithCode(c, http.StatusNotFound, err.Error())
+			return
+		}
+		logger.Error("生成站点地图失败", "type", sitemapType, "page", page, "error", err)
+		resp.FailWithCode(c, http.StatusInternalServerError, "生成站点地图失败")
+		return
+	}
+
+	var lastModified time.Time
+	for _, u := range urls {
+		if u.LastMod.After(lastModified) {
+			lastModified = u.LastMod
+		}
+	}
+
+	body, err := sitemap.URLSet(urls)
+	if err != nil {
+		logger.Error("序列化站点地图失败", "type", sitemapType, "page", page, "error", err)
+		resp.FailWithCode(c, http.StatusInternalServerError, "生成站点地图失败")
+		return
+	}
+	sc.serve(c, body, lastModified, sitemap.ContentType)
+}
+
+// RobotsTxt 获取robots.txt
+// @Summary 获取robots.txt
+// @Description 获取搜索引擎抓取规则，规则可在系统配置robots_txt中修改，未声明站点地图时自动追加站点地图地址
+// @Tags 站点地图
+// @Produce plain
+// @Success 200 {string} string "robots.txt"
+// @Success 304 {string} string "未修改"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /robots.txt [get]
+func (sc *SitemapController) RobotsTxt(c *gin.Context) {
+	content, err := service.GetRobotsTxt(requestBaseURL(c))
+	if err != nil {
+		logger.Error("获取robots.txt失败", "error", err)
+		resp.FailWithCode(c, http.StatusInternalServerError, "获取robots.txt失败")
+		return
+	}
+	sc.serve(c, []byte(content), time.Time{}, "text/plain; charset=utf-8")
+}
+
+// serve 处理条件请求并输出内容
+func (sc *SitemapController) serve(c *gin.Context, body []byte, lastModified time.Time, contentType string) {
+	sum := sha256.Sum256(body)
+	if checkNotModified(c, `W/"`+hex.EncodeToString(sum[:8])+`"`, lastModified, sitemapCacheMaxAge) {
+		return
+	}
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// RegisterRoutes 注册路由，站点地图和robots.txt挂载在站点根路径下
+func (sc *SitemapController) RegisterRoutes(router gin.IRouter) {
+	router.GET("/robots.txt", sc.RobotsTxt)
+	router.GET(service.SitemapIndexPath, sc.SitemapIndex)
+	router.GET(service.SitemapPagePrefix+":name", sc.Sitemap)
+}
+
+// parseSitemapName 解析分页站点地图文件名，如 article-1.xml
+func parseSitemapName(name string) (string, int, bool) {
+	name, ok := strings.CutSuffix(name, ".xml")
+	if !ok {
+		return "", 0, false
+	}
+	i := strings.LastIndex(name, "-")
+	if i <= 0 {
+		return "", 0, false
+	}
+	page, err := strconv.Atoi(name[i+1:])
+	if err != nil || page < 1 {
+		return "", 0, false
+	}
+	return name[:i], page, true
+}
@@ -0,0 +1,51 @@
+package v1
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/logger"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/middleware"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/resp"
+)
+
+// StaticController 静态站点生成控制器
+type StaticController struct{}
+
+// NewStaticController 创建静态站点生成控制器实例
+func NewStaticController() *StaticController {
+	return &StaticController{}
+}
+
+// BuildStaticSite 全量生成静态站点
+// @Summary 全量生成静态站点
+// @Description 将已发布文章、首页、分类和标签列表、归档、订阅源和站点地图渲染为静态HTML写入输出目录，并删除已下线内容的页面；开启自动生成后文章变更时只增量生成受影响的页面
+// @Tags 系统管理
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} resp.Response{data=model.StaticBuildResult} "生成成功"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 403 {object} resp.Response "无权限"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /admin/api/v1/static/build [post]
+func (sc *StaticController) BuildStaticSite(c *gin.Context) {
+	result, err := service.BuildStaticSite()
+	if err != nil {
+		if errors.Is(err, service.ErrStaticSiteNotInitialized) {
+			resp.FailWithMsg(c, err.Error())
+			return
+		}
+		logger.Error("生成静态站点失败", "error", err)
+		resp.FailWithMsg(c, "生成静态站点失败，请稍后重试")
+		return
+	}
+
+	resp.OkWithData(c, result)
+}
+
+// RegisterRoutes 注册路由
+func (sc *StaticController) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/build", middleware.RequirePermission("system:site:static"), sc.BuildStaticSite)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/sunmoonstrand/go-react-blog/server/internal/logger"
 	"github.com/sunmoonstrand/go-react-blog/server/internal/middleware"
 	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
 	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/resp"
 )
 
@@ -216,6 +217,9 @@ func (tc *TagController) CreateTag(c *gin.Context) {
 		return
 	}
 
+	// 清除站点地图缓存
+	service.InvalidateSitemap(model.SitemapTypeTag)
+
 	resp.OkWithData(c, gin.H{
 		"tag_id":  tag.ID,
 		"message": "创建标签成功",
@@ -304,6 +308,9 @@ func (tc *TagController) UpdateTag(c *gin.Context) {
 		return
 	}
 
+	// 清除站点地图缓存
+	service.InvalidateSitemap(model.SitemapTypeTag)
+
 	resp.OkWithMsg(c, "更新标签成功")
 }
 
@@ -361,6 +368,9 @@ func (tc *TagController) DeleteTag(c *gin.Context) {
 		return
 	}
 
+	// 清除站点地图缓存
+	service.InvalidateSitemap(model.SitemapTypeTag)
+
 	resp.OkWithMsg(c, "删除标签成功")
 }
 
@@ -398,6 +408,9 @@ func (tc *TagController) BatchDeleteTags(c *gin.Context) {
 		return
 	}
 
+	// 清除站点地图缓存
+	service.InvalidateSitemap(model.SitemapTypeTag)
+
 	resp.OkWithData(c, gin.H{
 		"success": result.Success,
 		"failed":  result.Failed,
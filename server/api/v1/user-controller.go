@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/sunmoonstrand/go-react-blog/server/internal/logger"
 	"github.com/sunmoonstrand/go-react-blog/server/internal/middleware"
 	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
 	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/resp"
 )
 
@@ -535,6 +537,114 @@ func (uc *UserController) GetUserInfo(c *gin.Context) {
 	resp.OkWithData(c, respData)
 }
 
+// ListAppPasswords 获取当前用户的应用密码列表
+// @Summary 获取应用密码列表
+// @Description 获取当前用户创建的应用密码，不包含密码明文
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} resp.Response{data=[]model.AppPassword} "返回应用密码列表"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /api/v1/users/app-passwords [get]
+func (uc *UserController) ListAppPasswords(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	appPasswords, err := service.ListAppPasswords(userID.(int))
+	if err != nil {
+		logger.Error("获取应用密码列表失败", "user_id", userID, "error", err)
+		resp.FailWithMsg(c, "获取应用密码列表失败")
+		return
+	}
+
+	resp.OkWithData(c, appPasswords)
+}
+
+// CreateAppPassword 创建应用密码
+// @Summary 创建应用密码
+// @Description 为桌面写作客户端等第三方应用创建独立密码，可用于XML-RPC接口认证，密码明文只在创建时返回一次
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param data body model.AppPasswordCreateForm true "应用信息"
+// @Success 200 {object} resp.Response{data=model.AppPasswordCreateResult} "创建成功"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /api/v1/users/app-passwords [post]
+func (uc *UserController) CreateAppPassword(c *gin.Context) {
+	var form model.AppPasswordCreateForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	result, err := service.CreateAppPassword(userID.(int), form)
+	if err != nil {
+		if errors.Is(err, service.ErrAppPasswordLimit) {
+			resp.FailWithMsg(c, err.Error())
+			return
+		}
+		logger.Error("创建应用密码失败", "user_id", userID, "error", err)
+		resp.FailWithMsg(c, "创建应用密码失败，请稍后重试")
+		return
+	}
+
+	resp.OkWithData(c, result)
+}
+
+// DeleteAppPassword 删除应用密码
+// @Summary 删除应用密码
+// @Description 撤销当前用户的应用密码，使用该密码的客户端将无法继续登录
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "应用密码ID"
+// @Success 200 {object} resp.Response "删除成功"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 404 {object} resp.Response "应用密码不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /api/v1/users/app-passwords/{id} [delete]
+func (uc *UserController) DeleteAppPassword(c *gin.Context) {
+	appPasswordID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		resp.FailWithMsg(c, "无效的应用密码ID")
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	if err := service.DeleteAppPassword(userID.(int), appPasswordID); err != nil {
+		if errors.Is(err, service.ErrAppPasswordNotFound) {
+			resp.FailWithCode(c, http.StatusNotFound, err.Error())
+			return
+		}
+		logger.Error("删除应用密码失败", "user_id", userID, "app_password_id", appPasswordID, "error", err)
+		resp.FailWithMsg(c, "删除应用密码失败")
+		return
+	}
+
+	resp.OkWithMsg(c, "删除应用密码成功")
+}
+
 // RegisterRoutes 注册路由
 func (uc *UserController) RegisterRoutes(router *gin.RouterGroup) {
 	userGroup := router.Group("/users")
@@ -545,6 +655,11 @@ func (uc *UserController) RegisterRoutes(router *gin.RouterGroup) {
 		// 更新自己的密码
 		userGroup.PUT("/password", uc.UpdatePassword)
 
+		// 应用密码
+		userGroup.GET("/app-passwords", uc.ListAppPasswords)
+		userGroup.POST("/app-passwords", uc.CreateAppPassword)
+		userGroup.DELETE("/app-passwords/:id", uc.DeleteAppPassword)
+
 		// 需要管理员权限的路由
 		userGroup.Use(middleware.RequirePermission("system:user:list"))
 		{
@@ -0,0 +1,347 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/logger"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/resp"
+)
+
+// WebAuthnController 通行密钥控制器
+type WebAuthnController struct{}
+
+// NewWebAuthnController 创建通行密钥控制器实例
+func NewWebAuthnController() *WebAuthnController {
+	return &WebAuthnController{}
+}
+
+// BeginLogin 开始通行密钥登录
+// @Summary 开始通行密钥登录
+// @Description 返回传给 navigator.credentials.get 的选项和会话ID，5分钟内有效。填写用户名时只能使用该用户的通行密钥，不填时由浏览器列出本站的通行密钥
+// @Tags 认证管理
+// @Accept json
+// @Produce json
+// @Param data body model.WebAuthnLoginBeginForm false "用户名（可选）"
+// @Success 200 {object} resp.Response{data=model.WebAuthnOptions} 返回登录选项
+// @Failure 400 {object} resp.Response 请求参数错误
+// @Failure 500 {object} resp.Response 服务器内部错误
+// @Router /api/v1/auth/webauthn/login/begin [post]
+func (wc *WebAuthnController) BeginLogin(c *gin.Context) {
+	var form model.WebAuthnLoginBeginForm
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&form); err != nil {
+			resp.FailWithValidation(c, err)
+			return
+		}
+	}
+
+	options, err := service.BeginWebAuthnLogin(form.Username)
+	if err != nil {
+		logger.Error("开始通行密钥登录失败", "username", form.Username, "error", err)
+		resp.FailWithMsg(c, "登录失败，请稍后重试")
+		return
+	}
+
+	resp.OkWithData(c, options)
+}
+
+// FinishLogin 完成通行密钥登录
+// @Summary 完成通行密钥登录
+// @Description 提交 navigator.credentials.get 返回的凭据，签名校验通过后签发与密码登录相同的访问令牌和刷新令牌。校验失败计入登录失败次数
+// @Tags 认证管理
+// @Accept json
+// @Produce json
+// @Param data body model.WebAuthnFinishForm true "会话ID和凭据"
+// @Success 200 {object} resp.Response 登录成功，返回令牌和用户信息；认证器未验证用户身份且需要两步验证时返回 mfa_required 和临时令牌
+// @Failure 400 {object} resp.Response 通行密钥验证失败
+// @Failure 401 {object} resp.Response 会话已过期
+// @Failure 403 {object} resp.Response 邮箱尚未验证
+// @Failure 423 {object} resp.Response 账号或IP已被临时锁定
+// @Failure 429 {object} resp.Response 登录尝试过于频繁
+// @Failure 500 {object} resp.Response 服务器内部错误
+// @Router /api/v1/auth/webauthn/login/finish [post]
+func (wc *WebAuthnController) FinishLogin(c *gin.Context) {
+	var form model.WebAuthnFinishForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	tokens, user, err := service.FinishWebAuthnLogin(form.SessionID, form.Credential, sessionClient(c))
+	if err != nil {
+		if user != nil {
+			wc.recordLoginLog(c, &user.UserID, user.Username, "通行密钥验证失败")
+		}
+		var limitErr *service.LoginLimitError
+		switch {
+		case errors.As(err, &limitErr):
+			failLoginLimited(c, limitErr)
+		case errors.Is(err, service.ErrWebAuthnSessionInvalid):
+			resp.FailWithCode(c, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, service.ErrWebAuthnCredentialInvalid):
+			resp.FailWithMsg(c, service.ErrWebAuthnCredentialInvalid.Error())
+		case errors.Is(err, service.ErrUserDisabled):
+			resp.FailWithMsg(c, err.Error())
+		case errors.Is(err, service.ErrEmailNotVerified):
+			resp.FailWithCode(c, http.StatusForbidden, err.Error())
+		default:
+			logger.Error("通行密钥登录失败", "error", err)
+			resp.FailWithMsg(c, "登录失败，请稍后重试")
+		}
+		return
+	}
+
+	// 认证器未验证用户身份且需要两步验证时只返回临时令牌，完成第二步后才签发令牌
+	if tokens.MFA != nil {
+		resp.OkWithData(c, gin.H{
+			"mfa_required": true,
+			"mfa":          tokens.MFA,
+		})
+		return
+	}
+
+	wc.recordLoginLog(c, &user.UserID, user.Username, "")
+	resp.OkWithData(c, gin.H{
+		"access_token":  tokens.Token,
+		"refresh_token": tokens.RefreshToken,
+		"token_type":    tokens.TokenType,
+		"expires_in":    tokens.ExpiresIn,
+		"user_info": gin.H{
+			"user_id":  user.UserID,
+			"username": user.Username,
+			"nickname": user.Nickname,
+			"avatar":   user.Avatar,
+			"email":    user.Email,
+		},
+	})
+}
+
+// BeginRegistration 开始注册通行密钥
+// @Summary 开始注册通行密钥
+// @Description 返回传给 navigator.credentials.create 的选项和会话ID，5分钟内有效。已注册的通行密钥不会重复注册
+// @Tags 认证管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} resp.Response{data=model.WebAuthnOptions} 返回注册选项
+// @Failure 400 {object} resp.Response 通行密钥数量已达上限
+// @Failure 401 {object} resp.Response 未授权
+// @Failure 500 {object} resp.Response 服务器内部错误
+// @Router /api/v1/auth/webauthn/register/begin [post]
+func (wc *WebAuthnController) BeginRegistration(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	options, err := service.BeginWebAuthnRegistration(userID.(int))
+	if err != nil {
+		wc.fail(c, userID.(int), err)
+		return
+	}
+
+	resp.OkWithData(c, options)
+}
+
+// FinishRegistration 完成注册通行密钥
+// @Summary 完成注册通行密钥
+// @Description 提交 navigator.credentials.create 返回的凭据，校验通过后保存，之后可使用该通行密钥登录
+// @Tags 认证管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param data body model.WebAuthnFinishForm true "会话ID、凭据和名称"
+// @Success 200 {object} resp.Response{data=model.WebAuthnCredential} 返回注册的通行密钥
+// @Failure 400 {object} resp.Response 通行密钥验证失败或已注册
+// @Failure 401 {object} resp.Response 未授权或会话已过期
+// @Failure 500 {object} resp.Response 服务器内部错误
+// @Router /api/v1/auth/webauthn/register/finish [post]
+func (wc *WebAuthnController) FinishRegistration(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var form model.WebAuthnFinishForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	credential, err := service.FinishWebAuthnRegistration(userID.(int), form.SessionID, form.Name, form.Credential)
+	if err != nil {
+		wc.fail(c, userID.(int), err)
+		return
+	}
+
+	resp.OkWithData(c, credential)
+}
+
+// ListCredentials 获取当前用户的通行密钥
+// @Summary 获取通行密钥列表
+// @Description 获取当前用户注册的全部通行密钥
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} resp.Response{data=[]model.WebAuthnCredential} "返回通行密钥列表"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /api/v1/user/webauthn/credentials [get]
+func (wc *WebAuthnController) ListCredentials(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	credentials, err := service.ListWebAuthnCredentials(userID.(int))
+	if err != nil {
+		logger.Error("获取通行密钥列表失败", "user_id", userID, "error", err)
+		resp.FailWithMsg(c, "获取通行密钥列表失败")
+		return
+	}
+
+	resp.OkWithData(c, credentials)
+}
+
+// RenameCredential 重命名通行密钥
+// @Summary 重命名通行密钥
+// @Description 修改当前用户的通行密钥名称，便于区分不同设备
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "通行密钥ID"
+// @Param data body model.WebAuthnRenameForm true "名称"
+// @Success 200 {object} resp.Response "修改成功"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 404 {object} resp.Response "通行密钥不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /api/v1/user/webauthn/credentials/{id} [put]
+func (wc *WebAuthnController) RenameCredential(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	credentialID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || credentialID <= 0 {
+		resp.FailWithMsg(c, "无效的通行密钥ID")
+		return
+	}
+
+	var form model.WebAuthnRenameForm
+	if err := c.ShouldBindJSON(&form); err != nil {
+		resp.FailWithValidation(c, err)
+		return
+	}
+
+	if err := service.RenameWebAuthnCredential(userID.(int), credentialID, form.Name); err != nil {
+		wc.fail(c, userID.(int), err)
+		return
+	}
+
+	resp.OkWithMsg(c, "修改成功")
+}
+
+// DeleteCredential 删除通行密钥
+// @Summary 删除通行密钥
+// @Description 删除当前用户的通行密钥，删除后该通行密钥不能再登录，认证器中的凭据需用户自行移除
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "通行密钥ID"
+// @Success 200 {object} resp.Response "删除成功"
+// @Failure 400 {object} resp.Response "请求参数错误"
+// @Failure 401 {object} resp.Response "未授权"
+// @Failure 404 {object} resp.Response "通行密钥不存在"
+// @Failure 500 {object} resp.Response "服务器内部错误"
+// @Router /api/v1/user/webauthn/credentials/{id} [delete]
+func (wc *WebAuthnController) DeleteCredential(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		resp.FailWithCode(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	credentialID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || credentialID <= 0 {
+		resp.FailWithMsg(c, "无效的通行密钥ID")
+		return
+	}
+
+	if err := service.DeleteWebAuthnCredential(userID.(int), credentialID); err != nil {
+		wc.fail(c, userID.(int), err)
+		return
+	}
+
+	resp.OkWithMsg(c, "删除成功")
+}
+
+// fail 返回通行密钥操作失败的结果
+func (wc *WebAuthnController) fail(c *gin.Context, userID int, err error) {
+	switch {
+	case errors.Is(err, service.ErrWebAuthnSessionInvalid):
+		resp.FailWithCode(c, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, service.ErrWebAuthnCredentialNotFound):
+		resp.FailWithCode(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrWebAuthnCredentialInvalid),
+		errors.Is(err, service.ErrWebAuthnCredentialExists),
+		errors.Is(err, service.ErrWebAuthnTooManyCredentials):
+		resp.FailWithMsg(c, err.Error())
+	default:
+		logger.Error("通行密钥操作失败", "user_id", userID, "error", err)
+		resp.FailWithMsg(c, "操作失败，请稍后重试")
+	}
+}
+
+// recordLoginLog 记录通行密钥登录日志
+func (wc *WebAuthnController) recordLoginLog(c *gin.Context, userID *int, username string, failReason string) {
+	client := sessionClient(c)
+	loginLog := model.LoginLog{
+		UserID:      userID,
+		Username:    username,
+		LoginType:   model.LoginTypePasskey,
+		LoginStatus: model.LoginStatusSuccess,
+		Action:      model.LoginActionLogin,
+		IPAddress:   client.IPAddress,
+		UserAgent:   client.UserAgent,
+		FailReason:  failReason,
+	}
+	if failReason != "" {
+		loginLog.LoginStatus = model.LoginStatusFailed
+	}
+
+	service.RecordLoginLog(loginLog)
+}
+
+// RegisterPublicRoutes 注册无需认证的登录路由
+func (wc *WebAuthnController) RegisterPublicRoutes(router *gin.RouterGroup) {
+	router.POST("/webauthn/login/begin", wc.BeginLogin)
+	router.POST("/webauthn/login/finish", wc.FinishLogin)
+}
+
+// RegisterAuthRoutes 注册需要登录的注册路由
+func (wc *WebAuthnController) RegisterAuthRoutes(router *gin.RouterGroup) {
+	router.POST("/webauthn/register/begin", wc.BeginRegistration)
+	router.POST("/webauthn/register/finish", wc.FinishRegistration)
+}
+
+// RegisterRoutes 注册用户路由
+func (wc *WebAuthnController) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/webauthn/credentials", wc.ListCredentials)
+	router.PUT("/webauthn/credentials/:id", wc.RenameCredential)
+	router.DELETE("/webauthn/credentials/:id", wc.DeleteCredential)
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/config"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
+)
+
+// runCommand 执行命令行子命令，数据库和Redis已初始化
+func runCommand(cfg *config.Config, name string, args []string) error {
+	switch name {
+	case "export":
+		return runExport(cfg, args)
+	case "import":
+		return runImport(args)
+	case "import-wordpress":
+		return runImportWordPress(args)
+	case "build-static":
+		return runBuildStatic(cfg, args)
+	}
+	return fmt.Errorf("未知命令: %s", name)
+}
+
+// runImport 从 Hexo、Hugo、Jekyll 的Markdown压缩包导入文章，结果以JSON输出
+//
+// 用法：server import -file posts.zip -user 1 [-dry-run] [-on-conflict skip|rename]
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := fs.String("file", "", "Markdown文章压缩包(zip)")
+	userID := fs.Int("user", 0, "文章作者的用户ID")
+	dryRun := fs.Bool("dry-run", false, "只预检不导入，报告文章标识冲突等问题")
+	onConflict := fs.String("on-conflict", model.ImportConflictSkip, "文章标识冲突时的处理方式：skip跳过，rename追加序号")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" || *userID <= 0 {
+		fs.Usage()
+		return errors.New("必须指定 -file 和 -user")
+	}
+	if *onConflict != model.ImportConflictSkip && *onConflict != model.ImportConflictRename {
+		return fmt.Errorf("无效的冲突处理方式: %s", *onConflict)
+	}
+
+	if err := checkUserExists(*userID); err != nil {
+		return err
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	result, err := service.ImportMarkdownArchive(f, info.Size(), *userID, nil, model.ImportOptions{
+		DryRun:     *dryRun,
+		OnConflict: *onConflict,
+	})
+	if err != nil {
+		return err
+	}
+
+	return printResult(result)
+}
+
+// runImportWordPress 从 WordPress 导出文件导入文章，结果以JSON输出
+//
+// 用法：server import-wordpress -file export.xml -user 1 [-uploads wp-content/uploads] [-author-map admin=1,editor=2] [-dry-run] [-on-conflict skip|rename]
+func runImportWordPress(args []string) error {
+	fs := flag.NewFlagSet("import-wordpress", flag.ContinueOnError)
+	file := fs.String("file", "", "WordPress导出文件(xml)")
+	uploadsDir := fs.String("uploads", "", "WordPress上传目录(wp-content/uploads)，不指定时保留附件原地址")
+	userID := fs.Int("user", 0, "执行导入的用户ID，未对应到用户的文章以此用户为作者")
+	authorMap := fs.String("author-map", "", "作者对应关系，格式为 登录名=用户ID，多个以逗号分隔")
+	dryRun := fs.Bool("dry-run", false, "只预检不导入，报告文章标识冲突等问题")
+	onConflict := fs.String("on-conflict", model.ImportConflictSkip, "文章标识冲突时的处理方式：skip跳过，rename追加序号")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" || *userID <= 0 {
+		fs.Usage()
+		return errors.New("必须指定 -file 和 -user")
+	}
+	if *onConflict != model.ImportConflictSkip && *onConflict != model.ImportConflictRename {
+		return fmt.Errorf("无效的冲突处理方式: %s", *onConflict)
+	}
+	authors, err := service.ParseImportAuthorMap(*authorMap)
+	if err != nil {
+		return err
+	}
+	if err := checkUserExists(*userID); err != nil {
+		return err
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	result, err := service.ImportWordPress(f, uploadsFS(*uploadsDir), *userID, nil, model.ImportOptions{
+		DryRun:     *dryRun,
+		OnConflict: *onConflict,
+	}, authors)
+	if err != nil {
+		return err
+	}
+
+	return printResult(result)
+}
+
+// runBuildStatic 全量生成静态站点，结果以JSON输出
+//
+// 用法：server build-static [-output ./public] [-templates ./templates]
+func runBuildStatic(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("build-static", flag.ContinueOnError)
+	output := fs.String("output", cfg.Static.OutputDir, "输出目录")
+	templates := fs.String("templates", cfg.Static.TemplateDir, "站点模板目录，其中的同名文件替换内置模板")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	static := cfg.Static
+	static.OutputDir = *output
+	static.TemplateDir = *templates
+	if err := service.InitStaticSite(static); err != nil {
+		return err
+	}
+
+	result, err := service.BuildStaticSite()
+	if err != nil {
+		return err
+	}
+
+	return printResult(result)
+}
+
+// runExport 将全站内容导出为zip压缩包，导出说明以JSON输出
+//
+// 用法：server export -output site.zip [-versions] [-skip-files] [-after-id 0] [-limit 0]
+func runExport(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := fs.String("output", "", "导出文件(zip)")
+	versions := fs.Bool("versions", false, "导出文章历史版本")
+	skipFiles := fs.Bool("skip-files", false, "跳过上传文件")
+	afterID := fs.Int64("after-id", 0, "从该文章ID之后继续导出")
+	limit := fs.Int("limit", 0, "最多导出的文章数，0为不限制")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *output == "" {
+		fs.Usage()
+		return errors.New("必须指定 -output")
+	}
+	if *afterID < 0 || *limit < 0 {
+		return errors.New("-after-id 和 -limit 不能为负数")
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	manifest, err := service.ExportSite(f, cfg.Upload, model.ExportOptions{
+		IncludeVersions: *versions,
+		AfterID:         *afterID,
+		Limit:           *limit,
+		SkipFiles:       *skipFiles,
+	})
+	if err != nil {
+		f.Close()
+		os.Remove(*output)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return printResult(manifest)
+}
+
+// uploadsFS 返回上传目录，未指定时返回空
+func uploadsFS(dir string) fs.FS {
+	if dir == "" {
+		return nil
+	}
+	return os.DirFS(dir)
+}
+
+// checkUserExists 检查用户是否存在
+func checkUserExists(userID int) error {
+	var count int64
+	if err := model.DB.Model(&model.User{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("用户不存在: %d", userID)
+	}
+	return nil
+}
+
+// printResult 以JSON格式输出导入结果
+func printResult(result interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
@@ -8,24 +8,43 @@ import (
 
 // Config 总配置结构体
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	Log      LogConfig      `mapstructure:"log"`
-	Upload   UploadConfig   `mapstructure:"upload"`
-	Swagger  SwaggerConfig  `mapstructure:"swagger"`
+	Server    ServerConfig    `mapstructure:"server"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	Redis     RedisConfig     `mapstructure:"redis"`
+	Log       LogConfig       `mapstructure:"log"`
+	Upload    UploadConfig    `mapstructure:"upload"`
+	Swagger   SwaggerConfig   `mapstructure:"swagger"`
+	Scheduler SchedulerConfig `mapstructure:"scheduler"`
+	Search    SearchConfig    `mapstructure:"search"`
+	Static    StaticConfig    `mapstructure:"static"`
+	WebAuthn  WebAuthnConfig  `mapstructure:"webauthn"`
+	OAuth     OAuthConfig     `mapstructure:"oauth"`
 }
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Host             string     `mapstructure:"host"`
-	Port             int        `mapstructure:"port"`
-	Mode             string     `mapstructure:"mode"`
-	JWTSecret        string     `mapstructure:"jwt_secret"`
-	JWTExpire        int        `mapstructure:"jwt_expire"`
-	JWTIssuer        string     `mapstructure:"jwt_issuer"`
-	JWTRefreshExpire int        `mapstructure:"jwt_refresh_expire"`
-	Cors             CorsConfig `mapstructure:"cors"`
+	Host               string         `mapstructure:"host"`
+	Port               int            `mapstructure:"port"`
+	Mode               string         `mapstructure:"mode"`
+	JWTSecret          string         `mapstructure:"jwt_secret"`
+	JWTExpire          int            `mapstructure:"jwt_expire"`
+	JWTIssuer          string         `mapstructure:"jwt_issuer"`
+	JWTRefreshExpire   int            `mapstructure:"jwt_refresh_expire"`
+	JWTKeys            []JWTKeyConfig `mapstructure:"jwt_keys"`             // 非对称签名密钥，为空时使用 jwt_secret 以 HS256 签名
+	MFASecretKey       string         `mapstructure:"mfa_secret_key"`       // 加密两步验证密钥，为空时由 jwt_secret 派生
+	AccountTokenSecret string         `mapstructure:"account_token_secret"` // 签名邮件验证、找回密码等链接中的令牌，为空时使用 jwt_secret
+	Cors               CorsConfig     `mapstructure:"cors"`
+}
+
+// JWTKeyConfig 令牌签名密钥配置。轮换时先添加新密钥并设置 not_before，新密钥提前在JWKS中公布，
+// 到时间后接替签名；旧密钥设置 expire_at，在此之前的令牌最长有效期内不再签名，只验证已签发的令牌
+type JWTKeyConfig struct {
+	KeyID          string `mapstructure:"kid"`              // 为空时使用公钥的 RFC 7638 指纹
+	Algorithm      string `mapstructure:"algorithm"`        // RS256、ES256 或 EdDSA，为空时根据密钥类型确定
+	PrivateKeyFile string `mapstructure:"private_key_file"` // PEM 格式的私钥文件
+	PublicKeyFile  string `mapstructure:"public_key_file"`  // 私钥已销毁的旧密钥只配置公钥文件，用于验证其签发的令牌
+	NotBefore      string `mapstructure:"not_before"`       // 开始用于签名的时间(RFC3339)，为空表示立即
+	ExpireAt       string `mapstructure:"expire_at"`        // 停止验证的时间(RFC3339)，为空表示不过期
 }
 
 // CorsConfig CORS配置
@@ -97,6 +116,53 @@ type SwaggerConfig struct {
 	BasePath    string `mapstructure:"base_path"`
 }
 
+// SchedulerConfig 后台调度器配置
+type SchedulerConfig struct {
+	Enabled   bool `mapstructure:"enabled"`
+	Interval  int  `mapstructure:"interval"`
+	BatchSize int  `mapstructure:"batch_size"`
+}
+
+// SearchConfig 文章搜索配置
+type SearchConfig struct {
+	UserDict string `mapstructure:"user_dict"` // 用户词典路径，为空时仅使用内置词典
+}
+
+// StaticConfig 静态站点生成配置
+type StaticConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`      // 文章变更后是否自动增量生成
+	OutputDir   string `mapstructure:"output_dir"`   // 输出目录
+	TemplateDir string `mapstructure:"template_dir"` // 站点模板目录，其中的同名文件替换内置模板
+	BaseURL     string `mapstructure:"base_url"`     // 站点地址，系统配置 site_url 为空时使用
+	PageSize    int    `mapstructure:"page_size"`    // 列表页每页文章数
+}
+
+// WebAuthnConfig 通行密钥配置
+type WebAuthnConfig struct {
+	RPID    string   `mapstructure:"rp_id"`   // 依赖方ID，即站点域名，通行密钥与其绑定，上线后不能再修改
+	RPName  string   `mapstructure:"rp_name"` // 站点名称，显示在浏览器的通行密钥提示中
+	Origins []string `mapstructure:"origins"` // 允许发起请求的前端地址，如 https://blog.example.com
+}
+
+// OAuthConfig 第三方登录配置
+type OAuthConfig struct {
+	Providers []OAuthProviderConfig `mapstructure:"providers"`
+}
+
+// OAuthProviderConfig 第三方登录服务配置，回调地址为 网站地址/oauth/callback/{name}，需在第三方服务中登记
+type OAuthProviderConfig struct {
+	Name         string   `mapstructure:"name"`          // 唯一标识，用于接口路径和回调地址，已有用户关联后不能再修改
+	DisplayName  string   `mapstructure:"display_name"`  // 登录按钮上显示的名称
+	Type         string   `mapstructure:"type"`          // github、google、oidc、wechat，微信只能配置一个
+	ClientID     string   `mapstructure:"client_id"`     // 微信为 AppID
+	ClientSecret string   `mapstructure:"client_secret"` // 微信为 AppSecret
+	Issuer       string   `mapstructure:"issuer"`        // OIDC 签发者地址，从 issuer/.well-known/openid-configuration 获取接口地址
+	Scopes       []string `mapstructure:"scopes"`        // 为空时使用默认权限
+	AuthURL      string   `mapstructure:"auth_url"`      // 以下地址为空时使用默认地址或发现文档中的地址
+	TokenURL     string   `mapstructure:"token_url"`
+	UserInfoURL  string   `mapstructure:"userinfo_url"`
+}
+
 // LoadConfig 加载配置文件
 func LoadConfig(configPath string) (*Config, error) {
 	viper.AddConfigPath(configPath)
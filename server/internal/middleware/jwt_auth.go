@@ -8,11 +8,14 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
+	jwt_utils "github.com/sunmoonstrand/go-react-blog/server/internal/utils/jwt"
 	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/response"
+	"go.uber.org/zap"
 )
 
-// JWTAuth JWT认证中间件
-func JWTAuth(secret string) gin.HandlerFunc {
+// JWTAuth JWT认证中间件，按令牌头中的 kid 选择验证密钥，同时接受个人访问令牌
+func JWTAuth(keys *jwt_utils.KeySet) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 获取Authorization头
 		authHeader := c.GetHeader("Authorization")
@@ -30,18 +33,18 @@ func JWTAuth(secret string) gin.HandlerFunc {
 			return
 		}
 
-		// 解析JWT
+		// 个人访问令牌
 		tokenString := parts[1]
+		if service.IsAccessToken(tokenString) {
+			accessTokenAuth(c, tokenString)
+			return
+		}
+
+		// 解析JWT
 		claims := &model.CustomClaims{}
 
 		// 解析token
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			// 验证签名算法
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("无效的签名算法")
-			}
-			return []byte(secret), nil
-		})
+		token, err := jwt.ParseWithClaims(tokenString, claims, keys.Keyfunc)
 
 		// 处理解析错误
 		if err != nil {
@@ -54,8 +57,8 @@ func JWTAuth(secret string) gin.HandlerFunc {
 			return
 		}
 
-		// 验证token有效性
-		if !token.Valid {
+		// 验证token有效性，刷新令牌和两步验证临时令牌不能用于访问接口
+		if !token.Valid || claims.TokenType != model.TokenTypeAccess {
 			response.Unauthorized(c, "无效的认证令牌")
 			c.Abort()
 			return
@@ -68,11 +71,60 @@ func JWTAuth(secret string) gin.HandlerFunc {
 			return
 		}
 
+		// 检查令牌是否已退出登录或所属会话已被移除
+		if err := service.CheckTokenRevoked(claims, c.ClientIP()); err != nil {
+			if errors.Is(err, service.ErrTokenRevoked) {
+				response.Unauthorized(c, err.Error())
+			} else {
+				zap.L().Error("检查令牌吊销状态失败", zap.Int("user_id", claims.UserID), zap.Error(err))
+				response.ServerError(c, "认证服务暂不可用")
+			}
+			c.Abort()
+			return
+		}
+
 		// 将用户信息存储到上下文
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("role_ids", claims.RoleIDs)
+		c.Set("claims", claims)
+
+		c.Next()
+	}
+}
+
+// accessTokenAuth 使用个人访问令牌认证，令牌的权限范围存储到上下文的 token_scopes 中，由权限中间件进一步限制
+func accessTokenAuth(c *gin.Context, token string) {
+	identity, err := service.AuthenticateAccessToken(token, c.ClientIP())
+	if err != nil {
+		if errors.Is(err, service.ErrAccessTokenInvalid) {
+			response.Unauthorized(c, err.Error())
+		} else {
+			zap.L().Error("校验访问令牌失败", zap.Error(err))
+			response.ServerError(c, "认证服务暂不可用")
+		}
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", identity.UserID)
+	c.Set("username", identity.Username)
+	c.Set("role_ids", identity.RoleIDs)
+	c.Set("token_id", identity.TokenID)
+	c.Set("token_scopes", identity.Scopes)
 
+	c.Next()
+}
+
+// RejectAccessToken 拒绝个人访问令牌，用于账号安全相关的接口，
+// 避免泄露的令牌被用来修改密码、创建新令牌等超出其权限范围的操作
+func RejectAccessToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("token_scopes"); exists {
+			response.Forbidden(c, "访问令牌不能用于此操作，请登录后重试")
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
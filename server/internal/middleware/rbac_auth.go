@@ -7,10 +7,10 @@ import (
 	"go.uber.org/zap"
 )
 
-// RBACAuth RBAC权限控制中间件
-func RBACAuth() gin.HandlerFunc {
+// RequirePermission 权限标识校验中间件，要求当前用户拥有指定的权限标识，
+// 使用个人访问令牌时该权限标识还需在令牌的权限范围内
+func RequirePermission(permKey string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 获取当前用户ID
 		userID, exists := c.Get("user_id")
 		if !exists {
 			response.Unauthorized(c, "请先登录")
@@ -18,36 +18,17 @@ func RBACAuth() gin.HandlerFunc {
 			return
 		}
 
-		// 获取当前请求的路径和方法
-		requestPath := c.Request.URL.Path
-		requestMethod := c.Request.Method
-
-		// 获取用户角色ID列表
-		roleIDs, exists := c.Get("role_ids")
-		if !exists {
-			zap.L().Error("用户角色信息缺失", zap.Any("user_id", userID))
-			response.Forbidden(c, "权限不足")
+		if scopes, ok := c.Get("token_scopes"); ok && !hasScope(scopes.([]string), permKey) {
+			response.Forbidden(c, "访问令牌权限不足")
 			c.Abort()
 			return
 		}
 
-		// 检查是否为超级管理员
-		for _, roleID := range roleIDs.([]int) {
-			// 角色ID为1表示超级管理员
-			if roleID == 1 {
-				c.Next()
-				return
-			}
-		}
-
-		// 检查用户是否有权限访问当前路径
-		hasPermission, err := service.CheckPermission(userID.(int), roleIDs.([]int), requestPath, requestMethod)
+		hasPermission, err := service.HasPermission(userID.(int), permKey)
 		if err != nil {
 			zap.L().Error("权限检查失败",
 				zap.Any("user_id", userID),
-				zap.Any("role_ids", roleIDs),
-				zap.String("path", requestPath),
-				zap.String("method", requestMethod),
+				zap.String("perm_key", permKey),
 				zap.Error(err),
 			)
 			response.ServerError(c, "权限检查失败")
@@ -64,3 +45,13 @@ func RBACAuth() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// hasScope 判断个人访问令牌的权限范围是否包含指定权限标识
+func hasScope(scopes []string, permKey string) bool {
+	for _, scope := range scopes {
+		if scope == permKey {
+			return true
+		}
+	}
+	return false
+}
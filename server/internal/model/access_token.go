@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// PersonalAccessToken 个人访问令牌，供CI等自动化脚本调用接口，权限限定在创建时选择的范围内
+type PersonalAccessToken struct {
+	TokenID     int        `gorm:"column:token_id;primaryKey;autoIncrement" json:"token_id"`
+	UserID      int        `gorm:"column:user_id;not null" json:"user_id"`
+	Name        string     `gorm:"column:name;size:50;not null" json:"name"`
+	TokenPrefix string     `gorm:"column:token_prefix;size:20;not null" json:"token_prefix"` // 令牌开头几位，便于用户辨认
+	TokenHash   string     `gorm:"column:token_hash;size:64;not null;unique" json:"-"`
+	Scopes      string     `gorm:"column:scopes;type:text;not null" json:"-"` // 权限标识，以逗号分隔
+	ScopeList   []string   `gorm:"-" json:"scopes"`
+	ExpiresAt   time.Time  `gorm:"column:expires_at;not null" json:"expires_at"`
+	LastUsedAt  *time.Time `gorm:"column:last_used_at" json:"last_used_at"`
+	LastUsedIP  *string    `gorm:"column:last_used_ip;type:inet" json:"last_used_ip"`
+	CreatedAt   time.Time  `gorm:"column:created_at;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName 指定表名
+func (PersonalAccessToken) TableName() string {
+	return "sys_personal_access_tokens"
+}
+
+// PersonalAccessTokenCreateForm 个人访问令牌创建表单
+type PersonalAccessTokenCreateForm struct {
+	Name      string   `json:"name" binding:"required,max=50" example:"CI发布版本说明"`
+	Scopes    []string `json:"scopes" binding:"required,min=1,max=50,dive,required,max=50" example:"content:article:add"` // 不能超出当前账号拥有的权限
+	ExpiresIn int      `json:"expires_in" binding:"required,min=1,max=365" example:"90"`                                  // 有效天数
+}
+
+// PersonalAccessTokenCreateResult 个人访问令牌创建结果，令牌明文只返回这一次
+type PersonalAccessTokenCreateResult struct {
+	PersonalAccessToken
+	Token string `json:"token"`
+}
+
+// AccessTokenIdentity 个人访问令牌认证通过后的请求身份
+type AccessTokenIdentity struct {
+	TokenID  int
+	UserID   int
+	Username string
+	RoleIDs  []int
+	Scopes   []string
+}
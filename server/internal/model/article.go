@@ -2,8 +2,51 @@ package model
 
 import (
 	"time"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/diff"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/render"
+)
+
+// 文章状态
+const (
+	ArticleStatusDraft     int8 = 1 // 草稿
+	ArticleStatusPending   int8 = 2 // 待审核
+	ArticleStatusPublished int8 = 3 // 已发布
+	ArticleStatusOffline   int8 = 4 // 已下线
+)
+
+// ArticleStatusNames 文章状态名称
+var ArticleStatusNames = map[int8]string{
+	ArticleStatusDraft:     "草稿",
+	ArticleStatusPending:   "待审核",
+	ArticleStatusPublished: "已发布",
+	ArticleStatusOffline:   "已下线",
+}
+
+// 文章审核操作类型
+const (
+	ReviewActionSubmit          int8 = 1 // 提交审核
+	ReviewActionApprove         int8 = 2 // 审核通过
+	ReviewActionReject          int8 = 3 // 审核驳回
+	ReviewActionWithdraw        int8 = 4 // 撤回
+	ReviewActionPublish         int8 = 5 // 直接发布
+	ReviewActionOffline         int8 = 6 // 下线
+	ReviewActionSchedulePublish int8 = 7 // 定时发布
+	ReviewActionScheduleOffline int8 = 8 // 定时下线
 )
 
+// ReviewActionNames 审核操作类型名称
+var ReviewActionNames = map[int8]string{
+	ReviewActionSubmit:          "提交审核",
+	ReviewActionApprove:         "审核通过",
+	ReviewActionReject:          "审核驳回",
+	ReviewActionWithdraw:        "撤回",
+	ReviewActionPublish:         "直接发布",
+	ReviewActionOffline:         "下线",
+	ReviewActionSchedulePublish: "定时发布",
+	ReviewActionScheduleOffline: "定时下线",
+}
+
 // Article 文章模型
 type Article struct {
 	ArticleID      int64          `gorm:"column:article_id;primaryKey;autoIncrement" json:"article_id"`
@@ -26,6 +69,9 @@ type Article struct {
 	SourceURL      string         `gorm:"column:source_url;size:255" json:"source_url"`
 	SourceName     string         `gorm:"column:source_name;size:100" json:"source_name"`
 	PublishTime    time.Time      `gorm:"column:publish_time" json:"publish_time"`
+	ScheduleTime   *time.Time     `gorm:"column:schedule_time" json:"schedule_time"`
+	ExpireTime     *time.Time     `gorm:"column:expire_time" json:"expire_time"`
+	RejectReason   string         `gorm:"column:reject_reason;size:500" json:"reject_reason"`
 	CreatedAt      time.Time      `gorm:"column:created_at;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt      time.Time      `gorm:"column:updated_at;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
 	User           User           `gorm:"foreignKey:UserID" json:"user"`
@@ -58,46 +104,93 @@ func (ArticleContent) TableName() string {
 
 // ArticleCreateForm 文章创建表单
 type ArticleCreateForm struct {
-	Title          string `json:"title" binding:"required,max=200" example:"文章标题"`
-	ArticleKey     string `json:"article_key" binding:"required,max=200" example:"article-key"`
-	Content        string `json:"content" binding:"required" example:"文章内容..."`
-	ContentFormat  int8   `json:"content_format" binding:"required,oneof=1 2" example:"1"`
-	Summary        string `json:"summary" binding:"omitempty,max=500" example:"文章摘要..."`
-	Thumbnail      string `json:"thumbnail" binding:"omitempty,max=255" example:"http://example.com/image.jpg"`
-	Status         int8   `json:"status" binding:"required,oneof=1 2 3 4" example:"1"`
-	ArticleType    int8   `json:"article_type" binding:"required,oneof=1 2 3 4" example:"1"`
-	CategoryIDs    []int  `json:"category_ids" binding:"required" example:"1,2"`
-	TagIDs         []int  `json:"tag_ids" binding:"omitempty" example:"1,2,3"`
-	AllowComment   bool   `json:"allow_comment" example:"true"`
-	IsTop          bool   `json:"is_top" example:"false"`
-	IsRecommend    bool   `json:"is_recommend" example:"false"`
-	SEOTitle       string `json:"seo_title" binding:"omitempty,max=100" example:"SEO标题"`
-	SEOKeywords    string `json:"seo_keywords" binding:"omitempty,max=200" example:"关键词1,关键词2"`
-	SEODescription string `json:"seo_description" binding:"omitempty,max=300" example:"SEO描述..."`
-	SourceURL      string `json:"source_url" binding:"omitempty,max=255" example:"http://example.com/source"`
-	SourceName     string `json:"source_name" binding:"omitempty,max=100" example:"来源网站"`
+	Title          string     `json:"title" binding:"required,max=200" example:"文章标题"`
+	ArticleKey     string     `json:"article_key" binding:"required,max=200" example:"article-key"`
+	Content        string     `json:"content" binding:"required" example:"文章内容..."`
+	ContentFormat  int8       `json:"content_format" binding:"required,oneof=1 2" example:"1"`
+	Summary        string     `json:"summary" binding:"omitempty,max=500" example:"文章摘要..."`
+	Thumbnail      string     `json:"thumbnail" binding:"omitempty,max=255" example:"http://example.com/image.jpg"`
+	Status         int8       `json:"status" binding:"required,oneof=1 2 3 4" example:"1"`
+	ArticleType    int8       `json:"article_type" binding:"required,oneof=1 2 3 4" example:"1"`
+	CategoryIDs    []int      `json:"category_ids" binding:"required" example:"1,2"`
+	TagIDs         []int      `json:"tag_ids" binding:"omitempty" example:"1,2,3"`
+	AllowComment   bool       `json:"allow_comment" example:"true"`
+	IsTop          bool       `json:"is_top" example:"false"`
+	IsRecommend    bool       `json:"is_recommend" example:"false"`
+	SEOTitle       string     `json:"seo_title" binding:"omitempty,max=100" example:"SEO标题"`
+	SEOKeywords    string     `json:"seo_keywords" binding:"omitempty,max=200" example:"关键词1,关键词2"`
+	SEODescription string     `json:"seo_description" binding:"omitempty,max=300" example:"SEO描述..."`
+	SourceURL      string     `json:"source_url" binding:"omitempty,max=255" example:"http://example.com/source"`
+	SourceName     string     `json:"source_name" binding:"omitempty,max=100" example:"来源网站"`
+	ScheduleTime   *time.Time `json:"schedule_time" binding:"omitempty" example:"2025-01-01T08:00:00+08:00"`
+	ExpireTime     *time.Time `json:"expire_time" binding:"omitempty" example:"2025-12-31T23:59:59+08:00"`
 }
 
 // ArticleUpdateForm 文章更新表单
 type ArticleUpdateForm struct {
-	Title          string `json:"title" binding:"omitempty,max=200" example:"文章标题"`
-	ArticleKey     string `json:"article_key" binding:"omitempty,max=200" example:"article-key"`
-	Content        string `json:"content" binding:"omitempty" example:"文章内容..."`
-	ContentFormat  int8   `json:"content_format" binding:"omitempty,oneof=1 2" example:"1"`
-	Summary        string `json:"summary" binding:"omitempty,max=500" example:"文章摘要..."`
-	Thumbnail      string `json:"thumbnail" binding:"omitempty,max=255" example:"http://example.com/image.jpg"`
-	Status         int8   `json:"status" binding:"omitempty,oneof=1 2 3 4" example:"1"`
-	ArticleType    int8   `json:"article_type" binding:"omitempty,oneof=1 2 3 4" example:"1"`
-	CategoryIDs    []int  `json:"category_ids" binding:"omitempty" example:"1,2"`
-	TagIDs         []int  `json:"tag_ids" binding:"omitempty" example:"1,2,3"`
-	AllowComment   bool   `json:"allow_comment" example:"true"`
-	IsTop          bool   `json:"is_top" example:"false"`
-	IsRecommend    bool   `json:"is_recommend" example:"false"`
-	SEOTitle       string `json:"seo_title" binding:"omitempty,max=100" example:"SEO标题"`
-	SEOKeywords    string `json:"seo_keywords" binding:"omitempty,max=200" example:"关键词1,关键词2"`
-	SEODescription string `json:"seo_description" binding:"omitempty,max=300" example:"SEO描述..."`
-	SourceURL      string `json:"source_url" binding:"omitempty,max=255" example:"http://example.com/source"`
-	SourceName     string `json:"source_name" binding:"omitempty,max=100" example:"来源网站"`
+	Title          string     `json:"title" binding:"omitempty,max=200" example:"文章标题"`
+	ArticleKey     string     `json:"article_key" binding:"omitempty,max=200" example:"article-key"`
+	Content        string     `json:"content" binding:"omitempty" example:"文章内容..."`
+	ContentFormat  int8       `json:"content_format" binding:"omitempty,oneof=1 2" example:"1"`
+	Summary        string     `json:"summary" binding:"omitempty,max=500" example:"文章摘要..."`
+	Thumbnail      string     `json:"thumbnail" binding:"omitempty,max=255" example:"http://example.com/image.jpg"`
+	Status         int8       `json:"status" binding:"omitempty,oneof=1 2 3 4" example:"1"`
+	ArticleType    int8       `json:"article_type" binding:"omitempty,oneof=1 2 3 4" example:"1"`
+	CategoryIDs    []int      `json:"category_ids" binding:"omitempty" example:"1,2"`
+	TagIDs         []int      `json:"tag_ids" binding:"omitempty" example:"1,2,3"`
+	AllowComment   bool       `json:"allow_comment" example:"true"`
+	IsTop          bool       `json:"is_top" example:"false"`
+	IsRecommend    bool       `json:"is_recommend" example:"false"`
+	SEOTitle       string     `json:"seo_title" binding:"omitempty,max=100" example:"SEO标题"`
+	SEOKeywords    string     `json:"seo_keywords" binding:"omitempty,max=200" example:"关键词1,关键词2"`
+	SEODescription string     `json:"seo_description" binding:"omitempty,max=300" example:"SEO描述..."`
+	SourceURL      string     `json:"source_url" binding:"omitempty,max=255" example:"http://example.com/source"`
+	SourceName     string     `json:"source_name" binding:"omitempty,max=100" example:"来源网站"`
+	ScheduleTime   *time.Time `json:"schedule_time" binding:"omitempty" example:"2025-01-01T08:00:00+08:00"`
+	ExpireTime     *time.Time `json:"expire_time" binding:"omitempty" example:"2025-12-31T23:59:59+08:00"`
+}
+
+// ArticleScheduleForm 文章定时发布/下线表单，时间为空表示取消对应的定时任务
+type ArticleScheduleForm struct {
+	ScheduleTime *time.Time `json:"schedule_time" binding:"omitempty" example:"2025-01-01T08:00:00+08:00"`
+	ExpireTime   *time.Time `json:"expire_time" binding:"omitempty" example:"2025-12-31T23:59:59+08:00"`
+}
+
+// ArticleReview 文章审核记录模型
+type ArticleReview struct {
+	ReviewID   int64     `gorm:"column:review_id;primaryKey;autoIncrement" json:"review_id"`
+	ArticleID  int64     `gorm:"column:article_id;not null" json:"article_id"`
+	Action     int8      `gorm:"column:action;not null" json:"action"`
+	FromStatus int8      `gorm:"column:from_status;not null" json:"from_status"`
+	ToStatus   int8      `gorm:"column:to_status;not null" json:"to_status"`
+	OperatorID *int      `gorm:"column:operator_id" json:"operator_id"`
+	Reason     string    `gorm:"column:reason;size:500" json:"reason"`
+	CreatedAt  time.Time `gorm:"column:created_at;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	Operator   *User     `gorm:"foreignKey:OperatorID" json:"operator,omitempty"`
+}
+
+// TableName 指定表名
+func (ArticleReview) TableName() string {
+	return "cms_article_reviews"
+}
+
+// ArticleReviewForm 文章审核表单
+type ArticleReviewForm struct {
+	Reason string `json:"reason" binding:"omitempty,max=500" example:"内容需要补充参考资料"`
+}
+
+// ArticleReviewResponse 文章审核记录响应
+type ArticleReviewResponse struct {
+	ReviewID     int64     `json:"review_id"`
+	ArticleID    int64     `json:"article_id"`
+	Action       int8      `json:"action"`
+	ActionName   string    `json:"action_name"`
+	FromStatus   int8      `json:"from_status"`
+	ToStatus     int8      `json:"to_status"`
+	OperatorID   *int      `json:"operator_id"`
+	OperatorName string    `json:"operator_name"`
+	Reason       string    `json:"reason"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 // ArticleQueryParams 文章查询参数
@@ -119,28 +212,56 @@ type ArticleQueryParams struct {
 
 // ArticleResponse 文章信息响应
 type ArticleResponse struct {
-	ArticleID       int64     `json:"article_id"`
-	UserID          int       `json:"user_id"`
-	Author          string    `json:"author"`
-	Title           string    `json:"title"`
-	ArticleKey      string    `json:"article_key"`
-	Summary         string    `json:"summary"`
-	Thumbnail       string    `json:"thumbnail"`
-	Status          int8      `json:"status"`
-	StatusName      string    `json:"status_name"`
-	ArticleType     int8      `json:"article_type"`
-	ArticleTypeName string    `json:"article_type_name"`
-	ViewCount       int       `json:"view_count"`
-	LikeCount       int       `json:"like_count"`
-	CommentCount    int       `json:"comment_count"`
-	AllowComment    bool      `json:"allow_comment"`
-	IsTop           bool      `json:"is_top"`
-	IsRecommend     bool      `json:"is_recommend"`
-	PublishTime     time.Time `json:"publish_time"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
-	Categories      []string  `json:"categories"`
-	Tags            []string  `json:"tags"`
+	ArticleID       int64      `json:"article_id"`
+	UserID          int        `json:"user_id"`
+	Author          string     `json:"author"`
+	Title           string     `json:"title"`
+	ArticleKey      string     `json:"article_key"`
+	Summary         string     `json:"summary"`
+	Thumbnail       string     `json:"thumbnail"`
+	Status          int8       `json:"status"`
+	StatusName      string     `json:"status_name"`
+	ArticleType     int8       `json:"article_type"`
+	ArticleTypeName string     `json:"article_type_name"`
+	ViewCount       int        `json:"view_count"`
+	LikeCount       int        `json:"like_count"`
+	CommentCount    int        `json:"comment_count"`
+	AllowComment    bool       `json:"allow_comment"`
+	IsTop           bool       `json:"is_top"`
+	IsRecommend     bool       `json:"is_recommend"`
+	PublishTime     time.Time  `json:"publish_time"`
+	ScheduleTime    *time.Time `json:"schedule_time"`
+	ExpireTime      *time.Time `json:"expire_time"`
+	RejectReason    string     `json:"reject_reason"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	Categories      []string   `json:"categories"`
+	Tags            []string   `json:"tags"`
+}
+
+// ArticleSearchResult 文章搜索结果
+type ArticleSearchResult struct {
+	ArticleID      int64     `json:"article_id"`
+	UserID         int       `json:"user_id"`
+	Author         string    `json:"author"`
+	Title          string    `json:"title"`
+	TitleHighlight string    `json:"title_highlight"`
+	ArticleKey     string    `json:"article_key"`
+	Summary        string    `json:"summary"`
+	Snippet        string    `json:"snippet"`
+	Thumbnail      string    `json:"thumbnail"`
+	ArticleType    int8      `json:"article_type"`
+	ViewCount      int       `json:"view_count"`
+	LikeCount      int       `json:"like_count"`
+	CommentCount   int       `json:"comment_count"`
+	PublishTime    time.Time `json:"publish_time"`
+	Rank           float64   `json:"rank"`
+}
+
+// ArchiveStat 文章月份归档统计
+type ArchiveStat struct {
+	Month        string `json:"month"` // 月份，格式为 2006-01
+	ArticleCount int    `json:"article_count"`
 }
 
 // ArticleDetailResponse 文章详情响应
@@ -154,3 +275,77 @@ type ArticleDetailResponse struct {
 	SourceURL      string `json:"source_url"`
 	SourceName     string `json:"source_name"`
 }
+
+// ArticleRenderResponse 文章内容渲染结果响应
+type ArticleRenderResponse struct {
+	ArticleID     int64             `json:"article_id"`
+	ContentID     int64             `json:"content_id"`
+	Version       int               `json:"version"`
+	ContentFormat int8              `json:"content_format"`
+	ContentHTML   string            `json:"content_html"` // 已过滤的HTML
+	TOC           []*render.TOCItem `json:"toc"`          // 目录树
+	WordCount     int               `json:"word_count"`   // 字数
+	ReadingTime   int               `json:"reading_time"` // 预计阅读时长（分钟）
+}
+
+// ArticleVersionResponse 文章内容版本响应
+type ArticleVersionResponse struct {
+	ContentID     int64     `json:"content_id"`
+	ArticleID     int64     `json:"article_id"`
+	Version       int       `json:"version"`
+	ContentFormat int8      `json:"content_format"`
+	IsCurrent     bool      `json:"is_current"`
+	ContentLength int       `json:"content_length"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ArticleVersionDiffResponse 文章内容版本差异响应
+type ArticleVersionDiffResponse struct {
+	ArticleID   int64       `json:"article_id"`
+	FromVersion int         `json:"from_version"`
+	ToVersion   int         `json:"to_version"`
+	Added       int         `json:"added"`
+	Removed     int         `json:"removed"`
+	Lines       []diff.Line `json:"lines"`
+}
+
+// 订阅源范围
+const (
+	FeedScopeSite     = "site"     // 全站
+	FeedScopeCategory = "category" // 分类（含子分类）
+	FeedScopeTag      = "tag"      // 标签
+	FeedScopeAuthor   = "author"   // 作者
+)
+
+// FeedQuery 订阅源查询条件
+type FeedQuery struct {
+	Scope       string // 订阅源范围
+	Key         string // 分类标识、标签标识或作者用户名
+	FullContent bool   // 是否输出全文
+	BaseURL     string // 请求的站点地址，未配置 site_url 时使用
+	FeedPath    string // 订阅源路径
+}
+
+// MetaWeblogPost MetaWeblog接口中的文章
+type MetaWeblogPost struct {
+	ArticleID     int64     // 文章ID
+	UserID        int       // 作者ID
+	Title         string    // 标题
+	Description   string    // 正文HTML
+	Excerpt       string    // 摘要（mt_excerpt）
+	Keywords      string    // 标签，逗号分隔（mt_keywords）
+	Slug          string    // 文章标识（wp_slug）
+	Categories    []string  // 分类名称
+	AllowComments *bool     // 是否允许评论（mt_allow_comments），为空表示不修改
+	Status        int8      // 文章状态
+	DateCreated   time.Time // 发布时间，未发布时为创建时间
+	Link          string    // 文章地址
+}
+
+// MetaWeblogCategory MetaWeblog接口中的分类
+type MetaWeblogCategory struct {
+	CategoryID int    // 分类ID
+	Title      string // 分类名称
+	HTMLURL    string // 分类页面地址
+	RSSURL     string // 分类订阅源地址
+}
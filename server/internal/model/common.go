@@ -46,10 +46,11 @@ type EnumItem struct {
 
 // LoginResult 登录结果
 type LoginResult struct {
-	Token        string `json:"token"`         // 访问令牌
-	RefreshToken string `json:"refresh_token"` // 刷新令牌
-	ExpiresIn    int    `json:"expires_in"`    // 过期时间(秒)
-	TokenType    string `json:"token_type"`    // 令牌类型
+	Token        string        `json:"token"`         // 访问令牌
+	RefreshToken string        `json:"refresh_token"` // 刷新令牌
+	ExpiresIn    int           `json:"expires_in"`    // 过期时间(秒)
+	TokenType    string        `json:"token_type"`    // 令牌类型
+	MFA          *MFAChallenge `json:"mfa,omitempty"` // 需要两步验证时不签发令牌，返回验证信息
 }
 
 // RefreshTokenResult 刷新令牌结果
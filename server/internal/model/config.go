@@ -75,3 +75,15 @@ type ConfigResponse struct {
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
+
+// SiteInfo 站点信息，来自 site 分组的系统配置
+type SiteInfo struct {
+	Name        string    `json:"name"`        // 网站名称
+	Description string    `json:"description"` // 网站描述
+	Keywords    string    `json:"keywords"`    // 网站关键词
+	Logo        string    `json:"logo"`        // 网站Logo
+	URL         string    `json:"url"`         // 网站地址，不含末尾斜杠
+	Language    string    `json:"language"`    // 网站语言
+	Copyright   string    `json:"copyright"`   // 版权信息
+	UpdatedAt   time.Time `json:"updated_at"`  // 配置最后更新时间
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"time"
+)
+
+// ExportFormatVersion 导出压缩包的格式版本，结构变化时递增
+const ExportFormatVersion = 1
+
+// ExportOptions 全站导出选项
+type ExportOptions struct {
+	IncludeVersions bool  `form:"include_versions" json:"include_versions"`      // 是否导出文章的历史版本
+	AfterID         int64 `form:"after_id" json:"after_id" binding:"min=0"`      // 从该文章ID之后继续导出，用于分段导出
+	Limit           int   `form:"limit" json:"limit" binding:"min=0,max=100000"` // 本次最多导出的文章数，0为不限制
+	SkipFiles       bool  `form:"skip_files" json:"skip_files"`                  // 是否跳过上传文件
+}
+
+// ExportManifest 导出说明，写入压缩包的 manifest.json
+type ExportManifest struct {
+	FormatVersion   int       `json:"format_version"`
+	ExportedAt      time.Time `json:"exported_at"`
+	IncludeVersions bool      `json:"include_versions"`
+	AfterID         int64     `json:"after_id"`                // 本段起始位置
+	LastArticleID   int64     `json:"last_article_id"`         // 本段导出的最后一篇文章ID
+	Complete        bool      `json:"complete"`                // 是否已导出全部文章，否则以 last_article_id 作为 after_id 继续导出
+	Articles        int       `json:"articles"`                // 文章数
+	Versions        int       `json:"versions"`                // 历史版本数
+	Comments        int       `json:"comments"`                // 评论数
+	Categories      int       `json:"categories"`              // 分类数，只在第一段中导出
+	Tags            int       `json:"tags"`                    // 标签数，只在第一段中导出
+	Configs         int       `json:"configs"`                 // 系统配置数，只在第一段中导出
+	Files           int       `json:"files"`                   // 上传文件数
+	MissingFiles    []string  `json:"missing_files,omitempty"` // 有记录但文件不存在的上传文件
+}
+
+// ExportCategory 导出的分类，按上下级组成树
+type ExportCategory struct {
+	CategoryID     int               `json:"category_id"`
+	CategoryName   string            `json:"category_name"`
+	CategoryKey    string            `json:"category_key"`
+	Description    string            `json:"description,omitempty"`
+	Thumbnail      string            `json:"thumbnail,omitempty"`
+	Icon           string            `json:"icon,omitempty"`
+	SortOrder      int16             `json:"sort_order"`
+	IsVisible      bool              `json:"is_visible"`
+	SEOTitle       string            `json:"seo_title,omitempty"`
+	SEOKeywords    string            `json:"seo_keywords,omitempty"`
+	SEODescription string            `json:"seo_description,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	Children       []*ExportCategory `json:"children,omitempty"`
+}
+
+// ExportComment 导出的评论，回复嵌套在上级评论中
+type ExportComment struct {
+	CommentID    int64            `json:"comment_id"`
+	UserID       int              `json:"user_id"`
+	Username     string           `json:"username"`
+	Nickname     string           `json:"nickname,omitempty"`
+	Content      string           `json:"content"`
+	IPAddress    string           `json:"ip_address,omitempty"`
+	UserAgent    string           `json:"user_agent,omitempty"`
+	LikedCount   int              `json:"liked_count"`
+	IsApproved   bool             `json:"is_approved"`
+	IsAdminReply bool             `json:"is_admin_reply"`
+	CreatedAt    time.Time        `json:"created_at"`
+	Replies      []*ExportComment `json:"replies,omitempty"`
+}
+
+// ExportFile 导出的上传文件记录，文件位于压缩包的 uploads 目录下
+type ExportFile struct {
+	FileID       int64     `json:"file_id"`
+	OriginalName string    `json:"original_name"`
+	FilePath     string    `json:"file_path"`
+	FileSize     int64     `json:"file_size"`
+	MimeType     string    `json:"mime_type"`
+	IsPublic     bool      `json:"is_public"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,54 @@
+package model
+
+// 导入时文章标识冲突的处理方式
+const (
+	ImportConflictSkip   = "skip"   // 跳过冲突的文章
+	ImportConflictRename = "rename" // 追加序号后导入
+)
+
+// 导入条目状态
+const (
+	ImportItemReady   = "ready"   // 预检通过，可以导入
+	ImportItemCreated = "created" // 已导入
+	ImportItemSkipped = "skipped" // 因标识冲突跳过
+	ImportItemFailed  = "failed"  // 解析或导入失败
+)
+
+// ImportOptions 导入选项
+type ImportOptions struct {
+	DryRun     bool   `form:"dry_run" json:"dry_run" example:"true"`                                               // 只预检不写入
+	OnConflict string `form:"on_conflict" json:"on_conflict" binding:"omitempty,oneof=skip rename" example:"skip"` // 标识冲突处理方式，默认跳过
+}
+
+// ImportItem 单篇文章的导入结果
+type ImportItem struct {
+	File              string   `json:"file"`                          // 压缩包内的文件路径
+	Title             string   `json:"title"`                         // 文章标题
+	ArticleKey        string   `json:"article_key"`                   // 导入后的文章标识
+	Status            string   `json:"status"`                        // 导入状态
+	ArticleID         int      `json:"article_id,omitempty"`          // 导入后的文章ID
+	ConflictKey       string   `json:"conflict_key,omitempty"`        // 冲突的原始标识
+	ConflictArticleID int64    `json:"conflict_article_id,omitempty"` // 冲突的已有文章ID，与压缩包内其他文章冲突时为0
+	Images            int      `json:"images"`                        // 改写的图片数
+	Comments          int      `json:"comments,omitempty"`            // 导入的评论数
+	Message           string   `json:"message,omitempty"`             // 失败或跳过原因
+	Warnings          []string `json:"warnings,omitempty"`            // 不影响导入的问题，如图片缺失
+}
+
+// ImportResult 导入结果
+type ImportResult struct {
+	DryRun        bool         `json:"dry_run"`               // 是否为预检
+	Total         int          `json:"total"`                 // 文章总数
+	Created       int          `json:"created"`               // 导入成功数，预检时为可导入数
+	Skipped       int          `json:"skipped"`               // 跳过数
+	Failed        int          `json:"failed"`                // 失败数
+	Conflicts     int          `json:"conflicts"`             // 标识冲突数
+	Images        int          `json:"images"`                // 上传的图片数，预检时为需要上传的图片数
+	NewCategories []string     `json:"new_categories"`        // 新建的分类
+	NewTags       []string     `json:"new_tags"`              // 新建的标签
+	NewUsers      []string     `json:"new_users,omitempty"`   // 新建的用户，WordPress导入时为作者和评论者
+	Comments      int          `json:"comments,omitempty"`    // 导入的评论数
+	Attachments   int          `json:"attachments,omitempty"` // 导入的附件数
+	Warnings      []string     `json:"warnings,omitempty"`    // 不影响导入的问题，如附件缺失
+	Items         []ImportItem `json:"items"`                 // 各文章的导入结果
+}
@@ -4,16 +4,38 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// 令牌类型，防止一种令牌被当作另一种使用
+const (
+	TokenTypeAccess  = "access"  // 访问令牌
+	TokenTypeRefresh = "refresh" // 刷新令牌
+	TokenTypeMFA     = "mfa"     // 已通过密码验证、等待两步验证的临时令牌
+)
+
 // CustomClaims 自定义JWT声明
 type CustomClaims struct {
-	UserID   int    `json:"user_id"`
-	Username string `json:"username"`
-	RoleIDs  []int  `json:"role_ids"`
+	UserID    int    `json:"user_id"`
+	Username  string `json:"username"`
+	RoleIDs   []int  `json:"role_ids"`
+	FamilyID  string `json:"fid,omitempty"` // 签发时所属的刷新令牌族，用于退出登录时一并吊销
+	Version   int64  `json:"ver,omitempty"` // 签发时用户的令牌版本，退出全部设备后旧版本的令牌失效
+	TokenType string `json:"token_type"`
 	jwt.RegisteredClaims
 }
 
 // RefreshClaims 刷新令牌声明
 type RefreshClaims struct {
-	UserID int `json:"user_id"`
+	UserID    int    `json:"user_id"`
+	FamilyID  string `json:"fid"`           // 刷新令牌族，同一次登录轮换出的刷新令牌属于同一族
+	Version   int64  `json:"ver,omitempty"` // 签发时用户的令牌版本
+	TokenType string `json:"token_type"`
+	jwt.RegisteredClaims
+}
+
+// MFAClaims 两步验证临时令牌声明，只能用于完成两步验证或首次绑定，不能访问其他接口
+type MFAClaims struct {
+	UserID    int    `json:"user_id"`
+	Username  string `json:"username"`
+	Purpose   string `json:"purpose"` // verify 验证已绑定的验证器，enroll 角色要求两步验证但尚未绑定
+	TokenType string `json:"token_type"`
 	jwt.RegisteredClaims
 }
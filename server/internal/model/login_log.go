@@ -0,0 +1,99 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// 登录类型
+const (
+	LoginTypeUsername  int8 = 1 // 用户名密码
+	LoginTypeEmail     int8 = 2 // 邮箱
+	LoginTypeMobile    int8 = 3 // 手机号
+	LoginTypeWechat    int8 = 4 // 微信
+	LoginTypePasskey   int8 = 5 // 通行密钥
+	LoginTypeMagicLink int8 = 6 // 邮件登录链接
+	LoginTypeOAuth     int8 = 7 // 第三方账号（微信除外）
+)
+
+// 登录结果
+const (
+	LoginStatusSuccess int8 = 1 // 成功
+	LoginStatusFailed  int8 = 2 // 失败
+)
+
+// 登录日志操作
+const (
+	LoginActionLogin   int8 = 1 // 登录
+	LoginActionRefresh int8 = 2 // 刷新令牌
+	LoginActionLogout  int8 = 3 // 退出登录
+)
+
+// LoginLog 登录日志模型
+type LoginLog struct {
+	LogID       int64           `gorm:"column:log_id;primaryKey;autoIncrement" json:"log_id"`
+	UserID      *int            `gorm:"column:user_id" json:"user_id"` // 用户不存在时为空
+	Username    string          `gorm:"column:username;size:30" json:"username"`
+	LoginType   int8            `gorm:"column:login_type;not null" json:"login_type"`
+	LoginStatus int8            `gorm:"column:login_status;not null" json:"login_status"`
+	Action      int8            `gorm:"column:action;not null;default:1" json:"action"`
+	IPAddress   string          `gorm:"column:ip_address;type:inet;not null" json:"ip_address"`
+	UserAgent   string          `gorm:"column:user_agent" json:"user_agent"`
+	DeviceInfo  LoginDeviceInfo `gorm:"column:device_info;type:jsonb" json:"device_info"`
+	LoginTime   time.Time       `gorm:"column:login_time;not null;default:CURRENT_TIMESTAMP" json:"login_time"`
+	FailReason  string          `gorm:"column:fail_reason;size:100" json:"fail_reason"`
+}
+
+// TableName 指定表名
+func (LoginLog) TableName() string {
+	return "sys_login_logs"
+}
+
+// LoginDeviceInfo 从 User-Agent 识别的登录设备信息，以JSONB保存
+type LoginDeviceInfo struct {
+	Device         string `json:"device"` // 设备描述，如 Chrome 120 / Windows
+	Browser        string `json:"browser,omitempty"`
+	BrowserVersion string `json:"browser_version,omitempty"`
+	OS             string `json:"os,omitempty"`
+	DeviceType     string `json:"device_type"`
+}
+
+// Value 实现 driver.Valuer 接口
+func (d LoginDeviceInfo) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+// Scan 实现 sql.Scanner 接口
+func (d *LoginDeviceInfo) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*d = LoginDeviceInfo{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, d)
+	case string:
+		return json.Unmarshal([]byte(v), d)
+	}
+	return errors.New("无效的设备信息")
+}
+
+// LoginLogFilter 登录日志筛选条件
+type LoginLogFilter struct {
+	UserID      *int       `form:"user_id" json:"user_id"`
+	Username    string     `form:"username" json:"username"`
+	IPAddress   string     `form:"ip_address" json:"ip_address"` // IP地址或网段，如 192.168.1.0/24
+	LoginType   *int8      `form:"login_type" json:"login_type" binding:"omitempty,oneof=1 2 3 4 5 6 7"`
+	LoginStatus *int8      `form:"login_status" json:"login_status" binding:"omitempty,oneof=1 2"`
+	Action      *int8      `form:"action" json:"action" binding:"omitempty,oneof=1 2 3"`
+	StartTime   *time.Time `form:"start_time" json:"start_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndTime     *time.Time `form:"end_time" json:"end_time" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// LoginLogQueryParams 登录日志查询参数
+type LoginLogQueryParams struct {
+	LoginLogFilter
+	Page     int `form:"page" json:"page" binding:"required,min=1" default:"1"`
+	PageSize int `form:"page_size" json:"page_size" binding:"required,min=1,max=100" default:"10"`
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"time"
+)
+
+// 两步验证临时令牌用途
+const (
+	MFAPurposeVerify = "verify" // 验证已绑定的验证器
+	MFAPurposeEnroll = "enroll" // 角色要求两步验证，首次登录时绑定验证器
+)
+
+// 两步验证方式
+const (
+	MFAMethodTOTP     = "totp"     // 身份验证器应用的动态口令
+	MFAMethodRecovery = "recovery" // 恢复码
+)
+
+// UserTOTP 用户绑定的TOTP验证器
+type UserTOTP struct {
+	UserID     int        `gorm:"column:user_id;primaryKey" json:"user_id"`
+	Secret     string     `gorm:"column:secret;size:255;not null" json:"-"` // 加密后的密钥
+	EnabledAt  time.Time  `gorm:"column:enabled_at;not null;default:CURRENT_TIMESTAMP" json:"enabled_at"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at" json:"last_used_at"`
+}
+
+// TableName 指定表名
+func (UserTOTP) TableName() string {
+	return "sys_user_totp"
+}
+
+// RecoveryCode 两步验证恢复码，每个只能使用一次
+type RecoveryCode struct {
+	CodeID    int        `gorm:"column:code_id;primaryKey;autoIncrement" json:"code_id"`
+	UserID    int        `gorm:"column:user_id;not null" json:"user_id"`
+	CodeHash  string     `gorm:"column:code_hash;size:64;not null" json:"-"`
+	UsedAt    *time.Time `gorm:"column:used_at" json:"used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName 指定表名
+func (RecoveryCode) TableName() string {
+	return "sys_user_recovery_codes"
+}
+
+// MFAChallenge 密码验证通过后返回的两步验证信息
+type MFAChallenge struct {
+	MFAToken  string   `json:"mfa_token"`  // 临时令牌，完成两步验证时提交
+	Purpose   string   `json:"purpose"`    // verify 输入验证码，enroll 需要先绑定验证器
+	Methods   []string `json:"methods"`    // 可用的验证方式
+	ExpiresIn int      `json:"expires_in"` // 临时令牌有效期(秒)
+}
+
+// TOTPEnrollment 绑定验证器时返回的密钥
+type TOTPEnrollment struct {
+	Secret    string `json:"secret"`     // Base32密钥，无法扫码时手动输入
+	URI       string `json:"uri"`        // otpauth URI，用于生成二维码
+	ExpiresIn int    `json:"expires_in"` // 需要在此时间(秒)内输入验证码完成绑定
+}
+
+// MFAEnrollResult 完成绑定的结果，恢复码只在此时返回一次
+type MFAEnrollResult struct {
+	RecoveryCodes []string     `json:"recovery_codes"`
+	Tokens        *LoginResult `json:"tokens,omitempty"` // 登录时强制绑定的，完成后签发令牌
+}
+
+// MFAStatus 用户的两步验证状态
+type MFAStatus struct {
+	TOTPEnabled       bool       `json:"totp_enabled"`
+	EnabledAt         *time.Time `json:"enabled_at"`
+	LastUsedAt        *time.Time `json:"last_used_at"`
+	RecoveryCodesLeft int64      `json:"recovery_codes_left"` // 未使用的恢复码数量
+	Required          bool       `json:"required"`            // 角色要求启用，不能关闭
+}
+
+// MFACodeForm 两步验证码表单
+type MFACodeForm struct {
+	Code string `json:"code" binding:"required,max=20" example:"123456"` // 验证器动态口令或恢复码
+}
+
+// MFALoginForm 登录第二步表单
+type MFALoginForm struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required,max=20" example:"123456"` // 验证器动态口令或恢复码
+}
+
+// MFATokenForm 登录时开始绑定验证器的表单
+type MFATokenForm struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
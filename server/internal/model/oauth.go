@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// OAuthAccount 用户关联的第三方账号。微信账号保存在用户表的 wechat_openid/wechat_unionid 字段中，不在此表
+type OAuthAccount struct {
+	AccountID   int        `gorm:"column:account_id;primaryKey;autoIncrement" json:"account_id"`
+	UserID      int        `gorm:"column:user_id;not null" json:"user_id"`
+	Provider    string     `gorm:"column:provider;size:30;not null" json:"provider"` // 配置中的第三方登录服务标识
+	Subject     string     `gorm:"column:subject;size:255;not null" json:"-"`        // 第三方账号唯一标识
+	Email       string     `gorm:"column:email;size:100" json:"email"`
+	Nickname    string     `gorm:"column:nickname;size:100" json:"nickname"`
+	Avatar      string     `gorm:"column:avatar;size:255" json:"avatar"`
+	LastLoginAt *time.Time `gorm:"column:last_login_at" json:"last_login_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName 指定表名
+func (OAuthAccount) TableName() string {
+	return "sys_user_oauth_accounts"
+}
+
+// OAuthProvider 可用的第三方登录服务
+type OAuthProvider struct {
+	Name        string `json:"name"`         // 服务标识，用于接口路径
+	DisplayName string `json:"display_name"` // 显示名称
+	Type        string `json:"type"`         // github、google、oidc、wechat
+}
+
+// OAuthAuthorization 发起第三方登录时返回的授权地址
+type OAuthAuthorization struct {
+	AuthorizeURL string `json:"authorize_url"` // 跳转到第三方服务的地址
+	State        string `json:"state"`         // 前端需保存，回调时与地址中的 state 比对，防止跨站请求伪造
+	ExpiresIn    int    `json:"expires_in"`    // 需要在此时间(秒)内完成授权
+}
+
+// OAuthCallbackForm 第三方登录回调表单，参数取自回调地址
+type OAuthCallbackForm struct {
+	Code  string `json:"code" binding:"required,max=1024"`
+	State string `json:"state" binding:"required,max=100"`
+}
@@ -12,7 +12,7 @@ type Permission struct {
 	PermType  int8          `gorm:"column:perm_type;not null;default:1" json:"perm_type"`
 	ParentID  *int          `gorm:"column:parent_id" json:"parent_id"`
 	Path      string        `gorm:"column:path" json:"path"`
-	APIPath   string        `gorm:"column:api_path;size:200" json:"api_path"`
+	APIPath   string        `gorm:"column:api_path;size:200" json:"api_path"` // 仅作说明，接口权限按 perm_key 和 perms 校验
 	Component string        `gorm:"column:component;size:100" json:"component"`
 	Perms     string        `gorm:"column:perms;size:100" json:"perms"`
 	Icon      string        `gorm:"column:icon;size:100" json:"icon"`
@@ -13,6 +13,7 @@ type Role struct {
 	RoleDesc    string       `gorm:"column:role_desc;size:200" json:"role_desc"`
 	IsDefault   bool         `gorm:"column:is_default;not null;default:false" json:"is_default"`
 	IsEnabled   bool         `gorm:"column:is_enabled;not null;default:true" json:"is_enabled"`
+	RequireMFA  bool         `gorm:"column:require_mfa;not null;default:false" json:"require_mfa"` // 拥有该角色的用户必须启用两步验证
 	CreatedAt   time.Time    `gorm:"column:created_at;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt   time.Time    `gorm:"column:updated_at;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
 	Permissions []Permission `gorm:"many2many:sys_role_permissions;foreignKey:RoleID;joinForeignKey:RoleID;References:PermID;joinReferences:PermID" json:"permissions"`
@@ -26,22 +27,24 @@ func (Role) TableName() string {
 
 // RoleCreateForm 角色创建表单
 type RoleCreateForm struct {
-	RoleName  string `json:"role_name" binding:"required,max=50" example:"编辑角色"`
-	RoleKey   string `json:"role_key" binding:"required,max=50" example:"editor"`
-	RoleSort  int16  `json:"role_sort" binding:"omitempty" example:"5"`
-	RoleDesc  string `json:"role_desc" binding:"omitempty,max=200" example:"负责内容编辑的角色"`
-	IsDefault bool   `json:"is_default" example:"false"`
-	IsEnabled bool   `json:"is_enabled" example:"true"`
+	RoleName   string `json:"role_name" binding:"required,max=50" example:"编辑角色"`
+	RoleKey    string `json:"role_key" binding:"required,max=50" example:"editor"`
+	RoleSort   int16  `json:"role_sort" binding:"omitempty" example:"5"`
+	RoleDesc   string `json:"role_desc" binding:"omitempty,max=200" example:"负责内容编辑的角色"`
+	IsDefault  bool   `json:"is_default" example:"false"`
+	IsEnabled  bool   `json:"is_enabled" example:"true"`
+	RequireMFA bool   `json:"require_mfa" example:"false"`
 }
 
 // RoleUpdateForm 角色更新表单
 type RoleUpdateForm struct {
-	RoleName  string `json:"role_name" binding:"omitempty,max=50" example:"编辑角色"`
-	RoleKey   string `json:"role_key" binding:"omitempty,max=50" example:"editor"`
-	RoleSort  int16  `json:"role_sort" binding:"omitempty" example:"5"`
-	RoleDesc  string `json:"role_desc" binding:"omitempty,max=200" example:"负责内容编辑的角色"`
-	IsDefault bool   `json:"is_default" example:"false"`
-	IsEnabled bool   `json:"is_enabled" example:"true"`
+	RoleName   string `json:"role_name" binding:"omitempty,max=50" example:"编辑角色"`
+	RoleKey    string `json:"role_key" binding:"omitempty,max=50" example:"editor"`
+	RoleSort   int16  `json:"role_sort" binding:"omitempty" example:"5"`
+	RoleDesc   string `json:"role_desc" binding:"omitempty,max=200" example:"负责内容编辑的角色"`
+	IsDefault  bool   `json:"is_default" example:"false"`
+	IsEnabled  bool   `json:"is_enabled" example:"true"`
+	RequireMFA *bool  `json:"require_mfa" example:"false"`
 }
 
 // RolePermissionForm 角色权限分配表单
@@ -58,6 +61,7 @@ type RoleResponse struct {
 	RoleDesc    string    `json:"role_desc"`
 	IsDefault   bool      `json:"is_default"`
 	IsEnabled   bool      `json:"is_enabled"`
+	RequireMFA  bool      `json:"require_mfa"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 	Permissions []string  `json:"permissions,omitempty"`
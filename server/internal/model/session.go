@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+)
+
+// SessionClient 登录时的客户端信息
+type SessionClient struct {
+	IPAddress string
+	UserAgent string
+}
+
+// Session 登录会话，每次登录创建一个会话，会话内轮换的令牌共用同一会话ID
+type Session struct {
+	SessionID  string    `json:"session_id"`
+	UserID     int       `json:"user_id"`
+	Device     string    `json:"device"`      // 设备描述，如 Chrome 120 / Windows
+	DeviceType string    `json:"device_type"` // 设备类型：desktop、mobile、tablet、bot、unknown
+	IPAddress  string    `json:"ip_address"`  // 登录IP
+	UserAgent  string    `json:"user_agent"`
+	LastIP     string    `json:"last_ip"`    // 最近访问IP
+	CreatedAt  time.Time `json:"created_at"` // 登录时间
+	LastSeen   time.Time `json:"last_seen"`  // 最近访问时间
+	ExpiresAt  time.Time `json:"expires_at"` // 会话过期时间，刷新令牌时顺延
+	Current    bool      `json:"current"`    // 是否为发起请求的当前会话
+}
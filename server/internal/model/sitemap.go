@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+)
+
+// 站点地图类型
+const (
+	SitemapTypeArticle  = "article"  // 已发布文章
+	SitemapTypeCategory = "category" // 可见分类
+	SitemapTypeTag      = "tag"      // 可见标签
+)
+
+// SitemapURL 站点地图页面，地址为站内路径，输出时再拼接站点地址
+type SitemapURL struct {
+	Path    string    `json:"path"`
+	LastMod time.Time `json:"lastmod"`
+	Images  []string  `json:"images,omitempty"`
+}
+
+// SitemapPage 站点地图分页，按记录ID区间划分，记录变更时只需重新生成所在分页
+type SitemapPage struct {
+	Type    string    `json:"type"`
+	Page    int       `json:"page"`
+	LastMod time.Time `json:"lastmod"`
+}
@@ -0,0 +1,17 @@
+package model
+
+// StaticBuildResult 静态站点生成结果
+type StaticBuildResult struct {
+	Pages      int   `json:"pages"`       // 生成的页面和文件数
+	Removed    int   `json:"removed"`     // 删除的过期页面数
+	DurationMs int64 `json:"duration_ms"` // 耗时（毫秒）
+}
+
+// StaticArticleRefs 文章变更前关联的静态页面，变更后据此更新或删除旧页面
+type StaticArticleRefs struct {
+	ArticleKey  string // 文章标识，已发布时对应文章页
+	Published   bool   // 是否已发布
+	Month       string // 发布月份，格式为 2006-01
+	CategoryIDs []int
+	TagIDs      []int
+}
@@ -7,27 +7,43 @@ import (
 	"gorm.io/gorm"
 )
 
+// 用户状态
+const (
+	UserStatusNormal   int8 = 1 // 正常
+	UserStatusDisabled int8 = 2 // 禁用
+	UserStatusInactive int8 = 3 // 未激活
+)
+
+// 注册来源
+const (
+	RegisterSourceEmail  int8 = 1 // 邮箱注册
+	RegisterSourceMobile int8 = 2 // 手机号注册
+	RegisterSourceWechat int8 = 3 // 微信登录
+	RegisterSourceOAuth  int8 = 4 // 其他第三方账号登录
+)
+
 // User 用户模型
 type User struct {
-	UserID         int       `gorm:"column:user_id;primaryKey;autoIncrement" json:"user_id"`
-	Username       string    `gorm:"column:username;size:30;not null;unique" json:"username"`
-	PasswordHash   string    `gorm:"column:password_hash;size:100" json:"-"`
-	Email          string    `gorm:"column:email;size:100;unique" json:"email"`
-	Mobile         string    `gorm:"column:mobile;size:20;unique" json:"mobile"`
-	WechatOpenID   string    `gorm:"column:wechat_openid;size:50;unique" json:"-"`
-	WechatUnionID  string    `gorm:"column:wechat_unionid;size:50;unique" json:"-"`
-	Avatar         string    `gorm:"column:avatar;size:255" json:"avatar"`
-	Nickname       string    `gorm:"column:nickname;size:50" json:"nickname"`
-	RealName       string    `gorm:"column:real_name;size:50" json:"real_name"`
-	Gender         int8      `gorm:"column:gender;default:0" json:"gender"`
-	Birthday       time.Time `gorm:"column:birthday" json:"birthday"`
-	Status         int8      `gorm:"column:status;not null;default:1" json:"status"`
-	RegisterSource int8      `gorm:"column:register_source;not null;default:1" json:"register_source"`
-	LastLogin      time.Time `gorm:"column:last_login" json:"last_login"`
-	LoginCount     int       `gorm:"column:login_count;not null;default:0" json:"login_count"`
-	CreatedAt      time.Time `gorm:"column:created_at;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt      time.Time `gorm:"column:updated_at;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
-	Roles          []Role    `gorm:"many2many:sys_user_roles;foreignKey:UserID;joinForeignKey:UserID;References:RoleID;joinReferences:RoleID" json:"roles"`
+	UserID          int        `gorm:"column:user_id;primaryKey;autoIncrement" json:"user_id"`
+	Username        string     `gorm:"column:username;size:30;not null;unique" json:"username"`
+	PasswordHash    string     `gorm:"column:password_hash;size:100" json:"-"`
+	Email           string     `gorm:"column:email;size:100;unique" json:"email"`
+	EmailVerifiedAt *time.Time `gorm:"column:email_verified_at" json:"email_verified_at"` // 邮箱验证时间，为空表示未验证
+	Mobile          string     `gorm:"column:mobile;size:20;unique" json:"mobile"`
+	WechatOpenID    string     `gorm:"column:wechat_openid;size:50;unique" json:"-"`
+	WechatUnionID   string     `gorm:"column:wechat_unionid;size:50;unique" json:"-"`
+	Avatar          string     `gorm:"column:avatar;size:255" json:"avatar"`
+	Nickname        string     `gorm:"column:nickname;size:50" json:"nickname"`
+	RealName        string     `gorm:"column:real_name;size:50" json:"real_name"`
+	Gender          int8       `gorm:"column:gender;default:0" json:"gender"`
+	Birthday        time.Time  `gorm:"column:birthday" json:"birthday"`
+	Status          int8       `gorm:"column:status;not null;default:1" json:"status"`
+	RegisterSource  int8       `gorm:"column:register_source;not null;default:1" json:"register_source"`
+	LastLogin       time.Time  `gorm:"column:last_login" json:"last_login"`
+	LoginCount      int        `gorm:"column:login_count;not null;default:0" json:"login_count"`
+	CreatedAt       time.Time  `gorm:"column:created_at;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at;not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	Roles           []Role     `gorm:"many2many:sys_user_roles;foreignKey:UserID;joinForeignKey:UserID;References:RoleID;joinReferences:RoleID" json:"roles"`
 }
 
 // TableName 指定表名
@@ -98,6 +114,35 @@ type PasswordUpdateForm struct {
 	NewPassword string `json:"new_password" binding:"required,min=6,max=20" example:"654321"`
 }
 
+// EmailForm 邮箱表单，用于重新发送验证邮件和找回密码
+type EmailForm struct {
+	Email string `json:"email" binding:"required,email,max=100" example:"user@example.com"`
+}
+
+// EmailVerifyForm 邮箱验证表单
+type EmailVerifyForm struct {
+	Token string `json:"token" binding:"required,max=200"` // 验证邮件链接中的令牌
+}
+
+// PasswordResetForm 重置密码表单
+type PasswordResetForm struct {
+	Token       string `json:"token" binding:"required,max=200"` // 找回密码邮件链接中的令牌
+	NewPassword string `json:"new_password" binding:"required,min=6,max=20" example:"654321"`
+}
+
+// MagicLinkForm 申请邮件登录链接表单
+type MagicLinkForm struct {
+	Email      string `json:"email" binding:"required,email,max=100" example:"user@example.com"`
+	BindDevice bool   `json:"bind_device" example:"true"`                                   // 是否只允许在申请登录的设备上打开链接
+	DeviceID   string `json:"device_id" binding:"omitempty,max=100" example:"3f2a9c1e7b4d"` // 前端生成并保存在本地的设备标识，绑定设备时与浏览器标识一起校验
+}
+
+// MagicLinkLoginForm 使用邮件登录链接登录表单
+type MagicLinkLoginForm struct {
+	Token    string `json:"token" binding:"required,max=200"`                             // 登录邮件链接中的令牌
+	DeviceID string `json:"device_id" binding:"omitempty,max=100" example:"3f2a9c1e7b4d"` // 申请链接时提交的设备标识
+}
+
 // UserResponse 用户信息响应
 type UserResponse struct {
 	UserID         int       `json:"user_id"`
@@ -113,3 +158,30 @@ type UserResponse struct {
 	CreatedAt      time.Time `json:"created_at"`
 	Roles          []string  `json:"roles"`
 }
+
+// AppPassword 应用密码模型，供不支持验证码、二次验证的第三方客户端使用
+type AppPassword struct {
+	AppPasswordID int        `gorm:"column:app_password_id;primaryKey;autoIncrement" json:"app_password_id"`
+	UserID        int        `gorm:"column:user_id;not null" json:"user_id"`
+	AppName       string     `gorm:"column:app_name;size:50;not null" json:"app_name"`
+	PasswordHash  string     `gorm:"column:password_hash;size:100;not null" json:"-"`
+	LastUsedAt    *time.Time `gorm:"column:last_used_at" json:"last_used_at"`
+	LastUsedIP    *string    `gorm:"column:last_used_ip;type:inet" json:"last_used_ip"`
+	CreatedAt     time.Time  `gorm:"column:created_at;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName 指定表名
+func (AppPassword) TableName() string {
+	return "sys_app_passwords"
+}
+
+// AppPasswordCreateForm 应用密码创建表单
+type AppPasswordCreateForm struct {
+	AppName string `json:"app_name" binding:"required,max=50" example:"Open Live Writer"`
+}
+
+// AppPasswordCreateResult 应用密码创建结果，明文密码只返回这一次
+type AppPasswordCreateResult struct {
+	AppPassword
+	Password string `json:"password"`
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebAuthnCredential 用户注册的通行密钥
+type WebAuthnCredential struct {
+	CredentialID   int        `gorm:"column:credential_id;primaryKey;autoIncrement" json:"credential_id"`
+	UserID         int        `gorm:"column:user_id;not null" json:"user_id"`
+	RawID          []byte     `gorm:"column:raw_id;not null;uniqueIndex" json:"-"`            // 认证器生成的凭据ID
+	PublicKey      []byte     `gorm:"column:public_key;not null" json:"-"`                    // COSE 编码的公钥
+	SignCount      int64      `gorm:"column:sign_count;not null;default:0" json:"-"`          // 签名计数器，用于发现被复制的凭据
+	AAGUID         string     `gorm:"column:aaguid;size:36" json:"aaguid"`                    // 认证器型号标识
+	Transports     string     `gorm:"column:transports;size:100" json:"transports"`           // 认证器支持的连接方式，逗号分隔
+	BackupEligible bool       `gorm:"column:backup_eligible;not null" json:"backup_eligible"` // 是否为可同步的多设备凭据
+	BackupState    bool       `gorm:"column:backup_state;not null" json:"backup_state"`
+	Name           string     `gorm:"column:name;size:50;not null" json:"name"`
+	LastUsedAt     *time.Time `gorm:"column:last_used_at" json:"last_used_at"`
+	CreatedAt      time.Time  `gorm:"column:created_at;not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName 指定表名
+func (WebAuthnCredential) TableName() string {
+	return "sys_user_webauthn_credentials"
+}
+
+// WebAuthnOptions 开始注册或登录时返回的选项
+type WebAuthnOptions struct {
+	SessionID string      `json:"session_id"` // 完成注册或登录时提交
+	PublicKey interface{} `json:"publicKey"`  // 直接传给 navigator.credentials.create/get
+	ExpiresIn int         `json:"expires_in"` // 需要在此时间(秒)内完成
+}
+
+// WebAuthnLoginBeginForm 开始通行密钥登录表单
+type WebAuthnLoginBeginForm struct {
+	Username string `json:"username" binding:"omitempty,max=30" example:"admin"` // 为空时由浏览器列出本站的全部通行密钥供选择
+}
+
+// WebAuthnFinishForm 完成通行密钥注册或登录表单
+type WebAuthnFinishForm struct {
+	SessionID  string          `json:"session_id" binding:"required"`
+	Credential json.RawMessage `json:"credential" binding:"required" swaggertype:"object"` // PublicKeyCredential.toJSON() 的结果
+	Name       string          `json:"name" binding:"omitempty,max=50" example:"我的手机"`     // 注册时的凭据名称，为空时自动生成
+}
+
+// WebAuthnRenameForm 重命名通行密钥表单
+type WebAuthnRenameForm struct {
+	Name string `json:"name" binding:"required,max=50" example:"我的手机"`
+}
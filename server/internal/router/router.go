@@ -9,6 +9,7 @@ import (
 	v1 "github.com/sunmoonstrand/go-react-blog/server/api/v1"
 	"github.com/sunmoonstrand/go-react-blog/server/internal/config"
 	"github.com/sunmoonstrand/go-react-blog/server/internal/middleware"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -62,19 +63,47 @@ func InitRouter(cfg *config.Config) *gin.Engine {
 	commentController := v1.NewCommentController()
 	configController := v1.NewConfigController()
 	fileController := v1.NewFileController()
+	feedController := v1.NewFeedController()
+	sitemapController := v1.NewSitemapController()
+	metaWeblogController := v1.NewMetaWeblogController()
+	jwksController := v1.NewJWKSController()
+	exportController := v1.NewExportController(cfg.Upload)
+	staticController := v1.NewStaticController()
+	sessionController := v1.NewSessionController()
+	loginLogController := v1.NewLoginLogController()
+	loginLockController := v1.NewLoginLockController()
+	mfaController := v1.NewMFAController()
+	webAuthnController := v1.NewWebAuthnController()
+	accountController := v1.NewAccountController()
+	oauthController := v1.NewOAuthController()
+	accessTokenController := v1.NewAccessTokenController()
+
+	// 订阅源（RSS/Atom/JSON Feed）
+	feedController.RegisterRoutes(r)
+
+	// 站点地图和robots.txt
+	sitemapController.RegisterRoutes(r)
+
+	// MetaWeblog（XML-RPC），供桌面写作客户端发布文章
+	metaWeblogController.RegisterRoutes(r)
+
+	// 令牌验证公钥集，供其他服务验证本站签发的令牌
+	jwksController.RegisterRoutes(r)
 
 	// API路由组 - 前台接口
 	apiV1 := r.Group("/api/v1")
 	{
 		// 无需认证的路由
-		publicRoutes(apiV1, authController, articleController, categoryController, tagController, commentController)
+		publicRoutes(apiV1, authController, webAuthnController, accountController, oauthController, articleController,
+			categoryController, tagController, commentController)
 
 		// 需要认证的路由
 		authRoutes := apiV1.Group("")
-		authRoutes.Use(middleware.JWTAuth(cfg.Server.JWTSecret))
+		authRoutes.Use(middleware.JWTAuth(service.TokenKeys()))
 		{
 			// 用户相关路由
-			userRoutes(authRoutes, userController)
+			userRoutes(authRoutes, authController, userController, sessionController, loginLogController, mfaController,
+				webAuthnController, accountController, oauthController, accessTokenController)
 
 			// 内容相关路由
 			contentRoutes(authRoutes, articleController, categoryController, tagController, commentController)
@@ -90,19 +119,19 @@ func InitRouter(cfg *config.Config) *gin.Engine {
 		// 无需认证的后台路由
 		adminPublicRoutes(adminV1, authController)
 
-		// 需要认证的后台路由
+		// 需要认证的后台路由，各路由通过 RequirePermission 按权限标识校验
 		adminAuthRoutes := adminV1.Group("")
-		adminAuthRoutes.Use(middleware.JWTAuth(cfg.Server.JWTSecret))
-		adminAuthRoutes.Use(middleware.RBACAuth())
+		adminAuthRoutes.Use(middleware.JWTAuth(service.TokenKeys()))
 		{
 			// 用户管理路由
-			adminUserRoutes(adminAuthRoutes, userController, roleController)
+			adminUserRoutes(adminAuthRoutes, userController, roleController, sessionController, loginLogController,
+				loginLockController, mfaController)
 
 			// 内容管理路由
 			adminContentRoutes(adminAuthRoutes, articleController, categoryController, tagController, commentController, fileController)
 
 			// 系统管理路由
-			adminSystemRoutes(adminAuthRoutes, configController)
+			adminSystemRoutes(adminAuthRoutes, configController, exportController, staticController, loginLogController, loginLockController)
 		}
 	}
 
@@ -118,13 +147,17 @@ func InitRouter(cfg *config.Config) *gin.Engine {
 }
 
 // publicRoutes 注册公开路由
-func publicRoutes(rg *gin.RouterGroup, authCtrl *v1.AuthController, articleCtrl *v1.ArticleController,
+func publicRoutes(rg *gin.RouterGroup, authCtrl *v1.AuthController, webAuthnCtrl *v1.WebAuthnController,
+	accountCtrl *v1.AccountController, oauthCtrl *v1.OAuthController, articleCtrl *v1.ArticleController,
 	categoryCtrl *v1.CategoryController, tagCtrl *v1.TagController, commentCtrl *v1.CommentController) {
 
 	// 认证相关
 	authGroup := rg.Group("/auth")
 	{
 		authCtrl.RegisterPublicRoutes(authGroup)
+		webAuthnCtrl.RegisterPublicRoutes(authGroup)
+		accountCtrl.RegisterPublicRoutes(authGroup)
+		oauthCtrl.RegisterPublicRoutes(authGroup)
 	}
 
 	// 文章相关
@@ -153,10 +186,29 @@ func publicRoutes(rg *gin.RouterGroup, authCtrl *v1.AuthController, articleCtrl
 }
 
 // userRoutes 注册用户相关路由
-func userRoutes(rg *gin.RouterGroup, userCtrl *v1.UserController) {
+func userRoutes(rg *gin.RouterGroup, authCtrl *v1.AuthController, userCtrl *v1.UserController,
+	sessionCtrl *v1.SessionController, loginLogCtrl *v1.LoginLogController, mfaCtrl *v1.MFAController,
+	webAuthnCtrl *v1.WebAuthnController, accountCtrl *v1.AccountController, oauthCtrl *v1.OAuthController, accessTokenCtrl *v1.AccessTokenController) {
+	// 账号相关接口不接受个人访问令牌
 	userGroup := rg.Group("/user")
+	userGroup.Use(middleware.RejectAccessToken())
 	{
 		userCtrl.RegisterRoutes(userGroup)
+		sessionCtrl.RegisterRoutes(userGroup)
+		loginLogCtrl.RegisterRoutes(userGroup)
+		mfaCtrl.RegisterRoutes(userGroup)
+		webAuthnCtrl.RegisterRoutes(userGroup)
+		accountCtrl.RegisterRoutes(userGroup)
+		oauthCtrl.RegisterRoutes(userGroup)
+		accessTokenCtrl.RegisterRoutes(userGroup)
+	}
+
+	// 退出登录、注册通行密钥
+	authGroup := rg.Group("/auth")
+	authGroup.Use(middleware.RejectAccessToken())
+	{
+		authCtrl.RegisterRoutes(authGroup)
+		webAuthnCtrl.RegisterAuthRoutes(authGroup)
 	}
 }
 
@@ -208,11 +260,17 @@ func adminPublicRoutes(rg *gin.RouterGroup, authCtrl *v1.AuthController) {
 }
 
 // adminUserRoutes 注册后台用户管理路由
-func adminUserRoutes(rg *gin.RouterGroup, userCtrl *v1.UserController, roleCtrl *v1.RoleController) {
+func adminUserRoutes(rg *gin.RouterGroup, userCtrl *v1.UserController, roleCtrl *v1.RoleController,
+	sessionCtrl *v1.SessionController, loginLogCtrl *v1.LoginLogController, loginLockCtrl *v1.LoginLockController,
+	mfaCtrl *v1.MFAController) {
 	// 用户管理
 	userGroup := rg.Group("/user")
 	{
 		userCtrl.RegisterAdminRoutes(userGroup)
+		sessionCtrl.RegisterAdminRoutes(userGroup)
+		loginLogCtrl.RegisterAdminUserRoutes(userGroup)
+		loginLockCtrl.RegisterAdminUserRoutes(userGroup)
+		mfaCtrl.RegisterAdminUserRoutes(userGroup)
 	}
 
 	// 角色管理
@@ -259,10 +317,35 @@ func adminContentRoutes(rg *gin.RouterGroup, articleCtrl *v1.ArticleController,
 }
 
 // adminSystemRoutes 注册后台系统管理路由
-func adminSystemRoutes(rg *gin.RouterGroup, configCtrl *v1.ConfigController) {
+func adminSystemRoutes(rg *gin.RouterGroup, configCtrl *v1.ConfigController, exportCtrl *v1.ExportController,
+	staticCtrl *v1.StaticController, loginLogCtrl *v1.LoginLogController, loginLockCtrl *v1.LoginLockController) {
 	// 系统配置
 	configGroup := rg.Group("/config")
 	{
 		configCtrl.RegisterRoutes(configGroup)
 	}
+
+	// 全站导出
+	exportGroup := rg.Group("/export")
+	{
+		exportCtrl.RegisterRoutes(exportGroup)
+	}
+
+	// 静态站点生成
+	staticGroup := rg.Group("/static")
+	{
+		staticCtrl.RegisterRoutes(staticGroup)
+	}
+
+	// 登录日志
+	loginLogGroup := rg.Group("/login-log")
+	{
+		loginLogCtrl.RegisterAdminRoutes(loginLogGroup)
+	}
+
+	// 登录锁定管理
+	loginLockGroup := rg.Group("/login-lock")
+	{
+		loginLockCtrl.RegisterAdminRoutes(loginLockGroup)
+	}
 }
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"go.uber.org/zap"
+)
+
+// 个人访问令牌参数
+const (
+	accessTokenPrefix        = "blog_pat_" // 令牌前缀，认证时据此区分个人访问令牌和登录令牌
+	accessTokenBytes         = 32          // 随机部分的字节数
+	accessTokenDisplayLength = 6           // 列表中展示的随机部分长度
+	maxAccessTokens          = 20          // 每个用户最多创建的个人访问令牌数
+	accessTokenTouchInterval = time.Minute // 最后使用时间的更新间隔，避免每次请求都写数据库
+)
+
+var (
+	// ErrAccessTokenNotFound 个人访问令牌不存在
+	ErrAccessTokenNotFound = errors.New("访问令牌不存在")
+	// ErrAccessTokenLimit 个人访问令牌数量超出限制
+	ErrAccessTokenLimit = errors.New("访问令牌数量已达上限，请先删除不再使用的访问令牌")
+	// ErrAccessTokenScope 申请的权限超出当前账号拥有的权限
+	ErrAccessTokenScope = errors.New("访问令牌的权限范围不能超出当前账号拥有的权限")
+	// ErrAccessTokenInvalid 个人访问令牌无效、已过期或所属账号不可用
+	ErrAccessTokenInvalid = errors.New("访问令牌无效或已过期")
+)
+
+// IsAccessToken 判断认证头中的令牌是否为个人访问令牌
+func IsAccessToken(token string) bool {
+	return strings.HasPrefix(token, accessTokenPrefix)
+}
+
+// CreateAccessToken 创建个人访问令牌，返回的令牌明文只在此时可见
+func CreateAccessToken(userID int, form model.PersonalAccessTokenCreateForm) (*model.PersonalAccessTokenCreateResult, error) {
+	var count int64
+	if err := model.DB.Model(&model.PersonalAccessToken{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count >= maxAccessTokens {
+		return nil, ErrAccessTokenLimit
+	}
+
+	// 令牌权限只能是账号当前权限的子集
+	scopes := normalizeScopes(form.Scopes)
+	granted, err := grantableScopes(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, scope := range scopes {
+		if !granted[scope] {
+			return nil, ErrAccessTokenScope
+		}
+	}
+
+	b := make([]byte, accessTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	token := accessTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+
+	accessToken := model.PersonalAccessToken{
+		UserID:      userID,
+		Name:        strings.TrimSpace(form.Name),
+		TokenPrefix: token[:len(accessTokenPrefix)+accessTokenDisplayLength],
+		TokenHash:   hashAccessToken(token),
+		Scopes:      strings.Join(scopes, ","),
+		ExpiresAt:   time.Now().AddDate(0, 0, form.ExpiresIn),
+	}
+	if err := model.DB.Create(&accessToken).Error; err != nil {
+		return nil, err
+	}
+	accessToken.ScopeList = scopes
+
+	return &model.PersonalAccessTokenCreateResult{PersonalAccessToken: accessToken, Token: token}, nil
+}
+
+// ListAccessTokens 获取用户的个人访问令牌列表，包括已过期的令牌
+func ListAccessTokens(userID int) ([]model.PersonalAccessToken, error) {
+	accessTokens := make([]model.PersonalAccessToken, 0)
+	if err := model.DB.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&accessTokens).Error; err != nil {
+		return nil, err
+	}
+	for i := range accessTokens {
+		accessTokens[i].ScopeList = splitScopes(accessTokens[i].Scopes)
+	}
+	return accessTokens, nil
+}
+
+// DeleteAccessToken 删除（撤销）用户的个人访问令牌，立即生效
+func DeleteAccessToken(userID int, tokenID int) error {
+	result := model.DB.Where("token_id = ? AND user_id = ?", tokenID, userID).Delete(&model.PersonalAccessToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAccessTokenNotFound
+	}
+	return nil
+}
+
+// AuthenticateAccessToken 校验个人访问令牌，返回令牌所属用户及其权限范围。
+// 令牌过期、已删除或所属账号被禁用时返回 ErrAccessTokenInvalid
+func AuthenticateAccessToken(token, ip string) (*model.AccessTokenIdentity, error) {
+	var accessToken model.PersonalAccessToken
+	if err := model.DB.Where("token_hash = ?", hashAccessToken(token)).First(&accessToken).Error; err != nil {
+		return nil, ErrAccessTokenInvalid
+	}
+	now := time.Now()
+	if !accessToken.ExpiresAt.After(now) {
+		return nil, ErrAccessTokenInvalid
+	}
+
+	var user model.User
+	if err := model.DB.Select("user_id", "username", "status").First(&user, accessToken.UserID).Error; err != nil {
+		return nil, ErrAccessTokenInvalid
+	}
+	if user.Status != model.UserStatusNormal {
+		return nil, ErrAccessTokenInvalid
+	}
+
+	var roleIDs []int
+	if err := model.DB.Table("sys_user_roles").
+		Where("user_id = ?", user.UserID).
+		Pluck("role_id", &roleIDs).Error; err != nil {
+		return nil, err
+	}
+
+	// 记录使用情况，失败不影响认证结果
+	if accessToken.LastUsedAt == nil || now.Sub(*accessToken.LastUsedAt) >= accessTokenTouchInterval {
+		updates := map[string]interface{}{"last_used_at": now}
+		if ip != "" {
+			updates["last_used_ip"] = ip
+		}
+		if err := model.DB.Model(&accessToken).Updates(updates).Error; err != nil {
+			zap.L().Warn("更新访问令牌使用记录失败", zap.Int("token_id", accessToken.TokenID), zap.Error(err))
+		}
+	}
+
+	return &model.AccessTokenIdentity{
+		TokenID:  accessToken.TokenID,
+		UserID:   user.UserID,
+		Username: user.Username,
+		RoleIDs:  roleIDs,
+		Scopes:   splitScopes(accessToken.Scopes),
+	}, nil
+}
+
+// grantableScopes 获取用户可以授予个人访问令牌的权限标识，与 HasPermission 使用相同的权限集合，
+// 超级管理员可以授予任意已启用的权限
+func grantableScopes(userID int) (map[string]bool, error) {
+	keys, err := loadUserPermissionKeys(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if key == permAll {
+			if keys, err = loadEnabledPermissionKeys(); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	granted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		granted[key] = true
+	}
+	return granted, nil
+}
+
+// normalizeScopes 去除空白和重复的权限标识并排序
+func normalizeScopes(scopes []string) []string {
+	seen := make(map[string]bool, len(scopes))
+	normalized := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if scope == "" || seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		normalized = append(normalized, scope)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// splitScopes 解析以逗号分隔的权限标识
+func splitScopes(scopes string) []string {
+	if scopes == "" {
+		return []string{}
+	}
+	return strings.Split(scopes, ",")
+}
+
+// hashAccessToken 计算个人访问令牌的哈希。令牌为高强度随机值，使用SHA-256即可，便于直接按哈希查询
+func hashAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
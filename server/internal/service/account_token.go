@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/jwt"
+)
+
+// 账号令牌缓存键
+const (
+	accountTokenKey         = "blog:auth:token:%s:%s"          // 令牌对应的用户ID、绑定的设备指纹和邮箱，使用后删除
+	accountTokenUserKey     = "blog:auth:token:%s:user:%d"     // 用户当前有效的令牌ID，签发新令牌时旧令牌作废
+	accountTokenCooldownKey = "blog:auth:token:%s:cooldown:%d" // 发送邮件的间隔限制
+)
+
+// 账号令牌用途
+const (
+	accountTokenVerifyEmail   = "verify_email"
+	accountTokenResetPassword = "reset_password"
+	accountTokenMagicLogin    = "magic_login"
+)
+
+// accountTokenCooldown 同一用途的邮件两次发送的最小间隔
+const accountTokenCooldown = 60 * time.Second
+
+var (
+	// ErrAccountTokenInvalid 令牌无效、已过期或已使用
+	ErrAccountTokenInvalid = errors.New("链接无效或已过期，请重新获取")
+	// ErrAccountTokenTooFrequent 发送邮件过于频繁
+	ErrAccountTokenTooFrequent = errors.New("发送过于频繁，请1分钟后再试")
+)
+
+// accountToken 令牌签发时保存的信息
+type accountToken struct {
+	UserID  int
+	Email   string
+	Binding string // 绑定的设备指纹，为空表示不限制设备
+}
+
+// issueAccountToken 签发邮件链接中使用的一次性令牌，格式为“令牌ID.签名”。
+// 令牌与邮箱绑定，邮箱变更后失效；同一用户同一用途只有最新签发的令牌有效
+func issueAccountToken(purpose string, info accountToken, ttl time.Duration) (string, error) {
+	ctx := context.Background()
+	ok, err := model.RDB.SetNX(ctx, fmt.Sprintf(accountTokenCooldownKey, purpose, info.UserID), 1, accountTokenCooldown).Result()
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", ErrAccountTokenTooFrequent
+	}
+
+	tokenID, err := jwt.NewTokenID()
+	if err != nil {
+		return "", err
+	}
+	signature, err := accountTokenSignature(purpose, tokenID)
+	if err != nil {
+		return "", err
+	}
+	userKey := fmt.Sprintf(accountTokenUserKey, purpose, info.UserID)
+	oldID, err := model.RDB.GetSet(ctx, userKey, tokenID).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", err
+	}
+
+	pipe := model.RDB.TxPipeline()
+	pipe.Expire(ctx, userKey, ttl)
+	pipe.Set(ctx, fmt.Sprintf(accountTokenKey, purpose, tokenID), fmt.Sprintf("%d:%s:%s", info.UserID, info.Binding, info.Email), ttl)
+	if oldID != "" {
+		pipe.Del(ctx, fmt.Sprintf(accountTokenKey, purpose, oldID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", err
+	}
+	return tokenID + "." + signature, nil
+}
+
+// consumeAccountToken 校验签名后取出并删除令牌，返回签发时保存的信息。令牌只能使用一次
+func consumeAccountToken(purpose, token string) (*accountToken, error) {
+	tokenID, signature, ok := strings.Cut(token, ".")
+	if !ok || tokenID == "" {
+		return nil, ErrAccountTokenInvalid
+	}
+	expected, err := accountTokenSignature(purpose, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, ErrAccountTokenInvalid
+	}
+
+	ctx := context.Background()
+	key := fmt.Sprintf(accountTokenKey, purpose, tokenID)
+	var get *redis.StringCmd
+	if _, err := model.RDB.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	}); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	value, err := get.Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrAccountTokenInvalid
+		}
+		return nil, err
+	}
+	parts := strings.SplitN(value, ":", 3)
+	if len(parts) != 3 {
+		return nil, ErrAccountTokenInvalid
+	}
+	userID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, ErrAccountTokenInvalid
+	}
+	model.RDB.Del(ctx, fmt.Sprintf(accountTokenUserKey, purpose, userID))
+	return &accountToken{UserID: userID, Binding: parts[1], Email: parts[2]}, nil
+}
+
+// accountTokenSignature 使用 account_token_secret 计算令牌ID的签名，不同用途的令牌不能混用
+func accountTokenSignature(purpose, tokenID string) (string, error) {
+	if tokenConfig.AccountTokenSecret == "" {
+		return "", ErrTokenSecretMissing
+	}
+	mac := hmac.New(sha256.New, []byte(tokenConfig.AccountTokenSecret))
+	mac.Write([]byte("account:" + purpose + ":" + tokenID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 应用密码参数
+const (
+	appPasswordLength   = 24                                // 密码长度（不含分隔符）
+	appPasswordAlphabet = "abcdefghjkmnpqrstuvwxyz23456789" // 去除易混淆字符
+	maxAppPasswords     = 20                                // 每个用户最多创建的应用密码数
+)
+
+var (
+	// ErrAppPasswordNotFound 应用密码不存在
+	ErrAppPasswordNotFound = errors.New("应用密码不存在")
+	// ErrAppPasswordLimit 应用密码数量超出限制
+	ErrAppPasswordLimit = errors.New("应用密码数量已达上限，请先删除不再使用的应用密码")
+	// ErrInvalidCredentials 用户名或密码错误，不区分具体原因以免泄露账号是否存在
+	ErrInvalidCredentials = errors.New("用户名或密码错误")
+	// ErrAppPasswordRequired 已启用或所属角色要求两步验证，不能使用登录密码，只能使用应用密码
+	ErrAppPasswordRequired = errors.New("账号已启用两步验证，请使用应用密码")
+)
+
+// CreateAppPassword 创建应用密码，返回的明文密码只在此时可见
+func CreateAppPassword(userID int, form model.AppPasswordCreateForm) (*model.AppPasswordCreateResult, error) {
+	var count int64
+	if err := model.DB.Model(&model.AppPassword{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count >= maxAppPasswords {
+		return nil, ErrAppPasswordLimit
+	}
+
+	password, err := generateAppPassword()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(normalizeAppPassword(password)), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	appPassword := model.AppPassword{
+		UserID:       userID,
+		AppName:      strings.TrimSpace(form.AppName),
+		PasswordHash: string(hash),
+	}
+	if err := model.DB.Create(&appPassword).Error; err != nil {
+		return nil, err
+	}
+
+	return &model.AppPasswordCreateResult{AppPassword: appPassword, Password: password}, nil
+}
+
+// ListAppPasswords 获取用户的应用密码列表
+func ListAppPasswords(userID int) ([]model.AppPassword, error) {
+	appPasswords := make([]model.AppPassword, 0)
+	if err := model.DB.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&appPasswords).Error; err != nil {
+		return nil, err
+	}
+	return appPasswords, nil
+}
+
+// DeleteAppPassword 删除（撤销）用户的应用密码
+func DeleteAppPassword(userID int, appPasswordID int) error {
+	result := model.DB.Where("app_password_id = ? AND user_id = ?", appPasswordID, userID).Delete(&model.AppPassword{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAppPasswordNotFound
+	}
+	return nil
+}
+
+// AuthenticateUser 使用用户名和登录密码或应用密码认证，用于XML-RPC等无法使用令牌的接口，
+// 与登录接口共用失败计数，账号或IP被限制时返回 *LoginLimitError。
+// 这些接口无法完成两步验证，已启用或所属角色要求两步验证的用户只能使用应用密码；
+// 系统要求验证邮箱时，未验证邮箱的用户返回 ErrEmailNotVerified
+func AuthenticateUser(username, password, ip string) (*model.User, error) {
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := CheckLoginAllowed(username, ip); err != nil {
+		var limitErr *LoginLimitError
+		if errors.As(err, &limitErr) {
+			return nil, err
+		}
+		zap.L().Error("检查登录限制失败", zap.String("username", username), zap.Error(err))
+	}
+
+	user, err := authenticateUser(username, password, ip)
+	if errors.Is(err, ErrInvalidCredentials) {
+		return nil, loginFailed(username, ip, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ResetLoginFailures(username)
+	if err := CheckEmailVerified(user.UserID); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// authenticateUser 校验登录密码或应用密码，需要两步验证的用户登录密码正确时返回 ErrAppPasswordRequired
+func authenticateUser(username, password, ip string) (*model.User, error) {
+	var user model.User
+	if err := model.DB.Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if user.Status != 1 {
+		return nil, ErrInvalidCredentials
+	}
+
+	// 登录密码，需要两步验证时不能单独使用
+	if user.PasswordHash != "" && bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) == nil {
+		required, err := mfaRequiredForPassword(user.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if required {
+			return nil, ErrAppPasswordRequired
+		}
+		return &user, nil
+	}
+
+	// 应用密码，数量有限，逐个比对
+	var appPasswords []model.AppPassword
+	if err := model.DB.Where("user_id = ?", user.UserID).Find(&appPasswords).Error; err != nil {
+		return nil, err
+	}
+	normalized := normalizeAppPassword(password)
+	for _, appPassword := range appPasswords {
+		if bcrypt.CompareHashAndPassword([]byte(appPassword.PasswordHash), []byte(normalized)) != nil {
+			continue
+		}
+
+		// 记录使用情况，失败不影响认证结果
+		updates := map[string]interface{}{"last_used_at": time.Now()}
+		if ip != "" {
+			updates["last_used_ip"] = ip
+		}
+		if err := model.DB.Model(&appPassword).Updates(updates).Error; err != nil {
+			zap.L().Warn("更新应用密码使用记录失败", zap.Int("app_password_id", appPassword.AppPasswordID), zap.Error(err))
+		}
+		return &user, nil
+	}
+
+	return nil, ErrInvalidCredentials
+}
+
+// mfaRequiredForPassword 用户是否已绑定验证器或所属角色要求两步验证
+func mfaRequiredForPassword(userID int) (bool, error) {
+	enabled, err := totpEnabled(userID)
+	if err != nil || enabled {
+		return enabled, err
+	}
+	return userRequiresMFA(userID)
+}
+
+// generateAppPassword 生成随机应用密码，每4个字符以空格分隔便于抄写
+func generateAppPassword() (string, error) {
+	var b strings.Builder
+	max := big.NewInt(int64(len(appPasswordAlphabet)))
+	for i := 0; i < appPasswordLength; i++ {
+		if i > 0 && i%4 == 0 {
+			b.WriteByte(' ')
+		}
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(appPasswordAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// normalizeAppPassword 去除分隔符并转为小写，客户端输入时可带或不带空格
+func normalizeAppPassword(password string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(password))
+}
@@ -20,10 +20,19 @@ func CreateArticle(form model.ArticleCreateForm, userID int) (int, error) {
 		return 0, err
 	}
 
+	// 检查定时发布/下线时间
+	if err := ValidateArticleSchedule(form.ScheduleTime, form.ExpireTime); err != nil {
+		return 0, err
+	}
+
+	// 没有发布权限的作者只能保存草稿或提交审核。调用方（MetaWeblog、导入）已按令牌权限范围确定状态
+	if err := CheckArticleCreatable(userID, nil, form.Status, form.ScheduleTime, form.ExpireTime); err != nil {
+		return 0, err
+	}
+
 	// 创建文章
 	article := model.Article{
 		Title:        form.Title,
-		Content:      form.Content,
 		Summary:      form.Summary,
 		CategoryID:   form.CategoryID,
 		CoverImage:   form.CoverImage,
@@ -34,6 +43,8 @@ func CreateArticle(form model.ArticleCreateForm, userID int) (int, error) {
 		CommentCount: 0,
 		UserID:       userID,
 		PublishedAt:  nil,
+		ScheduleTime: form.ScheduleTime,
+		ExpireTime:   form.ExpireTime,
 	}
 
 	// 如果发布，设置发布时间
@@ -56,6 +67,12 @@ func CreateArticle(form model.ArticleCreateForm, userID int) (int, error) {
 		return 0, err
 	}
 
+	// 保存内容，作为第1个版本
+	if _, err := appendArticleContent(tx, article.ArticleID, form.Content, form.ContentFormat); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
 	// 保存标签关联
 	if len(form.TagIDs) > 0 {
 		for _, tagID := range form.TagIDs {
@@ -81,11 +98,20 @@ func CreateArticle(form model.ArticleCreateForm, userID int) (int, error) {
 		}
 	}
 
+	// 更新全文检索向量
+	if err := refreshArticleSearchIndex(tx, article.ArticleID); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		return 0, err
 	}
 
+	InvalidateArticleSitemap(article.ArticleID)
+	RebuildStaticArticle(article.ArticleID, &model.StaticArticleRefs{})
+
 	return article.ArticleID, nil
 }
 
@@ -128,6 +154,32 @@ func UpdateArticle(articleID int, form model.ArticleUpdateForm, userID int) erro
 		}
 	}
 
+	// 已发布或已排期的文章需要发布权限才能修改
+	if err := CheckArticleEditable(articleID, userID, nil); err != nil {
+		return err
+	}
+
+	// 检查定时发布/下线时间，未传入的一项沿用文章当前设置
+	if form.ScheduleTime != nil || form.ExpireTime != nil {
+		// 设置定时任务需要发布权限
+		if err := CheckArticleCreatable(userID, nil, model.ArticleStatusDraft, form.ScheduleTime, form.ExpireTime); err != nil {
+			return err
+		}
+		scheduleTime, expireTime := article.ScheduleTime, article.ExpireTime
+		if form.ScheduleTime != nil {
+			scheduleTime = form.ScheduleTime
+		}
+		if form.ExpireTime != nil {
+			expireTime = form.ExpireTime
+		}
+		if err := ValidateArticleSchedule(scheduleTime, expireTime); err != nil {
+			return err
+		}
+	}
+
+	// 记录变更前关联的静态页面
+	staticRefs := SnapshotStaticArticle(article.ArticleID)
+
 	// 开启事务
 	tx := model.DB.Begin()
 	defer func() {
@@ -141,9 +193,6 @@ func UpdateArticle(articleID int, form model.ArticleUpdateForm, userID int) erro
 	if form.Title != "" {
 		updates["title"] = form.Title
 	}
-	if form.Content != "" {
-		updates["content"] = form.Content
-	}
 	if form.Summary != nil {
 		updates["summary"] = *form.Summary
 	}
@@ -165,11 +214,26 @@ func UpdateArticle(articleID int, form model.ArticleUpdateForm, userID int) erro
 		}
 	}
 
+	if form.ScheduleTime != nil {
+		updates["schedule_time"] = *form.ScheduleTime
+	}
+	if form.ExpireTime != nil {
+		updates["expire_time"] = *form.ExpireTime
+	}
+
 	if err := tx.Model(&article).Updates(updates).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
 
+	// 内容变更时追加新版本，保留历史内容
+	if form.Content != "" {
+		if _, err := appendArticleContent(tx, article.ArticleID, form.Content, form.ContentFormat); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
 	// 更新标签关联
 	if form.TagIDs != nil {
 		// 删除现有标签关联
@@ -202,11 +266,22 @@ func UpdateArticle(articleID int, form model.ArticleUpdateForm, userID int) erro
 		}
 	}
 
+	// 更新全文检索向量
+	if err := refreshArticleSearchIndex(tx, article.ArticleID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// 提交事务
 	if err := tx.Commit().Error; err != nil {
 		return err
 	}
 
+	// 内容可能已变更，清除渲染缓存和站点地图缓存
+	InvalidateArticleRender(article.ArticleID)
+	InvalidateArticleSitemap(article.ArticleID)
+	RebuildStaticArticle(article.ArticleID, staticRefs)
+
 	return nil
 }
 
@@ -405,6 +480,9 @@ func DeleteArticle(articleID int, userID int) error {
 		}
 	}
 
+	// 记录删除前关联的静态页面
+	staticRefs := SnapshotStaticArticle(article.ArticleID)
+
 	// 开启事务
 	tx := model.DB.Begin()
 	defer func() {
@@ -436,6 +514,11 @@ func DeleteArticle(articleID int, userID int) error {
 		return err
 	}
 
+	// 清除渲染缓存和站点地图缓存
+	InvalidateArticleRender(article.ArticleID)
+	InvalidateArticleSitemap(article.ArticleID)
+	RebuildStaticArticle(article.ArticleID, staticRefs)
+
 	return nil
 }
 
@@ -624,8 +707,8 @@ func GetArticleArchives() ([]model.ArchiveStat, error) {
 	var stats []model.ArchiveStat
 
 	if err := model.DB.Table("cms_articles").
-		Select("DATE_FORMAT(published_at, '%Y-%m') as month, COUNT(*) as article_count").
-		Where("is_published = true").
+		Select("TO_CHAR(publish_time, 'YYYY-MM') AS month, COUNT(*) AS article_count").
+		Where("status = ? AND publish_time <= ?", model.ArticleStatusPublished, time.Now()).
 		Group("month").
 		Order("month DESC").
 		Find(&stats).Error; err != nil {
@@ -634,3 +717,35 @@ func GetArticleArchives() ([]model.ArchiveStat, error) {
 
 	return stats, nil
 }
+
+// newArticleResponse 将文章模型转换为列表响应对象
+func newArticleResponse(article model.Article) model.ArticleResponse {
+	author := article.User.Nickname
+	if author == "" {
+		author = article.User.Username
+	}
+	return model.ArticleResponse{
+		ArticleID:    article.ArticleID,
+		UserID:       article.UserID,
+		Author:       author,
+		Title:        article.Title,
+		ArticleKey:   article.ArticleKey,
+		Summary:      article.Summary,
+		Thumbnail:    article.Thumbnail,
+		Status:       article.Status,
+		StatusName:   model.ArticleStatusNames[article.Status],
+		ArticleType:  article.ArticleType,
+		ViewCount:    article.ViewCount,
+		LikeCount:    article.LikeCount,
+		CommentCount: article.CommentCount,
+		AllowComment: article.AllowComment,
+		IsTop:        article.IsTop,
+		IsRecommend:  article.IsRecommend,
+		PublishTime:  article.PublishTime,
+		ScheduleTime: article.ScheduleTime,
+		ExpireTime:   article.ExpireTime,
+		RejectReason: article.RejectReason,
+		CreatedAt:    article.CreatedAt,
+		UpdatedAt:    article.UpdatedAt,
+	}
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/slug"
+	"gorm.io/gorm"
+)
+
+// 标识长度，与表字段长度一致
+const (
+	articleKeyMaxLen   = 200
+	tagNameMaxLen      = 50
+	tagKeyMaxLen       = 50
+	categoryNameMaxLen = 50
+	categoryKeyMaxLen  = 50
+)
+
+// ErrNoCategory 没有可用的分类
+var ErrNoCategory = errors.New("没有可用的分类，请先创建分类")
+
+// uniqueArticleKey 生成不重复的文章标识，key为空时根据标题生成，仍为空时使用当前时间，excludeID为修改中的文章
+func uniqueArticleKey(key, title string, excludeID int64) (string, error) {
+	base := slug.Make(key, articleKeyMaxLen-4)
+	if base == "" {
+		base = slug.Make(title, articleKeyMaxLen-4)
+	}
+	if base == "" {
+		base = time.Now().Format("20060102150405")
+	}
+
+	candidate := base
+	for i := 2; ; i++ {
+		var count int64
+		if err := model.DB.Model(&model.Article{}).
+			Where("article_key = ? AND article_id <> ?", candidate, excludeID).
+			Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = base + "-" + strconv.Itoa(i)
+	}
+}
+
+// ensureTags 根据名称查找标签，不存在的自动创建，返回去重后的标签ID
+func ensureTags(names []string) ([]int, error) {
+	tagIDs := make([]int, 0, len(names))
+	seen := make(map[string]bool)
+	created := false
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		if len([]rune(name)) > tagNameMaxLen {
+			name = string([]rune(name)[:tagNameMaxLen])
+		}
+
+		var tag model.Tag
+		err := model.DB.Where("LOWER(tag_name) = LOWER(?)", name).First(&tag).Error
+		if err == nil {
+			tagIDs = append(tagIDs, tag.TagID)
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		key, err := uniqueTagKey(name)
+		if err != nil {
+			return nil, err
+		}
+		tag = model.Tag{TagName: name, TagKey: key, IsVisible: true}
+		if err := model.DB.Create(&tag).Error; err != nil {
+			return nil, err
+		}
+		tagIDs = append(tagIDs, tag.TagID)
+		created = true
+	}
+
+	if created {
+		InvalidateSitemap(model.SitemapTypeTag)
+	}
+	return tagIDs, nil
+}
+
+// uniqueTagKey 根据标签名称生成不重复的标签标识
+func uniqueTagKey(name string) (string, error) {
+	base := slug.Make(name, tagKeyMaxLen-4)
+	if base == "" {
+		base = "tag"
+	}
+	candidate := base
+	for i := 2; ; i++ {
+		var count int64
+		if err := model.DB.Model(&model.Tag{}).Where("tag_key = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = base + "-" + strconv.Itoa(i)
+	}
+}
+
+// missingTagNames 返回不存在的标签名称，按名称忽略大小写匹配
+func missingTagNames(names []string) ([]string, error) {
+	var missing []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		if len([]rune(name)) > tagNameMaxLen {
+			name = string([]rune(name)[:tagNameMaxLen])
+		}
+
+		var count int64
+		if err := model.DB.Model(&model.Tag{}).Where("LOWER(tag_name) = LOWER(?)", name).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			missing = append(missing, name)
+		}
+	}
+	return missing, nil
+}
+
+// ensureCategoryPaths 按层级路径查找分类，create为真时自动创建不存在的分类
+//
+// 每条路径从上级到下级排列，返回各路径末级分类的ID和不存在的分类路径（以 / 连接）。
+// 只有一级的路径按名称匹配任意层级的分类，优先匹配顶级分类；多级路径逐级匹配。
+// create为假时只检查，路径中有不存在的分类则不返回该路径的ID。
+func ensureCategoryPaths(paths [][]string, create bool) ([]int, []string, error) {
+	var categoryIDs []int
+	var missing []string
+	seenID := make(map[int]bool)
+	created := false
+
+	for _, names := range paths {
+		var parentID *int
+		complete := true
+		for i, name := range names {
+			name = strings.TrimSpace(name)
+			if len([]rune(name)) > categoryNameMaxLen {
+				name = string([]rune(name)[:categoryNameMaxLen])
+			}
+
+			query := model.DB.Where("LOWER(category_name) = LOWER(?)", name)
+			switch {
+			case parentID != nil:
+				query = query.Where("parent_id = ?", *parentID)
+			case len(names) > 1:
+				query = query.Where("parent_id IS NULL")
+			}
+			var category model.Category
+			err := query.Order("parent_id IS NOT NULL, sort_order ASC, category_id ASC").First(&category).Error
+			if err == nil {
+				parentID = &category.CategoryID
+				continue
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil, err
+			}
+
+			missing = append(missing, strings.Join(names[:i+1], "/"))
+			if !create {
+				// 下级分类也不存在
+				for j := i + 1; j < len(names); j++ {
+					missing = append(missing, strings.Join(names[:j+1], "/"))
+				}
+				complete = false
+				break
+			}
+
+			key, err := uniqueCategoryKey(name)
+			if err != nil {
+				return nil, nil, err
+			}
+			// 分类路径由数据库触发器根据上级分类生成
+			category = model.Category{
+				ParentID:     parentID,
+				CategoryName: name,
+				CategoryKey:  key,
+				IsVisible:    true,
+			}
+			if err := model.DB.Create(&category).Error; err != nil {
+				return nil, nil, err
+			}
+			parentID = &category.CategoryID
+			created = true
+		}
+
+		if complete && parentID != nil && !seenID[*parentID] {
+			seenID[*parentID] = true
+			categoryIDs = append(categoryIDs, *parentID)
+		}
+	}
+
+	if created {
+		InvalidateSitemap(model.SitemapTypeCategory)
+	}
+	return categoryIDs, missing, nil
+}
+
+// uniqueCategoryKey 根据分类名称生成不重复的分类标识
+func uniqueCategoryKey(name string) (string, error) {
+	base := slug.Make(name, categoryKeyMaxLen-4)
+	if base == "" {
+		base = "category"
+	}
+	candidate := base
+	for i := 2; ; i++ {
+		var count int64
+		if err := model.DB.Model(&model.Category{}).Where("category_key = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = base + "-" + strconv.Itoa(i)
+	}
+}
+
+// categoryIDsByName 根据名称查找分类ID，忽略不存在的分类，都不存在时使用默认分类
+func categoryIDsByName(names []string) ([]int, error) {
+	var categoryIDs []int
+	if len(names) > 0 {
+		if err := model.DB.Model(&model.Category{}).
+			Where("category_name IN ?", names).
+			Order("sort_order ASC, category_id ASC").
+			Pluck("category_id", &categoryIDs).Error; err != nil {
+			return nil, err
+		}
+	}
+	if len(categoryIDs) > 0 {
+		return categoryIDs, nil
+	}
+
+	// 默认分类：排序最靠前的可见分类
+	var category model.Category
+	if err := model.DB.Where("is_visible = ?", true).
+		Order("sort_order ASC, category_id ASC").
+		First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoCategory
+		}
+		return nil, err
+	}
+	return []int{category.CategoryID}, nil
+}
+
+// articlePublishStatus 发布时的目标状态，没有发布权限的用户提交审核，scopes 为个人访问令牌的权限范围，不受限时传nil
+func articlePublishStatus(userID int, scopes []string) (int8, error) {
+	canPublish, err := HasScopedPermission(userID, scopes, PermArticlePublish)
+	if err != nil {
+		return 0, err
+	}
+	if canPublish {
+		return model.ArticleStatusPublished, nil
+	}
+	return model.ArticleStatusPending, nil
+}
+
+// splitTagNames 拆分以逗号（含全角逗号）分隔的标签
+func splitTagNames(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '，' || r == '、'
+	})
+}
@@ -0,0 +1,674 @@
+package service
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/frontmatter"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/render"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/slug"
+	"go.uber.org/zap"
+)
+
+// 导入限制
+const (
+	importMaxFiles    = 10000    // 压缩包内最多文件数
+	importMaxFileSize = 20 << 20 // 单个文件解压后最大字节数
+	titleMaxLen       = 200
+	summaryMaxLen     = 500
+	thumbnailMaxLen   = 255
+	seoKeywordsMaxLen = 200
+)
+
+// importMarkdownExts 作为文章导入的文件扩展名
+var importMarkdownExts = map[string]bool{
+	".md": true, ".markdown": true, ".mdown": true, ".mkd": true,
+}
+
+// importImageExts 导入时上传的图片扩展名
+var importImageExts = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".bmp": true, ".webp": true,
+}
+
+// 文章导入相关错误
+var (
+	ErrImportArchiveInvalid = errors.New("无法读取压缩包，请上传zip格式的文件")
+	ErrImportTooManyFiles   = fmt.Errorf("压缩包内文件数超过%d个", importMaxFiles)
+	ErrImportNoMarkdown     = errors.New("压缩包中没有Markdown文件")
+	ErrImportFileTooLarge   = fmt.Errorf("文件解压后超过%dMB", importMaxFileSize>>20)
+)
+
+// 正文中引用图片的写法，第一个匹配到的分组为地址
+var (
+	importMarkdownImage = regexp.MustCompile(`!\[[^\]]*\]\(\s*(?:<([^>\n]+)>|([^)\s]+))`)
+	importHTMLImage     = regexp.MustCompile(`(?i)<img\b[^>]*?\bsrc\s*=\s*["']([^"']+)`)
+	importReferenceLink = regexp.MustCompile(`(?m)^ {0,3}\[[^\]]+\]:\s*<?([^\s>]+)`)
+	// Hexo 文章资源标签：{% asset_img 文件名 [标题] %}
+	importHexoAssetImg = regexp.MustCompile(`\{%\s*asset_img\s+(\S+)\s*(.*?)\s*%\}`)
+	// Jekyll 文件名中的日期前缀
+	importJekyllDate = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-(.+)$`)
+)
+
+// importArchive 导入中的压缩包
+type importArchive struct {
+	files    map[string]*zip.File // 规范化后的路径
+	paths    []string             // 按路径排序
+	userID   int
+	dryRun   bool
+	uploaded map[string]string // 压缩包内路径 → 上传后的地址
+}
+
+// ImportMarkdownArchive 从 Hexo、Hugo、Jekyll 的Markdown压缩包导入文章
+//
+// 压缩包内每个Markdown文件导入为一篇文章，前置元数据支持YAML、TOML和JSON。
+// 字段对应关系：title→标题，date→发布时间，slug（缺省为文件名）→文章标识，
+// categories→分类，tags→标签，draft/published→状态，description→摘要。
+// 不存在的分类和标签会自动创建，正文中引用的压缩包内图片会上传并改写为上传后的地址。
+// 文章标识冲突时按 OnConflict 跳过或追加序号，DryRun 只检查不写入。
+// scopes 为个人访问令牌的权限范围，不包含发布权限时已发布的文章提交审核，不受限时传nil。
+func ImportMarkdownArchive(r io.ReaderAt, size int64, userID int, scopes []string, opts model.ImportOptions) (*model.ImportResult, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrImportArchiveInvalid
+	}
+	if len(zr.File) > importMaxFiles {
+		return nil, ErrImportTooManyFiles
+	}
+
+	archive := &importArchive{
+		files:    make(map[string]*zip.File),
+		userID:   userID,
+		dryRun:   opts.DryRun,
+		uploaded: make(map[string]string),
+	}
+	var markdownPaths []string
+	for _, f := range zr.File {
+		p, ok := importFilePath(f)
+		if !ok {
+			continue
+		}
+		archive.files[p] = f
+		archive.paths = append(archive.paths, p)
+		// Hugo 的 _index.md 是列表页，不是文章
+		if importMarkdownExts[strings.ToLower(path.Ext(p))] && path.Base(p) != "_index.md" {
+			markdownPaths = append(markdownPaths, p)
+		}
+	}
+	if len(markdownPaths) == 0 {
+		return nil, ErrImportNoMarkdown
+	}
+	sort.Strings(archive.paths)
+	sort.Strings(markdownPaths)
+
+	// 非草稿文章的状态，没有发布权限时提交审核
+	publishStatus, err := articlePublishStatus(userID, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	report := newImportReport(opts.DryRun)
+	seenKeys := make(map[string]bool)
+	for _, p := range markdownPaths {
+		item, err := archive.importFile(p, opts, publishStatus, seenKeys)
+		if err != nil {
+			item.Status = model.ImportItemFailed
+			item.Message = err.Error()
+			zap.L().Warn("导入文章失败", zap.String("file", p), zap.Error(err))
+		}
+		report.add(item)
+	}
+	report.result.Images = len(archive.uploaded)
+
+	return report.result, nil
+}
+
+// importReport 汇总导入结果
+type importReport struct {
+	result     *model.ImportResult
+	categories map[string]bool
+	tags       map[string]bool
+	users      map[string]bool
+}
+
+// newImportReport 创建导入结果汇总
+func newImportReport(dryRun bool) *importReport {
+	return &importReport{
+		result: &model.ImportResult{
+			DryRun:        dryRun,
+			NewCategories: make([]string, 0),
+			NewTags:       make([]string, 0),
+			Items:         make([]model.ImportItem, 0),
+		},
+		categories: make(map[string]bool),
+		tags:       make(map[string]bool),
+		users:      make(map[string]bool),
+	}
+}
+
+// add 记录单篇文章的导入结果
+func (r *importReport) add(item importFileResult) {
+	result := r.result
+	result.Total++
+	switch item.Status {
+	case model.ImportItemCreated, model.ImportItemReady:
+		result.Created++
+	case model.ImportItemSkipped:
+		result.Skipped++
+	case model.ImportItemFailed:
+		result.Failed++
+	}
+	if item.ConflictKey != "" {
+		result.Conflicts++
+	}
+	r.addCategories(item.newCategories)
+	r.addTags(item.newTags)
+	result.Items = append(result.Items, item.ImportItem)
+}
+
+// addCategories 记录新建的分类
+func (r *importReport) addCategories(names []string) {
+	for _, name := range names {
+		if !r.categories[name] {
+			r.categories[name] = true
+			r.result.NewCategories = append(r.result.NewCategories, name)
+		}
+	}
+}
+
+// addTags 记录新建的标签，名称忽略大小写
+func (r *importReport) addTags(names []string) {
+	for _, name := range names {
+		if !r.tags[strings.ToLower(name)] {
+			r.tags[strings.ToLower(name)] = true
+			r.result.NewTags = append(r.result.NewTags, name)
+		}
+	}
+}
+
+// addUser 记录新建的用户
+func (r *importReport) addUser(name string) {
+	if !r.users[name] {
+		r.users[name] = true
+		r.result.NewUsers = append(r.result.NewUsers, name)
+	}
+}
+
+// importFilePath 规范化压缩包内的文件路径，忽略目录、隐藏文件和越界路径
+func importFilePath(f *zip.File) (string, bool) {
+	if f.FileInfo().IsDir() {
+		return "", false
+	}
+	p := path.Clean(strings.ReplaceAll(f.Name, "\\", "/"))
+	p = strings.TrimPrefix(p, "/")
+	if p == "." || p == ".." || strings.HasPrefix(p, "../") {
+		return "", false
+	}
+	for _, part := range strings.Split(p, "/") {
+		if strings.HasPrefix(part, ".") || part == "__MACOSX" {
+			return "", false
+		}
+	}
+	return p, true
+}
+
+// importFileResult 单个文件的导入结果，附带新建的分类和标签
+type importFileResult struct {
+	model.ImportItem
+	newCategories []string
+	newTags       []string
+}
+
+// importFile 导入单个Markdown文件
+func (a *importArchive) importFile(p string, opts model.ImportOptions, publishStatus int8, seenKeys map[string]bool) (importFileResult, error) {
+	res := importFileResult{ImportItem: model.ImportItem{File: p}}
+
+	data, err := a.read(p)
+	if err != nil {
+		return res, err
+	}
+	matter, body, err := frontmatter.Parse(data)
+	if err != nil {
+		return res, err
+	}
+
+	// 文件名：Hugo 页面包取目录名，Jekyll 去掉日期前缀
+	name := strings.TrimSuffix(path.Base(p), path.Ext(p))
+	if name == "index" && path.Dir(p) != "." {
+		name = path.Base(path.Dir(p))
+	}
+	var fileDate time.Time
+	if m := importJekyllDate.FindStringSubmatch(name); m != nil {
+		fileDate, _ = frontmatter.ParseTime(m[1], time.Local)
+		name = m[2]
+	}
+
+	res.Title = truncateRunes(matter.String("title"), titleMaxLen)
+	if res.Title == "" {
+		res.Title = truncateRunes(name, titleMaxLen)
+	}
+
+	// 文章标识冲突检查
+	key := slug.Make(matter.String("slug"), articleKeyMaxLen)
+	if key == "" {
+		key = slug.Make(name, articleKeyMaxLen)
+	}
+	if key == "" {
+		key = slug.Make(res.Title, articleKeyMaxLen)
+	}
+	if ok, err := resolveImportKey(key, opts, seenKeys, &res.ImportItem); err != nil || !ok {
+		return res, err
+	}
+
+	// 状态：草稿或未发布的文章保存为草稿，Jekyll 的 _drafts 目录也是草稿
+	status := publishStatus
+	if draft, ok := matter.Bool("draft"); ok && draft {
+		status = model.ArticleStatusDraft
+	}
+	if published, ok := matter.Bool("published"); ok && !published {
+		status = model.ArticleStatusDraft
+	}
+	if importInDir(p, "_drafts") {
+		status = model.ArticleStatusDraft
+	}
+
+	// 分类和标签
+	categoryPaths := importCategoryPaths(p, matter)
+	tagNames := importTagNames(p, matter)
+	categoryIDs, missingCategories, err := ensureCategoryPaths(categoryPaths, !a.dryRun)
+	if err != nil {
+		return res, err
+	}
+	res.newCategories = missingCategories
+	if len(categoryPaths) == 0 {
+		// 没有分类时使用默认分类
+		if categoryIDs, err = categoryIDsByName(nil); err != nil {
+			return res, err
+		}
+	}
+	if res.newTags, err = missingTagNames(tagNames); err != nil {
+		return res, err
+	}
+
+	// 改写图片地址
+	content, err := a.rewriteImages(p, string(body), &res.ImportItem)
+	if err != nil {
+		return res, err
+	}
+	thumbnail := matter.String("cover", "thumbnail", "image", "banner")
+	if thumbnail != "" {
+		if thumbnail, _, err = a.rewriteImage(p, thumbnail, &res.ImportItem); err != nil {
+			return res, err
+		}
+		if len(thumbnail) > thumbnailMaxLen {
+			thumbnail = ""
+		}
+	}
+
+	if a.dryRun {
+		res.Status = model.ImportItemReady
+		return res, nil
+	}
+
+	tagIDs, err := ensureTags(tagNames)
+	if err != nil {
+		return res, err
+	}
+	allowComment := true
+	if comments, ok := matter.Bool("comments"); ok {
+		allowComment = comments
+	}
+
+	articleID, err := CreateArticle(model.ArticleCreateForm{
+		Title:         res.Title,
+		ArticleKey:    res.ArticleKey,
+		Content:       content,
+		ContentFormat: render.FormatMarkdown,
+		Summary:       truncateRunes(matter.String("description", "excerpt", "summary"), summaryMaxLen),
+		Thumbnail:     thumbnail,
+		Status:        status,
+		ArticleType:   1,
+		CategoryIDs:   categoryIDs,
+		TagIDs:        tagIDs,
+		AllowComment:  allowComment,
+		SEOKeywords:   truncateRunes(strings.Join(matter.Strings("keywords"), ","), seoKeywordsMaxLen),
+	}, a.userID)
+	if err != nil {
+		return res, err
+	}
+	res.ArticleID = articleID
+	res.Status = model.ImportItemCreated
+
+	// 沿用原文的发布时间
+	date, ok := matter.Time(time.Local, "date")
+	if !ok {
+		date = fileDate
+	}
+	importArticleCreated(articleID, status, date, 0, a.userID)
+
+	return res, nil
+}
+
+// resolveImportKey 检查文章标识冲突，按冲突处理方式跳过或追加序号，返回是否继续导入
+func resolveImportKey(key string, opts model.ImportOptions, seenKeys map[string]bool, item *model.ImportItem) (bool, error) {
+	if key == "" {
+		var err error
+		if key, err = uniqueArticleKey("", "", 0); err != nil {
+			return false, err
+		}
+	} else {
+		conflictID, err := articleKeyOwner(key)
+		if err != nil {
+			return false, err
+		}
+		if conflictID > 0 || seenKeys[key] {
+			item.ConflictKey = key
+			item.ConflictArticleID = conflictID
+			if opts.OnConflict != model.ImportConflictRename {
+				item.ArticleKey = key
+				item.Status = model.ImportItemSkipped
+				item.Message = "文章标识已存在"
+				return false, nil
+			}
+			if key, err = importRenameKey(key, seenKeys); err != nil {
+				return false, err
+			}
+		}
+	}
+	seenKeys[key] = true
+	item.ArticleKey = key
+	return true, nil
+}
+
+// importArticleCreated 导入的文章创建后沿用原文的发布时间和作者，并记录初始状态
+//
+// date为零值时保留当前时间，authorID为0时作者为导入者；更新时间由数据库触发器维护。
+func importArticleCreated(articleID int, status int8, date time.Time, authorID int, userID int) {
+	updates := make(map[string]interface{})
+	if !date.IsZero() {
+		updates["created_at"] = date
+		if status == model.ArticleStatusPublished {
+			updates["publish_time"] = date
+		}
+	}
+	if authorID > 0 && authorID != userID {
+		updates["user_id"] = authorID
+	}
+	if len(updates) > 0 {
+		if err := model.DB.Model(&model.Article{}).Where("article_id = ?", articleID).Updates(updates).Error; err != nil {
+			zap.L().Error("更新导入文章信息失败", zap.Int("article_id", articleID), zap.Error(err))
+		}
+	}
+
+	if err := RecordArticleCreated(int64(articleID), status, userID); err != nil {
+		zap.L().Error("记录文章审核日志失败", zap.Int("article_id", articleID), zap.Error(err))
+	}
+}
+
+// read 读取压缩包内的文件
+func (a *importArchive) read(p string) ([]byte, error) {
+	f, ok := a.files[p]
+	if !ok {
+		return nil, fmt.Errorf("文件不存在: %s", p)
+	}
+	if f.UncompressedSize64 > importMaxFileSize {
+		return nil, fmt.Errorf("%s: %w", p, ErrImportFileTooLarge)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	// 不信任压缩包声明的大小
+	data, err := io.ReadAll(io.LimitReader(rc, importMaxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > importMaxFileSize {
+		return nil, fmt.Errorf("%s: %w", p, ErrImportFileTooLarge)
+	}
+	return data, nil
+}
+
+// rewriteImages 上传正文引用的压缩包内图片并改写地址
+func (a *importArchive) rewriteImages(mdPath, body string, item *model.ImportItem) (string, error) {
+	var firstErr error
+	rewrite := func(ref string) string {
+		u, _, err := a.rewriteImage(mdPath, ref, item)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		return u
+	}
+
+	// Hexo 资源标签转换为Markdown图片
+	body = importHexoAssetImg.ReplaceAllStringFunc(body, func(tag string) string {
+		m := importHexoAssetImg.FindStringSubmatch(tag)
+		u, ok, err := a.rewriteImage(mdPath, m[1], item)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if !ok {
+			return tag
+		}
+		return "![" + strings.Trim(m[2], `"'`) + "](" + u + ")"
+	})
+	for _, re := range []*regexp.Regexp{importMarkdownImage, importHTMLImage, importReferenceLink} {
+		body = replaceSubmatch(re, body, rewrite)
+	}
+	return body, firstErr
+}
+
+// rewriteImage 上传单个压缩包内的图片并返回新地址，不是本地图片或图片缺失时返回原地址
+func (a *importArchive) rewriteImage(mdPath, ref string, item *model.ImportItem) (string, bool, error) {
+	p, local := a.resolve(mdPath, ref)
+	if !local {
+		return ref, false, nil
+	}
+	if p == "" {
+		item.Warnings = append(item.Warnings, "图片不存在: "+ref)
+		return ref, false, nil
+	}
+
+	u, ok := a.uploaded[p]
+	if !ok {
+		if a.dryRun {
+			u = p
+		} else {
+			data, err := a.read(p)
+			if err != nil {
+				return ref, false, err
+			}
+			result, err := UploadFileData(path.Base(p), mime.TypeByExtension(path.Ext(p)), data, a.userID, true)
+			if err != nil {
+				return ref, false, err
+			}
+			u = result.URL
+		}
+		a.uploaded[p] = u
+	}
+	item.Images++
+	return u, true, nil
+}
+
+// resolve 查找图片引用对应的压缩包内文件
+//
+// local为假表示不是需要处理的本地图片（外部地址或非图片）；local为真且路径为空表示图片缺失。
+// 依次尝试：相对文章所在目录、Hexo 文章资源目录、按路径后缀唯一匹配
+// （绝对路径对应 Hugo 的 static、Hexo 的 source 等目录）。
+func (a *importArchive) resolve(mdPath, ref string) (string, bool) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "#") || strings.HasPrefix(ref, "//") {
+		return "", false
+	}
+	u, err := url.Parse(ref)
+	if err != nil || u.Scheme != "" {
+		return "", false
+	}
+	ref = u.Path
+	if !importImageExts[strings.ToLower(path.Ext(ref))] {
+		return "", false
+	}
+
+	if !strings.HasPrefix(ref, "/") {
+		dir := path.Dir(mdPath)
+		assetDir := path.Join(dir, strings.TrimSuffix(path.Base(mdPath), path.Ext(mdPath)))
+		for _, candidate := range []string{path.Join(dir, ref), path.Join(assetDir, ref)} {
+			if _, ok := a.files[candidate]; ok {
+				return candidate, true
+			}
+		}
+	}
+
+	suffix := strings.TrimPrefix(path.Clean("/"+ref), "/")
+	match := ""
+	for _, p := range a.paths {
+		if p == suffix || strings.HasSuffix(p, "/"+suffix) {
+			if match != "" {
+				// 多个文件匹配时无法确定
+				return "", true
+			}
+			match = p
+		}
+	}
+	return match, true
+}
+
+// importRenameKey 为冲突的文章标识追加序号，同时避开本次导入已使用的标识
+func importRenameKey(key string, seenKeys map[string]bool) (string, error) {
+	base := slug.Make(key, articleKeyMaxLen-4)
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", base, i)
+		if seenKeys[candidate] {
+			continue
+		}
+		conflictID, err := articleKeyOwner(candidate)
+		if err != nil {
+			return "", err
+		}
+		if conflictID == 0 {
+			return candidate, nil
+		}
+	}
+}
+
+// articleKeyOwner 返回使用该文章标识的文章ID，不存在时返回0
+func articleKeyOwner(key string) (int64, error) {
+	var ids []int64
+	if err := model.DB.Model(&model.Article{}).
+		Where("article_key = ?", key).
+		Limit(1).
+		Pluck("article_id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return ids[0], nil
+}
+
+// importCategoryPaths 读取分类层级
+//
+// Hexo 的分类列表表示层级（[A, B] 为 A 下的 B），嵌套列表表示多个分类；
+// Hugo、Jekyll 的分类列表表示多个分类，Jekyll 的字符串分类以空格分隔。
+func importCategoryPaths(p string, matter frontmatter.Matter) [][]string {
+	key := "categories"
+	if _, ok := matter[key]; !ok {
+		key = "category"
+	}
+
+	switch v := matter[key].(type) {
+	case string:
+		if importIsJekyll(p) {
+			var paths [][]string
+			for _, name := range strings.Fields(v) {
+				paths = append(paths, []string{name})
+			}
+			return paths
+		}
+	case []interface{}:
+		if importIsHexo(p) {
+			nested := false
+			for _, item := range v {
+				if _, ok := item.([]interface{}); ok {
+					nested = true
+				}
+			}
+			if !nested {
+				if names := matter.Strings(key); len(names) > 0 {
+					return [][]string{names}
+				}
+				return nil
+			}
+		}
+	}
+	return matter.Paths(key)
+}
+
+// importTagNames 读取标签，字符串标签按逗号分隔，Jekyll 按空格分隔
+func importTagNames(p string, matter frontmatter.Matter) []string {
+	key := "tags"
+	if _, ok := matter[key]; !ok {
+		key = "tag"
+	}
+	if s, ok := matter[key].(string); ok {
+		if importIsJekyll(p) {
+			return strings.Fields(s)
+		}
+		return splitTagNames(s)
+	}
+	return matter.Strings(key)
+}
+
+// importIsHexo 是否为 Hexo 的文章目录
+func importIsHexo(p string) bool {
+	return strings.Contains("/"+p, "/source/_posts/") || strings.Contains("/"+p, "/source/_drafts/")
+}
+
+// importIsJekyll 是否为 Jekyll 的文章目录
+func importIsJekyll(p string) bool {
+	return !importIsHexo(p) && (importInDir(p, "_posts") || importInDir(p, "_drafts"))
+}
+
+// importInDir 路径中是否包含指定目录
+func importInDir(p, dir string) bool {
+	return strings.Contains("/"+path.Dir(p)+"/", "/"+dir+"/")
+}
+
+// replaceSubmatch 替换正则中第一个匹配到的分组的内容
+func replaceSubmatch(re *regexp.Regexp, s string, fn func(string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range re.FindAllStringSubmatchIndex(s, -1) {
+		for i := 2; i+1 < len(m); i += 2 {
+			if m[i] < 0 {
+				continue
+			}
+			b.WriteString(s[last:m[i]])
+			b.WriteString(fn(s[m[i]:m[i+1]]))
+			last = m[i+1]
+			break
+		}
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// truncateRunes 按字符数截断字符串
+func truncateRunes(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/render"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 文章渲染缓存
+const (
+	articleRenderCacheKey = "blog:article:render:%d"
+	articleRenderCacheTTL = 24 * time.Hour
+	// articleRenderVersion 渲染规则（Markdown语法、过滤白名单等）变更时递增，使旧缓存失效
+	articleRenderVersion = 1
+)
+
+// articleRenderCache 渲染缓存内容，内容版本或渲染规则变化时缓存失效
+type articleRenderCache struct {
+	RenderVersion int                         `json:"render_version"`
+	Result        model.ArticleRenderResponse `json:"result"`
+}
+
+// GetArticleRender 获取文章当前版本内容的渲染结果，优先读取缓存
+func GetArticleRender(articleID int) (*model.ArticleRenderResponse, error) {
+	var content model.ArticleContent
+	if err := model.DB.Where("article_id = ? AND is_current = ?", articleID, true).
+		Order("version DESC").
+		First(&content).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrArticleNotFound
+		}
+		return nil, err
+	}
+
+	ctx := context.Background()
+	key := fmt.Sprintf(articleRenderCacheKey, articleID)
+
+	// 缓存与当前内容版本一致时直接返回
+	data, err := model.RDB.Get(ctx, key).Bytes()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		zap.L().Warn("读取文章渲染缓存失败", zap.Int("article_id", articleID), zap.Error(err))
+	} else if err == nil {
+		var cached articleRenderCache
+		if err := json.Unmarshal(data, &cached); err == nil &&
+			cached.RenderVersion == articleRenderVersion && cached.Result.ContentID == content.ContentID {
+			return &cached.Result, nil
+		}
+	}
+
+	// 渲染并写入缓存，缓存失败不影响返回结果
+	rendered := render.Render(content.Content, content.ContentFormat)
+	result := model.ArticleRenderResponse{
+		ArticleID:     content.ArticleID,
+		ContentID:     content.ContentID,
+		Version:       content.Version,
+		ContentFormat: content.ContentFormat,
+		ContentHTML:   rendered.HTML,
+		TOC:           rendered.TOC,
+		WordCount:     rendered.WordCount,
+		ReadingTime:   rendered.ReadingTime,
+	}
+	if data, err := json.Marshal(articleRenderCache{RenderVersion: articleRenderVersion, Result: result}); err == nil {
+		if err := model.RDB.Set(ctx, key, data, articleRenderCacheTTL).Err(); err != nil {
+			zap.L().Warn("写入文章渲染缓存失败", zap.Int("article_id", articleID), zap.Error(err))
+		}
+	}
+
+	return &result, nil
+}
+
+// InvalidateArticleRender 删除文章渲染缓存，文章内容修改、恢复版本或删除后调用
+func InvalidateArticleRender(articleID int64) {
+	key := fmt.Sprintf(articleRenderCacheKey, articleID)
+	if err := model.RDB.Del(context.Background(), key).Err(); err != nil {
+		zap.L().Warn("删除文章渲染缓存失败", zap.Int64("article_id", articleID), zap.Error(err))
+	}
+}
@@ -0,0 +1,363 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PermArticlePublish 文章发布权限标识，拥有该权限的用户可直接发布文章并审核他人文章
+const PermArticlePublish = "content:article:publish"
+
+// 文章审核相关错误
+var (
+	ErrArticleForbidden        = errors.New("无权限操作该文章")
+	ErrArticlePublishDenied    = errors.New("无发布权限，文章需提交审核")
+	ErrArticleStatusTransition = errors.New("当前文章状态不允许该操作")
+	ErrArticleLocked           = errors.New("文章已发布或已设置定时发布，需由审核员修改")
+	ErrRejectReasonRequired    = errors.New("请填写驳回原因")
+)
+
+// articleTransition 文章状态流转定义
+type articleTransition struct {
+	action      int8
+	from        []int8
+	to          int8
+	needPublish bool // 是否需要发布权限
+}
+
+// 文章状态流转规则
+var (
+	transitionSubmit = articleTransition{
+		action: model.ReviewActionSubmit,
+		from:   []int8{model.ArticleStatusDraft, model.ArticleStatusOffline},
+		to:     model.ArticleStatusPending,
+	}
+	transitionWithdraw = articleTransition{
+		action: model.ReviewActionWithdraw,
+		from:   []int8{model.ArticleStatusPending, model.ArticleStatusOffline},
+		to:     model.ArticleStatusDraft,
+	}
+	transitionApprove = articleTransition{
+		action:      model.ReviewActionApprove,
+		from:        []int8{model.ArticleStatusPending},
+		to:          model.ArticleStatusPublished,
+		needPublish: true,
+	}
+	transitionReject = articleTransition{
+		action:      model.ReviewActionReject,
+		from:        []int8{model.ArticleStatusPending},
+		to:          model.ArticleStatusDraft,
+		needPublish: true,
+	}
+	transitionPublish = articleTransition{
+		action:      model.ReviewActionPublish,
+		from:        []int8{model.ArticleStatusDraft, model.ArticleStatusOffline},
+		to:          model.ArticleStatusPublished,
+		needPublish: true,
+	}
+	transitionOffline = articleTransition{
+		action:      model.ReviewActionOffline,
+		from:        []int8{model.ArticleStatusPublished},
+		to:          model.ArticleStatusOffline,
+		needPublish: true,
+	}
+	transitionUnpublish = articleTransition{
+		action:      model.ReviewActionWithdraw,
+		from:        []int8{model.ArticleStatusPublished},
+		to:          model.ArticleStatusDraft,
+		needPublish: true,
+	}
+)
+
+// SubmitArticle 作者提交文章审核。scopes 为个人访问令牌的权限范围，不受限时传nil，下同
+func SubmitArticle(articleID int, userID int, scopes []string) error {
+	return transitionArticle(articleID, userID, scopes, transitionSubmit, "")
+}
+
+// WithdrawArticle 作者撤回审核中的文章，或将已下线文章转为草稿
+func WithdrawArticle(articleID int, userID int, scopes []string) error {
+	return transitionArticle(articleID, userID, scopes, transitionWithdraw, "")
+}
+
+// ApproveArticle 审核通过并发布文章
+func ApproveArticle(articleID int, userID int, scopes []string, remark string) error {
+	return transitionArticle(articleID, userID, scopes, transitionApprove, remark)
+}
+
+// RejectArticle 驳回文章，驳回原因保存在文章上
+func RejectArticle(articleID int, userID int, scopes []string, reason string) error {
+	if reason == "" {
+		return ErrRejectReasonRequired
+	}
+	return transitionArticle(articleID, userID, scopes, transitionReject, reason)
+}
+
+// ChangeArticleStatus 按目标状态执行对应的文章状态流转
+func ChangeArticleStatus(articleID int, status int8, userID int, scopes []string) error {
+	// 查询当前状态以选择流转规则
+	var article model.Article
+	if err := model.DB.Select("article_id, status").First(&article, articleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrArticleNotFound
+		}
+		return err
+	}
+
+	// 状态未变化时无需流转
+	if article.Status == status {
+		return nil
+	}
+
+	switch status {
+	case model.ArticleStatusDraft:
+		if article.Status == model.ArticleStatusPublished {
+			return transitionArticle(articleID, userID, scopes, transitionUnpublish, "")
+		}
+		return transitionArticle(articleID, userID, scopes, transitionWithdraw, "")
+	case model.ArticleStatusPending:
+		return transitionArticle(articleID, userID, scopes, transitionSubmit, "")
+	case model.ArticleStatusPublished:
+		if article.Status == model.ArticleStatusPending {
+			return transitionArticle(articleID, userID, scopes, transitionApprove, "")
+		}
+		return transitionArticle(articleID, userID, scopes, transitionPublish, "")
+	case model.ArticleStatusOffline:
+		return transitionArticle(articleID, userID, scopes, transitionOffline, "")
+	}
+	return ErrArticleStatusTransition
+}
+
+// transitionArticle 执行文章状态流转并记录审核日志
+func transitionArticle(articleID int, userID int, scopes []string, t articleTransition, reason string) error {
+	// 检查发布权限，个人访问令牌还需包含发布权限
+	canPublish, err := HasScopedPermission(userID, scopes, PermArticlePublish)
+	if err != nil {
+		return err
+	}
+	if t.needPublish && !canPublish {
+		return ErrArticlePublishDenied
+	}
+
+	// 开启事务
+	tx := model.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// 锁定文章行，避免并发审核
+	var article model.Article
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("article_id, user_id, status").
+		First(&article, articleID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrArticleNotFound
+		}
+		return err
+	}
+
+	// 没有发布权限的用户只能操作自己的文章
+	if !canPublish && article.UserID != userID {
+		tx.Rollback()
+		return ErrArticleForbidden
+	}
+
+	// 检查当前状态是否允许流转
+	allowed := false
+	for _, from := range t.from {
+		if article.Status == from {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		tx.Rollback()
+		return ErrArticleStatusTransition
+	}
+
+	// 更新文章状态
+	updates := map[string]interface{}{
+		"status": t.to,
+	}
+	switch t.action {
+	case model.ReviewActionSubmit:
+		// 重新提交时清空上次的驳回原因
+		updates["reject_reason"] = nil
+	case model.ReviewActionReject:
+		updates["reject_reason"] = reason
+	case model.ReviewActionApprove, model.ReviewActionPublish:
+		// 立即发布时取消尚未执行的定时发布
+		updates["reject_reason"] = nil
+		updates["schedule_time"] = nil
+	}
+	if err := tx.Model(&article).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// 记录状态流转
+	if err := recordArticleReview(tx, article.ArticleID, t.action, article.Status, t.to, &userID, reason); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// 提交事务
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	// 发布状态变化会影响站点地图
+	InvalidateArticleSitemap(article.ArticleID)
+	RebuildStaticArticle(article.ArticleID, nil)
+
+	return nil
+}
+
+// recordArticleReview 写入文章审核记录，operatorID为空表示系统操作
+func recordArticleReview(tx *gorm.DB, articleID int64, action int8, fromStatus, toStatus int8, operatorID *int, reason string) error {
+	review := model.ArticleReview{
+		ArticleID:  articleID,
+		Action:     action,
+		FromStatus: fromStatus,
+		ToStatus:   toStatus,
+		OperatorID: operatorID,
+		Reason:     reason,
+	}
+	return tx.Create(&review).Error
+}
+
+// RecordArticleCreated 记录新建文章的初始状态，草稿不记录
+func RecordArticleCreated(articleID int64, status int8, userID int) error {
+	var action int8
+	switch status {
+	case model.ArticleStatusPending:
+		action = model.ReviewActionSubmit
+	case model.ArticleStatusPublished:
+		action = model.ReviewActionPublish
+	case model.ArticleStatusOffline:
+		action = model.ReviewActionOffline
+	default:
+		return nil
+	}
+	return recordArticleReview(model.DB, articleID, action, 0, status, &userID, "")
+}
+
+// CheckArticleCreatable 检查用户能否以指定状态和定时设置创建文章，scopes 为个人访问令牌的权限范围，不受限时传nil
+func CheckArticleCreatable(userID int, scopes []string, status int8, scheduleTime, expireTime *time.Time) error {
+	if status != model.ArticleStatusPublished && status != model.ArticleStatusOffline &&
+		scheduleTime == nil && expireTime == nil {
+		return nil
+	}
+
+	canPublish, err := HasScopedPermission(userID, scopes, PermArticlePublish)
+	if err != nil {
+		return err
+	}
+	if !canPublish {
+		return ErrArticlePublishDenied
+	}
+	return nil
+}
+
+// CheckArticleEditable 检查用户能否修改文章，没有发布权限的作者不能修改已发布或已排期的文章，
+// scopes 为个人访问令牌的权限范围，不受限时传nil
+func CheckArticleEditable(articleID int, userID int, scopes []string) error {
+	var article model.Article
+	if err := model.DB.Select("article_id, status, schedule_time").First(&article, articleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrArticleNotFound
+		}
+		return err
+	}
+
+	if article.Status != model.ArticleStatusPublished && article.ScheduleTime == nil {
+		return nil
+	}
+
+	canPublish, err := HasScopedPermission(userID, scopes, PermArticlePublish)
+	if err != nil {
+		return err
+	}
+	if !canPublish {
+		return ErrArticleLocked
+	}
+	return nil
+}
+
+// ListReviewQueue 获取待审核文章列表，按提交时间先后排序
+func ListReviewQueue(page, pageSize int) (*model.PageResult, error) {
+	query := model.DB.Model(&model.Article{}).Where("status = ?", model.ArticleStatusPending)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var articles []model.Article
+	if err := query.Preload("User").
+		Order("updated_at ASC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&articles).Error; err != nil {
+		return nil, err
+	}
+
+	list := make([]model.ArticleResponse, 0, len(articles))
+	for _, article := range articles {
+		list = append(list, newArticleResponse(article))
+	}
+
+	return model.NewPageResult(list, total, page, pageSize), nil
+}
+
+// ListArticleReviews 获取文章的状态流转记录
+func ListArticleReviews(articleID int) ([]model.ArticleReviewResponse, error) {
+	// 检查文章是否存在
+	var count int64
+	if err := model.DB.Model(&model.Article{}).Where("article_id = ?", articleID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrArticleNotFound
+	}
+
+	var reviews []model.ArticleReview
+	if err := model.DB.Preload("Operator").
+		Where("article_id = ?", articleID).
+		Order("created_at DESC, review_id DESC").
+		Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+
+	list := make([]model.ArticleReviewResponse, 0, len(reviews))
+	for _, review := range reviews {
+		item := model.ArticleReviewResponse{
+			ReviewID:   review.ReviewID,
+			ArticleID:  review.ArticleID,
+			Action:     review.Action,
+			ActionName: model.ReviewActionNames[review.Action],
+			FromStatus: review.FromStatus,
+			ToStatus:   review.ToStatus,
+			OperatorID: review.OperatorID,
+			Reason:     review.Reason,
+			CreatedAt:  review.CreatedAt,
+		}
+		if review.Operator != nil {
+			item.OperatorName = review.Operator.Nickname
+			if item.OperatorName == "" {
+				item.OperatorName = review.Operator.Username
+			}
+		} else {
+			item.OperatorName = "系统"
+		}
+		list = append(list, item)
+	}
+
+	return list, nil
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/config"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// articleScheduleLockKey 文章定时任务分布式锁，保证多实例部署时同一时刻只有一个实例执行
+const articleScheduleLockKey = "blog:scheduler:lock:article"
+
+// 调度器默认配置
+const (
+	defaultScheduleInterval  = 30
+	defaultScheduleBatchSize = 100
+)
+
+// 文章定时相关错误
+var (
+	ErrScheduleTimeInPast  = errors.New("定时发布时间必须晚于当前时间")
+	ErrExpireTimeInPast    = errors.New("定时下线时间必须晚于当前时间")
+	ErrExpireBeforePublish = errors.New("定时下线时间必须晚于定时发布时间")
+)
+
+// ValidateArticleSchedule 校验定时发布/下线时间
+func ValidateArticleSchedule(scheduleTime, expireTime *time.Time) error {
+	now := time.Now()
+	if scheduleTime != nil && !scheduleTime.After(now) {
+		return ErrScheduleTimeInPast
+	}
+	if expireTime != nil {
+		if !expireTime.After(now) {
+			return ErrExpireTimeInPast
+		}
+		if scheduleTime != nil && !expireTime.After(*scheduleTime) {
+			return ErrExpireBeforePublish
+		}
+	}
+	return nil
+}
+
+// StartArticleScheduler 启动文章定时发布/下线调度器，阻塞直到ctx取消
+func StartArticleScheduler(ctx context.Context, cfg config.SchedulerConfig) {
+	interval := time.Duration(cfg.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultScheduleInterval * time.Second
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultScheduleBatchSize
+	}
+
+	zap.L().Info("文章定时调度器已启动", zap.Duration("interval", interval), zap.Int("batch_size", batchSize))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// 启动时立即执行一次，补偿停机期间到期的任务
+	runArticleSchedule(ctx, interval, batchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			zap.L().Info("文章定时调度器已停止")
+			return
+		case <-ticker.C:
+			runArticleSchedule(ctx, interval, batchSize)
+		}
+	}
+}
+
+// runArticleSchedule 执行一轮文章定时任务
+func runArticleSchedule(ctx context.Context, interval time.Duration, batchSize int) {
+	// 获取分布式锁，锁过期时间与调度周期一致，防止实例崩溃后锁无法释放
+	release, ok, err := tryLock(ctx, articleScheduleLockKey, interval)
+	if err != nil {
+		// Redis不可用时仍继续执行，数据库行锁保证同一篇文章不会被重复处理
+		zap.L().Error("获取文章调度锁失败", zap.Error(err))
+	} else if !ok {
+		return
+	} else {
+		defer release()
+	}
+
+	published, err := PublishScheduledArticles(batchSize)
+	if err != nil {
+		zap.L().Error("定时发布文章失败", zap.Error(err))
+	} else if len(published) > 0 {
+		zap.L().Info("定时发布文章", zap.Int64s("article_ids", published))
+	}
+	for _, articleID := range published {
+		InvalidateArticleSitemap(articleID)
+		RebuildStaticArticle(articleID, nil)
+	}
+
+	expired, err := ExpireScheduledArticles(batchSize)
+	if err != nil {
+		zap.L().Error("定时下线文章失败", zap.Error(err))
+	} else if len(expired) > 0 {
+		zap.L().Info("定时下线文章", zap.Int64s("article_ids", expired))
+	}
+	for _, articleID := range expired {
+		InvalidateArticleSitemap(articleID)
+		RebuildStaticArticle(articleID, nil)
+	}
+}
+
+// PublishScheduledArticles 发布已到定时发布时间的文章，返回本次发布的文章ID
+func PublishScheduledArticles(batchSize int) ([]int64, error) {
+	// 以单条语句完成状态变更并记录审核日志，FOR UPDATE SKIP LOCKED 保证并发执行时每篇文章只被处理一次
+	var articleIDs []int64
+	err := model.DB.Raw(`
+		WITH due AS (
+			SELECT article_id, status FROM cms_articles
+			WHERE schedule_time IS NOT NULL AND schedule_time <= NOW()
+			ORDER BY schedule_time
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		), changed AS (
+			UPDATE cms_articles a
+			SET status = 3,
+				publish_time = CASE WHEN a.status = 3 THEN a.publish_time ELSE a.schedule_time END,
+				schedule_time = NULL,
+				reject_reason = NULL
+			FROM due
+			WHERE a.article_id = due.article_id
+			RETURNING a.article_id, due.status AS from_status
+		), logged AS (
+			INSERT INTO cms_article_reviews (article_id, action, from_status, to_status)
+			SELECT article_id, ?, from_status, 3 FROM changed WHERE from_status <> 3
+		)
+		SELECT article_id FROM changed`, batchSize, model.ReviewActionSchedulePublish).Scan(&articleIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return articleIDs, nil
+}
+
+// ExpireScheduledArticles 下线已到定时下线时间的文章，返回本次下线的文章ID
+func ExpireScheduledArticles(batchSize int) ([]int64, error) {
+	// 定时发布尚未执行的文章不参与下线，未发布的文章只清空下线时间
+	var articleIDs []int64
+	err := model.DB.Raw(`
+		WITH due AS (
+			SELECT article_id, status FROM cms_articles
+			WHERE expire_time IS NOT NULL AND expire_time <= NOW() AND schedule_time IS NULL
+			ORDER BY expire_time
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		), changed AS (
+			UPDATE cms_articles a
+			SET status = CASE WHEN a.status = 3 THEN 4 ELSE a.status END,
+				expire_time = NULL
+			FROM due
+			WHERE a.article_id = due.article_id
+			RETURNING a.article_id, due.status AS from_status
+		), logged AS (
+			INSERT INTO cms_article_reviews (article_id, action, from_status, to_status)
+			SELECT article_id, ?, from_status, 4 FROM changed WHERE from_status = 3
+		)
+		SELECT article_id FROM changed WHERE from_status = 3`, batchSize, model.ReviewActionScheduleOffline).Scan(&articleIDs).Error
+	if err != nil {
+		return nil, err
+	}
+	return articleIDs, nil
+}
+
+// SetArticleSchedule 设置文章定时发布/下线时间，时间为空表示取消
+func SetArticleSchedule(articleID int, form model.ArticleScheduleForm, userID int, scopes []string) error {
+	// 检查文章是否存在
+	var article model.Article
+	if err := model.DB.First(&article, articleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrArticleNotFound
+		}
+		return err
+	}
+
+	// 定时发布等同于审核通过，需要发布权限，个人访问令牌还需包含发布权限
+	canPublish, err := HasScopedPermission(userID, scopes, PermArticlePublish)
+	if err != nil {
+		return err
+	}
+	if !canPublish {
+		return ErrArticlePublishDenied
+	}
+
+	// 已发布的文章只能设置定时下线
+	if form.ScheduleTime != nil && article.Status == model.ArticleStatusPublished {
+		return errors.New("文章已发布，无法设置定时发布")
+	}
+
+	if err := ValidateArticleSchedule(form.ScheduleTime, form.ExpireTime); err != nil {
+		return err
+	}
+
+	// 使用map更新以便写入NULL
+	updates := map[string]interface{}{
+		"schedule_time": form.ScheduleTime,
+		"expire_time":   form.ExpireTime,
+	}
+	if err := model.DB.Model(&article).Updates(updates).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListScheduledArticles 获取待执行定时任务的文章列表
+func ListScheduledArticles(page, pageSize int) (*model.PageResult, error) {
+	query := model.DB.Model(&model.Article{}).
+		Where("schedule_time IS NOT NULL OR expire_time IS NOT NULL")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var articles []model.Article
+	if err := query.Preload("User").
+		Order("COALESCE(schedule_time, expire_time) ASC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&articles).Error; err != nil {
+		return nil, err
+	}
+
+	list := make([]model.ArticleResponse, 0, len(articles))
+	for _, article := range articles {
+		list = append(list, newArticleResponse(article))
+	}
+
+	return model.NewPageResult(list, total, page, pageSize), nil
+}
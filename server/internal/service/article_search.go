@@ -0,0 +1,211 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/config"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/segment"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 搜索关键词最大长度（字符数）
+const searchKeywordMaxLen = 100
+
+// 重建搜索索引时每批处理的文章数
+const searchReindexBatchSize = 200
+
+// htmlTagPattern 匹配HTML标签，建立索引和生成摘要前去除
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// 文章搜索相关错误
+var (
+	ErrSearchKeywordEmpty = errors.New("请输入搜索关键词")
+	ErrSearchTimeInvalid  = errors.New("时间格式错误，应为YYYY-MM-DD")
+)
+
+// InitArticleSearch 初始化文章搜索分词器，加载配置的用户词典
+func InitArticleSearch(cfg config.SearchConfig) error {
+	seg := segment.Default()
+	if cfg.UserDict == "" {
+		return nil
+	}
+	if err := seg.LoadFile(cfg.UserDict); err != nil {
+		return err
+	}
+	zap.L().Info("已加载搜索用户词典", zap.String("path", cfg.UserDict))
+	return nil
+}
+
+// searchTokens 对文本分词并去除停用词，以空格连接后供to_tsvector使用
+func searchTokens(text string) string {
+	words := segment.Default().CutForSearch(htmlTagPattern.ReplaceAllString(text, " "))
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		if !segment.IsStopWord(word) {
+			tokens = append(tokens, word)
+		}
+	}
+	return strings.Join(tokens, " ")
+}
+
+// refreshArticleSearchIndex 对标题、摘要和当前版本内容分词后重建文章全文检索向量
+func refreshArticleSearchIndex(tx *gorm.DB, articleID int64) error {
+	var article model.Article
+	if err := tx.Select("article_id, title, summary").First(&article, articleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrArticleNotFound
+		}
+		return err
+	}
+
+	var content model.ArticleContent
+	if err := tx.Select("content").
+		Where("article_id = ? AND is_current = ?", articleID, true).
+		Order("version DESC").
+		First(&content).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	return tx.Exec(`
+		UPDATE cms_articles SET search_vector =
+			setweight(to_tsvector('simple', ?), 'A') ||
+			setweight(to_tsvector('simple', ?), 'B') ||
+			setweight(to_tsvector('simple', ?), 'C')
+		WHERE article_id = ?`,
+		searchTokens(article.Title), searchTokens(article.Summary), searchTokens(content.Content), articleID).Error
+}
+
+// RefreshArticleSearchIndex 重建指定文章的全文检索向量
+func RefreshArticleSearchIndex(articleID int64) error {
+	return refreshArticleSearchIndex(model.DB, articleID)
+}
+
+// RebuildArticleSearchIndex 分批重建全部文章的全文检索向量，词典变更后需要执行，返回处理的文章数
+func RebuildArticleSearchIndex() (int, error) {
+	var lastID int64
+	count := 0
+	for {
+		var articleIDs []int64
+		if err := model.DB.Model(&model.Article{}).
+			Where("article_id > ?", lastID).
+			Order("article_id ASC").
+			Limit(searchReindexBatchSize).
+			Pluck("article_id", &articleIDs).Error; err != nil {
+			return count, err
+		}
+		if len(articleIDs) == 0 {
+			return count, nil
+		}
+
+		for _, articleID := range articleIDs {
+			if err := refreshArticleSearchIndex(model.DB, articleID); err != nil && !errors.Is(err, ErrArticleNotFound) {
+				return count, err
+			}
+			count++
+		}
+		lastID = articleIDs[len(articleIDs)-1]
+	}
+}
+
+// searchQueryWords 对搜索关键词分词，去除停用词；全部为停用词时保留原分词结果
+func searchQueryWords(keyword string) []string {
+	words := segment.Default().Cut(keyword)
+	if filtered := segment.FilterStopWords(words); len(filtered) > 0 {
+		return filtered
+	}
+	return words
+}
+
+// SearchArticles 全文搜索已发布文章，按相关度排序并返回高亮摘要
+func SearchArticles(params model.ArticleQueryParams) (*model.PageResult, error) {
+	keyword := strings.TrimSpace(params.Keyword)
+	if keyword == "" {
+		return nil, ErrSearchKeywordEmpty
+	}
+	if utf8.RuneCountInString(keyword) > searchKeywordMaxLen {
+		keyword = string([]rune(keyword)[:searchKeywordMaxLen])
+	}
+
+	// 关键词与索引使用相同的分词器，分词结果之间为“与”关系
+	words := searchQueryWords(keyword)
+	tsQuery := strings.Join(words, " ")
+
+	// 全文匹配或标题三元组相似（容错拼写错误）
+	query := model.DB.Table("cms_articles a").
+		Where("a.status = ?", model.ArticleStatusPublished).
+		Where("(a.search_vector @@ plainto_tsquery('simple', ?) OR a.title %> ?)", tsQuery, keyword)
+
+	// 分类筛选，包含子分类
+	if params.CategoryID > 0 {
+		query = query.Where(`EXISTS (
+			SELECT 1 FROM cms_article_categories ac
+			JOIN cms_categories cat ON cat.category_id = ac.category_id
+			WHERE ac.article_id = a.article_id
+			AND cat.path <@ (SELECT path FROM cms_categories WHERE category_id = ?))`, params.CategoryID)
+	}
+	if params.TagID > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM cms_article_tags t WHERE t.article_id = a.article_id AND t.tag_id = ?)", params.TagID)
+	}
+	if params.UserID > 0 {
+		query = query.Where("a.user_id = ?", params.UserID)
+	}
+	if params.ArticleType > 0 {
+		query = query.Where("a.article_type = ?", params.ArticleType)
+	}
+
+	// 发布日期筛选
+	if params.StartTime != "" {
+		startTime, err := time.ParseInLocation("2006-01-02", params.StartTime, time.Local)
+		if err != nil {
+			return nil, ErrSearchTimeInvalid
+		}
+		query = query.Where("a.publish_time >= ?", startTime)
+	}
+	if params.EndTime != "" {
+		endTime, err := time.ParseInLocation("2006-01-02", params.EndTime, time.Local)
+		if err != nil {
+			return nil, ErrSearchTimeInvalid
+		}
+		query = query.Where("a.publish_time < ?", endTime.AddDate(0, 0, 1))
+	}
+
+	// 计算总数
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	// 分页查询并计算相关度
+	paged := query.Session(&gorm.Session{}).
+		Select(`a.article_id, a.user_id, a.title, a.article_key, a.summary, a.thumbnail, a.article_type,
+			a.view_count, a.like_count, a.comment_count, a.publish_time,
+			ts_rank_cd(a.search_vector, plainto_tsquery('simple', ?), 32) + word_similarity(?, a.title) AS rank`, tsQuery, keyword).
+		Order("rank DESC, a.publish_time DESC").
+		Offset((params.Page - 1) * params.PageSize).
+		Limit(params.PageSize)
+
+	// 仅对当前页生成高亮摘要
+	var results []model.ArticleSearchResult
+	if err := model.DB.Table("(?) AS p", paged).
+		Select(`p.*, COALESCE(NULLIF(u.nickname, ''), u.username) AS author,
+			COALESCE(c.content, p.summary, '') AS snippet`).
+		Joins("LEFT JOIN sys_users u ON u.user_id = p.user_id").
+		Joins("LEFT JOIN cms_article_contents c ON c.article_id = p.article_id AND c.is_current").
+		Order("p.rank DESC, p.publish_time DESC").
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		results[i].TitleHighlight = segment.Highlight(results[i].Title, words)
+		results[i].Snippet = segment.Snippet(htmlTagPattern.ReplaceAllString(results[i].Snippet, " "), words)
+	}
+
+	return model.NewPageResult(results, total, params.Page, params.PageSize), nil
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/diff"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 文章版本相关错误
+var (
+	ErrArticleNotFound        = errors.New("文章不存在")
+	ErrArticleVersionNotFound = errors.New("文章版本不存在")
+	ErrArticleVersionCurrent  = errors.New("该版本已是当前版本")
+)
+
+// appendArticleContent 追加文章内容新版本并设为当前版本，需在事务中调用
+func appendArticleContent(tx *gorm.DB, articleID int64, content string, contentFormat int8) (*model.ArticleContent, error) {
+	// 锁定文章行，避免并发编辑产生重复的版本号
+	var article model.Article
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("article_id").
+		First(&article, articleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrArticleNotFound
+		}
+		return nil, err
+	}
+
+	// 查询当前版本
+	var current model.ArticleContent
+	if err := tx.Where("article_id = ? AND is_current = ?", articleID, true).
+		Order("version DESC").
+		First(&current).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// 未指定格式时沿用当前版本格式
+	if contentFormat == 0 {
+		contentFormat = current.ContentFormat
+		if contentFormat == 0 {
+			contentFormat = 1
+		}
+	}
+
+	// 内容未变化时不产生新版本
+	if current.ContentID != 0 && current.Content == content && current.ContentFormat == contentFormat {
+		return &current, nil
+	}
+
+	// 计算新版本号
+	var maxVersion int
+	if err := tx.Model(&model.ArticleContent{}).
+		Select("COALESCE(MAX(version), 0)").
+		Where("article_id = ?", articleID).
+		Scan(&maxVersion).Error; err != nil {
+		return nil, err
+	}
+
+	// 取消旧的当前版本
+	if err := tx.Model(&model.ArticleContent{}).
+		Where("article_id = ? AND is_current = ?", articleID, true).
+		Update("is_current", false).Error; err != nil {
+		return nil, err
+	}
+
+	// 写入新版本
+	newContent := model.ArticleContent{
+		ArticleID:     articleID,
+		Content:       content,
+		ContentFormat: contentFormat,
+		Version:       maxVersion + 1,
+		IsCurrent:     true,
+	}
+	if err := tx.Create(&newContent).Error; err != nil {
+		return nil, err
+	}
+
+	// 同步更新文章修改时间
+	if err := tx.Model(&model.Article{}).
+		Where("article_id = ?", articleID).
+		Update("updated_at", time.Now()).Error; err != nil {
+		return nil, err
+	}
+
+	return &newContent, nil
+}
+
+// ListArticleVersions 获取文章内容版本列表
+func ListArticleVersions(articleID int) ([]model.ArticleVersionResponse, error) {
+	// 检查文章是否存在
+	var count int64
+	if err := model.DB.Model(&model.Article{}).Where("article_id = ?", articleID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrArticleNotFound
+	}
+
+	var versions []model.ArticleVersionResponse
+	if err := model.DB.Model(&model.ArticleContent{}).
+		Select("content_id, article_id, version, content_format, is_current, CHAR_LENGTH(content) AS content_length, created_at").
+		Where("article_id = ?", articleID).
+		Order("version DESC").
+		Scan(&versions).Error; err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// GetArticleVersion 获取文章指定版本的内容
+func GetArticleVersion(articleID int, version int) (*model.ArticleContent, error) {
+	var content model.ArticleContent
+	if err := model.DB.Where("article_id = ? AND version = ?", articleID, version).
+		First(&content).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrArticleVersionNotFound
+		}
+		return nil, err
+	}
+	return &content, nil
+}
+
+// DiffArticleVersions 比较文章两个版本的行级差异
+func DiffArticleVersions(articleID int, fromVersion, toVersion int) (*model.ArticleVersionDiffResponse, error) {
+	from, err := GetArticleVersion(articleID, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := GetArticleVersion(articleID, toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	result := diff.Lines(from.Content, to.Content)
+
+	return &model.ArticleVersionDiffResponse{
+		ArticleID:   int64(articleID),
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		Added:       result.Added,
+		Removed:     result.Removed,
+		Lines:       result.Lines,
+	}, nil
+}
+
+// RestoreArticleVersion 将文章历史版本恢复为新的当前版本
+func RestoreArticleVersion(articleID int, version int, userID int) (*model.ArticleContent, error) {
+	// 检查文章是否存在
+	var article model.Article
+	if err := model.DB.First(&article, articleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrArticleNotFound
+		}
+		return nil, err
+	}
+
+	// 检查是否有权限恢复（作者或拥有文章编辑权限的用户可以恢复）
+	if article.UserID != userID {
+		canEdit, err := HasPermission(userID, PermArticleEdit)
+		if err != nil {
+			return nil, err
+		}
+		if !canEdit {
+			return nil, errors.New("无权限恢复该文章")
+		}
+	}
+
+	// 获取要恢复的版本
+	target, err := GetArticleVersion(articleID, version)
+	if err != nil {
+		return nil, err
+	}
+	if target.IsCurrent {
+		return nil, ErrArticleVersionCurrent
+	}
+
+	// 开启事务
+	tx := model.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// 以历史内容追加新版本
+	restored, err := appendArticleContent(tx, int64(articleID), target.Content, target.ContentFormat)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// 更新全文检索向量
+	if err := refreshArticleSearchIndex(tx, int64(articleID)); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// 提交事务
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	// 清除渲染缓存和站点地图缓存
+	InvalidateArticleRender(int64(articleID))
+	InvalidateArticleSitemap(int64(articleID))
+	RebuildStaticArticle(int64(articleID), nil)
+
+	return restored, nil
+}
@@ -0,0 +1,451 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/config"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/jwt"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/useragent"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 令牌缓存键
+const (
+	tokenBlacklistKey    = "blog:auth:blacklist:%s"     // 已吊销的访问令牌，值为1，过期时间与令牌一致
+	tokenFamilyKey       = "blog:auth:family:%s"        // 刷新令牌族（登录会话），记录当前有效的刷新令牌、最近签发的访问令牌和客户端信息
+	userTokenFamiliesKey = "blog:auth:user:%d:families" // 用户的全部刷新令牌族
+	userTokenVersionKey  = "blog:auth:user:%d:version"  // 用户的令牌版本，退出全部设备时递增，低于当前版本的令牌失效
+)
+
+// 令牌默认有效期（秒）
+const (
+	defaultAccessTokenExpire  = 7200
+	defaultRefreshTokenExpire = 7 * 24 * 3600
+)
+
+var (
+	// ErrRefreshTokenInvalid 刷新令牌无效或已过期
+	ErrRefreshTokenInvalid = errors.New("刷新令牌无效或已过期")
+	// ErrRefreshTokenReused 刷新令牌被重复使用，可能已泄露，该次登录的全部令牌已吊销
+	ErrRefreshTokenReused = errors.New("刷新令牌已失效，请重新登录")
+	// ErrTokenRevoked 令牌已被吊销
+	ErrTokenRevoked = errors.New("登录已失效，请重新登录")
+	// ErrTokenSecretMissing 没有可用于两步验证密钥加密和邮件链接令牌签名的对称密钥
+	ErrTokenSecretMissing = errors.New("未配置 jwt_secret，使用 jwt_keys 签名时需配置 mfa_secret_key 和 account_token_secret")
+)
+
+// rotateRefreshTokenScript 轮换刷新令牌：只有提交的令牌是令牌族当前的刷新令牌时才替换为新令牌。
+// 返回1成功，0令牌族不存在（已退出或过期），-1令牌已被轮换过（重复使用）
+var rotateRefreshTokenScript = redis.NewScript(`
+local current = redis.call('HGET', KEYS[1], 'refresh_jti')
+if not current then
+	return 0
+end
+if current ~= ARGV[1] then
+	return -1
+end
+redis.call('HSET', KEYS[1], 'refresh_jti', ARGV[2], 'access_jti', ARGV[3], 'access_exp', ARGV[4])
+redis.call('EXPIRE', KEYS[1], ARGV[5])
+return 1
+`)
+
+// checkTokenScript 检查访问令牌：已加入黑名单，或所属令牌族已被吊销时返回0；
+// 否则更新令牌族的最近访问时间和IP，返回1
+var checkTokenScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+if KEYS[2] ~= KEYS[1] then
+	if redis.call('EXISTS', KEYS[2]) == 0 then
+		return 0
+	end
+	redis.call('HSET', KEYS[2], 'last_seen', ARGV[1], 'last_ip', ARGV[2])
+end
+return 1
+`)
+
+// tokenConfig 令牌签发配置
+var tokenConfig config.ServerConfig
+
+// tokenKeys 令牌签名密钥
+var tokenKeys *jwt.KeySet
+
+// InitAuthToken 设置令牌有效期并加载签名密钥。未配置 jwt_keys 时使用 jwt_secret 以 HS256 签名。
+// 两步验证密钥加密和邮件链接令牌签名未单独配置密钥时使用 jwt_secret，都为空时返回错误
+func InitAuthToken(cfg config.ServerConfig) error {
+	if cfg.JWTExpire <= 0 {
+		cfg.JWTExpire = defaultAccessTokenExpire
+	}
+	if cfg.JWTRefreshExpire <= 0 {
+		cfg.JWTRefreshExpire = defaultRefreshTokenExpire
+	}
+	if cfg.MFASecretKey == "" {
+		cfg.MFASecretKey = cfg.JWTSecret
+	}
+	if cfg.AccountTokenSecret == "" {
+		cfg.AccountTokenSecret = cfg.JWTSecret
+	}
+	if cfg.MFASecretKey == "" || cfg.AccountTokenSecret == "" {
+		return ErrTokenSecretMissing
+	}
+
+	var keys *jwt.KeySet
+	var err error
+	if len(cfg.JWTKeys) == 0 {
+		keys, err = jwt.NewHMACKeySet(cfg.JWTSecret)
+	} else {
+		keys, err = loadTokenKeys(cfg)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := keys.SigningKey(time.Now()); err != nil {
+		return err
+	}
+
+	tokenConfig = cfg
+	tokenKeys = keys
+	return nil
+}
+
+// TokenKeys 返回令牌签名密钥，用于验证令牌
+func TokenKeys() *jwt.KeySet {
+	return tokenKeys
+}
+
+// loadTokenKeys 从文件加载配置的非对称签名密钥
+func loadTokenKeys(cfg config.ServerConfig) (*jwt.KeySet, error) {
+	keys := make([]*jwt.Key, 0, len(cfg.JWTKeys))
+	for i, item := range cfg.JWTKeys {
+		opts := jwt.KeyOptions{
+			ID:             item.KeyID,
+			Algorithm:      item.Algorithm,
+			PrivateKeyFile: item.PrivateKeyFile,
+			PublicKeyFile:  item.PublicKeyFile,
+		}
+		var err error
+		if item.NotBefore != "" {
+			if opts.NotBefore, err = time.Parse(time.RFC3339, item.NotBefore); err != nil {
+				return nil, fmt.Errorf("第%d个令牌签名密钥的 not_before 格式错误：%w", i+1, err)
+			}
+		}
+		if item.ExpireAt != "" {
+			if opts.ExpireAt, err = time.Parse(time.RFC3339, item.ExpireAt); err != nil {
+				return nil, fmt.Errorf("第%d个令牌签名密钥的 expire_at 格式错误：%w", i+1, err)
+			}
+		}
+
+		key, err := jwt.LoadKey(opts)
+		if err != nil {
+			return nil, fmt.Errorf("加载第%d个令牌签名密钥失败：%w", i+1, err)
+		}
+		keys = append(keys, key)
+	}
+
+	// 旧密钥在过期前的令牌最长有效期内停止签名，保证其签发的令牌在有效期内都能验证
+	maxLifetime := time.Duration(cfg.JWTRefreshExpire) * time.Second
+	if access := time.Duration(cfg.JWTExpire) * time.Second; access > maxLifetime {
+		maxLifetime = access
+	}
+	return jwt.NewKeySet(keys, maxLifetime)
+}
+
+// issuedTokens 新签发的令牌
+type issuedTokens struct {
+	result    *model.LoginResult
+	accessID  string
+	refreshID string
+	accessExp time.Time
+}
+
+// IssueTokens 登录成功后签发访问令牌和刷新令牌，并创建新的刷新令牌族作为登录会话
+func IssueTokens(userID int, username string, client model.SessionClient) (*model.LoginResult, error) {
+	familyID, err := jwt.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := signTokens(userID, username, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	now := time.Now().Unix()
+	device := useragent.Parse(client.UserAgent)
+	familyKey := fmt.Sprintf(tokenFamilyKey, familyID)
+	familiesKey := fmt.Sprintf(userTokenFamiliesKey, userID)
+	ttl := time.Duration(tokenConfig.JWTRefreshExpire) * time.Second
+	pipe := model.RDB.TxPipeline()
+	pipe.HSet(ctx, familyKey,
+		"user_id", userID,
+		"refresh_jti", tokens.refreshID,
+		"access_jti", tokens.accessID,
+		"access_exp", tokens.accessExp.Unix(),
+		"created_at", now,
+		"ip", client.IPAddress,
+		"user_agent", client.UserAgent,
+		"device", device.String(),
+		"device_type", device.DeviceType,
+		"last_seen", now,
+		"last_ip", client.IPAddress,
+	)
+	pipe.Expire(ctx, familyKey, ttl)
+	pipe.SAdd(ctx, familiesKey, familyID)
+	pipe.Expire(ctx, familiesKey, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	return tokens.result, nil
+}
+
+// RotateRefreshToken 使用刷新令牌换取新的访问令牌和刷新令牌，旧刷新令牌随即失效。
+// 已轮换过的刷新令牌再次使用时视为泄露，吊销该令牌族的全部令牌。结果记入登录日志
+func RotateRefreshToken(refreshToken string, client model.SessionClient) (*model.LoginResult, error) {
+	claims, err := jwt.ParseRefreshToken(refreshToken, tokenKeys)
+	if err != nil || claims.ID == "" || claims.FamilyID == "" {
+		recordTokenLog(nil, "", model.LoginActionRefresh, client, ErrRefreshTokenInvalid)
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	result, username, err := rotateRefreshToken(claims)
+	recordTokenLog(&claims.UserID, username, model.LoginActionRefresh, client, err)
+	return result, err
+}
+
+// rotateRefreshToken 轮换刷新令牌，返回新令牌和用户名
+func rotateRefreshToken(claims *model.RefreshClaims) (*model.LoginResult, string, error) {
+	if err := checkUserTokensRevoked(claims.UserID, claims.Version); err != nil {
+		if errors.Is(err, ErrTokenRevoked) {
+			return nil, "", ErrRefreshTokenInvalid
+		}
+		return nil, "", err
+	}
+
+	// 用户被禁用或删除后不再续期
+	var user model.User
+	if err := model.DB.Select("user_id, username, status").First(&user, claims.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrRefreshTokenInvalid
+		}
+		return nil, "", err
+	}
+	if user.Status != model.UserStatusNormal {
+		if err := revokeTokenFamily(claims.UserID, claims.FamilyID); err != nil {
+			zap.L().Warn("吊销刷新令牌族失败", zap.Int("user_id", claims.UserID), zap.Error(err))
+		}
+		return nil, user.Username, ErrRefreshTokenInvalid
+	}
+
+	tokens, err := signTokens(user.UserID, user.Username, claims.FamilyID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ctx := context.Background()
+	ttl := time.Duration(tokenConfig.JWTRefreshExpire) * time.Second
+	status, err := rotateRefreshTokenScript.Run(ctx, model.RDB,
+		[]string{fmt.Sprintf(tokenFamilyKey, claims.FamilyID)},
+		claims.ID, tokens.refreshID, tokens.accessID, tokens.accessExp.Unix(), int(ttl.Seconds()),
+	).Int()
+	if err != nil {
+		return nil, "", err
+	}
+	switch status {
+	case 0:
+		return nil, user.Username, ErrRefreshTokenInvalid
+	case -1:
+		zap.L().Warn("检测到刷新令牌重复使用，吊销该次登录的全部令牌",
+			zap.Int("user_id", claims.UserID),
+			zap.String("family_id", claims.FamilyID),
+		)
+		if err := revokeTokenFamily(claims.UserID, claims.FamilyID); err != nil {
+			return nil, "", err
+		}
+		return nil, user.Username, ErrRefreshTokenReused
+	}
+
+	model.RDB.Expire(ctx, fmt.Sprintf(userTokenFamiliesKey, user.UserID), ttl)
+	return tokens.result, user.Username, nil
+}
+
+// RevokeToken 退出登录：吊销当前访问令牌及其所属刷新令牌族
+func RevokeToken(claims *model.CustomClaims) error {
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		if err := blacklistToken(claims.ID, claims.ExpiresAt.Time); err != nil {
+			return err
+		}
+	}
+	if claims.FamilyID != "" {
+		return revokeTokenFamily(claims.UserID, claims.FamilyID)
+	}
+	return nil
+}
+
+// RevokeAllTokens 退出全部设备：吊销用户此前签发的全部访问令牌和刷新令牌
+func RevokeAllTokens(userID int) error {
+	ctx := context.Background()
+	familiesKey := fmt.Sprintf(userTokenFamiliesKey, userID)
+	familyIDs, err := model.RDB.SMembers(ctx, familiesKey).Result()
+	if err != nil {
+		return err
+	}
+
+	// 访问令牌无法逐个列出，递增用户的令牌版本，此前签发的令牌都视为失效。
+	// 版本不设过期时间，否则过期后版本从头计数，旧令牌会重新生效
+	keys := []string{familiesKey}
+	for _, familyID := range familyIDs {
+		keys = append(keys, fmt.Sprintf(tokenFamilyKey, familyID))
+	}
+	pipe := model.RDB.TxPipeline()
+	pipe.Incr(ctx, fmt.Sprintf(userTokenVersionKey, userID))
+	pipe.Del(ctx, keys...)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// CheckTokenRevoked 检查访问令牌是否已被吊销（退出登录、会话被移除或退出全部设备），
+// 已吊销时返回 ErrTokenRevoked，未吊销时更新所属会话的最近访问时间和IP
+func CheckTokenRevoked(claims *model.CustomClaims, clientIP string) error {
+	if claims.ID != "" {
+		blacklistKey := fmt.Sprintf(tokenBlacklistKey, claims.ID)
+		familyKey := blacklistKey
+		if claims.FamilyID != "" {
+			familyKey = fmt.Sprintf(tokenFamilyKey, claims.FamilyID)
+		}
+		valid, err := checkTokenScript.Run(context.Background(), model.RDB,
+			[]string{blacklistKey, familyKey},
+			time.Now().Unix(), clientIP,
+		).Int()
+		if err != nil {
+			return err
+		}
+		if valid == 0 {
+			return ErrTokenRevoked
+		}
+	}
+	return checkUserTokensRevoked(claims.UserID, claims.Version)
+}
+
+// checkUserTokensRevoked 令牌签发时的版本低于用户当前的令牌版本（此后退出过全部设备）时返回 ErrTokenRevoked
+func checkUserTokensRevoked(userID int, version int64) error {
+	current, err := userTokenVersion(userID)
+	if err != nil {
+		return err
+	}
+	if version < current {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// userTokenVersion 获取用户当前的令牌版本，从未退出全部设备时为0
+func userTokenVersion(userID int) (int64, error) {
+	version, err := model.RDB.Get(context.Background(), fmt.Sprintf(userTokenVersionKey, userID)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return version, err
+}
+
+// signTokens 签发一对新令牌
+func signTokens(userID int, username, familyID string) (*issuedTokens, error) {
+	var roleIDs []int
+	if err := model.DB.Table("sys_user_roles").
+		Where("user_id = ?", userID).
+		Pluck("role_id", &roleIDs).Error; err != nil {
+		return nil, err
+	}
+	version, err := userTokenVersion(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	accessID, err := jwt.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+	refreshID, err := jwt.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := jwt.GenerateToken(userID, username, roleIDs, accessID, familyID, version,
+		tokenKeys, tokenConfig.JWTExpire, tokenConfig.JWTIssuer)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := jwt.GenerateRefreshToken(userID, refreshID, familyID, version,
+		tokenKeys, tokenConfig.JWTRefreshExpire, tokenConfig.JWTIssuer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &issuedTokens{
+		result: &model.LoginResult{
+			Token:        accessToken,
+			RefreshToken: refreshToken,
+			ExpiresIn:    tokenConfig.JWTExpire,
+			TokenType:    "Bearer",
+		},
+		accessID:  accessID,
+		refreshID: refreshID,
+		accessExp: time.Now().Add(time.Duration(tokenConfig.JWTExpire) * time.Second),
+	}, nil
+}
+
+// revokeTokenFamily 吊销刷新令牌族，并将其最近签发的访问令牌加入黑名单
+func revokeTokenFamily(userID int, familyID string) error {
+	ctx := context.Background()
+	familyKey := fmt.Sprintf(tokenFamilyKey, familyID)
+	values, err := model.RDB.HMGet(ctx, familyKey, "access_jti", "access_exp").Result()
+	if err != nil {
+		return err
+	}
+	if accessID, ok := values[0].(string); ok && accessID != "" {
+		expValue, _ := values[1].(string)
+		exp, _ := strconv.ParseInt(expValue, 10, 64)
+		if err := blacklistToken(accessID, time.Unix(exp, 0)); err != nil {
+			return err
+		}
+	}
+
+	pipe := model.RDB.TxPipeline()
+	pipe.Del(ctx, familyKey)
+	pipe.SRem(ctx, fmt.Sprintf(userTokenFamiliesKey, userID), familyID)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// recordTokenLog 记录刷新令牌和退出登录的日志，err 不为空时记为失败
+func recordTokenLog(userID *int, username string, action int8, client model.SessionClient, err error) {
+	log := model.LoginLog{
+		UserID:      userID,
+		Username:    username,
+		LoginStatus: model.LoginStatusSuccess,
+		Action:      action,
+		IPAddress:   client.IPAddress,
+		UserAgent:   client.UserAgent,
+	}
+	if err != nil {
+		log.LoginStatus = model.LoginStatusFailed
+		log.FailReason = err.Error()
+	}
+	RecordLoginLog(log)
+}
+
+// blacklistToken 将访问令牌加入黑名单直到其过期
+func blacklistToken(tokenID string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	return model.RDB.Set(context.Background(), fmt.Sprintf(tokenBlacklistKey, tokenID), 1, ttl).Err()
+}
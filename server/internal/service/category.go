@@ -42,6 +42,8 @@ func CreateCategory(form model.CategoryCreateForm) (int, error) {
 		return 0, err
 	}
 
+	InvalidateSitemap(model.SitemapTypeCategory)
+
 	return category.CategoryID, nil
 }
 
@@ -110,6 +112,8 @@ func UpdateCategory(categoryID int, form model.CategoryUpdateForm) error {
 		return err
 	}
 
+	InvalidateSitemap(model.SitemapTypeCategory)
+
 	return nil
 }
 
@@ -211,6 +215,8 @@ func DeleteCategory(categoryID int) error {
 		return err
 	}
 
+	InvalidateSitemap(model.SitemapTypeCategory)
+
 	return nil
 }
 
@@ -283,5 +289,7 @@ func UpdateCategoryStatus(categoryID int, isEnabled bool) error {
 		return err
 	}
 
+	InvalidateSitemap(model.SitemapTypeCategory)
+
 	return nil
 }
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/mail"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// emailVerifyRequiredKey 是否禁止未验证邮箱的账号登录
+const emailVerifyRequiredKey = "email_verify_required"
+
+// emailVerifyExpire 邮箱验证链接有效期
+const emailVerifyExpire = 24 * time.Hour
+
+var (
+	// ErrEmailNotVerified 邮箱未验证，系统要求验证后才能登录
+	ErrEmailNotVerified = errors.New("邮箱尚未验证，请先点击验证邮件中的链接")
+	// ErrEmailAlreadyVerified 邮箱已验证
+	ErrEmailAlreadyVerified = errors.New("邮箱已验证")
+	// ErrEmailNotSet 未填写邮箱
+	ErrEmailNotSet = errors.New("未填写邮箱")
+)
+
+// SendVerificationEmail 向用户当前的邮箱发送验证邮件，此前发送的验证链接随即失效
+func SendVerificationEmail(userID int) error {
+	var user model.User
+	if err := model.DB.Select("user_id, username, email, email_verified_at").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if user.Email == "" {
+		return ErrEmailNotSet
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+
+	token, err := issueAccountToken(accountTokenVerifyEmail, accountToken{UserID: user.UserID, Email: user.Email}, emailVerifyExpire)
+	if err != nil {
+		return err
+	}
+	link, err := siteLink(SiteVerifyEmailPath, token)
+	if err != nil {
+		return err
+	}
+	return SendTemplateMail(user.Email, mail.TemplateVerifyEmail, mail.TemplateData{
+		Username:      user.Username,
+		Link:          link,
+		ExpireMinutes: int(emailVerifyExpire / time.Minute),
+	})
+}
+
+// sendVerificationEmailAsync 在后台发送验证邮件，用于注册或修改邮箱后，发送失败不影响主流程
+func sendVerificationEmailAsync(userID int) {
+	go func() {
+		if err := SendVerificationEmail(userID); err != nil && !errors.Is(err, ErrMailDisabled) {
+			zap.L().Warn("发送验证邮件失败", zap.Int("user_id", userID), zap.Error(err))
+		}
+	}()
+}
+
+// RequestVerificationEmail 根据邮箱重新发送验证邮件。邮箱不存在或已验证时同样返回成功，不暴露邮箱是否注册
+func RequestVerificationEmail(email string) error {
+	var user model.User
+	if err := model.DB.Select("user_id, email_verified_at").
+		Where("LOWER(email) = LOWER(?)", strings.TrimSpace(email)).
+		First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+	return SendVerificationEmail(user.UserID)
+}
+
+// VerifyEmail 使用验证邮件中的令牌完成邮箱验证。发送后修改过邮箱的令牌无效
+func VerifyEmail(token string) error {
+	info, err := consumeAccountToken(accountTokenVerifyEmail, token)
+	if err != nil {
+		return err
+	}
+
+	result := model.DB.Model(&model.User{}).
+		Where("user_id = ? AND email = ?", info.UserID, info.Email).
+		Update("email_verified_at", gorm.Expr("COALESCE(email_verified_at, ?)", time.Now()))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAccountTokenInvalid
+	}
+	return nil
+}
+
+// CheckEmailVerified 系统要求验证邮箱时，检查用户是否已验证，未验证返回 ErrEmailNotVerified
+func CheckEmailVerified(userID int) error {
+	required, err := emailVerifyRequired()
+	if err != nil || !required {
+		return err
+	}
+
+	var user model.User
+	if err := model.DB.Select("user_id, email_verified_at").First(&user, userID).Error; err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt == nil {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
+
+// emailVerifyRequired 读取是否禁止未验证邮箱的账号登录，未配置时不限制
+func emailVerifyRequired() (bool, error) {
+	var config model.SysConfig
+	if err := model.DB.Where("config_key = ?", emailVerifyRequiredKey).First(&config).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	required, _ := strconv.ParseBool(strings.TrimSpace(config.ConfigValue))
+	return required, nil
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"errors"
+	"html"
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/feed"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 订阅源参数
+const (
+	feedItemLimit     = 20  // 条目数
+	feedSummaryMaxLen = 200 // 自动生成摘要的最大长度（字符数）
+)
+
+// relativeURLPattern 匹配HTML中的站内相对地址，订阅源中需要转换为绝对地址
+var relativeURLPattern = regexp.MustCompile(`(src|href)="/([^/"][^"]*)?"`)
+
+// ErrFeedNotFound 订阅源对应的分类、标签或作者不存在
+var ErrFeedNotFound = errors.New("订阅源不存在")
+
+// BuildFeed 根据范围生成订阅源，包含最近发布的文章
+func BuildFeed(query model.FeedQuery) (*feed.Feed, error) {
+	site, err := GetSiteInfo()
+	if err != nil {
+		return nil, err
+	}
+	baseURL := site.URL
+	if baseURL == "" {
+		baseURL = strings.TrimRight(query.BaseURL, "/")
+	}
+
+	f := &feed.Feed{
+		ID:          baseURL + query.FeedPath,
+		Title:       site.Name,
+		Description: site.Description,
+		Link:        baseURL + "/",
+		FeedURL:     baseURL + query.FeedPath,
+		Language:    site.Language,
+		Copyright:   site.Copyright,
+		Icon:        SiteAbsURL(baseURL, site.Logo),
+		Updated:     site.UpdatedAt,
+	}
+
+	db := model.DB.Model(&model.Article{}).
+		Where("cms_articles.status = ? AND cms_articles.publish_time <= ?", model.ArticleStatusPublished, time.Now())
+
+	// 按范围筛选并设置订阅源标题
+	switch query.Scope {
+	case model.FeedScopeCategory:
+		var category model.Category
+		if err := model.DB.Where("category_key = ? AND is_visible = ?", query.Key, true).First(&category).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrFeedNotFound
+			}
+			return nil, err
+		}
+		f.Title = category.CategoryName + " - " + site.Name
+		f.Link = baseURL + SiteCategoryPath + url.PathEscape(category.CategoryKey)
+		if category.Description != "" {
+			f.Description = category.Description
+		}
+		db = db.Where(`EXISTS (
+			SELECT 1 FROM cms_article_categories ac
+			JOIN cms_categories cat ON cat.category_id = ac.category_id
+			WHERE ac.article_id = cms_articles.article_id AND cat.path <@ ?::ltree)`, category.Path)
+
+	case model.FeedScopeTag:
+		var tag model.Tag
+		if err := model.DB.Where("tag_key = ? AND is_visible = ?", query.Key, true).First(&tag).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrFeedNotFound
+			}
+			return nil, err
+		}
+		f.Title = tag.TagName + " - " + site.Name
+		f.Link = baseURL + SiteTagPath + url.PathEscape(tag.TagKey)
+		if tag.Description != "" {
+			f.Description = tag.Description
+		}
+		db = db.Where("EXISTS (SELECT 1 FROM cms_article_tags t WHERE t.article_id = cms_articles.article_id AND t.tag_id = ?)", tag.TagID)
+
+	case model.FeedScopeAuthor:
+		var user model.User
+		if err := model.DB.Select("user_id, username, nickname").
+			Where("username = ? AND status = ?", query.Key, 1).
+			First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrFeedNotFound
+			}
+			return nil, err
+		}
+		name := user.Nickname
+		if name == "" {
+			name = user.Username
+		}
+		f.Title = name + " - " + site.Name
+		f.Link = baseURL + SiteAuthorPath + url.PathEscape(user.Username)
+		db = db.Where("cms_articles.user_id = ?", user.UserID)
+	}
+
+	var articles []model.Article
+	if err := db.Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("user_id, username, nickname")
+	}).
+		Preload("Categories").
+		Preload("Tags").
+		Order("cms_articles.publish_time DESC, cms_articles.article_id DESC").
+		Limit(feedItemLimit).
+		Find(&articles).Error; err != nil {
+		return nil, err
+	}
+
+	enclosures := feedEnclosures(baseURL, articles)
+	host := feedHost(baseURL)
+
+	f.Items = make([]*feed.Item, 0, len(articles))
+	for _, article := range articles {
+		item := &feed.Item{
+			ID:         "tag:" + host + "," + article.PublishTime.Format("2006-01-02") + ":article:" + strconv.FormatInt(article.ArticleID, 10),
+			Title:      article.Title,
+			Link:       SiteArticleURL(baseURL, article.ArticleKey),
+			Summary:    article.Summary,
+			AuthorName: article.User.Nickname,
+			AuthorURL:  baseURL + SiteAuthorPath + url.PathEscape(article.User.Username),
+			Published:  article.PublishTime,
+			Updated:    article.UpdatedAt,
+			Enclosure:  enclosures[article.Thumbnail],
+		}
+		if item.AuthorName == "" {
+			item.AuthorName = article.User.Username
+		}
+		if item.Updated.Before(item.Published) {
+			item.Updated = item.Published
+		}
+		for _, category := range article.Categories {
+			item.Categories = append(item.Categories, category.CategoryName)
+		}
+		for _, tag := range article.Tags {
+			item.Categories = append(item.Categories, tag.TagName)
+		}
+
+		// 全文或缺少摘要时需要渲染正文
+		if query.FullContent || item.Summary == "" {
+			rendered, err := GetArticleRender(int(article.ArticleID))
+			if err != nil {
+				zap.L().Warn("订阅源渲染文章内容失败", zap.Int64("article_id", article.ArticleID), zap.Error(err))
+			} else {
+				if query.FullContent {
+					item.ContentHTML = absolutizeURLs(rendered.ContentHTML, baseURL)
+				}
+				if item.Summary == "" {
+					item.Summary = plainSummary(rendered.ContentHTML, feedSummaryMaxLen)
+				}
+			}
+		}
+
+		if item.Updated.After(f.Updated) {
+			f.Updated = item.Updated
+		}
+		f.Items = append(f.Items, item)
+	}
+
+	return f, nil
+}
+
+// feedEnclosures 根据文章封面图生成附件，从文件表中查询文件大小和类型
+func feedEnclosures(baseURL string, articles []model.Article) map[string]*feed.Enclosure {
+	enclosures := make(map[string]*feed.Enclosure)
+	var filePaths []string
+	for _, article := range articles {
+		if article.Thumbnail == "" || enclosures[article.Thumbnail] != nil {
+			continue
+		}
+		enclosures[article.Thumbnail] = &feed.Enclosure{
+			URL:  SiteAbsURL(baseURL, article.Thumbnail),
+			Type: mime.TypeByExtension(strings.ToLower(path.Ext(article.Thumbnail))),
+		}
+		if filePath := uploadedFilePath(article.Thumbnail); filePath != "" {
+			filePaths = append(filePaths, filePath)
+		}
+	}
+	if len(filePaths) == 0 {
+		return enclosures
+	}
+
+	var files []model.File
+	if err := model.DB.Select("file_path, file_size, mime_type").
+		Where("file_path IN ?", filePaths).
+		Find(&files).Error; err != nil {
+		zap.L().Warn("查询封面图文件信息失败", zap.Error(err))
+		return enclosures
+	}
+	for _, enclosure := range enclosures {
+		for _, file := range files {
+			if strings.HasSuffix(enclosure.URL, "/"+file.FilePath) {
+				enclosure.Length = file.FileSize
+				enclosure.Type = file.MimeType
+			}
+		}
+	}
+	for _, enclosure := range enclosures {
+		if enclosure.Type == "" {
+			enclosure.Type = "application/octet-stream"
+		}
+	}
+	return enclosures
+}
+
+// uploadedFilePath 从上传文件的访问地址中提取存储路径，非本站上传文件返回空
+func uploadedFilePath(fileURL string) string {
+	const prefix = "/uploads/"
+	if i := strings.Index(fileURL, prefix); i >= 0 {
+		return fileURL[i+len(prefix):]
+	}
+	return ""
+}
+
+// feedHost 获取站点域名，用于生成条目的tag URI
+func feedHost(baseURL string) string {
+	if u, err := url.Parse(baseURL); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return "localhost"
+}
+
+// absolutizeURLs 将HTML中的站内相对地址转换为绝对地址
+func absolutizeURLs(content, baseURL string) string {
+	return relativeURLPattern.ReplaceAllString(content, `$1="`+baseURL+`/$2"`)
+}
+
+// plainSummary 从HTML中提取纯文本摘要
+func plainSummary(content string, maxLen int) string {
+	text := html.UnescapeString(htmlTagPattern.ReplaceAllString(content, " "))
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	if len(runes) <= maxLen {
+		return string(runes)
+	}
+	return string(runes[:maxLen]) + "…"
+}
@@ -1,10 +1,12 @@
 package service
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/textproto"
 	"os"
 	"path/filepath"
 	"strings"
@@ -129,6 +131,39 @@ func UploadFile(file *multipart.FileHeader, userID int, isPublic bool) (*model.U
 	return result, nil
 }
 
+// UploadFileData 上传内存中的文件数据，用于XML-RPC等不是表单上传的场景
+func UploadFileData(name, mimeType string, data []byte, userID int, isPublic bool) (*model.UploadResult, error) {
+	// 构造表单文件后复用 UploadFile 的存储逻辑
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`,
+		strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(name)))
+	if mimeType != "" {
+		header.Set("Content-Type", mimeType)
+	}
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	form, err := multipart.NewReader(&buf, writer.Boundary()).ReadForm(int64(len(data)) + 1<<20)
+	if err != nil {
+		return nil, err
+	}
+	defer form.RemoveAll()
+	if len(form.File["file"]) == 0 {
+		return nil, errors.New("文件内容为空")
+	}
+	return UploadFile(form.File["file"][0], userID, isPublic)
+}
+
 // GetFileByID 根据ID获取文件
 func GetFileByID(fileID int) (*model.File, error) {
 	var file model.File
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+)
+
+// releaseLockScript 仅当锁仍由当前持有者持有时才删除，避免误删其他实例的锁
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// tryLock 尝试获取Redis分布式锁，成功时返回用于释放锁的函数
+func tryLock(ctx context.Context, key string, ttl time.Duration) (func(), bool, error) {
+	token := uuid.New().String()
+	ok, err := model.RDB.SetNX(ctx, key, token, ttl).Result()
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	release := func() {
+		// 使用独立的上下文，保证调用方上下文取消后仍能释放锁
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		releaseLockScript.Run(releaseCtx, model.RDB, []string{key}, token)
+	}
+	return release, true, nil
+}
@@ -0,0 +1,391 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/mail"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 登录防护缓存键，用户名统一转为小写
+const (
+	loginFailUserKey = "blog:login:fail:user:%s" // 用户名的登录失败记录（有序集合，分值为失败时间毫秒数），用于滑动窗口计数
+	loginFailIPKey   = "blog:login:fail:ip:%s"   // IP的登录失败记录
+	loginLockUserKey = "blog:login:lock:user:%s" // 账号临时锁定，值为锁定时间，过期自动解锁
+	loginLockIPKey   = "blog:login:lock:ip:%s"   // IP临时封禁
+	loginPendingKey  = "blog:login:pending:%s"   // 用户名已放行但尚未记录结果的登录尝试（有序集合，分值为放行时间毫秒数）
+)
+
+// 登录防护配置，保存在 sys_configs 的 security 分组
+const (
+	loginGuardConfigGroup = "security"
+
+	loginMaxFailuresKey    = "login_max_failures"    // 窗口内同一用户名允许的失败次数，达到后锁定账号
+	loginIPMaxFailuresKey  = "login_ip_max_failures" // 窗口内同一IP允许的失败次数，达到后封禁IP
+	loginFailureWindowKey  = "login_failure_window"  // 失败计数的滑动窗口（分钟）
+	loginLockoutMinutesKey = "login_lockout_minutes" // 锁定时长（分钟）
+	loginDelayAfterKey     = "login_delay_after"     // 连续失败多少次后开始要求等待，0表示不延迟
+)
+
+// 登录防护默认值，未配置或配置无效时使用
+const (
+	defaultLoginMaxFailures    = 5
+	defaultLoginIPMaxFailures  = 20
+	defaultLoginFailureWindow  = 15
+	defaultLoginLockoutMinutes = 30
+	defaultLoginDelayAfter     = 3
+	maxLoginDelay              = time.Minute      // 逐次加倍的等待时间上限
+	loginAttemptTimeout        = 10 * time.Second // 放行的登录尝试未记录结果时，超过该时间不再占用名额
+)
+
+var (
+	// ErrLoginAccountLocked 账号因连续登录失败被临时锁定
+	ErrLoginAccountLocked = errors.New("登录失败次数过多，账号已被临时锁定")
+	// ErrLoginIPLocked IP因登录失败过多被临时封禁
+	ErrLoginIPLocked = errors.New("登录失败次数过多，当前IP已被临时限制登录")
+	// ErrLoginTooFrequent 连续登录失败后需要等待一段时间才能再次尝试
+	ErrLoginTooFrequent = errors.New("登录尝试过于频繁")
+	// ErrUserNotFound 用户不存在
+	ErrUserNotFound = errors.New("用户不存在")
+	// ErrUserDisabled 账号已被禁用或未激活
+	ErrUserDisabled = errors.New("账号已被禁用或未激活")
+)
+
+// checkLoginScript 检查并预占一次登录尝试：账号或IP被锁定时返回 {1, 剩余毫秒} 或 {2, 剩余毫秒}；
+// 窗口内失败次数加上进行中的尝试达到等待条件时返回 {3, 需等待毫秒}；否则记录本次尝试并返回 {0, 0}。
+// 进行中的尝试同样计入次数和最近尝试时间，并发请求不能绕过逐次加倍的等待
+var checkLoginScript = redis.NewScript(`
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl > 0 then
+	return {1, ttl}
+end
+ttl = redis.call('PTTL', KEYS[2])
+if ttl > 0 then
+	return {2, ttl}
+end
+
+local now = tonumber(ARGV[1])
+local timeout = tonumber(ARGV[4])
+redis.call('ZREMRANGEBYSCORE', KEYS[3], '-inf', ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[4], '-inf', now - timeout)
+local failures = redis.call('ZCARD', KEYS[3])
+local pending = redis.call('ZCARD', KEYS[4])
+local count = failures + pending
+
+if pending > 0 and count >= tonumber(ARGV[5]) then
+	local oldest = redis.call('ZRANGE', KEYS[4], 0, 0, 'WITHSCORES')
+	return {3, tonumber(oldest[2]) + timeout - now}
+end
+
+local delayAfter = tonumber(ARGV[6])
+local maxDelay = tonumber(ARGV[7])
+if delayAfter > 0 and count >= delayAfter then
+	local delay = 1000
+	for i = delayAfter, count - 1 do
+		if delay >= maxDelay then
+			break
+		end
+		delay = delay * 2
+	end
+	if delay > maxDelay then
+		delay = maxDelay
+	end
+
+	local last = 0
+	local lastFailure = redis.call('ZREVRANGE', KEYS[3], 0, 0, 'WITHSCORES')
+	if lastFailure[2] then
+		last = tonumber(lastFailure[2])
+	end
+	local lastPending = redis.call('ZREVRANGE', KEYS[4], 0, 0, 'WITHSCORES')
+	if lastPending[2] and tonumber(lastPending[2]) > last then
+		last = tonumber(lastPending[2])
+	end
+	if last > 0 and last + delay > now then
+		return {3, last + delay - now}
+	end
+end
+
+redis.call('ZADD', KEYS[4], now, ARGV[3])
+redis.call('PEXPIRE', KEYS[4], timeout)
+return {0, 0}
+`)
+
+// LoginLimitError 登录受限错误，RetryAfter 为距离可以再次尝试的时间
+type LoginLimitError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+// Error 返回包含等待时间的提示，如"登录失败次数过多，账号已被临时锁定，请30分钟后再试"
+func (e *LoginLimitError) Error() string {
+	seconds := e.RetrySeconds()
+	if seconds < 60 {
+		return fmt.Sprintf("%s，请%d秒后再试", e.Err.Error(), seconds)
+	}
+	return fmt.Sprintf("%s，请%d分钟后再试", e.Err.Error(), (seconds+59)/60)
+}
+
+// Unwrap 返回具体的受限原因
+func (e *LoginLimitError) Unwrap() error {
+	return e.Err
+}
+
+// RetrySeconds 距离可以再次尝试的秒数，向上取整
+func (e *LoginLimitError) RetrySeconds() int {
+	seconds := int((e.RetryAfter + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
+
+// loginGuardSettings 登录防护设置
+type loginGuardSettings struct {
+	MaxFailures   int
+	IPMaxFailures int
+	Window        time.Duration
+	Lockout       time.Duration
+	DelayAfter    int
+}
+
+// CheckLoginAllowed 在验证密码前检查是否允许登录：账号或IP被锁定，或距上次尝试未满等待时间时返回 *LoginLimitError。
+// 允许时同时预占本次尝试，直到 RecordLoginFailure 或 ResetLoginFailures 记录结果（最长 loginAttemptTimeout）
+func CheckLoginAllowed(username, ip string) error {
+	settings, err := getLoginGuardSettings()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	name := loginGuardName(username)
+	result, err := checkLoginScript.Run(context.Background(), model.RDB,
+		[]string{
+			fmt.Sprintf(loginLockUserKey, name),
+			fmt.Sprintf(loginLockIPKey, ip),
+			fmt.Sprintf(loginFailUserKey, name),
+			fmt.Sprintf(loginPendingKey, name),
+		},
+		now.UnixMilli(),
+		now.Add(-settings.Window).UnixMilli(),
+		strconv.FormatInt(now.UnixNano(), 10),
+		loginAttemptTimeout.Milliseconds(),
+		settings.MaxFailures,
+		settings.DelayAfter,
+		maxLoginDelay.Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		return err
+	}
+
+	retryAfter := time.Duration(result[1]) * time.Millisecond
+	switch result[0] {
+	case 1:
+		return &LoginLimitError{Err: ErrLoginAccountLocked, RetryAfter: retryAfter}
+	case 2:
+		return &LoginLimitError{Err: ErrLoginIPLocked, RetryAfter: retryAfter}
+	case 3:
+		return &LoginLimitError{Err: ErrLoginTooFrequent, RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// RecordLoginFailure 记录一次登录失败（用户不存在和密码错误同等计数，避免泄露用户是否存在），并释放 CheckLoginAllowed 预占的尝试。
+// 达到失败上限时锁定账号或封禁IP并返回 *LoginLimitError，否则返回账号剩余可尝试次数
+func RecordLoginFailure(username, ip string) (int, error) {
+	settings, err := getLoginGuardSettings()
+	if err != nil {
+		return 0, err
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	name := loginGuardName(username)
+	userFailKey := fmt.Sprintf(loginFailUserKey, name)
+	ipFailKey := fmt.Sprintf(loginFailIPKey, ip)
+	pendingKey := fmt.Sprintf(loginPendingKey, name)
+	windowStart := strconv.FormatInt(now.Add(-settings.Window).UnixMilli(), 10)
+	member := &redis.Z{Score: float64(now.UnixMilli()), Member: strconv.FormatInt(now.UnixNano(), 10)}
+
+	var userFailures, ipFailures *redis.IntCmd
+	if _, err := model.RDB.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, userFailKey, member)
+		pipe.ZRemRangeByScore(ctx, userFailKey, "-inf", windowStart)
+		userFailures = pipe.ZCard(ctx, userFailKey)
+		pipe.Expire(ctx, userFailKey, settings.Window)
+		pipe.ZAdd(ctx, ipFailKey, member)
+		pipe.ZRemRangeByScore(ctx, ipFailKey, "-inf", windowStart)
+		ipFailures = pipe.ZCard(ctx, ipFailKey)
+		pipe.Expire(ctx, ipFailKey, settings.Window)
+		pipe.ZPopMin(ctx, pendingKey)
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	if count := int(ipFailures.Val()); count >= settings.IPMaxFailures {
+		if err := lockLogin(ctx, fmt.Sprintf(loginLockIPKey, ip), ipFailKey, now, settings.Lockout); err != nil {
+			return 0, err
+		}
+		zap.L().Warn("登录失败次数过多，IP已被临时限制登录",
+			zap.String("ip", ip),
+			zap.Int("failures", count),
+			zap.Duration("lockout", settings.Lockout),
+		)
+	}
+
+	count := int(userFailures.Val())
+	if count >= settings.MaxFailures {
+		if err := lockLogin(ctx, fmt.Sprintf(loginLockUserKey, name), userFailKey, now, settings.Lockout); err != nil {
+			return 0, err
+		}
+		notifyLoginLocked(username, ip, count, settings.Lockout)
+		return 0, &LoginLimitError{Err: ErrLoginAccountLocked, RetryAfter: settings.Lockout}
+	}
+	if int(ipFailures.Val()) >= settings.IPMaxFailures {
+		return 0, &LoginLimitError{Err: ErrLoginIPLocked, RetryAfter: settings.Lockout}
+	}
+	return settings.MaxFailures - count, nil
+}
+
+// ResetLoginFailures 登录成功后清除用户名的失败记录和进行中的尝试，IP的失败记录保留至窗口过期
+func ResetLoginFailures(username string) {
+	name := loginGuardName(username)
+	if err := model.RDB.Del(context.Background(),
+		fmt.Sprintf(loginFailUserKey, name),
+		fmt.Sprintf(loginPendingKey, name),
+	).Err(); err != nil {
+		zap.L().Warn("清除登录失败记录失败", zap.String("username", username), zap.Error(err))
+	}
+}
+
+// UnlockLoginUser 解除用户的登录锁定并清除失败记录
+func UnlockLoginUser(userID int) error {
+	var user model.User
+	if err := model.DB.Select("user_id", "username").Where("user_id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	name := loginGuardName(user.Username)
+	return model.RDB.Del(context.Background(),
+		fmt.Sprintf(loginLockUserKey, name),
+		fmt.Sprintf(loginFailUserKey, name),
+		fmt.Sprintf(loginPendingKey, name),
+	).Err()
+}
+
+// UnlockLoginIP 解除IP的登录限制并清除失败记录
+func UnlockLoginIP(ip string) error {
+	return model.RDB.Del(context.Background(),
+		fmt.Sprintf(loginLockIPKey, ip),
+		fmt.Sprintf(loginFailIPKey, ip),
+	).Err()
+}
+
+// lockLogin 设置锁定并清空失败记录，解锁后重新计数
+func lockLogin(ctx context.Context, lockKey, failKey string, now time.Time, lockout time.Duration) error {
+	_, err := model.RDB.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, lockKey, now.Unix(), lockout)
+		pipe.Del(ctx, failKey)
+		return nil
+	})
+	return err
+}
+
+// notifyLoginLocked 通知账号已被锁定：记录告警，写入该用户的登录记录，
+// 邮箱已验证时在后台发送锁定通知邮件
+func notifyLoginLocked(username, ip string, failures int, lockout time.Duration) {
+	zap.L().Warn("登录失败次数过多，账号已被临时锁定",
+		zap.String("username", username),
+		zap.String("ip", ip),
+		zap.Int("failures", failures),
+		zap.Duration("lockout", lockout),
+	)
+
+	var user model.User
+	if err := model.DB.Select("user_id", "username", "email", "email_verified_at").
+		Where("username = ?", username).First(&user).Error; err != nil {
+		return
+	}
+	RecordLoginLog(model.LoginLog{
+		UserID:      &user.UserID,
+		Username:    user.Username,
+		LoginStatus: model.LoginStatusFailed,
+		IPAddress:   ip,
+		FailReason:  fmt.Sprintf("连续%d次登录失败，账号已锁定%d分钟", failures, int(lockout/time.Minute)),
+	})
+
+	// 未验证的邮箱不一定属于该用户，不发送
+	if user.Email == "" || user.EmailVerifiedAt == nil {
+		return
+	}
+	go func() {
+		err := SendTemplateMail(user.Email, mail.TemplateLoginLocked, mail.TemplateData{
+			Username:    user.Username,
+			Failures:    failures,
+			LockMinutes: int(lockout / time.Minute),
+			IPAddress:   ip,
+		})
+		if err != nil && !errors.Is(err, ErrMailDisabled) {
+			zap.L().Warn("发送账号锁定通知失败", zap.Int("user_id", user.UserID), zap.Error(err))
+		}
+	}()
+}
+
+// loginGuardName 用户名转为小写后计数，避免通过大小写变化绕过限制
+func loginGuardName(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// getLoginGuardSettings 读取登录防护配置
+func getLoginGuardSettings() (loginGuardSettings, error) {
+	settings := loginGuardSettings{
+		MaxFailures:   defaultLoginMaxFailures,
+		IPMaxFailures: defaultLoginIPMaxFailures,
+		Window:        defaultLoginFailureWindow * time.Minute,
+		Lockout:       defaultLoginLockoutMinutes * time.Minute,
+		DelayAfter:    defaultLoginDelayAfter,
+	}
+
+	var configs []model.SysConfig
+	if err := model.DB.Where("config_group = ?", loginGuardConfigGroup).Find(&configs).Error; err != nil {
+		return settings, err
+	}
+	for _, config := range configs {
+		value, err := strconv.Atoi(strings.TrimSpace(config.ConfigValue))
+		if err != nil || value < 0 {
+			continue
+		}
+		switch config.ConfigKey {
+		case loginMaxFailuresKey:
+			if value > 0 {
+				settings.MaxFailures = value
+			}
+		case loginIPMaxFailuresKey:
+			if value > 0 {
+				settings.IPMaxFailures = value
+			}
+		case loginFailureWindowKey:
+			if value > 0 {
+				settings.Window = time.Duration(value) * time.Minute
+			}
+		case loginLockoutMinutesKey:
+			if value > 0 {
+				settings.Lockout = time.Duration(value) * time.Minute
+			}
+		case loginDelayAfterKey:
+			settings.DelayAfter = value
+		}
+	}
+	return settings, nil
+}
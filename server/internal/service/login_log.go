@@ -0,0 +1,262 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/useragent"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 登录日志参数
+const (
+	loginLogQueueSize      = 1024   // 待写入日志队列长度，队列满时丢弃新日志，避免暴力登录拖慢请求
+	loginLogExportLimit    = 100000 // 单次最多导出的日志条数
+	loginLogExportBatch    = 1000   // 导出时每批查询的条数
+	defaultRecentLoginSize = 10     // 最近登录记录默认条数
+	maxRecentLoginSize     = 50     // 最近登录记录最多条数
+	loginLogUnknownIP      = "0.0.0.0"
+)
+
+// ErrLoginLogIPInvalid IP筛选条件格式错误
+var ErrLoginLogIPInvalid = errors.New("无效的IP地址或网段")
+
+var (
+	loginLogQueue chan model.LoginLog
+	loginLogOnce  sync.Once
+)
+
+// loginLogHeader 导出CSV的表头
+var loginLogHeader = []string{"日志ID", "用户ID", "用户名", "操作", "登录类型", "结果", "IP地址", "设备", "设备类型", "User-Agent", "时间", "失败原因"}
+
+// RecordLoginLog 异步记录登录日志，自动识别 User-Agent 中的设备信息，不阻塞登录请求
+func RecordLoginLog(log model.LoginLog) {
+	loginLogOnce.Do(func() {
+		loginLogQueue = make(chan model.LoginLog, loginLogQueueSize)
+		go writeLoginLogs(loginLogQueue)
+	})
+
+	if ip := net.ParseIP(log.IPAddress); ip == nil {
+		log.IPAddress = loginLogUnknownIP
+	}
+	if log.LoginType == 0 {
+		log.LoginType = model.LoginTypeUsername
+	}
+	if log.Action == 0 {
+		log.Action = model.LoginActionLogin
+	}
+	if log.LoginTime.IsZero() {
+		log.LoginTime = time.Now()
+	}
+	log.Username = truncateRunes(log.Username, 30)
+	log.FailReason = truncateRunes(log.FailReason, 100)
+	device := useragent.Parse(log.UserAgent)
+	log.DeviceInfo = model.LoginDeviceInfo{
+		Device:         device.String(),
+		Browser:        device.Browser,
+		BrowserVersion: device.BrowserVersion,
+		OS:             device.OS,
+		DeviceType:     device.DeviceType,
+	}
+
+	select {
+	case loginLogQueue <- log:
+	default:
+		zap.L().Warn("登录日志队列已满，丢弃日志",
+			zap.String("username", log.Username),
+			zap.String("ip", log.IPAddress),
+		)
+	}
+}
+
+// writeLoginLogs 逐条写入队列中的登录日志
+func writeLoginLogs(queue <-chan model.LoginLog) {
+	for log := range queue {
+		if err := model.DB.Create(&log).Error; err != nil {
+			zap.L().Error("记录登录日志失败",
+				zap.String("username", log.Username),
+				zap.Int8("action", log.Action),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// ListLoginLogs 分页查询登录日志，按时间倒序排列
+func ListLoginLogs(params model.LoginLogQueryParams) (*model.PageResult, error) {
+	if err := validateLoginLogFilter(params.LoginLogFilter); err != nil {
+		return nil, err
+	}
+	query := filterLoginLogs(model.DB.Model(&model.LoginLog{}), params.LoginLogFilter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	logs := make([]model.LoginLog, 0)
+	if err := query.Order("login_time DESC, log_id DESC").
+		Offset((params.Page - 1) * params.PageSize).
+		Limit(params.PageSize).
+		Find(&logs).Error; err != nil {
+		return nil, err
+	}
+
+	return model.NewPageResult(logs, total, params.Page, params.PageSize), nil
+}
+
+// ListRecentLogins 获取用户最近的登录记录（含失败），用于查看账号是否有异常登录
+func ListRecentLogins(userID int, limit int) ([]model.LoginLog, error) {
+	if limit <= 0 {
+		limit = defaultRecentLoginSize
+	}
+	if limit > maxRecentLoginSize {
+		limit = maxRecentLoginSize
+	}
+
+	logs := make([]model.LoginLog, 0, limit)
+	if err := model.DB.Where("user_id = ? AND action = ?", userID, model.LoginActionLogin).
+		Order("login_time DESC, log_id DESC").
+		Limit(limit).
+		Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// ExportLoginLogs 将符合条件的登录日志以CSV写入 w，按时间倒序分批查询，最多导出 loginLogExportLimit 条，返回导出的条数
+func ExportLoginLogs(w io.Writer, filter model.LoginLogFilter) (int, error) {
+	if err := validateLoginLogFilter(filter); err != nil {
+		return 0, err
+	}
+	// 写入BOM，便于Excel识别UTF-8编码
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return 0, err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(loginLogHeader); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	var lastTime time.Time
+	var lastID int64
+	for count < loginLogExportLimit {
+		query := filterLoginLogs(model.DB.Model(&model.LoginLog{}), filter)
+		if lastID > 0 {
+			query = query.Where("(login_time, log_id) < (?, ?)", lastTime, lastID)
+		}
+		batch := loginLogExportBatch
+		if remaining := loginLogExportLimit - count; remaining < batch {
+			batch = remaining
+		}
+
+		var logs []model.LoginLog
+		if err := query.Order("login_time DESC, log_id DESC").Limit(batch).Find(&logs).Error; err != nil {
+			return count, err
+		}
+		for _, log := range logs {
+			if err := cw.Write(loginLogRecord(log)); err != nil {
+				return count, err
+			}
+		}
+		count += len(logs)
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return count, err
+		}
+		if len(logs) < batch {
+			break
+		}
+		lastTime, lastID = logs[len(logs)-1].LoginTime, logs[len(logs)-1].LogID
+	}
+	return count, nil
+}
+
+// validateLoginLogFilter 检查筛选条件中的IP地址或网段
+func validateLoginLogFilter(filter model.LoginLogFilter) error {
+	if filter.IPAddress == "" || net.ParseIP(filter.IPAddress) != nil {
+		return nil
+	}
+	if _, _, err := net.ParseCIDR(filter.IPAddress); err != nil {
+		return ErrLoginLogIPInvalid
+	}
+	return nil
+}
+
+// filterLoginLogs 应用登录日志筛选条件
+func filterLoginLogs(query *gorm.DB, filter model.LoginLogFilter) *gorm.DB {
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Username != "" {
+		query = query.Where("username = ?", filter.Username)
+	}
+	if filter.IPAddress != "" {
+		// 支持单个IP和网段
+		query = query.Where("ip_address <<= ?::inet", filter.IPAddress)
+	}
+	if filter.LoginType != nil {
+		query = query.Where("login_type = ?", *filter.LoginType)
+	}
+	if filter.LoginStatus != nil {
+		query = query.Where("login_status = ?", *filter.LoginStatus)
+	}
+	if filter.Action != nil {
+		query = query.Where("action = ?", *filter.Action)
+	}
+	if filter.StartTime != nil {
+		query = query.Where("login_time >= ?", *filter.StartTime)
+	}
+	if filter.EndTime != nil {
+		query = query.Where("login_time < ?", *filter.EndTime)
+	}
+	return query
+}
+
+// loginLogRecord 将登录日志转换为CSV行
+func loginLogRecord(log model.LoginLog) []string {
+	userID := ""
+	if log.UserID != nil {
+		userID = strconv.Itoa(*log.UserID)
+	}
+	action := map[int8]string{
+		model.LoginActionLogin:   "登录",
+		model.LoginActionRefresh: "刷新令牌",
+		model.LoginActionLogout:  "退出登录",
+	}[log.Action]
+	loginType := map[int8]string{
+		model.LoginTypeUsername:  "用户名密码",
+		model.LoginTypeEmail:     "邮箱",
+		model.LoginTypeMobile:    "手机号",
+		model.LoginTypeWechat:    "微信",
+		model.LoginTypePasskey:   "通行密钥",
+		model.LoginTypeMagicLink: "邮件登录链接",
+		model.LoginTypeOAuth:     "第三方账号",
+	}[log.LoginType]
+	status := "成功"
+	if log.LoginStatus != model.LoginStatusSuccess {
+		status = "失败"
+	}
+	return []string{
+		strconv.FormatInt(log.LogID, 10),
+		userID,
+		log.Username,
+		action,
+		loginType,
+		status,
+		log.IPAddress,
+		log.DeviceInfo.Device,
+		log.DeviceInfo.DeviceType,
+		log.UserAgent,
+		log.LoginTime.Format(time.RFC3339),
+		log.FailReason,
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/mail"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// magicLinkRateKey 同一邮箱申请登录链接的次数，按小时计
+const magicLinkRateKey = "blog:auth:magic:rate:%s"
+
+// 邮件登录链接参数
+const (
+	magicLinkExpire    = 15 * time.Minute // 登录链接有效期
+	magicLinkRateLimit = 5                // 同一邮箱每小时最多申请的次数
+	magicLinkRateTTL   = time.Hour
+)
+
+// ErrMagicLinkTooFrequent 同一邮箱申请登录链接过于频繁
+var ErrMagicLinkTooFrequent = errors.New("申请登录链接过于频繁，请稍后再试")
+
+// DeviceFingerprint 根据浏览器标识和前端保存的设备标识计算设备指纹，用于将登录链接绑定到申请的设备
+func DeviceFingerprint(userAgent, deviceID string) string {
+	sum := sha256.Sum256([]byte(userAgent + "\n" + deviceID))
+	return hex.EncodeToString(sum[:])
+}
+
+// RequestMagicLink 向邮箱发送一次性登录链接，binding 为设备指纹，为空表示任意设备均可打开。
+// 同一邮箱每小时最多申请5次，不论邮箱是否注册都计数；邮箱不存在或账号已禁用时同样返回成功，不暴露邮箱是否注册
+func RequestMagicLink(email, binding string) error {
+	email = strings.TrimSpace(email)
+	if err := checkMagicLinkRate(email); err != nil {
+		return err
+	}
+
+	var user model.User
+	if err := model.DB.Select("user_id, username, email, status").
+		Where("LOWER(email) = LOWER(?)", email).
+		First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.Status != model.UserStatusNormal {
+		return nil
+	}
+
+	token, err := issueAccountToken(accountTokenMagicLogin, accountToken{UserID: user.UserID, Email: user.Email, Binding: binding}, magicLinkExpire)
+	if err != nil {
+		return err
+	}
+	link, err := siteLink(SiteMagicLinkPath, token)
+	if err != nil {
+		return err
+	}
+	return SendTemplateMail(user.Email, mail.TemplateMagicLink, mail.TemplateData{
+		Username:      user.Username,
+		Link:          link,
+		ExpireMinutes: int(magicLinkExpire / time.Minute),
+	})
+}
+
+// ConsumeMagicLink 使用登录链接中的令牌登录，令牌只能使用一次。链接绑定了设备时，fingerprint 必须与申请时一致。
+// 能收到邮件说明邮箱属于该用户，未验证的邮箱同时标记为已验证；需要两步验证时只返回临时令牌。
+// 返回的用户用于记录登录日志，令牌无效时为nil
+func ConsumeMagicLink(token, fingerprint string, client model.SessionClient) (*model.LoginResult, *model.User, error) {
+	info, err := consumeAccountToken(accountTokenMagicLogin, token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var user model.User
+	if err := model.DB.First(&user, info.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrAccountTokenInvalid
+		}
+		return nil, nil, err
+	}
+	if user.Email != info.Email {
+		return nil, &user, ErrAccountTokenInvalid
+	}
+	if info.Binding != "" && !hmac.Equal([]byte(info.Binding), []byte(fingerprint)) {
+		return nil, &user, ErrAccountTokenInvalid
+	}
+	if err := CheckLoginAllowed(user.Username, client.IPAddress); err != nil {
+		var limitErr *LoginLimitError
+		if errors.As(err, &limitErr) {
+			return nil, &user, err
+		}
+		zap.L().Error("检查登录限制失败", zap.String("username", user.Username), zap.Error(err))
+	}
+	if user.Status != model.UserStatusNormal {
+		return nil, &user, ErrUserDisabled
+	}
+
+	now := time.Now()
+	if user.EmailVerifiedAt == nil {
+		if err := model.DB.Model(&user).Update("email_verified_at", now).Error; err != nil {
+			return nil, &user, err
+		}
+	}
+
+	challenge, err := BeginMFA(user.UserID, user.Username)
+	if err != nil {
+		return nil, &user, err
+	}
+	if challenge != nil {
+		return &model.LoginResult{MFA: challenge}, &user, nil
+	}
+
+	ResetLoginFailures(user.Username)
+	tokens, err := IssueTokens(user.UserID, user.Username, client)
+	if err != nil {
+		return nil, &user, err
+	}
+	if err := model.DB.Model(&user).Updates(map[string]interface{}{
+		"last_login":  now,
+		"login_count": gorm.Expr("login_count + 1"),
+	}).Error; err != nil {
+		zap.L().Warn("更新最后登录时间失败", zap.Int("user_id", user.UserID), zap.Error(err))
+	}
+	return tokens, &user, nil
+}
+
+// checkMagicLinkRate 累计邮箱的申请次数，超过每小时上限时返回 ErrMagicLinkTooFrequent
+func checkMagicLinkRate(email string) error {
+	ctx := context.Background()
+	key := fmt.Sprintf(magicLinkRateKey, strings.ToLower(email))
+	count, err := model.RDB.Incr(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if count == 1 {
+		model.RDB.Expire(ctx, key, magicLinkRateTTL)
+	}
+	if count > magicLinkRateLimit {
+		return ErrMagicLinkTooFrequent
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/mail"
+)
+
+// mailConfigGroup 邮件服务配置所在的分组
+const mailConfigGroup = "email"
+
+var (
+	// ErrMailDisabled 未启用邮件服务
+	ErrMailDisabled = errors.New("邮件服务未启用")
+	// ErrSiteURLNotConfigured 未配置网站地址，无法生成邮件中的链接
+	ErrSiteURLNotConfigured = errors.New("未配置网站地址，无法生成邮件链接")
+)
+
+// SendTemplateMail 使用系统配置的SMTP服务发送模板邮件，站点名称和地址自动填入模板数据
+func SendTemplateMail(to, templateName string, data mail.TemplateData) error {
+	cfg, err := getMailConfig()
+	if err != nil {
+		return err
+	}
+	site, err := GetSiteInfo()
+	if err != nil {
+		return err
+	}
+	data.SiteName = site.Name
+	data.SiteURL = site.URL
+
+	subject, body, err := mail.Render(templateName, data)
+	if err != nil {
+		return err
+	}
+	if cfg.FromName == "" {
+		cfg.FromName = site.Name
+	}
+	return mail.Send(cfg, mail.Message{To: to, Subject: subject, HTML: body})
+}
+
+// siteLink 生成前台页面的绝对地址。邮件中的链接只使用系统配置的网站地址，
+// 不使用请求地址，防止伪造 Host 请求头让用户收到指向其他站点的链接
+func siteLink(path, token string) (string, error) {
+	site, err := GetSiteInfo()
+	if err != nil {
+		return "", err
+	}
+	if site.URL == "" {
+		return "", ErrSiteURLNotConfigured
+	}
+	return site.URL + path + "?token=" + token, nil
+}
+
+// getMailConfig 读取邮件服务配置，未启用时返回 ErrMailDisabled
+func getMailConfig() (mail.Config, error) {
+	var cfg mail.Config
+	var configs []model.SysConfig
+	if err := model.DB.Where("config_group = ?", mailConfigGroup).Find(&configs).Error; err != nil {
+		return cfg, err
+	}
+
+	enabled := false
+	for _, config := range configs {
+		value := strings.TrimSpace(config.ConfigValue)
+		switch config.ConfigKey {
+		case "email_enabled":
+			enabled, _ = strconv.ParseBool(value)
+		case "email_host":
+			cfg.Host = value
+		case "email_port":
+			cfg.Port, _ = strconv.Atoi(value)
+		case "email_username":
+			cfg.Username = value
+		case "email_password":
+			cfg.Password = config.ConfigValue
+		case "email_from":
+			cfg.From = value
+		case "email_from_name":
+			cfg.FromName = value
+		}
+	}
+	if !enabled {
+		return cfg, ErrMailDisabled
+	}
+	return cfg, nil
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"errors"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/render"
+	"gorm.io/gorm"
+)
+
+// MetaWeblog接口参数
+const (
+	// PermArticleEdit 修改他人文章的权限标识
+	PermArticleEdit = "content:article:edit"
+	// metaWeblogMaxRecentPosts 最近文章最多返回数量
+	metaWeblogMaxRecentPosts = 100
+)
+
+// metaWeblogMediaTypes 允许通过MetaWeblog上传的文件类型
+var metaWeblogMediaTypes = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".bmp": true, ".webp": true, ".pdf": true,
+}
+
+// ErrMediaTypeNotAllowed 不支持的文件类型
+var ErrMediaTypeNotAllowed = errors.New("不支持的文件类型，允许的类型: jpg, jpeg, png, gif, bmp, webp, pdf")
+
+// MetaWeblogNewPost 通过MetaWeblog接口创建文章，publish为真时发布，没有发布权限的用户提交审核
+func MetaWeblogNewPost(userID int, post model.MetaWeblogPost, publish bool) (int, error) {
+	status := model.ArticleStatusDraft
+	if publish {
+		var err error
+		if status, err = articlePublishStatus(userID, nil); err != nil {
+			return 0, err
+		}
+	}
+
+	categoryIDs, err := categoryIDsByName(post.Categories)
+	if err != nil {
+		return 0, err
+	}
+	tagIDs, err := ensureTags(splitTagNames(post.Keywords))
+	if err != nil {
+		return 0, err
+	}
+	articleKey, err := uniqueArticleKey(post.Slug, post.Title, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	allowComment := true
+	if post.AllowComments != nil {
+		allowComment = *post.AllowComments
+	}
+
+	return CreateArticle(model.ArticleCreateForm{
+		Title:         post.Title,
+		ArticleKey:    articleKey,
+		Content:       post.Description,
+		ContentFormat: render.FormatHTML,
+		Summary:       post.Excerpt,
+		Status:        status,
+		ArticleType:   1,
+		CategoryIDs:   categoryIDs,
+		TagIDs:        tagIDs,
+		AllowComment:  allowComment,
+	}, userID)
+}
+
+// MetaWeblogEditPost 通过MetaWeblog接口修改文章，publish为真且文章未发布时发布或提交审核，为假时保持原状态
+func MetaWeblogEditPost(userID int, articleID int, post model.MetaWeblogPost, publish bool) error {
+	article, err := metaWeblogArticle(userID, articleID)
+	if err != nil {
+		return err
+	}
+	if err := CheckArticleEditable(articleID, userID, nil); err != nil {
+		return err
+	}
+
+	form := model.ArticleUpdateForm{
+		Title:         post.Title,
+		Content:       post.Description,
+		ContentFormat: render.FormatHTML,
+		Summary:       post.Excerpt,
+		AllowComment:  article.AllowComment,
+	}
+	if post.AllowComments != nil {
+		form.AllowComment = *post.AllowComments
+	}
+	if post.Slug != "" {
+		if form.ArticleKey, err = uniqueArticleKey(post.Slug, post.Title, article.ArticleID); err != nil {
+			return err
+		}
+	}
+	if len(post.Categories) > 0 {
+		if form.CategoryIDs, err = categoryIDsByName(post.Categories); err != nil {
+			return err
+		}
+	}
+	if post.Keywords != "" {
+		if form.TagIDs, err = ensureTags(splitTagNames(post.Keywords)); err != nil {
+			return err
+		}
+	}
+	if err := UpdateArticle(articleID, form, userID); err != nil {
+		return err
+	}
+
+	if !publish {
+		return nil
+	}
+	status, err := articlePublishStatus(userID, nil)
+	if err != nil {
+		return err
+	}
+	if article.Status == status || article.Status == model.ArticleStatusPublished {
+		return nil
+	}
+	return ChangeArticleStatus(articleID, status, userID, nil)
+}
+
+// MetaWeblogGetPost 获取文章，正文统一以HTML返回
+func MetaWeblogGetPost(userID int, articleID int, baseURL string) (*model.MetaWeblogPost, error) {
+	if _, err := metaWeblogArticle(userID, articleID); err != nil {
+		return nil, err
+	}
+	posts, err := metaWeblogPosts([]int64{int64(articleID)}, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if len(posts) == 0 {
+		return nil, ErrArticleNotFound
+	}
+	return &posts[0], nil
+}
+
+// MetaWeblogRecentPosts 获取用户最近的文章
+func MetaWeblogRecentPosts(userID int, limit int, baseURL string) ([]model.MetaWeblogPost, error) {
+	if limit <= 0 || limit > metaWeblogMaxRecentPosts {
+		limit = metaWeblogMaxRecentPosts
+	}
+	result, err := ListArticles(model.ArticleQueryParams{
+		UserID:   userID,
+		Page:     1,
+		PageSize: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	articles, _ := result.List.([]model.ArticleResponse)
+	articleIDs := make([]int64, 0, len(articles))
+	for _, article := range articles {
+		articleIDs = append(articleIDs, article.ArticleID)
+	}
+	return metaWeblogPosts(articleIDs, baseURL)
+}
+
+// MetaWeblogDeletePost 删除文章
+func MetaWeblogDeletePost(userID int, articleID int) error {
+	if _, err := metaWeblogArticle(userID, articleID); err != nil {
+		return err
+	}
+	return DeleteArticle(articleID, userID)
+}
+
+// MetaWeblogCategories 获取可见分类
+func MetaWeblogCategories(baseURL string) ([]model.MetaWeblogCategory, error) {
+	var categories []model.Category
+	if err := model.DB.Where("is_visible = ?", true).
+		Order("path ASC, sort_order ASC").
+		Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]model.MetaWeblogCategory, 0, len(categories))
+	for _, category := range categories {
+		link := baseURL + SiteCategoryPath + url.PathEscape(category.CategoryKey)
+		result = append(result, model.MetaWeblogCategory{
+			CategoryID: category.CategoryID,
+			Title:      category.CategoryName,
+			HTMLURL:    link,
+			RSSURL:     link + "/feed.xml",
+		})
+	}
+	return result, nil
+}
+
+// MetaWeblogNewMediaObject 上传写作客户端中插入的图片等文件
+func MetaWeblogNewMediaObject(userID int, name, mimeType string, data []byte) (*model.UploadResult, error) {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	if !metaWeblogMediaTypes[strings.ToLower(path.Ext(name))] {
+		return nil, ErrMediaTypeNotAllowed
+	}
+	return UploadFileData(name, mimeType, data, userID, true)
+}
+
+// metaWeblogArticle 获取文章并检查权限，作者本人或拥有修改权限的用户可以操作
+func metaWeblogArticle(userID int, articleID int) (*model.Article, error) {
+	var article model.Article
+	if err := model.DB.First(&article, articleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrArticleNotFound
+		}
+		return nil, err
+	}
+	if article.UserID == userID {
+		return &article, nil
+	}
+
+	canEdit, err := HasPermission(userID, PermArticleEdit)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		return nil, ErrArticleForbidden
+	}
+	return &article, nil
+}
+
+// metaWeblogPosts 批量加载文章及其当前内容，按传入顺序返回
+func metaWeblogPosts(articleIDs []int64, baseURL string) ([]model.MetaWeblogPost, error) {
+	posts := make([]model.MetaWeblogPost, 0, len(articleIDs))
+	if len(articleIDs) == 0 {
+		return posts, nil
+	}
+
+	var articles []model.Article
+	if err := model.DB.Preload("Categories").
+		Preload("Tags").
+		Preload("Content", "is_current = ?", true).
+		Where("article_id IN ?", articleIDs).
+		Find(&articles).Error; err != nil {
+		return nil, err
+	}
+	articleMap := make(map[int64]model.Article, len(articles))
+	for _, article := range articles {
+		articleMap[article.ArticleID] = article
+	}
+
+	for _, articleID := range articleIDs {
+		article, ok := articleMap[articleID]
+		if !ok {
+			continue
+		}
+
+		// Markdown内容转换为HTML，写作客户端只支持HTML
+		description := article.Content.Content
+		if article.Content.ContentFormat != render.FormatHTML {
+			description = render.Render(description, article.Content.ContentFormat).HTML
+		}
+
+		allowComments := article.AllowComment
+		post := model.MetaWeblogPost{
+			ArticleID:     article.ArticleID,
+			UserID:        article.UserID,
+			Title:         article.Title,
+			Description:   description,
+			Excerpt:       article.Summary,
+			Slug:          article.ArticleKey,
+			AllowComments: &allowComments,
+			Status:        article.Status,
+			DateCreated:   article.PublishTime,
+			Link:          SiteArticleURL(baseURL, article.ArticleKey),
+		}
+		if post.DateCreated.IsZero() {
+			post.DateCreated = article.CreatedAt
+		}
+		for _, category := range article.Categories {
+			post.Categories = append(post.Categories, category.CategoryName)
+		}
+		tagNames := make([]string, 0, len(article.Tags))
+		for _, tag := range article.Tags {
+			tagNames = append(tagNames, tag.TagName)
+		}
+		post.Keywords = strings.Join(tagNames, ",")
+		posts = append(posts, post)
+	}
+	return posts, nil
+}
@@ -0,0 +1,573 @@
+package service
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/jwt"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/totp"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 两步验证缓存键
+const (
+	mfaChallengeKey = "blog:auth:mfa:challenge:%s" // 临时令牌剩余可输错次数，完成验证后删除，保证临时令牌只能使用一次
+	mfaEnrollKey    = "blog:auth:mfa:enroll:%d"    // 等待确认的验证器密钥（已加密）
+	totpUsedKey     = "blog:auth:mfa:totp:%d:%d"   // 已使用过的动态口令时间步，防止同一口令被重放
+)
+
+// 两步验证参数
+const (
+	mfaTokenExpire     = 300 // 临时令牌有效期（秒）
+	mfaMaxAttempts     = 5   // 每个临时令牌允许输错的次数，用完需重新输入密码
+	totpEnrollExpire   = 600 // 绑定验证器时密钥的有效期（秒）
+	totpSkew           = 1   // 允许前后1个时间步的时钟误差
+	recoveryCodeCount  = 10  // 每次生成的恢复码数量
+	recoveryCodeLength = 10  // 恢复码长度（不含分隔符）
+)
+
+var (
+	// ErrMFATokenInvalid 临时令牌无效、过期、已使用或输错次数过多
+	ErrMFATokenInvalid = errors.New("验证已失效，请重新登录")
+	// ErrMFACodeInvalid 验证码或恢复码错误
+	ErrMFACodeInvalid = errors.New("验证码错误")
+	// ErrMFAAlreadyEnabled 已绑定验证器
+	ErrMFAAlreadyEnabled = errors.New("已启用两步验证")
+	// ErrMFANotEnabled 未绑定验证器
+	ErrMFANotEnabled = errors.New("未启用两步验证")
+	// ErrMFAEnrollExpired 绑定用的密钥已过期
+	ErrMFAEnrollExpired = errors.New("绑定已超时，请重新获取密钥")
+	// ErrMFARequired 所属角色要求启用两步验证
+	ErrMFARequired = errors.New("所属角色要求启用两步验证，不能关闭")
+)
+
+// BeginMFA 密码验证通过后检查是否需要两步验证：已绑定验证器时要求输入验证码，
+// 所属角色要求两步验证但尚未绑定时要求先绑定。不需要时返回nil，可以直接签发令牌
+func BeginMFA(userID int, username string) (*model.MFAChallenge, error) {
+	enabled, err := totpEnabled(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	purpose := model.MFAPurposeVerify
+	methods := []string{model.MFAMethodTOTP, model.MFAMethodRecovery}
+	if !enabled {
+		required, err := userRequiresMFA(userID)
+		if err != nil {
+			return nil, err
+		}
+		if !required {
+			return nil, nil
+		}
+		purpose = model.MFAPurposeEnroll
+		methods = []string{model.MFAMethodTOTP}
+	}
+
+	tokenID, err := jwt.NewTokenID()
+	if err != nil {
+		return nil, err
+	}
+	token, err := jwt.GenerateMFAToken(userID, username, purpose, tokenID,
+		tokenKeys, mfaTokenExpire, tokenConfig.JWTIssuer)
+	if err != nil {
+		return nil, err
+	}
+	if err := model.RDB.Set(context.Background(), fmt.Sprintf(mfaChallengeKey, tokenID),
+		mfaMaxAttempts, mfaTokenExpire*time.Second).Err(); err != nil {
+		return nil, err
+	}
+
+	return &model.MFAChallenge{
+		MFAToken:  token,
+		Purpose:   purpose,
+		Methods:   methods,
+		ExpiresIn: mfaTokenExpire,
+	}, nil
+}
+
+// CompleteMFALogin 登录第二步：校验验证器动态口令或恢复码，通过后签发令牌。
+// 输错计入登录失败次数，返回的声明用于记录登录日志
+func CompleteMFALogin(mfaToken, code string, client model.SessionClient) (*model.LoginResult, *model.MFAClaims, error) {
+	claims, err := parseMFAChallenge(mfaToken, model.MFAPurposeVerify)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := CheckLoginAllowed(claims.Username, client.IPAddress); err != nil {
+		var limitErr *LoginLimitError
+		if errors.As(err, &limitErr) {
+			return nil, claims, err
+		}
+		zap.L().Error("检查登录限制失败", zap.String("username", claims.Username), zap.Error(err))
+	}
+
+	if err := verifySecondFactor(claims.UserID, code); err != nil {
+		if errors.Is(err, ErrMFACodeInvalid) {
+			failMFAChallenge(claims)
+			return nil, claims, loginFailed(claims.Username, client.IPAddress, err)
+		}
+		return nil, claims, err
+	}
+	if err := consumeMFAChallenge(claims); err != nil {
+		return nil, claims, err
+	}
+
+	ResetLoginFailures(claims.Username)
+	tokens, err := IssueTokens(claims.UserID, claims.Username, client)
+	return tokens, claims, err
+}
+
+// StartMFAEnrollment 登录时角色要求两步验证但尚未绑定，使用临时令牌获取绑定用的密钥
+func StartMFAEnrollment(mfaToken string) (*model.TOTPEnrollment, error) {
+	claims, err := parseMFAChallenge(mfaToken, model.MFAPurposeEnroll)
+	if err != nil {
+		return nil, err
+	}
+	return StartTOTPEnrollment(claims.UserID, claims.Username)
+}
+
+// CompleteMFAEnrollment 登录时完成验证器绑定，返回恢复码并签发令牌
+func CompleteMFAEnrollment(mfaToken, code string, client model.SessionClient) (*model.MFAEnrollResult, *model.MFAClaims, error) {
+	claims, err := parseMFAChallenge(mfaToken, model.MFAPurposeEnroll)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	codes, err := ConfirmTOTPEnrollment(claims.UserID, code)
+	if err != nil {
+		if errors.Is(err, ErrMFACodeInvalid) {
+			failMFAChallenge(claims)
+		}
+		return nil, claims, err
+	}
+	if err := consumeMFAChallenge(claims); err != nil {
+		return nil, claims, err
+	}
+
+	ResetLoginFailures(claims.Username)
+	tokens, err := IssueTokens(claims.UserID, claims.Username, client)
+	if err != nil {
+		return nil, claims, err
+	}
+	return &model.MFAEnrollResult{RecoveryCodes: codes, Tokens: tokens}, claims, nil
+}
+
+// StartTOTPEnrollment 生成新的验证器密钥，需要在有效期内输入验证码确认后才会启用
+func StartTOTPEnrollment(userID int, username string) (*model.TOTPEnrollment, error) {
+	enabled, err := totpEnabled(userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := encryptMFASecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := model.RDB.Set(context.Background(), fmt.Sprintf(mfaEnrollKey, userID),
+		encrypted, totpEnrollExpire*time.Second).Err(); err != nil {
+		return nil, err
+	}
+
+	return &model.TOTPEnrollment{
+		Secret:    secret,
+		URI:       totp.URI(mfaIssuer(), username, secret),
+		ExpiresIn: totpEnrollExpire,
+	}, nil
+}
+
+// ConfirmTOTPEnrollment 校验验证器生成的第一个动态口令，通过后启用两步验证并生成恢复码，恢复码明文只返回这一次
+func ConfirmTOTPEnrollment(userID int, code string) ([]string, error) {
+	ctx := context.Background()
+	enrollKey := fmt.Sprintf(mfaEnrollKey, userID)
+	encrypted, err := model.RDB.Get(ctx, enrollKey).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMFAEnrollExpired
+	}
+	if err != nil {
+		return nil, err
+	}
+	secret, err := decryptMFASecret(encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now(), totpSkew)
+	if !ok {
+		return nil, ErrMFACodeInvalid
+	}
+	if _, err := markTOTPUsed(userID, step); err != nil {
+		zap.L().Warn("记录已使用的动态口令失败", zap.Int("user_id", userID), zap.Error(err))
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := model.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.UserTOTP{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrMFAAlreadyEnabled
+		}
+		if err := tx.Create(&model.UserTOTP{UserID: userID, Secret: encrypted, EnabledAt: now, LastUsedAt: &now}).Error; err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(tx, userID, hashes)
+	}); err != nil {
+		return nil, err
+	}
+
+	model.RDB.Del(ctx, enrollKey)
+	return codes, nil
+}
+
+// GetMFAStatus 获取用户的两步验证状态
+func GetMFAStatus(userID int) (*model.MFAStatus, error) {
+	status := &model.MFAStatus{}
+	var record model.UserTOTP
+	if err := model.DB.Where("user_id = ?", userID).First(&record).Error; err == nil {
+		status.TOTPEnabled = true
+		status.EnabledAt = &record.EnabledAt
+		status.LastUsedAt = record.LastUsedAt
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := model.DB.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&status.RecoveryCodesLeft).Error; err != nil {
+		return nil, err
+	}
+
+	required, err := userRequiresMFA(userID)
+	if err != nil {
+		return nil, err
+	}
+	status.Required = required
+	return status, nil
+}
+
+// DisableTOTP 用户验证后关闭两步验证，所属角色要求两步验证时不能关闭
+func DisableTOTP(userID int, code string) error {
+	required, err := userRequiresMFA(userID)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrMFARequired
+	}
+	if err := verifySecondFactor(userID, code); err != nil {
+		return err
+	}
+	return deleteMFA(userID)
+}
+
+// RegenerateRecoveryCodes 使用验证器动态口令验证后重新生成恢复码，旧恢复码全部作废
+func RegenerateRecoveryCodes(userID int, code string) ([]string, error) {
+	if err := verifyTOTP(userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := model.DB.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, hashes)
+	}); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// ResetMFA 管理员重置用户的两步验证，用于用户丢失验证器且恢复码用完的情况。
+// 角色要求两步验证的用户下次登录时需要重新绑定
+func ResetMFA(userID int) error {
+	var count int64
+	if err := model.DB.Model(&model.User{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrUserNotFound
+	}
+	return deleteMFA(userID)
+}
+
+// parseMFAChallenge 解析临时令牌，校验用途并确认令牌未被使用
+func parseMFAChallenge(mfaToken, purpose string) (*model.MFAClaims, error) {
+	claims, err := jwt.ParseMFAToken(mfaToken, tokenKeys)
+	if err != nil || claims.ID == "" || claims.Purpose != purpose {
+		return nil, ErrMFATokenInvalid
+	}
+
+	exists, err := model.RDB.Exists(context.Background(), fmt.Sprintf(mfaChallengeKey, claims.ID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		return nil, ErrMFATokenInvalid
+	}
+	return claims, nil
+}
+
+// failMFAChallenge 扣减临时令牌的剩余尝试次数，用完后令牌失效
+func failMFAChallenge(claims *model.MFAClaims) {
+	ctx := context.Background()
+	key := fmt.Sprintf(mfaChallengeKey, claims.ID)
+	left, err := model.RDB.Decr(ctx, key).Result()
+	if err != nil {
+		zap.L().Warn("更新两步验证尝试次数失败", zap.Int("user_id", claims.UserID), zap.Error(err))
+		return
+	}
+	if left <= 0 {
+		model.RDB.Del(ctx, key)
+	}
+}
+
+// consumeMFAChallenge 验证通过后删除临时令牌，并发提交时只有一个请求能成功
+func consumeMFAChallenge(claims *model.MFAClaims) error {
+	deleted, err := model.RDB.Del(context.Background(), fmt.Sprintf(mfaChallengeKey, claims.ID)).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrMFATokenInvalid
+	}
+	return nil
+}
+
+// verifySecondFactor 校验6位动态口令或恢复码
+func verifySecondFactor(userID int, code string) error {
+	code = strings.TrimSpace(code)
+	if isTOTPCode(code) {
+		return verifyTOTP(userID, code)
+	}
+	return useRecoveryCode(userID, code)
+}
+
+// verifyTOTP 校验验证器动态口令，同一口令只能使用一次
+func verifyTOTP(userID int, code string) error {
+	var record model.UserTOTP
+	if err := model.DB.Where("user_id = ?", userID).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMFANotEnabled
+		}
+		return err
+	}
+	secret, err := decryptMFASecret(record.Secret)
+	if err != nil {
+		return err
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now(), totpSkew)
+	if !ok {
+		return ErrMFACodeInvalid
+	}
+	fresh, err := markTOTPUsed(userID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return ErrMFACodeInvalid
+	}
+
+	if err := model.DB.Model(&record).Update("last_used_at", time.Now()).Error; err != nil {
+		zap.L().Warn("更新验证器使用时间失败", zap.Int("user_id", userID), zap.Error(err))
+	}
+	return nil
+}
+
+// useRecoveryCode 使用一个恢复码，使用后立即作废
+func useRecoveryCode(userID int, code string) error {
+	result := model.DB.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashRecoveryCode(code)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMFACodeInvalid
+	}
+
+	zap.L().Info("使用恢复码完成两步验证", zap.Int("user_id", userID))
+	return nil
+}
+
+// markTOTPUsed 记录动态口令的时间步，该时间步此前已使用过时返回false
+func markTOTPUsed(userID int, step int64) (bool, error) {
+	ttl := time.Duration((2*totpSkew+1)*totp.Period) * time.Second
+	return model.RDB.SetNX(context.Background(), fmt.Sprintf(totpUsedKey, userID, step), 1, ttl).Result()
+}
+
+// isTOTPCode 是否为6位数字动态口令，恢复码长度不同
+func isTOTPCode(code string) bool {
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// generateRecoveryCodes 生成一组恢复码，返回明文和哈希
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	max := big.NewInt(int64(len(appPasswordAlphabet)))
+	for i := 0; i < recoveryCodeCount; i++ {
+		var b strings.Builder
+		for j := 0; j < recoveryCodeLength; j++ {
+			if j == recoveryCodeLength/2 {
+				b.WriteByte('-')
+			}
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return nil, nil, err
+			}
+			b.WriteByte(appPasswordAlphabet[n.Int64()])
+		}
+		codes = append(codes, b.String())
+		hashes = append(hashes, hashRecoveryCode(b.String()))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode 计算恢复码的哈希，忽略大小写、空格和分隔符。
+// 恢复码为随机生成且只能使用一次，使用SHA-256即可，便于直接按哈希查询
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// replaceRecoveryCodes 删除用户的全部恢复码并保存新的恢复码
+func replaceRecoveryCodes(tx *gorm.DB, userID int, hashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	records := make([]model.RecoveryCode, 0, len(hashes))
+	for _, hash := range hashes {
+		records = append(records, model.RecoveryCode{UserID: userID, CodeHash: hash})
+	}
+	return tx.Create(&records).Error
+}
+
+// deleteMFA 删除用户的验证器和恢复码
+func deleteMFA(userID int) error {
+	if err := model.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.UserTOTP{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error
+	}); err != nil {
+		return err
+	}
+	model.RDB.Del(context.Background(), fmt.Sprintf(mfaEnrollKey, userID))
+	return nil
+}
+
+// totpEnabled 用户是否已绑定验证器
+func totpEnabled(userID int) (bool, error) {
+	var count int64
+	if err := model.DB.Model(&model.UserTOTP{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// userRequiresMFA 用户是否拥有要求两步验证的已启用角色
+func userRequiresMFA(userID int) (bool, error) {
+	var count int64
+	if err := model.DB.Table("sys_user_roles ur").
+		Joins("JOIN sys_roles r ON r.role_id = ur.role_id").
+		Where("ur.user_id = ? AND r.is_enabled = ? AND r.require_mfa = ?", userID, true, true).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// mfaIssuer 验证器应用中显示的发行方，使用网站名称
+func mfaIssuer() string {
+	if site, err := GetSiteInfo(); err == nil && site.Name != "" {
+		return site.Name
+	}
+	if tokenConfig.JWTIssuer != "" {
+		return tokenConfig.JWTIssuer
+	}
+	return "Blog"
+}
+
+// mfaCipher 创建加密验证器密钥的AES-GCM，密钥由 mfa_secret_key 派生，未配置时使用 jwt_secret。
+// 修改后已绑定的验证器将无法解密，需要管理员重置
+func mfaCipher() (cipher.AEAD, error) {
+	if tokenConfig.MFASecretKey == "" {
+		return nil, ErrTokenSecretMissing
+	}
+	sum := sha256.Sum256([]byte("mfa:" + tokenConfig.MFASecretKey))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptMFASecret 加密验证器密钥，结果为Base64编码的随机数和密文
+func encryptMFASecret(secret string) (string, error) {
+	aead, err := mfaCipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(secret), nil)), nil
+}
+
+// decryptMFASecret 解密验证器密钥
+func decryptMFASecret(encrypted string) (string, error) {
+	aead, err := mfaCipher()
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < aead.NonceSize() {
+		return "", errors.New("无效的验证器密钥")
+	}
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("验证器密钥解密失败")
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,642 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/config"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/jwt"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/oauth"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// oauthStateKey 发起第三方登录时保存的 PKCE 校验码和 nonce，回调时取出并删除
+const oauthStateKey = "blog:auth:oauth:state:%s"
+
+// 第三方登录参数
+const (
+	oauthStateExpire     = 10 * time.Minute      // 需要在此时间内完成第三方授权
+	oauthExchangeTimeout = 15 * time.Second      // 请求第三方服务的总超时时间
+	oauthAutoRegisterKey = "oauth_auto_register" // 第三方账号未关联时是否自动注册
+)
+
+var (
+	// ErrOAuthProviderNotFound 未配置该第三方登录服务
+	ErrOAuthProviderNotFound = errors.New("不支持该第三方登录方式")
+	// ErrOAuthStateInvalid state 不存在、已使用或与服务不匹配
+	ErrOAuthStateInvalid = errors.New("第三方登录已超时或请求无效，请重新登录")
+	// ErrOAuthFailed 第三方服务授权或账号信息校验失败
+	ErrOAuthFailed = errors.New("第三方账号验证失败，请重新登录")
+	// ErrOAuthAccountLinked 第三方账号已关联其他用户
+	ErrOAuthAccountLinked = errors.New("该第三方账号已关联其他用户")
+	// ErrOAuthProviderLinked 用户已关联同一服务的其他账号
+	ErrOAuthProviderLinked = errors.New("已关联该平台的其他账号，请先解除关联")
+	// ErrOAuthAccountNotLinked 第三方账号未关联用户，且系统未开启自动注册
+	ErrOAuthAccountNotLinked = errors.New("该第三方账号未关联本站用户，请先使用其他方式登录后在账号设置中关联")
+	// ErrOAuthEmailConflict 第三方账号的邮箱已被本站未验证邮箱的用户使用，不能自动关联
+	ErrOAuthEmailConflict = errors.New("该第三方账号的邮箱已注册，请先使用其他方式登录后在账号设置中关联")
+	// ErrOAuthNotLinked 用户未关联该第三方服务的账号
+	ErrOAuthNotLinked = errors.New("未关联该第三方账号")
+	// ErrOAuthLastLoginMethod 解除关联后用户将无法登录
+	ErrOAuthLastLoginMethod = errors.New("这是当前唯一的登录方式，请先设置密码或关联其他账号后再解除")
+)
+
+var (
+	// oauthProviderNamePattern 第三方登录服务标识的格式
+	oauthProviderNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,30}$`)
+	// oauthUsernamePattern 生成用户名时去掉的字符
+	oauthUsernamePattern = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+)
+
+// oauthProvider 配置的第三方登录服务
+type oauthProvider struct {
+	model.OAuthProvider
+	client oauth.Provider
+}
+
+// 已配置的第三方登录服务，启动时初始化后只读
+var (
+	oauthProviders     = map[string]*oauthProvider{}
+	oauthProviderNames []string // 按配置顺序排列，用于展示登录按钮
+)
+
+// oauthState 发起授权时保存的状态
+type oauthState struct {
+	Provider string `json:"provider"`
+	UserID   int    `json:"user_id"` // 关联账号时为当前用户ID，登录时为0
+	Verifier string `json:"verifier"`
+	Nonce    string `json:"nonce"`
+}
+
+// InitOAuth 根据配置创建第三方登录服务
+func InitOAuth(cfg config.OAuthConfig) error {
+	providers := make(map[string]*oauthProvider, len(cfg.Providers))
+	names := make([]string, 0, len(cfg.Providers))
+	wechatConfigured := false
+	for _, item := range cfg.Providers {
+		name := strings.TrimSpace(item.Name)
+		if !oauthProviderNamePattern.MatchString(name) {
+			return fmt.Errorf("第三方登录服务标识 %q 无效，只能包含字母、数字、下划线和短横线", item.Name)
+		}
+		if _, exists := providers[name]; exists {
+			return fmt.Errorf("第三方登录服务标识 %q 重复", name)
+		}
+		if item.Type == oauth.TypeWechat {
+			if wechatConfigured {
+				return errors.New("只能配置一个微信登录服务")
+			}
+			wechatConfigured = true
+		}
+
+		client, err := oauth.New(oauth.Config{
+			Type:         item.Type,
+			ClientID:     item.ClientID,
+			ClientSecret: item.ClientSecret,
+			Issuer:       item.Issuer,
+			Scopes:       item.Scopes,
+			AuthURL:      item.AuthURL,
+			TokenURL:     item.TokenURL,
+			UserInfoURL:  item.UserInfoURL,
+		})
+		if err != nil {
+			return fmt.Errorf("第三方登录服务 %s 配置错误：%w", name, err)
+		}
+
+		displayName := item.DisplayName
+		if displayName == "" {
+			displayName = name
+		}
+		providers[name] = &oauthProvider{
+			OAuthProvider: model.OAuthProvider{Name: name, DisplayName: displayName, Type: item.Type},
+			client:        client,
+		}
+		names = append(names, name)
+	}
+
+	oauthProviders = providers
+	oauthProviderNames = names
+	return nil
+}
+
+// ListOAuthProviders 获取可用的第三方登录服务
+func ListOAuthProviders() []model.OAuthProvider {
+	providers := make([]model.OAuthProvider, 0, len(oauthProviderNames))
+	for _, name := range oauthProviderNames {
+		providers = append(providers, oauthProviders[name].OAuthProvider)
+	}
+	return providers
+}
+
+// OAuthLoginType 返回第三方登录在登录日志中的类型
+func OAuthLoginType(name string) int8 {
+	if p, ok := oauthProviders[name]; ok && p.Type == oauth.TypeWechat {
+		return model.LoginTypeWechat
+	}
+	return model.LoginTypeOAuth
+}
+
+// BeginOAuth 生成跳转到第三方服务的授权地址。userID 不为0时表示为该用户关联账号，
+// 回调时只能由同一用户完成，防止他人的第三方账号被关联到攻击者的账号上
+func BeginOAuth(name string, userID int) (*model.OAuthAuthorization, error) {
+	p, ok := oauthProviders[name]
+	if !ok {
+		return nil, ErrOAuthProviderNotFound
+	}
+	redirectURI, err := oauthRedirectURI(name)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := oauth.NewState()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := oauth.NewState()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := oauth.NewCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), oauthExchangeTimeout)
+	defer cancel()
+	authorizeURL, err := p.client.AuthCodeURL(ctx, oauth.AuthRequest{
+		State:         state,
+		Nonce:         nonce,
+		CodeChallenge: oauth.CodeChallenge(verifier),
+		RedirectURI:   redirectURI,
+	})
+	if err != nil {
+		zap.L().Error("生成第三方登录授权地址失败", zap.String("provider", name), zap.Error(err))
+		return nil, ErrOAuthFailed
+	}
+
+	data, err := json.Marshal(oauthState{Provider: name, UserID: userID, Verifier: verifier, Nonce: nonce})
+	if err != nil {
+		return nil, err
+	}
+	if err := model.RDB.Set(ctx, fmt.Sprintf(oauthStateKey, state), data, oauthStateExpire).Err(); err != nil {
+		return nil, err
+	}
+
+	return &model.OAuthAuthorization{
+		AuthorizeURL: authorizeURL,
+		State:        state,
+		ExpiresIn:    int(oauthStateExpire / time.Second),
+	}, nil
+}
+
+// FinishOAuthLogin 使用回调中的授权码完成第三方登录，通过后签发与密码登录相同的令牌。
+// 第三方账号未关联时，按已验证的邮箱关联到邮箱同样已验证的用户，找不到时自动注册新用户；
+// 需要两步验证时只返回临时令牌。返回的用户用于记录登录日志，无法对应到用户时为nil
+func FinishOAuthLogin(name, code, state string, client model.SessionClient) (*model.LoginResult, *model.User, error) {
+	p, identity, err := exchangeOAuthCode(name, code, state, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := findOAuthUser(p, identity)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil {
+		if user, err = linkOAuthUserByEmail(p, identity); err != nil {
+			return nil, nil, err
+		}
+	}
+	if user == nil {
+		if user, err = registerOAuthUser(p, identity); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := CheckLoginAllowed(user.Username, client.IPAddress); err != nil {
+		var limitErr *LoginLimitError
+		if errors.As(err, &limitErr) {
+			return nil, user, err
+		}
+		zap.L().Error("检查登录限制失败", zap.String("username", user.Username), zap.Error(err))
+	}
+	if user.Status != model.UserStatusNormal {
+		return nil, user, ErrUserDisabled
+	}
+	if err := CheckEmailVerified(user.UserID); err != nil {
+		return nil, user, err
+	}
+
+	now := time.Now()
+	if p.Type != oauth.TypeWechat {
+		if err := model.DB.Model(&model.OAuthAccount{}).
+			Where("provider = ? AND subject = ?", name, identity.Subject).
+			Updates(map[string]interface{}{
+				"last_login_at": now,
+				"email":         truncateRunes(identity.Email, 100),
+				"nickname":      truncateRunes(identity.Nickname, 100),
+				"avatar":        identity.Avatar,
+			}).Error; err != nil {
+			zap.L().Warn("更新第三方账号信息失败", zap.Int("user_id", user.UserID), zap.Error(err))
+		}
+	}
+
+	challenge, err := BeginMFA(user.UserID, user.Username)
+	if err != nil {
+		return nil, user, err
+	}
+	if challenge != nil {
+		return &model.LoginResult{MFA: challenge}, user, nil
+	}
+
+	ResetLoginFailures(user.Username)
+	tokens, err := IssueTokens(user.UserID, user.Username, client)
+	if err != nil {
+		return nil, user, err
+	}
+	if err := model.DB.Model(user).Updates(map[string]interface{}{
+		"last_login":  now,
+		"login_count": gorm.Expr("login_count + 1"),
+	}).Error; err != nil {
+		zap.L().Warn("更新最后登录时间失败", zap.Int("user_id", user.UserID), zap.Error(err))
+	}
+	return tokens, user, nil
+}
+
+// LinkOAuthAccount 使用回调中的授权码为当前用户关联第三方账号，state 必须由同一用户发起
+func LinkOAuthAccount(userID int, name, code, state string) error {
+	p, identity, err := exchangeOAuthCode(name, code, state, userID)
+	if err != nil {
+		return err
+	}
+
+	linked, err := findOAuthUser(p, identity)
+	if err != nil {
+		return err
+	}
+	if linked != nil {
+		if linked.UserID == userID {
+			return nil
+		}
+		return ErrOAuthAccountLinked
+	}
+	return model.DB.Transaction(func(tx *gorm.DB) error {
+		return linkOAuthIdentity(tx, userID, p, identity)
+	})
+}
+
+// ListOAuthAccounts 获取用户关联的第三方账号，已不在配置中的服务不返回
+func ListOAuthAccounts(userID int) ([]model.OAuthAccount, error) {
+	var records []model.OAuthAccount
+	if err := model.DB.Where("user_id = ?", userID).Order("account_id").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	accounts := make([]model.OAuthAccount, 0, len(records)+1)
+	for _, account := range records {
+		if _, ok := oauthProviders[account.Provider]; ok {
+			accounts = append(accounts, account)
+		}
+	}
+
+	// 微信账号保存在用户表中
+	if p := wechatOAuthProvider(); p != nil {
+		var user model.User
+		if err := model.DB.Select("user_id, wechat_openid").First(&user, userID).Error; err != nil {
+			return nil, err
+		}
+		if user.WechatOpenID != "" {
+			accounts = append(accounts, model.OAuthAccount{UserID: userID, Provider: p.Name})
+		}
+	}
+	return accounts, nil
+}
+
+// UnlinkOAuthAccount 解除用户关联的第三方账号。用户未设置密码且没有其他第三方账号或通行密钥时不允许解除
+func UnlinkOAuthAccount(userID int, name string) error {
+	p, ok := oauthProviders[name]
+	if !ok {
+		return ErrOAuthProviderNotFound
+	}
+
+	var user model.User
+	if err := model.DB.Select("user_id, password_hash, wechat_openid").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	var otherAccounts, passkeys int64
+	if err := model.DB.Model(&model.OAuthAccount{}).Where("user_id = ? AND provider <> ?", userID, name).Count(&otherAccounts).Error; err != nil {
+		return err
+	}
+	if p.Type != oauth.TypeWechat && user.WechatOpenID != "" {
+		otherAccounts++
+	}
+	if err := model.DB.Model(&model.WebAuthnCredential{}).Where("user_id = ?", userID).Count(&passkeys).Error; err != nil {
+		return err
+	}
+	if user.PasswordHash == "" && otherAccounts == 0 && passkeys == 0 {
+		return ErrOAuthLastLoginMethod
+	}
+
+	var result *gorm.DB
+	if p.Type == oauth.TypeWechat {
+		result = model.DB.Model(&model.User{}).
+			Where("user_id = ? AND wechat_openid IS NOT NULL", userID).
+			Updates(map[string]interface{}{
+				"wechat_openid":  gorm.Expr("NULL"),
+				"wechat_unionid": gorm.Expr("NULL"),
+			})
+	} else {
+		result = model.DB.Where("user_id = ? AND provider = ?", userID, name).Delete(&model.OAuthAccount{})
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrOAuthNotLinked
+	}
+	return nil
+}
+
+// exchangeOAuthCode 取出 state 并使用授权码换取第三方账号信息，userID 必须与发起授权时一致
+func exchangeOAuthCode(name, code, state string, userID int) (*oauthProvider, *oauth.Identity, error) {
+	p, ok := oauthProviders[name]
+	if !ok {
+		return nil, nil, ErrOAuthProviderNotFound
+	}
+	saved, err := takeOAuthState(state)
+	if err != nil {
+		return nil, nil, err
+	}
+	if saved.Provider != name || saved.UserID != userID {
+		return nil, nil, ErrOAuthStateInvalid
+	}
+	redirectURI, err := oauthRedirectURI(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), oauthExchangeTimeout)
+	defer cancel()
+	identity, err := p.client.Exchange(ctx, oauth.ExchangeRequest{
+		Code:         code,
+		CodeVerifier: saved.Verifier,
+		Nonce:        saved.Nonce,
+		RedirectURI:  redirectURI,
+	})
+	if err != nil {
+		zap.L().Warn("第三方登录授权失败", zap.String("provider", name), zap.Error(err))
+		return nil, nil, ErrOAuthFailed
+	}
+	return p, identity, nil
+}
+
+// takeOAuthState 取出并删除发起授权时保存的状态，state 只能使用一次
+func takeOAuthState(state string) (*oauthState, error) {
+	ctx := context.Background()
+	key := fmt.Sprintf(oauthStateKey, state)
+	var get *redis.StringCmd
+	if _, err := model.RDB.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	}); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	data, err := get.Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrOAuthStateInvalid
+		}
+		return nil, err
+	}
+	var saved oauthState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, ErrOAuthStateInvalid
+	}
+	return &saved, nil
+}
+
+// findOAuthUser 查找第三方账号关联的用户，未关联时返回nil
+func findOAuthUser(p *oauthProvider, identity *oauth.Identity) (*model.User, error) {
+	query := model.DB.Model(&model.User{})
+	if p.Type == oauth.TypeWechat {
+		// 同一开放平台下的应用 UnionID 相同，优先按 UnionID 查找
+		if identity.UnionID != "" {
+			query = query.Where("wechat_unionid = ? OR wechat_openid = ?", identity.UnionID, identity.Subject)
+		} else {
+			query = query.Where("wechat_openid = ?", identity.Subject)
+		}
+	} else {
+		query = query.Where("user_id = (?)", model.DB.Model(&model.OAuthAccount{}).
+			Select("user_id").
+			Where("provider = ? AND subject = ?", p.Name, identity.Subject))
+	}
+
+	var user model.User
+	if err := query.First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// linkOAuthUserByEmail 第三方服务已验证邮箱时，关联到本站邮箱同样已验证的用户，没有该邮箱的用户时返回nil。
+// 本站用户的邮箱未验证时不关联，防止他人预先用受害者的邮箱注册后接管其第三方登录
+func linkOAuthUserByEmail(p *oauthProvider, identity *oauth.Identity) (*model.User, error) {
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, nil
+	}
+
+	var user model.User
+	if err := model.DB.Where("LOWER(email) = LOWER(?)", identity.Email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if user.EmailVerifiedAt == nil {
+		return nil, ErrOAuthEmailConflict
+	}
+
+	if err := model.DB.Transaction(func(tx *gorm.DB) error {
+		return linkOAuthIdentity(tx, user.UserID, p, identity)
+	}); err != nil {
+		return nil, err
+	}
+	zap.L().Info("第三方账号按邮箱关联到已有用户", zap.String("provider", p.Name), zap.Int("user_id", user.UserID))
+	return &user, nil
+}
+
+// registerOAuthUser 使用第三方账号信息注册新用户并分配默认角色，系统关闭自动注册时返回 ErrOAuthAccountNotLinked
+func registerOAuthUser(p *oauthProvider, identity *oauth.Identity) (*model.User, error) {
+	allowed, err := oauthAutoRegister()
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrOAuthAccountNotLinked
+	}
+
+	username, err := newOAuthUsername(identity)
+	if err != nil {
+		return nil, err
+	}
+	user := model.User{
+		Username:       username,
+		Nickname:       truncateRunes(identity.Nickname, 50),
+		Avatar:         identity.Avatar,
+		Status:         model.UserStatusNormal,
+		RegisterSource: model.RegisterSourceOAuth,
+	}
+	if p.Type == oauth.TypeWechat {
+		user.RegisterSource = model.RegisterSourceWechat
+	}
+	if user.Nickname == "" {
+		user.Nickname = username
+	}
+	// 唯一字段为空时不写入，保持为NULL
+	omit := []string{"Email", "Mobile", "WechatOpenID", "WechatUnionID", "Roles"}
+	if identity.Email != "" && identity.EmailVerified {
+		now := time.Now()
+		user.Email = identity.Email
+		user.EmailVerifiedAt = &now
+		omit = omit[1:]
+	}
+
+	if err := model.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(omit...).Create(&user).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("INSERT INTO sys_user_roles (user_id, role_id) SELECT ?, role_id FROM sys_roles WHERE is_default AND is_enabled",
+			user.UserID).Error; err != nil {
+			return err
+		}
+		return linkOAuthIdentity(tx, user.UserID, p, identity)
+	}); err != nil {
+		return nil, err
+	}
+	zap.L().Info("第三方账号自动注册用户", zap.String("provider", p.Name), zap.Int("user_id", user.UserID))
+	return &user, nil
+}
+
+// linkOAuthIdentity 将第三方账号关联到用户，微信账号写入用户表，其他服务写入关联表
+func linkOAuthIdentity(tx *gorm.DB, userID int, p *oauthProvider, identity *oauth.Identity) error {
+	if p.Type == oauth.TypeWechat {
+		updates := map[string]interface{}{"wechat_openid": identity.Subject}
+		if identity.UnionID != "" {
+			updates["wechat_unionid"] = identity.UnionID
+		}
+		result := tx.Model(&model.User{}).
+			Where("user_id = ? AND (wechat_openid IS NULL OR wechat_openid = '')", userID).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrOAuthProviderLinked
+		}
+		return nil
+	}
+
+	var count int64
+	if err := tx.Model(&model.OAuthAccount{}).Where("user_id = ? AND provider = ?", userID, p.Name).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrOAuthProviderLinked
+	}
+	now := time.Now()
+	return tx.Create(&model.OAuthAccount{
+		UserID:      userID,
+		Provider:    p.Name,
+		Subject:     identity.Subject,
+		Email:       truncateRunes(identity.Email, 100),
+		Nickname:    truncateRunes(identity.Nickname, 100),
+		Avatar:      identity.Avatar,
+		LastLoginAt: &now,
+	}).Error
+}
+
+// newOAuthUsername 根据第三方账号的用户名或昵称生成本站未使用的用户名
+func newOAuthUsername(identity *oauth.Identity) (string, error) {
+	base := oauthUsernamePattern.ReplaceAllString(identity.Username, "")
+	if base == "" {
+		base = oauthUsernamePattern.ReplaceAllString(identity.Nickname, "")
+	}
+	if len(base) > 20 {
+		base = base[:20]
+	}
+	if len(base) < 4 {
+		base = "user" + base
+	}
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		var count int64
+		if err := model.DB.Model(&model.User{}).Where("username = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		suffix, err := jwt.NewTokenID()
+		if err != nil {
+			return "", err
+		}
+		candidate = base + "_" + suffix[:6]
+	}
+	return "", errors.New("生成用户名失败，请重试")
+}
+
+// oauthRedirectURI 第三方服务授权后跳转的前端地址，只使用系统配置的网站地址
+func oauthRedirectURI(name string) (string, error) {
+	site, err := GetSiteInfo()
+	if err != nil {
+		return "", err
+	}
+	if site.URL == "" {
+		return "", ErrSiteURLNotConfigured
+	}
+	return site.URL + SiteOAuthCallbackPath + name, nil
+}
+
+// wechatOAuthProvider 返回配置的微信登录服务，未配置时返回nil
+func wechatOAuthProvider() *oauthProvider {
+	for _, p := range oauthProviders {
+		if p.Type == oauth.TypeWechat {
+			return p
+		}
+	}
+	return nil
+}
+
+// oauthAutoRegister 读取第三方账号未关联时是否自动注册，未配置时允许
+func oauthAutoRegister() (bool, error) {
+	var config model.SysConfig
+	if err := model.DB.Where("config_key = ?", oauthAutoRegisterKey).First(&config).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return true, nil
+		}
+		return false, err
+	}
+	allowed, err := strconv.ParseBool(strings.TrimSpace(config.ConfigValue))
+	if err != nil {
+		return true, nil
+	}
+	return allowed, nil
+}
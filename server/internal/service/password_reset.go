@@ -0,0 +1,89 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/mail"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// passwordResetExpire 找回密码链接有效期
+const passwordResetExpire = 30 * time.Minute
+
+// RequestPasswordReset 向邮箱发送找回密码邮件。邮箱不存在或账号已禁用时同样返回成功，不暴露邮箱是否注册
+func RequestPasswordReset(email string) error {
+	var user model.User
+	if err := model.DB.Select("user_id, username, email, status").
+		Where("LOWER(email) = LOWER(?)", strings.TrimSpace(email)).
+		First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.Status == model.UserStatusDisabled {
+		return nil
+	}
+
+	token, err := issueAccountToken(accountTokenResetPassword, accountToken{UserID: user.UserID, Email: user.Email}, passwordResetExpire)
+	if err != nil {
+		return err
+	}
+	link, err := siteLink(SiteResetPasswordPath, token)
+	if err != nil {
+		return err
+	}
+	return SendTemplateMail(user.Email, mail.TemplateResetPassword, mail.TemplateData{
+		Username:      user.Username,
+		Link:          link,
+		ExpireMinutes: int(passwordResetExpire / time.Minute),
+	})
+}
+
+// ResetPasswordByToken 使用找回密码邮件中的令牌设置新密码。
+// 能收到邮件说明邮箱属于该用户，未验证的邮箱同时标记为已验证；
+// 重置后吊销全部登录令牌并解除登录锁定
+func ResetPasswordByToken(token, newPassword string) error {
+	info, err := consumeAccountToken(accountTokenResetPassword, token)
+	if err != nil {
+		return err
+	}
+
+	var user model.User
+	if err := model.DB.Select("user_id, username, email, status").First(&user, info.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAccountTokenInvalid
+		}
+		return err
+	}
+	if user.Email != info.Email {
+		return ErrAccountTokenInvalid
+	}
+	if user.Status == model.UserStatusDisabled {
+		return ErrUserDisabled
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := model.DB.Model(&user).Updates(map[string]interface{}{
+		"password_hash":     string(hashedPassword),
+		"email_verified_at": gorm.Expr("COALESCE(email_verified_at, ?)", time.Now()),
+	}).Error; err != nil {
+		return err
+	}
+
+	if err := RevokeAllTokens(user.UserID); err != nil {
+		zap.L().Error("重置密码后吊销令牌失败", zap.Int("user_id", user.UserID), zap.Error(err))
+	}
+	if err := UnlockLoginUser(user.UserID); err != nil {
+		zap.L().Warn("重置密码后解除登录锁定失败", zap.Int("user_id", user.UserID), zap.Error(err))
+	}
+	return nil
+}
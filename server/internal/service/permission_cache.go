@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"go.uber.org/zap"
+)
+
+// 权限缓存键。用户的有效权限集合按两个版本号缓存：角色权限、角色或权限状态变化时递增全局版本，
+// 分配用户角色时递增该用户的版本，旧版本的缓存不再被读取，过期后自动清除
+const (
+	permVersionKey     = "blog:perm:version"         // 全局角色权限版本
+	userPermVersionKey = "blog:perm:user:%d:version" // 用户角色版本
+	userPermCacheKey   = "blog:perm:user:%d:%d:%d"   // 用户有效权限标识集合（用户ID、全局版本、用户版本）
+)
+
+const (
+	permCacheTTL    = time.Hour
+	permAll         = "*" // 超级管理员拥有全部权限
+	permCacheMarker = "-" // 占位成员，没有任何权限时集合也能被缓存
+)
+
+// HasPermission 检查用户是否拥有指定权限标识（权限的 perm_key 或 perms），结果来自权限缓存，
+// 缓存不可用时直接查询数据库
+func HasPermission(userID int, permKey string) (bool, error) {
+	ctx := context.Background()
+
+	cacheKey, err := userPermissionCacheKey(ctx, userID)
+	if err == nil {
+		var exists *redis.IntCmd
+		var hasAll, has *redis.BoolCmd
+		_, err = model.RDB.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			exists = pipe.Exists(ctx, cacheKey)
+			hasAll = pipe.SIsMember(ctx, cacheKey, permAll)
+			has = pipe.SIsMember(ctx, cacheKey, permKey)
+			return nil
+		})
+		if err == nil && exists.Val() > 0 {
+			return hasAll.Val() || has.Val(), nil
+		}
+	}
+	if err != nil {
+		zap.L().Warn("读取权限缓存失败", zap.Int("user_id", userID), zap.Error(err))
+	}
+
+	keys, err := loadUserPermissionKeys(userID)
+	if err != nil {
+		zap.L().Error("查询用户权限失败",
+			zap.Int("user_id", userID),
+			zap.String("perm_key", permKey),
+			zap.Error(err),
+		)
+		return false, err
+	}
+	if cacheKey != "" {
+		cacheUserPermissionKeys(ctx, cacheKey, keys)
+	}
+
+	for _, key := range keys {
+		if key == permAll || key == permKey {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HasScopedPermission 检查用户是否拥有指定权限标识。scopes 为个人访问令牌的权限范围，
+// 不为nil时权限标识还需在范围内；使用登录令牌等不受限的方式认证时传nil
+func HasScopedPermission(userID int, scopes []string, permKey string) (bool, error) {
+	if scopes != nil {
+		inScope := false
+		for _, scope := range scopes {
+			if scope == permKey {
+				inScope = true
+				break
+			}
+		}
+		if !inScope {
+			return false, nil
+		}
+	}
+	return HasPermission(userID, permKey)
+}
+
+// InvalidatePermissionCache 使全部用户的权限缓存失效，在角色权限、角色状态或权限变化后调用
+func InvalidatePermissionCache() {
+	if err := model.RDB.Incr(context.Background(), permVersionKey).Err(); err != nil {
+		zap.L().Error("更新权限缓存版本失败", zap.Error(err))
+	}
+}
+
+// InvalidateUserPermissionCache 使用户的权限缓存失效，在用户角色变化后调用
+func InvalidateUserPermissionCache(userID int) {
+	if err := model.RDB.Incr(context.Background(), fmt.Sprintf(userPermVersionKey, userID)).Err(); err != nil {
+		zap.L().Error("更新用户权限缓存版本失败", zap.Int("user_id", userID), zap.Error(err))
+	}
+}
+
+// userPermissionCacheKey 根据当前的全局版本和用户版本生成用户权限缓存键
+func userPermissionCacheKey(ctx context.Context, userID int) (string, error) {
+	values, err := model.RDB.MGet(ctx, permVersionKey, fmt.Sprintf(userPermVersionKey, userID)).Result()
+	if err != nil {
+		return "", err
+	}
+
+	versions := make([]int64, len(values))
+	for i, value := range values {
+		if s, ok := value.(string); ok {
+			versions[i], _ = strconv.ParseInt(s, 10, 64)
+		}
+	}
+	return fmt.Sprintf(userPermCacheKey, userID, versions[0], versions[1]), nil
+}
+
+// cacheUserPermissionKeys 缓存用户的有效权限集合，失败只记录日志
+func cacheUserPermissionKeys(ctx context.Context, cacheKey string, keys []string) {
+	members := make([]interface{}, 0, len(keys)+1)
+	members = append(members, permCacheMarker)
+	for _, key := range keys {
+		members = append(members, key)
+	}
+
+	if _, err := model.RDB.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, cacheKey)
+		pipe.SAdd(ctx, cacheKey, members...)
+		pipe.Expire(ctx, cacheKey, permCacheTTL)
+		return nil
+	}); err != nil {
+		zap.L().Warn("写入权限缓存失败", zap.String("key", cacheKey), zap.Error(err))
+	}
+}
+
+// loadUserPermissionKeys 查询用户已启用角色的全部已启用权限标识，包括 perm_key 和 perms，
+// 超级管理员只返回 permAll
+func loadUserPermissionKeys(userID int) ([]string, error) {
+	var roleIDs []int
+	if err := model.DB.Table("sys_user_roles").
+		Joins("JOIN sys_roles ON sys_user_roles.role_id = sys_roles.role_id").
+		Where("sys_user_roles.user_id = ? AND sys_roles.is_enabled = ?", userID, true).
+		Pluck("sys_user_roles.role_id", &roleIDs).Error; err != nil {
+		return nil, err
+	}
+	if len(roleIDs) == 0 {
+		return []string{}, nil
+	}
+	for _, roleID := range roleIDs {
+		// 角色ID为1表示超级管理员
+		if roleID == 1 {
+			return []string{permAll}, nil
+		}
+	}
+
+	var permissions []model.Permission
+	if err := model.DB.Table("sys_permissions").
+		Select("DISTINCT sys_permissions.perm_key, sys_permissions.perms").
+		Joins("JOIN sys_role_permissions ON sys_permissions.perm_id = sys_role_permissions.perm_id").
+		Where("sys_role_permissions.role_id IN ?", roleIDs).
+		Where("sys_permissions.is_enabled = ?", true).
+		Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissionKeys(permissions), nil
+}
+
+// loadEnabledPermissionKeys 查询全部已启用权限的权限标识，即超级管理员 permAll 展开后的权限
+func loadEnabledPermissionKeys() ([]string, error) {
+	var permissions []model.Permission
+	if err := model.DB.Model(&model.Permission{}).
+		Select("perm_key, perms").
+		Where("is_enabled = ?", true).
+		Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissionKeys(permissions), nil
+}
+
+// permissionKeys 收集权限的 perm_key 和 perms，两者都可用于权限校验
+func permissionKeys(permissions []model.Permission) []string {
+	keys := make([]string, 0, len(permissions))
+	for _, perm := range permissions {
+		keys = append(keys, perm.PermKey)
+		if perm.Perms != "" && perm.Perms != perm.PermKey {
+			keys = append(keys, perm.Perms)
+		}
+	}
+	return keys
+}
@@ -28,16 +28,6 @@ func CreatePermission(form model.PermissionCreateForm) (int, error) {
 		}
 	}
 
-	// 检查API路径是否已存在（如果是API类型）
-	if form.PermType == 3 && form.APIPath != "" {
-		if err := model.DB.Model(&model.Permission{}).Where("api_path = ?", form.APIPath).Count(&count).Error; err != nil {
-			return 0, err
-		}
-		if count > 0 {
-			return 0, errors.New("API路径已存在")
-		}
-	}
-
 	// 创建权限
 	permission := model.Permission{
 		PermName:  form.PermName,
@@ -106,19 +96,6 @@ func UpdatePermission(permID int, form model.PermissionUpdateForm) error {
 		}
 	}
 
-	// 检查API路径是否已被其他权限使用（如果是API类型）
-	if form.PermType != nil && *form.PermType == 3 && form.APIPath != nil && *form.APIPath != "" && *form.APIPath != permission.APIPath {
-		var count int64
-		if err := model.DB.Model(&model.Permission{}).
-			Where("api_path = ? AND perm_id != ?", *form.APIPath, permID).
-			Count(&count).Error; err != nil {
-			return err
-		}
-		if count > 0 {
-			return errors.New("API路径已被其他权限使用")
-		}
-	}
-
 	// 更新权限
 	updates := map[string]interface{}{}
 	if form.PermName != "" {
@@ -168,6 +145,11 @@ func UpdatePermission(permID int, form model.PermissionUpdateForm) error {
 		return err
 	}
 
+	// 权限标识或启用状态变化会影响拥有该权限的用户
+	if form.PermKey != nil || form.IsEnabled != nil {
+		InvalidatePermissionCache()
+	}
+
 	return nil
 }
 
@@ -337,5 +319,6 @@ func UpdatePermissionStatus(permID int, isEnabled bool) error {
 		return err
 	}
 
+	InvalidatePermissionCache()
 	return nil
 }
@@ -28,12 +28,13 @@ func CreateRole(form model.RoleCreateForm) (int, error) {
 
 	// 创建角色
 	role := model.Role{
-		RoleName:  form.RoleName,
-		RoleKey:   form.RoleKey,
-		SortOrder: form.SortOrder,
-		IsEnabled: form.IsEnabled,
-		IsBuiltin: form.IsBuiltin,
-		Remark:    form.Remark,
+		RoleName:   form.RoleName,
+		RoleKey:    form.RoleKey,
+		SortOrder:  form.SortOrder,
+		IsEnabled:  form.IsEnabled,
+		IsBuiltin:  form.IsBuiltin,
+		Remark:     form.Remark,
+		RequireMFA: form.RequireMFA,
 	}
 
 	// 保存角色
@@ -106,11 +107,19 @@ func UpdateRole(roleID int, form model.RoleUpdateForm) error {
 	if form.Remark != nil {
 		updates["remark"] = *form.Remark
 	}
+	if form.RequireMFA != nil {
+		updates["require_mfa"] = *form.RequireMFA
+	}
 
 	if err := model.DB.Model(&role).Updates(updates).Error; err != nil {
 		return err
 	}
 
+	// 角色启用状态变化会影响拥有该角色的用户的权限
+	if form.IsEnabled != nil && *form.IsEnabled != role.IsEnabled {
+		InvalidatePermissionCache()
+	}
+
 	return nil
 }
 
@@ -281,7 +290,12 @@ func AssignPermissions(roleID int, permIDs []int) error {
 		}
 	}
 
-	return tx.Commit().Error
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	InvalidatePermissionCache()
+	return nil
 }
 
 // GetRolePermissions 获取角色权限
@@ -312,5 +326,6 @@ func UpdateRoleStatus(roleID int, isEnabled bool) error {
 		return err
 	}
 
+	InvalidatePermissionCache()
 	return nil
 }
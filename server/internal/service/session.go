@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+)
+
+// ErrSessionNotFound 会话不存在或已过期
+var ErrSessionNotFound = errors.New("会话不存在或已过期")
+
+// ListSessions 获取用户的有效登录会话，按最近访问时间倒序排列，currentSessionID 对应的会话标记为当前会话
+func ListSessions(userID int, currentSessionID string) ([]model.Session, error) {
+	ctx := context.Background()
+	familiesKey := fmt.Sprintf(userTokenFamiliesKey, userID)
+	familyIDs, err := model.RDB.SMembers(ctx, familiesKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := model.RDB.Pipeline()
+	values := make([]*redis.StringStringMapCmd, len(familyIDs))
+	ttls := make([]*redis.DurationCmd, len(familyIDs))
+	for i, familyID := range familyIDs {
+		key := fmt.Sprintf(tokenFamilyKey, familyID)
+		values[i] = pipe.HGetAll(ctx, key)
+		ttls[i] = pipe.TTL(ctx, key)
+	}
+	if len(familyIDs) > 0 {
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	sessions := make([]model.Session, 0, len(familyIDs))
+	var expired []interface{}
+	for i, familyID := range familyIDs {
+		fields := values[i].Val()
+		if len(fields) == 0 {
+			// 会话已过期或被吊销，从用户的会话列表中移除
+			expired = append(expired, familyID)
+			continue
+		}
+		session := model.Session{
+			SessionID:  familyID,
+			UserID:     userID,
+			Device:     fields["device"],
+			DeviceType: fields["device_type"],
+			IPAddress:  fields["ip"],
+			UserAgent:  fields["user_agent"],
+			LastIP:     fields["last_ip"],
+			CreatedAt:  unixField(fields["created_at"]),
+			LastSeen:   unixField(fields["last_seen"]),
+			Current:    familyID == currentSessionID,
+		}
+		if ttl := ttls[i].Val(); ttl > 0 {
+			session.ExpiresAt = now.Add(ttl).Truncate(time.Second)
+		}
+		sessions = append(sessions, session)
+	}
+	if len(expired) > 0 {
+		model.RDB.SRem(ctx, familiesKey, expired...)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeen.After(sessions[j].LastSeen)
+	})
+	return sessions, nil
+}
+
+// RevokeSession 移除用户的登录会话，该会话签发的访问令牌和刷新令牌立即失效
+func RevokeSession(userID int, sessionID string) error {
+	owner, err := model.RDB.HGet(context.Background(), fmt.Sprintf(tokenFamilyKey, sessionID), "user_id").Result()
+	if errors.Is(err, redis.Nil) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if owner != strconv.Itoa(userID) {
+		return ErrSessionNotFound
+	}
+	return revokeTokenFamily(userID, sessionID)
+}
+
+// unixField 将以Unix秒保存的时间字段转换为时间，无效时返回零值
+func unixField(value string) time.Time {
+	sec, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || sec <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
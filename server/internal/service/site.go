@@ -0,0 +1,96 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+)
+
+// siteConfigGroup 站点信息所在的配置分组
+const siteConfigGroup = "site"
+
+// 前台页面路径，订阅源、站点地图等生成链接时使用
+const (
+	SiteArticlePath  = "/article/"
+	SiteCategoryPath = "/category/"
+	SiteTagPath      = "/tag/"
+	SiteAuthorPath   = "/author/"
+
+	SiteVerifyEmailPath   = "/verify-email"    // 邮箱验证页，链接参数 token
+	SiteResetPasswordPath = "/reset-password"  // 重置密码页，链接参数 token
+	SiteMagicLinkPath     = "/magic-login"     // 邮件登录页，链接参数 token
+	SiteOAuthCallbackPath = "/oauth/callback/" // 第三方登录回调页，后接服务标识，参数 code 和 state
+)
+
+// GetSiteInfo 获取站点信息，网站地址为空时由调用方根据请求地址补全
+func GetSiteInfo() (*model.SiteInfo, error) {
+	var configs []model.SysConfig
+	if err := model.DB.Where("config_group = ?", siteConfigGroup).Find(&configs).Error; err != nil {
+		return nil, err
+	}
+
+	site := &model.SiteInfo{Language: "zh-CN"}
+	for _, config := range configs {
+		switch config.ConfigKey {
+		case "site_name":
+			site.Name = config.ConfigValue
+		case "site_description":
+			site.Description = config.ConfigValue
+		case "site_keywords":
+			site.Keywords = config.ConfigValue
+		case "site_logo":
+			site.Logo = config.ConfigValue
+		case "site_url":
+			site.URL = strings.TrimRight(strings.TrimSpace(config.ConfigValue), "/")
+		case "site_language":
+			if config.ConfigValue != "" {
+				site.Language = config.ConfigValue
+			}
+		case "site_copyright":
+			site.Copyright = config.ConfigValue
+		}
+		if config.UpdatedAt.After(site.UpdatedAt) {
+			site.UpdatedAt = config.UpdatedAt
+		}
+	}
+
+	return site, nil
+}
+
+// SiteBaseURL 获取站点地址，未配置网站地址时使用请求地址
+func SiteBaseURL(requestBaseURL string) (string, error) {
+	site, err := GetSiteInfo()
+	if err != nil {
+		return "", err
+	}
+	if site.URL != "" {
+		return site.URL, nil
+	}
+	return strings.TrimRight(requestBaseURL, "/"), nil
+}
+
+// SiteAbsURL 将站内路径转换为绝对地址，已是绝对地址时原样返回
+func SiteAbsURL(baseURL, path string) string {
+	if path == "" {
+		return ""
+	}
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	if strings.HasPrefix(path, "//") {
+		if u, err := url.Parse(baseURL); err == nil && u.Scheme != "" {
+			return u.Scheme + ":" + path
+		}
+		return "https:" + path
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return baseURL + path
+}
+
+// SiteArticleURL 文章页面地址
+func SiteArticleURL(baseURL, articleKey string) string {
+	return baseURL + SiteArticlePath + url.PathEscape(articleKey)
+}
@@ -0,0 +1,630 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/config"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/render"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// exportBatchSize 每批读取的文章数，每批写完后刷新输出，避免整站数据驻留内存
+const exportBatchSize = 100
+
+// 上传目录默认值，与配置文件一致
+const (
+	defaultUploadSavePath  = "uploads"
+	defaultUploadURLPrefix = "/uploads/"
+)
+
+// exportStatusNames 导出的文章状态
+var exportStatusNames = map[int8]string{
+	model.ArticleStatusDraft:     "draft",
+	model.ArticleStatusPending:   "pending",
+	model.ArticleStatusPublished: "published",
+	model.ArticleStatusOffline:   "offline",
+}
+
+// exportFileName 压缩包内文件名中不允许的字符
+var exportFileName = strings.NewReplacer("/", "-", `\`, "-", ":", "-")
+
+// exportFrontMatter 文章的前置元数据，字段名与 Hexo、Hugo 常用字段一致，可用Markdown导入重新导入
+type exportFrontMatter struct {
+	Title          string       `yaml:"title"`
+	Slug           string       `yaml:"slug"`
+	Date           time.Time    `yaml:"date"`
+	Updated        time.Time    `yaml:"updated"`
+	Author         string       `yaml:"author"`
+	Status         string       `yaml:"status"`
+	Draft          bool         `yaml:"draft,omitempty"`
+	Format         string       `yaml:"format"`
+	Version        int          `yaml:"version"`
+	ArticleType    int8         `yaml:"article_type"`
+	Categories     []exportPath `yaml:"categories,omitempty"`
+	Tags           []string     `yaml:"tags,omitempty"`
+	Summary        string       `yaml:"summary,omitempty"`
+	Thumbnail      string       `yaml:"thumbnail,omitempty"`
+	Keywords       []string     `yaml:"keywords,omitempty"`
+	SEOTitle       string       `yaml:"seo_title,omitempty"`
+	SEODescription string       `yaml:"seo_description,omitempty"`
+	SourceURL      string       `yaml:"source_url,omitempty"`
+	SourceName     string       `yaml:"source_name,omitempty"`
+	Comments       bool         `yaml:"comments"`
+	Top            bool         `yaml:"top,omitempty"`
+	Recommend      bool         `yaml:"recommend,omitempty"`
+	Views          int          `yaml:"views"`
+	Likes          int          `yaml:"likes"`
+	ScheduleTime   *time.Time   `yaml:"schedule_time,omitempty"`
+	ExpireTime     *time.Time   `yaml:"expire_time,omitempty"`
+}
+
+// exportVersionFrontMatter 历史版本的前置元数据
+type exportVersionFrontMatter struct {
+	Title   string    `yaml:"title"`
+	Slug    string    `yaml:"slug"`
+	Version int       `yaml:"version"`
+	Format  string    `yaml:"format"`
+	Date    time.Time `yaml:"date"`
+}
+
+// exportPath 分类路径，以行内列表输出，如 [技术, Go]
+type exportPath []string
+
+// MarshalYAML 实现 yaml.Marshaler
+func (p exportPath) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+	for _, name := range p {
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name})
+	}
+	return node, nil
+}
+
+// siteExporter 全站导出
+type siteExporter struct {
+	w          io.Writer
+	zw         *zip.Writer
+	opts       model.ExportOptions
+	manifest   *model.ExportManifest
+	uploadDir  string
+	uploadRef  *regexp.Regexp         // 引用上传文件的地址，第一个分组为文件相对路径
+	categories map[int]model.Category // 全部分类，用于生成分类路径
+	seenFiles  map[string]bool        // 已处理的上传文件
+	pending    []string               // 待写入的上传文件
+	files      []model.ExportFile     // 已写入的上传文件记录
+}
+
+// ExportSite 将全站内容导出为zip压缩包并写入 w，返回导出说明
+//
+// 压缩包结构：
+//
+//	manifest.json              导出说明
+//	site/categories.json       分类树
+//	site/tags.json             标签
+//	site/configs.json          系统配置
+//	posts/<文章标识>.md         文章正文及前置元数据
+//	versions/<文章标识>/v<N>.md 历史版本（可选）
+//	comments/<文章标识>.json    评论，回复嵌套在上级评论中
+//	files.json                 上传文件记录
+//	uploads/<文件路径>          文章、分类、标签引用的上传文件
+//
+// 文章按ID分批读取并边读边写，每批写完后刷新输出。指定 Limit 时分段导出，
+// 以 manifest.json 中的 last_article_id 作为下一段的 AfterID 继续，分类、标签和配置只在第一段中导出。
+func ExportSite(w io.Writer, upload config.UploadConfig, opts model.ExportOptions) (*model.ExportManifest, error) {
+	uploadDir := upload.SavePath
+	if uploadDir == "" {
+		uploadDir = defaultUploadSavePath
+	}
+	urlPrefix := strings.TrimSuffix(upload.URLPrefix, "/")
+	if urlPrefix == "" {
+		urlPrefix = strings.TrimSuffix(defaultUploadURLPrefix, "/")
+	}
+
+	e := &siteExporter{
+		w:    w,
+		zw:   zip.NewWriter(w),
+		opts: opts,
+		manifest: &model.ExportManifest{
+			FormatVersion:   model.ExportFormatVersion,
+			ExportedAt:      time.Now(),
+			IncludeVersions: opts.IncludeVersions,
+			AfterID:         opts.AfterID,
+			LastArticleID:   opts.AfterID,
+		},
+		uploadDir:  uploadDir,
+		uploadRef:  regexp.MustCompile(regexp.QuoteMeta(urlPrefix+"/") + `([^\s"'<>()\[\]?#]+)`),
+		categories: make(map[int]model.Category),
+		seenFiles:  make(map[string]bool),
+	}
+
+	if err := e.export(); err != nil {
+		// 已写出部分内容，压缩包不完整，由调用方中断输出
+		zap.L().Error("导出站点失败", zap.Int64("last_article_id", e.manifest.LastArticleID), zap.Error(err))
+		return nil, err
+	}
+	zap.L().Info("导出站点完成",
+		zap.Int("articles", e.manifest.Articles),
+		zap.Int64("last_article_id", e.manifest.LastArticleID),
+		zap.Bool("complete", e.manifest.Complete),
+	)
+	return e.manifest, nil
+}
+
+// export 依次导出站点数据、文章和说明
+func (e *siteExporter) export() error {
+	var categories []model.Category
+	if err := model.DB.Order("sort_order ASC, category_id ASC").Find(&categories).Error; err != nil {
+		return err
+	}
+	for _, category := range categories {
+		e.categories[category.CategoryID] = category
+	}
+
+	if e.opts.AfterID == 0 {
+		if err := e.exportSiteData(categories); err != nil {
+			return err
+		}
+	}
+	if err := e.exportArticles(); err != nil {
+		return err
+	}
+
+	if err := e.writeJSON("files.json", e.files, time.Now()); err != nil {
+		return err
+	}
+	if err := e.writeJSON("manifest.json", e.manifest, e.manifest.ExportedAt); err != nil {
+		return err
+	}
+	return e.zw.Close()
+}
+
+// exportSiteData 导出分类、标签和系统配置
+func (e *siteExporter) exportSiteData(categories []model.Category) error {
+	nodes := make(map[int]*model.ExportCategory, len(categories))
+	for _, category := range categories {
+		nodes[category.CategoryID] = &model.ExportCategory{
+			CategoryID:     category.CategoryID,
+			CategoryName:   category.CategoryName,
+			CategoryKey:    category.CategoryKey,
+			Description:    category.Description,
+			Thumbnail:      category.Thumbnail,
+			Icon:           category.Icon,
+			SortOrder:      category.SortOrder,
+			IsVisible:      category.IsVisible,
+			SEOTitle:       category.SEOTitle,
+			SEOKeywords:    category.SEOKeywords,
+			SEODescription: category.SEODescription,
+			CreatedAt:      category.CreatedAt,
+		}
+		e.collectFiles(category.Thumbnail)
+	}
+	tree := make([]*model.ExportCategory, 0)
+	for _, category := range categories {
+		node := nodes[category.CategoryID]
+		if category.ParentID != nil {
+			if parent, ok := nodes[*category.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		tree = append(tree, node)
+	}
+	if err := e.writeJSON("site/categories.json", tree, time.Now()); err != nil {
+		return err
+	}
+	e.manifest.Categories = len(categories)
+
+	var tags []model.Tag
+	if err := model.DB.Order("sort_order ASC, tag_id ASC").Find(&tags).Error; err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		e.collectFiles(tag.Thumbnail)
+	}
+	if err := e.writeJSON("site/tags.json", tags, time.Now()); err != nil {
+		return err
+	}
+	e.manifest.Tags = len(tags)
+
+	var configs []model.SysConfig
+	if err := model.DB.Order("config_group ASC, sort_order ASC, config_id ASC").Find(&configs).Error; err != nil {
+		return err
+	}
+	if err := e.writeJSON("site/configs.json", configs, time.Now()); err != nil {
+		return err
+	}
+	e.manifest.Configs = len(configs)
+
+	return e.flushFiles()
+}
+
+// exportArticles 按文章ID分批导出文章
+func (e *siteExporter) exportArticles() error {
+	lastID := e.opts.AfterID
+	for {
+		batchSize := exportBatchSize
+		if e.opts.Limit > 0 {
+			if remaining := e.opts.Limit - e.manifest.Articles; remaining < batchSize {
+				batchSize = remaining
+			}
+		}
+		if batchSize <= 0 {
+			break
+		}
+
+		var articles []model.Article
+		if err := model.DB.
+			Preload("User", func(db *gorm.DB) *gorm.DB {
+				return db.Select("user_id, username, nickname")
+			}).
+			Preload("Content", "is_current = ?", true).
+			Preload("Categories").
+			Preload("Tags").
+			Where("article_id > ?", lastID).
+			Order("article_id ASC").
+			Limit(batchSize).
+			Find(&articles).Error; err != nil {
+			return err
+		}
+		if len(articles) == 0 {
+			e.manifest.Complete = true
+			return nil
+		}
+
+		for i := range articles {
+			if err := e.exportArticle(&articles[i]); err != nil {
+				return err
+			}
+			lastID = articles[i].ArticleID
+			e.manifest.LastArticleID = lastID
+			e.manifest.Articles++
+		}
+		if err := e.flushFiles(); err != nil {
+			return err
+		}
+		if err := e.flush(); err != nil {
+			return err
+		}
+		if len(articles) < batchSize {
+			e.manifest.Complete = true
+			return nil
+		}
+	}
+
+	// 达到数量限制，检查是否还有未导出的文章
+	var count int64
+	if err := model.DB.Model(&model.Article{}).Where("article_id > ?", lastID).Count(&count).Error; err != nil {
+		return err
+	}
+	e.manifest.Complete = count == 0
+	return nil
+}
+
+// exportArticle 导出单篇文章、历史版本和评论
+func (e *siteExporter) exportArticle(article *model.Article) error {
+	name := exportFileName.Replace(article.ArticleKey)
+	if name == "" {
+		name = strconv.FormatInt(article.ArticleID, 10)
+	}
+
+	matter := exportFrontMatter{
+		Title:          article.Title,
+		Slug:           article.ArticleKey,
+		Date:           article.PublishTime,
+		Updated:        article.UpdatedAt,
+		Author:         article.User.Username,
+		Status:         exportStatusNames[article.Status],
+		Draft:          article.Status != model.ArticleStatusPublished,
+		Format:         exportFormatName(article.Content.ContentFormat),
+		Version:        article.Content.Version,
+		ArticleType:    article.ArticleType,
+		Summary:        article.Summary,
+		Thumbnail:      article.Thumbnail,
+		SEOTitle:       article.SEOTitle,
+		SEODescription: article.SEODescription,
+		SourceURL:      article.SourceURL,
+		SourceName:     article.SourceName,
+		Comments:       article.AllowComment,
+		Top:            article.IsTop,
+		Recommend:      article.IsRecommend,
+		Views:          article.ViewCount,
+		Likes:          article.LikeCount,
+		ScheduleTime:   article.ScheduleTime,
+		ExpireTime:     article.ExpireTime,
+	}
+	if matter.Date.IsZero() {
+		matter.Date = article.CreatedAt
+	}
+	for _, category := range article.Categories {
+		matter.Categories = append(matter.Categories, e.categoryPath(category.CategoryID))
+	}
+	for _, tag := range article.Tags {
+		matter.Tags = append(matter.Tags, tag.TagName)
+	}
+	if article.SEOKeywords != "" {
+		matter.Keywords = splitTagNames(article.SEOKeywords)
+	}
+
+	if err := e.writeMarkdown("posts/"+name+".md", matter, article.Content.Content, article.UpdatedAt); err != nil {
+		return err
+	}
+	e.collectFiles(article.Content.Content, article.Thumbnail)
+
+	if e.opts.IncludeVersions {
+		if err := e.exportVersions(article, name); err != nil {
+			return err
+		}
+	}
+	return e.exportComments(article.ArticleID, name)
+}
+
+// exportVersions 导出文章的历史版本，逐个读取避免大文章的全部版本同时驻留内存
+func (e *siteExporter) exportVersions(article *model.Article, name string) error {
+	var versions []int
+	if err := model.DB.Model(&model.ArticleContent{}).
+		Where("article_id = ? AND is_current = ?", article.ArticleID, false).
+		Order("version ASC").
+		Pluck("version", &versions).Error; err != nil {
+		return err
+	}
+
+	for _, version := range versions {
+		var content model.ArticleContent
+		if err := model.DB.Where("article_id = ? AND version = ?", article.ArticleID, version).
+			First(&content).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return err
+		}
+		matter := exportVersionFrontMatter{
+			Title:   article.Title,
+			Slug:    article.ArticleKey,
+			Version: content.Version,
+			Format:  exportFormatName(content.ContentFormat),
+			Date:    content.CreatedAt,
+		}
+		p := "versions/" + name + "/v" + strconv.Itoa(content.Version) + ".md"
+		if err := e.writeMarkdown(p, matter, content.Content, content.CreatedAt); err != nil {
+			return err
+		}
+		e.collectFiles(content.Content)
+		e.manifest.Versions++
+	}
+	return nil
+}
+
+// exportComments 导出文章评论，回复嵌套在上级评论中，没有评论时不生成文件
+func (e *siteExporter) exportComments(articleID int64, name string) error {
+	var comments []model.Comment
+	if err := model.DB.
+		Preload("User", func(db *gorm.DB) *gorm.DB {
+			return db.Select("user_id, username, nickname")
+		}).
+		Where("article_id = ?", articleID).
+		Order("comment_id ASC").
+		Find(&comments).Error; err != nil {
+		return err
+	}
+	if len(comments) == 0 {
+		return nil
+	}
+
+	nodes := make(map[int64]*model.ExportComment, len(comments))
+	threads := make([]*model.ExportComment, 0)
+	for _, comment := range comments {
+		node := &model.ExportComment{
+			CommentID:    comment.CommentID,
+			UserID:       comment.UserID,
+			Username:     comment.User.Username,
+			Nickname:     comment.User.Nickname,
+			Content:      comment.Content,
+			IPAddress:    comment.IPAddress,
+			UserAgent:    comment.UserAgent,
+			LikedCount:   comment.LikedCount,
+			IsApproved:   comment.IsApproved,
+			IsAdminReply: comment.IsAdminReply,
+			CreatedAt:    comment.CreatedAt,
+		}
+		nodes[comment.CommentID] = node
+		if comment.ParentID != nil {
+			if parent, ok := nodes[*comment.ParentID]; ok {
+				parent.Replies = append(parent.Replies, node)
+				continue
+			}
+		}
+		threads = append(threads, node)
+	}
+
+	e.manifest.Comments += len(comments)
+	return e.writeJSON("comments/"+name+".json", threads, comments[len(comments)-1].CreatedAt)
+}
+
+// categoryPath 返回从顶级分类到该分类的名称路径
+func (e *siteExporter) categoryPath(categoryID int) exportPath {
+	var names exportPath
+	seen := make(map[int]bool)
+	for id := categoryID; !seen[id]; {
+		category, ok := e.categories[id]
+		if !ok {
+			break
+		}
+		seen[id] = true
+		names = append(exportPath{category.CategoryName}, names...)
+		if category.ParentID == nil {
+			break
+		}
+		id = *category.ParentID
+	}
+	return names
+}
+
+// collectFiles 记录内容中引用的上传文件，在当前批次结束时写入
+func (e *siteExporter) collectFiles(contents ...string) {
+	if e.opts.SkipFiles {
+		return
+	}
+	for _, content := range contents {
+		for _, m := range e.uploadRef.FindAllStringSubmatch(content, -1) {
+			p := path.Clean(m[1])
+			if !fs.ValidPath(p) || e.seenFiles[p] {
+				continue
+			}
+			e.seenFiles[p] = true
+			e.pending = append(e.pending, p)
+		}
+	}
+}
+
+// flushFiles 写入当前批次引用的上传文件
+func (e *siteExporter) flushFiles() error {
+	if len(e.pending) == 0 {
+		return nil
+	}
+	pending := e.pending
+	e.pending = nil
+
+	var records []model.File
+	if err := model.DB.Where("file_path IN ?", pending).Find(&records).Error; err != nil {
+		return err
+	}
+	byPath := make(map[string]model.File, len(records))
+	for _, record := range records {
+		byPath[filepath.ToSlash(record.FilePath)] = record
+	}
+
+	for _, p := range pending {
+		written, err := e.writeUpload(p)
+		if err != nil {
+			return err
+		}
+		if !written {
+			e.manifest.MissingFiles = append(e.manifest.MissingFiles, p)
+			continue
+		}
+		e.manifest.Files++
+		if record, ok := byPath[p]; ok {
+			e.files = append(e.files, model.ExportFile{
+				FileID:       record.FileID,
+				OriginalName: record.OriginalName,
+				FilePath:     p,
+				FileSize:     record.FileSize,
+				MimeType:     record.MimeType,
+				IsPublic:     record.IsPublic,
+				CreatedAt:    record.CreatedAt,
+			})
+		}
+	}
+	return nil
+}
+
+// writeUpload 将上传目录中的文件写入压缩包，文件不存在时返回 false
+func (e *siteExporter) writeUpload(p string) (bool, error) {
+	f, err := os.Open(filepath.Join(e.uploadDir, filepath.FromSlash(p)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	if info.IsDir() {
+		return false, nil
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return false, err
+	}
+	header.Name = "uploads/" + p
+	// 图片等文件大多已压缩，直接存储
+	header.Method = zip.Store
+	dst, err := e.zw.CreateHeader(header)
+	if err != nil {
+		return false, err
+	}
+	if _, err := io.Copy(dst, f); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// writeMarkdown 写入带YAML前置元数据的Markdown文件
+func (e *siteExporter) writeMarkdown(name string, matter interface{}, body string, modified time.Time) error {
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(matter); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	buf.WriteString("---\n\n")
+	buf.WriteString(body)
+	if !strings.HasSuffix(body, "\n") {
+		buf.WriteString("\n")
+	}
+	return e.writeEntry(name, buf.Bytes(), modified)
+}
+
+// writeJSON 写入JSON文件
+func (e *siteExporter) writeJSON(name string, v interface{}, modified time.Time) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return e.writeEntry(name, data, modified)
+}
+
+// writeEntry 写入压缩包中的文件
+func (e *siteExporter) writeEntry(name string, data []byte, modified time.Time) error {
+	dst, err := e.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = dst.Write(data)
+	return err
+}
+
+// flush 将已写入的内容推送给客户端
+func (e *siteExporter) flush() error {
+	if err := e.zw.Flush(); err != nil {
+		return err
+	}
+	if f, ok := e.w.(interface{ Flush() }); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// exportFormatName 正文格式名称
+func exportFormatName(format int8) string {
+	if format == render.FormatHTML {
+		return "html"
+	}
+	return "markdown"
+}
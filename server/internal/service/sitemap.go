@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/sitemap"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 站点地图参数
+const (
+	// sitemapPageSize 每个分页覆盖的记录ID数量，分页按ID区间划分，记录变更只影响所在分页
+	sitemapPageSize = 5000
+	// SitemapIndexPath 站点地图索引地址
+	SitemapIndexPath = "/sitemap.xml"
+	// SitemapPagePrefix 分页站点地图地址前缀，完整地址如 /sitemaps/article-1.xml
+	SitemapPagePrefix = "/sitemaps/"
+)
+
+// 站点地图缓存，内容变更时按分页删除，过期时间用于兜底
+const (
+	sitemapIndexCacheKey = "blog:sitemap:index"
+	sitemapPageCacheKey  = "blog:sitemap:%s:%d"
+	sitemapCacheTTL      = 24 * time.Hour
+)
+
+// robots.txt
+const (
+	robotsConfigKey  = "robots_txt"
+	defaultRobotsTxt = "User-agent: *\nAllow: /\nDisallow: /admin/\nDisallow: /api/\n"
+)
+
+// ErrSitemapNotFound 分页站点地图不存在
+var ErrSitemapNotFound = errors.New("站点地图不存在")
+
+// sitemapSource 站点地图数据来源
+type sitemapSource struct {
+	table     string        // 表名
+	idColumn  string        // 主键列，用于划分分页
+	keyColumn string        // 页面标识列
+	condition string        // 可被收录的条件
+	args      []interface{} // 条件参数
+	path      string        // 页面路径前缀
+}
+
+// sitemapTypes 站点地图类型，按索引中的输出顺序排列
+var sitemapTypes = []string{model.SitemapTypeArticle, model.SitemapTypeCategory, model.SitemapTypeTag}
+
+var sitemapSources = map[string]sitemapSource{
+	model.SitemapTypeArticle: {
+		table:     "cms_articles",
+		idColumn:  "article_id",
+		keyColumn: "article_key",
+		condition: "status = ? AND publish_time <= NOW()",
+		args:      []interface{}{model.ArticleStatusPublished},
+		path:      SiteArticlePath,
+	},
+	model.SitemapTypeCategory: {
+		table:     "cms_categories",
+		idColumn:  "category_id",
+		keyColumn: "category_key",
+		condition: "is_visible = ?",
+		args:      []interface{}{true},
+		path:      SiteCategoryPath,
+	},
+	model.SitemapTypeTag: {
+		table:     "cms_tags",
+		idColumn:  "tag_id",
+		keyColumn: "tag_key",
+		condition: "is_visible = ?",
+		args:      []interface{}{true},
+		path:      SiteTagPath,
+	},
+}
+
+// BuildSitemapIndex 生成站点地图索引，列出所有非空分页
+func BuildSitemapIndex(requestBaseURL string) ([]sitemap.Sitemap, error) {
+	baseURL, err := SiteBaseURL(requestBaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	pages, err := getSitemapPages()
+	if err != nil {
+		return nil, err
+	}
+
+	sitemaps := make([]sitemap.Sitemap, 0, len(pages))
+	for _, page := range pages {
+		sitemaps = append(sitemaps, sitemap.Sitemap{
+			Loc:     baseURL + SitemapPagePath(page.Type, page.Page),
+			LastMod: page.LastMod,
+		})
+	}
+	return sitemaps, nil
+}
+
+// BuildSitemap 生成指定类型的分页站点地图
+func BuildSitemap(sitemapType string, page int, requestBaseURL string) ([]sitemap.URL, error) {
+	if _, ok := sitemapSources[sitemapType]; !ok || page < 1 {
+		return nil, ErrSitemapNotFound
+	}
+
+	baseURL, err := SiteBaseURL(requestBaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := getSitemapPage(sitemapType, page)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, ErrSitemapNotFound
+	}
+
+	urls := make([]sitemap.URL, 0, len(entries))
+	for _, entry := range entries {
+		u := sitemap.URL{Loc: baseURL + entry.Path, LastMod: entry.LastMod}
+		for _, image := range entry.Images {
+			u.Images = append(u.Images, SiteAbsURL(baseURL, image))
+		}
+		urls = append(urls, u)
+	}
+	return urls, nil
+}
+
+// SitemapPagePath 分页站点地图地址
+func SitemapPagePath(sitemapType string, page int) string {
+	return fmt.Sprintf("%s%s-%d.xml", SitemapPagePrefix, sitemapType, page)
+}
+
+// InvalidateArticleSitemap 文章新增、修改、删除或状态变化后调用，只清除文章所在分页和索引的缓存
+func InvalidateArticleSitemap(articleID int64) {
+	page := int((articleID-1)/sitemapPageSize) + 1
+	keys := []string{sitemapIndexCacheKey, fmt.Sprintf(sitemapPageCacheKey, model.SitemapTypeArticle, page)}
+	if err := model.RDB.Del(context.Background(), keys...).Err(); err != nil {
+		zap.L().Warn("删除站点地图缓存失败", zap.Int64("article_id", articleID), zap.Error(err))
+	}
+}
+
+// InvalidateSitemap 清除指定类型全部分页和索引的缓存，用于分类、标签等数量较少的类型
+func InvalidateSitemap(sitemapType string) {
+	ctx := context.Background()
+	keys := []string{sitemapIndexCacheKey}
+	iter := model.RDB.Scan(ctx, 0, fmt.Sprintf("blog:sitemap:%s:*", sitemapType), 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		zap.L().Warn("查询站点地图缓存失败", zap.String("type", sitemapType), zap.Error(err))
+	}
+	if err := model.RDB.Del(ctx, keys...).Err(); err != nil {
+		zap.L().Warn("删除站点地图缓存失败", zap.String("type", sitemapType), zap.Error(err))
+	}
+}
+
+// GetRobotsTxt 获取robots.txt内容，规则来自系统配置，未声明站点地图时自动追加
+func GetRobotsTxt(requestBaseURL string) (string, error) {
+	baseURL, err := SiteBaseURL(requestBaseURL)
+	if err != nil {
+		return "", err
+	}
+
+	content := defaultRobotsTxt
+	var config model.SysConfig
+	if err := model.DB.Where("config_key = ?", robotsConfigKey).First(&config).Error; err == nil {
+		content = config.ConfigValue
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	content = strings.TrimRight(strings.ReplaceAll(content, "\r\n", "\n"), "\n") + "\n"
+	if !strings.Contains(strings.ToLower(content), "sitemap:") {
+		content += "\nSitemap: " + baseURL + SitemapIndexPath + "\n"
+	}
+	return content, nil
+}
+
+// getSitemapPages 获取所有非空分页及其最后修改时间，优先读取缓存
+func getSitemapPages() ([]model.SitemapPage, error) {
+	var pages []model.SitemapPage
+	if getSitemapCache(sitemapIndexCacheKey, &pages) {
+		return pages, nil
+	}
+
+	pages = make([]model.SitemapPage, 0)
+	for _, sitemapType := range sitemapTypes {
+		source := sitemapSources[sitemapType]
+		var rows []struct {
+			Page    int
+			LastMod time.Time
+		}
+		if err := model.DB.Table(source.table).
+			Select(fmt.Sprintf("(%s - 1) / %d + 1 AS page, MAX(updated_at) AS last_mod", source.idColumn, sitemapPageSize)).
+			Where(source.condition, source.args...).
+			Group("page").
+			Order("page").
+			Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			pages = append(pages, model.SitemapPage{Type: sitemapType, Page: row.Page, LastMod: row.LastMod})
+		}
+	}
+
+	setSitemapCache(sitemapIndexCacheKey, pages)
+	return pages, nil
+}
+
+// getSitemapPage 获取分页中的页面，优先读取缓存
+func getSitemapPage(sitemapType string, page int) ([]model.SitemapURL, error) {
+	key := fmt.Sprintf(sitemapPageCacheKey, sitemapType, page)
+	var entries []model.SitemapURL
+	if getSitemapCache(key, &entries) {
+		return entries, nil
+	}
+
+	source := sitemapSources[sitemapType]
+	var rows []struct {
+		PageKey   string
+		Thumbnail string
+		UpdatedAt time.Time
+	}
+	if err := model.DB.Table(source.table).
+		Select(source.keyColumn+" AS page_key, thumbnail, updated_at").
+		Where(source.condition, source.args...).
+		Where(source.idColumn+" BETWEEN ? AND ?", (page-1)*sitemapPageSize+1, page*sitemapPageSize).
+		Order(source.idColumn).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	entries = make([]model.SitemapURL, 0, len(rows))
+	for _, row := range rows {
+		entry := model.SitemapURL{
+			Path:    source.path + url.PathEscape(row.PageKey),
+			LastMod: row.UpdatedAt,
+		}
+		if row.Thumbnail != "" {
+			entry.Images = []string{row.Thumbnail}
+		}
+		entries = append(entries, entry)
+	}
+
+	setSitemapCache(key, entries)
+	return entries, nil
+}
+
+// getSitemapCache 读取站点地图缓存，未命中或读取失败返回false
+func getSitemapCache(key string, v interface{}) bool {
+	data, err := model.RDB.Get(context.Background(), key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			zap.L().Warn("读取站点地图缓存失败", zap.String("key", key), zap.Error(err))
+		}
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+// setSitemapCache 写入站点地图缓存，失败不影响返回结果
+func setSitemapCache(key string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	if err := model.RDB.Set(context.Background(), key, data, sitemapCacheTTL).Err(); err != nil {
+		zap.L().Warn("写入站点地图缓存失败", zap.String("key", key), zap.Error(err))
+	}
+}
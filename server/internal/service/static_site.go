@@ -0,0 +1,812 @@
+package service
+
+import (
+	"errors"
+	"html/template"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/config"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/feed"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/sitemap"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/staticsite"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 静态站点默认配置
+const (
+	defaultStaticOutputDir = "./public"
+	defaultStaticPageSize  = 10
+	// staticArchivePath 归档页路径，月份归档如 /archives/2006/01
+	staticArchivePath = "/archives"
+)
+
+// ErrStaticSiteNotInitialized 静态站点生成未初始化
+var ErrStaticSiteNotInitialized = errors.New("静态站点生成未初始化")
+
+// staticFeedFormats 订阅源文件名及编码方式
+var staticFeedFormats = []struct {
+	name   string
+	encode func(*feed.Feed) ([]byte, error)
+}{
+	{"feed.xml", feed.RSS},
+	{"atom.xml", feed.Atom},
+	{"feed.json", feed.JSON},
+}
+
+// staticGenerator 静态站点生成器，同一时间只执行一个生成任务
+type staticGenerator struct {
+	mu       sync.Mutex
+	cfg      config.StaticConfig
+	renderer *staticsite.Renderer
+	dir      staticsite.Dir
+}
+
+// staticSite 静态站点生成器，未初始化时为空
+var staticSite *staticGenerator
+
+// InitStaticSite 加载静态站点模板，模板有误时返回错误
+func InitStaticSite(cfg config.StaticConfig) error {
+	if cfg.OutputDir == "" {
+		cfg.OutputDir = defaultStaticOutputDir
+	}
+	if cfg.PageSize <= 0 {
+		cfg.PageSize = defaultStaticPageSize
+	}
+	renderer, err := staticsite.NewRenderer(cfg.TemplateDir)
+	if err != nil {
+		return err
+	}
+	staticSite = &staticGenerator{
+		cfg:      cfg,
+		renderer: renderer,
+		dir:      staticsite.Dir(cfg.OutputDir),
+	}
+	return nil
+}
+
+// staticBuild 一次生成任务
+type staticBuild struct {
+	g       *staticGenerator
+	site    *staticsite.Site
+	baseURL string
+	now     time.Time
+	result  *model.StaticBuildResult
+	// 全部分类，按排序值排列
+	categories []model.Category
+	// 分类ID对应的分类，用于查找上级分类
+	categoryByID map[int]model.Category
+}
+
+// staticList 文章列表页
+type staticList struct {
+	path        string // 第一页路径，不以 / 结尾，首页为空
+	title       string
+	description string
+	feedPath    string
+	top         bool // 置顶文章排在前面
+	where       func(db *gorm.DB) *gorm.DB
+}
+
+// BuildStaticSite 全量生成静态站点：文章页、首页、分类、标签、归档、订阅源和站点地图，并删除过期页面
+func BuildStaticSite() (*model.StaticBuildResult, error) {
+	if staticSite == nil {
+		return nil, ErrStaticSiteNotInitialized
+	}
+	g := staticSite
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	start := time.Now()
+	b, err := g.newBuild()
+	if err != nil {
+		return nil, err
+	}
+	if err := b.buildAll(); err != nil {
+		return nil, err
+	}
+	b.result.DurationMs = time.Since(start).Milliseconds()
+	zap.L().Info("静态站点生成完成",
+		zap.Int("pages", b.result.Pages),
+		zap.Int("removed", b.result.Removed),
+		zap.Int64("duration_ms", b.result.DurationMs),
+	)
+	return b.result, nil
+}
+
+// SnapshotStaticArticle 记录文章变更前关联的静态页面，未开启自动生成时返回空
+func SnapshotStaticArticle(articleID int64) *model.StaticArticleRefs {
+	if staticSite == nil || !staticSite.cfg.Enabled {
+		return nil
+	}
+	refs, err := staticArticleRefs(articleID, time.Now())
+	if err != nil {
+		zap.L().Warn("读取文章静态页面关联失败", zap.Int64("article_id", articleID), zap.Error(err))
+		return nil
+	}
+	return refs
+}
+
+// RebuildStaticArticle 文章新增、修改、删除或状态变化后在后台增量生成受影响的页面：
+// 文章页、首页、文章变更前后所属的分类（含上级分类）和标签、发布月份的归档、对应的订阅源和站点地图。
+// before 为变更前通过 SnapshotStaticArticle 记录的关联，新增文章时传入空关联；
+// 为 nil 时表示变更前状态未知，按关联不变且可能已发布处理，如定时下线。
+func RebuildStaticArticle(articleID int64, before *model.StaticArticleRefs) {
+	if staticSite == nil || !staticSite.cfg.Enabled {
+		return
+	}
+	g := staticSite
+	go func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+
+		b, err := g.newBuild()
+		if err == nil {
+			err = b.buildArticleChange(articleID, before)
+		}
+		if err != nil {
+			zap.L().Error("增量生成静态页面失败", zap.Int64("article_id", articleID), zap.Error(err))
+			return
+		}
+		zap.L().Debug("增量生成静态页面完成", zap.Int64("article_id", articleID), zap.Int("pages", b.result.Pages))
+	}()
+}
+
+// newBuild 读取站点信息，开始一次生成任务
+func (g *staticGenerator) newBuild() (*staticBuild, error) {
+	info, err := GetSiteInfo()
+	if err != nil {
+		return nil, err
+	}
+	baseURL := info.URL
+	if baseURL == "" {
+		baseURL = strings.TrimRight(g.cfg.BaseURL, "/")
+	}
+
+	var categories []model.Category
+	if err := model.DB.Order("sort_order ASC, category_id ASC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	b := &staticBuild{
+		g: g,
+		site: &staticsite.Site{
+			Name:        info.Name,
+			Description: info.Description,
+			Keywords:    info.Keywords,
+			URL:         baseURL,
+			Logo:        SiteAbsURL(baseURL, info.Logo),
+			Language:    info.Language,
+			Copyright:   info.Copyright,
+		},
+		baseURL:      baseURL,
+		now:          time.Now(),
+		result:       &model.StaticBuildResult{},
+		categories:   categories,
+		categoryByID: make(map[int]model.Category, len(categories)),
+	}
+	for _, category := range categories {
+		b.categoryByID[category.CategoryID] = category
+	}
+	return b, nil
+}
+
+// buildAll 全量生成
+func (b *staticBuild) buildAll() error {
+	// 文章页
+	keep := make(map[string]bool)
+	var lastID int64
+	for {
+		var articles []model.Article
+		if err := b.preloadArticles(b.published()).
+			Where("cms_articles.article_id > ?", lastID).
+			Order("cms_articles.article_id ASC").
+			Limit(exportBatchSize).
+			Find(&articles).Error; err != nil {
+			return err
+		}
+		for _, article := range articles {
+			if err := b.buildArticle(article); err != nil {
+				return err
+			}
+			keep[article.ArticleKey] = true
+			lastID = article.ArticleID
+		}
+		if len(articles) < exportBatchSize {
+			break
+		}
+	}
+	if err := b.removeExcept(SiteArticlePath, keep); err != nil {
+		return err
+	}
+
+	// 首页和全站订阅源
+	if err := b.buildList(b.homeList()); err != nil {
+		return err
+	}
+	if err := b.buildFeeds(model.FeedScopeSite, "", ""); err != nil {
+		return err
+	}
+
+	// 分类
+	keep = make(map[string]bool)
+	for _, category := range b.categories {
+		if !category.IsVisible {
+			continue
+		}
+		if err := b.buildCategory(category); err != nil {
+			return err
+		}
+		keep[category.CategoryKey] = true
+	}
+	if err := b.removeExcept(SiteCategoryPath, keep); err != nil {
+		return err
+	}
+
+	// 标签
+	var tags []model.Tag
+	if err := model.DB.Where("is_visible = ?", true).Find(&tags).Error; err != nil {
+		return err
+	}
+	keep = make(map[string]bool)
+	for _, tag := range tags {
+		if err := b.buildTag(tag); err != nil {
+			return err
+		}
+		keep[tag.TagKey] = true
+	}
+	if err := b.removeExcept(SiteTagPath, keep); err != nil {
+		return err
+	}
+
+	// 归档
+	if err := b.buildArchives(nil); err != nil {
+		return err
+	}
+
+	return b.buildSitemaps(nil)
+}
+
+// buildArticleChange 增量生成文章变更影响的页面
+func (b *staticBuild) buildArticleChange(articleID int64, before *model.StaticArticleRefs) error {
+	after, err := staticArticleRefs(articleID, b.now)
+	if err != nil {
+		return err
+	}
+	if after == nil {
+		after = &model.StaticArticleRefs{}
+	}
+	if before == nil {
+		refs := *after
+		refs.Published = true
+		before = &refs
+	}
+
+	// 文章页：未发布或标识变化时删除旧页面
+	if before.Published && (!after.Published || before.ArticleKey != after.ArticleKey) {
+		if err := b.g.dir.RemoveAll(SiteArticlePath + before.ArticleKey); err != nil {
+			return err
+		}
+		b.result.Removed++
+	}
+	if after.Published {
+		var article model.Article
+		if err := b.preloadArticles(model.DB.Model(&model.Article{})).
+			Where("cms_articles.article_id = ?", articleID).
+			First(&article).Error; err != nil {
+			return err
+		}
+		if err := b.buildArticle(article); err != nil {
+			return err
+		}
+	}
+
+	// 列表页、归档和订阅源只在文章发布前后有一方可见时需要更新
+	if !before.Published && !after.Published {
+		return nil
+	}
+	if err := b.buildList(b.homeList()); err != nil {
+		return err
+	}
+	if err := b.buildFeeds(model.FeedScopeSite, "", ""); err != nil {
+		return err
+	}
+
+	// 分类列表包含子分类的文章，上级分类也需要更新
+	categoryIDs := make(map[int]bool)
+	for _, id := range append(append([]int{}, before.CategoryIDs...), after.CategoryIDs...) {
+		for seen := make(map[int]bool); !seen[id]; {
+			category, ok := b.categoryByID[id]
+			if !ok {
+				break
+			}
+			seen[id] = true
+			categoryIDs[id] = true
+			if category.ParentID == nil {
+				break
+			}
+			id = *category.ParentID
+		}
+	}
+	for id := range categoryIDs {
+		if category := b.categoryByID[id]; category.IsVisible {
+			if err := b.buildCategory(category); err != nil {
+				return err
+			}
+		}
+	}
+
+	tagIDs := append(append([]int{}, before.TagIDs...), after.TagIDs...)
+	if len(tagIDs) > 0 {
+		var tags []model.Tag
+		if err := model.DB.Where("tag_id IN ? AND is_visible = ?", tagIDs, true).Find(&tags).Error; err != nil {
+			return err
+		}
+		for _, tag := range tags {
+			if err := b.buildTag(tag); err != nil {
+				return err
+			}
+		}
+	}
+
+	months := make(map[string]bool)
+	for _, month := range []string{before.Month, after.Month} {
+		if month != "" {
+			months[month] = true
+		}
+	}
+	if err := b.buildArchives(months); err != nil {
+		return err
+	}
+
+	return b.buildSitemaps(&articleID)
+}
+
+// staticArticleRefs 读取文章当前关联的静态页面，文章不存在时返回空
+func staticArticleRefs(articleID int64, now time.Time) (*model.StaticArticleRefs, error) {
+	var article model.Article
+	if err := model.DB.Select("article_id, article_key, status, publish_time").
+		Where("article_id = ?", articleID).
+		First(&article).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	refs := &model.StaticArticleRefs{
+		ArticleKey: article.ArticleKey,
+		Published:  article.Status == model.ArticleStatusPublished && !article.PublishTime.After(now),
+	}
+	if !article.PublishTime.IsZero() {
+		refs.Month = article.PublishTime.Format("2006-01")
+	}
+	if err := model.DB.Table("cms_article_categories").
+		Where("article_id = ?", articleID).
+		Pluck("category_id", &refs.CategoryIDs).Error; err != nil {
+		return nil, err
+	}
+	if err := model.DB.Table("cms_article_tags").
+		Where("article_id = ?", articleID).
+		Pluck("tag_id", &refs.TagIDs).Error; err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+// published 已发布且到达发布时间的文章
+func (b *staticBuild) published() *gorm.DB {
+	return model.DB.Model(&model.Article{}).
+		Where("cms_articles.status = ? AND cms_articles.publish_time <= ?", model.ArticleStatusPublished, b.now)
+}
+
+// preloadArticles 预加载作者、分类和标签
+func (b *staticBuild) preloadArticles(db *gorm.DB) *gorm.DB {
+	return db.Preload("User", func(db *gorm.DB) *gorm.DB {
+		return db.Select("user_id, username, nickname")
+	}).
+		Preload("Categories").
+		Preload("Tags")
+}
+
+// buildArticle 生成文章页
+func (b *staticBuild) buildArticle(article model.Article) error {
+	rendered, err := GetArticleRender(int(article.ArticleID))
+	if err != nil {
+		return err
+	}
+	page := b.page(SiteArticlePath + url.PathEscape(article.ArticleKey))
+	page.Title = article.Title
+	page.Description = article.SEODescription
+	if page.Description == "" {
+		page.Description = article.Summary
+	}
+	page.Article = &staticsite.Article{
+		ArticleItem: b.articleItem(article),
+		ContentHTML: template.HTML(rendered.ContentHTML),
+		TOC:         rendered.TOC,
+		WordCount:   rendered.WordCount,
+		ReadingTime: rendered.ReadingTime,
+		UpdatedAt:   article.UpdatedAt,
+		SourceURL:   article.SourceURL,
+		SourceName:  article.SourceName,
+	}
+	return b.writePage(SiteArticlePath+article.ArticleKey+"/", staticsite.TemplateArticle, page)
+}
+
+// homeList 首页
+func (b *staticBuild) homeList() staticList {
+	return staticList{top: true}
+}
+
+// buildCategory 生成分类列表页和订阅源，列表包含子分类的文章
+func (b *staticBuild) buildCategory(category model.Category) error {
+	base := SiteCategoryPath + category.CategoryKey
+	list := staticList{
+		path:        base,
+		title:       category.CategoryName,
+		description: category.Description,
+		feedPath:    base + "/feed.xml",
+		where: func(db *gorm.DB) *gorm.DB {
+			return db.Where(`EXISTS (
+				SELECT 1 FROM cms_article_categories ac
+				JOIN cms_categories cat ON cat.category_id = ac.category_id
+				WHERE ac.article_id = cms_articles.article_id AND cat.path <@ ?::ltree)`, category.Path)
+		},
+	}
+	if err := b.buildList(list); err != nil {
+		return err
+	}
+	return b.buildFeeds(model.FeedScopeCategory, category.CategoryKey, base)
+}
+
+// buildTag 生成标签列表页和订阅源
+func (b *staticBuild) buildTag(tag model.Tag) error {
+	base := SiteTagPath + tag.TagKey
+	list := staticList{
+		path:        base,
+		title:       tag.TagName,
+		description: tag.Description,
+		feedPath:    base + "/feed.xml",
+		where: func(db *gorm.DB) *gorm.DB {
+			return db.Where("EXISTS (SELECT 1 FROM cms_article_tags t WHERE t.article_id = cms_articles.article_id AND t.tag_id = ?)", tag.TagID)
+		},
+	}
+	if err := b.buildList(list); err != nil {
+		return err
+	}
+	return b.buildFeeds(model.FeedScopeTag, tag.TagKey, base)
+}
+
+// buildArchives 生成归档首页和月份归档，months 为空时生成全部月份并删除过期月份
+func (b *staticBuild) buildArchives(months map[string]bool) error {
+	archives, err := GetArticleArchives()
+	if err != nil {
+		return err
+	}
+
+	page := b.page(staticArchivePath + "/")
+	page.Title = "归档"
+	existing := make(map[string]bool, len(archives))
+	for _, archive := range archives {
+		existing[archive.Month] = true
+		page.Archives = append(page.Archives, staticsite.Link{
+			Name:  archive.Month,
+			URL:   b.baseURL + staticMonthPath(archive.Month),
+			Count: archive.ArticleCount,
+		})
+	}
+	for _, category := range b.categories {
+		if category.IsVisible && category.ArticleCount > 0 {
+			page.Categories = append(page.Categories, b.categoryLink(category))
+		}
+	}
+	var tags []model.Tag
+	if err := model.DB.Where("is_visible = ? AND article_count > 0", true).
+		Order("article_count DESC, tag_id ASC").
+		Find(&tags).Error; err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		link := b.tagLink(tag)
+		link.Count = tag.ArticleCount
+		page.Tags = append(page.Tags, link)
+	}
+	if err := b.writePage(staticArchivePath+"/", staticsite.TemplateArchives, page); err != nil {
+		return err
+	}
+
+	// 月份归档，增量生成时文章已移出的月份也需要重新生成或删除
+	all := months == nil
+	if all {
+		months = existing
+	}
+	for month := range months {
+		if !existing[month] {
+			if err := b.g.dir.RemoveAll(staticMonthPath(month)); err != nil {
+				return err
+			}
+			b.result.Removed++
+			continue
+		}
+		list := staticList{
+			path:  staticMonthPath(month),
+			title: strings.Replace(month, "-", "年", 1) + "月",
+			where: func(db *gorm.DB) *gorm.DB {
+				return db.Where("TO_CHAR(cms_articles.publish_time, 'YYYY-MM') = ?", month)
+			},
+		}
+		if err := b.buildList(list); err != nil {
+			return err
+		}
+	}
+	if !all {
+		return nil
+	}
+
+	// 删除过期的年份和月份
+	years := make(map[string]map[string]bool)
+	for month := range existing {
+		year, m, _ := strings.Cut(month, "-")
+		if years[year] == nil {
+			years[year] = make(map[string]bool)
+		}
+		years[year][m] = true
+	}
+	keep := make(map[string]bool, len(years)+1)
+	keep["index.html"] = true
+	for year := range years {
+		keep[year] = true
+	}
+	if err := b.removeExcept(staticArchivePath, keep); err != nil {
+		return err
+	}
+	for year, ms := range years {
+		if err := b.removeExcept(staticArchivePath+"/"+year, ms); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// staticMonthPath 月份归档路径，如 /archives/2006/01
+func staticMonthPath(month string) string {
+	return staticArchivePath + "/" + strings.Replace(month, "-", "/", 1)
+}
+
+// buildList 生成文章列表的全部分页，并删除多余的分页
+func (b *staticBuild) buildList(list staticList) error {
+	query := func() *gorm.DB {
+		db := b.published()
+		if list.where != nil {
+			db = list.where(db)
+		}
+		return db
+	}
+
+	var total int64
+	if err := query().Count(&total).Error; err != nil {
+		return err
+	}
+	pageSize := b.g.cfg.PageSize
+	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	order := "cms_articles.publish_time DESC, cms_articles.article_id DESC"
+	if list.top {
+		order = "cms_articles.is_top DESC, " + order
+	}
+	for n := 1; n <= totalPages; n++ {
+		var articles []model.Article
+		if err := b.preloadArticles(query()).
+			Order(order).
+			Offset((n - 1) * pageSize).
+			Limit(pageSize).
+			Find(&articles).Error; err != nil {
+			return err
+		}
+
+		page := b.page(staticPagePath(list.path, n))
+		page.Title = list.title
+		page.Description = list.description
+		if list.feedPath != "" {
+			page.FeedURL = b.baseURL + list.feedPath
+		}
+		for _, article := range articles {
+			page.Articles = append(page.Articles, b.articleItem(article))
+		}
+		page.Pagination = &staticsite.Pagination{Page: n, TotalPages: totalPages}
+		if n > 1 {
+			page.Pagination.PrevURL = b.baseURL + staticPagePath(list.path, n-1)
+		}
+		if n < totalPages {
+			page.Pagination.NextURL = b.baseURL + staticPagePath(list.path, n+1)
+		}
+		if err := b.writePage(staticPagePath(list.path, n)+"/", staticsite.TemplateList, page); err != nil {
+			return err
+		}
+	}
+
+	removed, err := b.g.dir.RemovePagesAfter(list.path, totalPages)
+	b.result.Removed += removed
+	return err
+}
+
+// staticPagePath 列表分页路径，第一页为列表路径本身，首页为 /
+func staticPagePath(base string, n int) string {
+	if n <= 1 {
+		if base == "" {
+			return "/"
+		}
+		return base
+	}
+	return base + "/page/" + strconv.Itoa(n)
+}
+
+// buildFeeds 生成 RSS、Atom 和 JSON Feed 订阅源，分类或标签不可见时跳过
+func (b *staticBuild) buildFeeds(scope, key, base string) error {
+	f, err := BuildFeed(model.FeedQuery{
+		Scope:    scope,
+		Key:      key,
+		BaseURL:  b.baseURL,
+		FeedPath: base + "/" + staticFeedFormats[0].name,
+	})
+	if errors.Is(err, ErrFeedNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, format := range staticFeedFormats {
+		f.ID = b.baseURL + base + "/" + format.name
+		f.FeedURL = f.ID
+		data, err := format.encode(f)
+		if err != nil {
+			return err
+		}
+		if err := b.writeFile(base+"/"+format.name, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildSitemaps 生成站点地图索引、分页站点地图和robots.txt，
+// articleID 不为空时只更新文章所在的分页
+func (b *staticBuild) buildSitemaps(articleID *int64) error {
+	sitemaps, err := BuildSitemapIndex(b.baseURL)
+	if err != nil {
+		return err
+	}
+	data, err := sitemap.Index(sitemaps)
+	if err != nil {
+		return err
+	}
+	if err := b.writeFile(SitemapIndexPath, data); err != nil {
+		return err
+	}
+
+	var pages []model.SitemapPage
+	if articleID != nil {
+		pages = []model.SitemapPage{{Type: model.SitemapTypeArticle, Page: int((*articleID-1)/sitemapPageSize) + 1}}
+	} else {
+		if pages, err = getSitemapPages(); err != nil {
+			return err
+		}
+	}
+
+	keep := make(map[string]bool, len(pages))
+	for _, page := range pages {
+		p := SitemapPagePath(page.Type, page.Page)
+		urls, err := BuildSitemap(page.Type, page.Page, b.baseURL)
+		if errors.Is(err, ErrSitemapNotFound) {
+			// 分页中的文章都已下线
+			if err := b.g.dir.RemoveAll(p); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if data, err = sitemap.URLSet(urls); err != nil {
+			return err
+		}
+		if err := b.writeFile(p, data); err != nil {
+			return err
+		}
+		keep[strings.TrimPrefix(p, SitemapPagePrefix)] = true
+	}
+	if articleID != nil {
+		return nil
+	}
+	if err := b.removeExcept(SitemapPagePrefix, keep); err != nil {
+		return err
+	}
+
+	robots, err := GetRobotsTxt(b.baseURL)
+	if err != nil {
+		return err
+	}
+	return b.writeFile("/robots.txt", []byte(robots))
+}
+
+// page 创建页面数据
+func (b *staticBuild) page(path string) *staticsite.Page {
+	return &staticsite.Page{
+		Site:        b.site,
+		Path:        path,
+		GeneratedAt: b.now,
+	}
+}
+
+// articleItem 将文章转换为列表项
+func (b *staticBuild) articleItem(article model.Article) staticsite.ArticleItem {
+	item := staticsite.ArticleItem{
+		Title:       article.Title,
+		URL:         SiteArticleURL(b.baseURL, article.ArticleKey),
+		Summary:     article.Summary,
+		Thumbnail:   SiteAbsURL(b.baseURL, article.Thumbnail),
+		Author:      article.User.Nickname,
+		PublishTime: article.PublishTime,
+		IsTop:       article.IsTop,
+	}
+	if item.Author == "" {
+		item.Author = article.User.Username
+	}
+	for _, category := range article.Categories {
+		item.Categories = append(item.Categories, b.categoryLink(category))
+	}
+	for _, tag := range article.Tags {
+		item.Tags = append(item.Tags, b.tagLink(tag))
+	}
+	return item
+}
+
+// categoryLink 分类链接
+func (b *staticBuild) categoryLink(category model.Category) staticsite.Link {
+	return staticsite.Link{
+		Name:  category.CategoryName,
+		URL:   b.baseURL + SiteCategoryPath + url.PathEscape(category.CategoryKey),
+		Count: category.ArticleCount,
+	}
+}
+
+// tagLink 标签链接
+func (b *staticBuild) tagLink(tag model.Tag) staticsite.Link {
+	return staticsite.Link{
+		Name: tag.TagName,
+		URL:  b.baseURL + SiteTagPath + url.PathEscape(tag.TagKey),
+	}
+}
+
+// writePage 渲染并写入页面
+func (b *staticBuild) writePage(path, name string, page *staticsite.Page) error {
+	data, err := b.g.renderer.Render(name, page)
+	if err != nil {
+		return err
+	}
+	return b.writeFile(path, data)
+}
+
+// writeFile 写入文件
+func (b *staticBuild) writeFile(path string, data []byte) error {
+	if err := b.g.dir.WriteFile(path, data); err != nil {
+		return err
+	}
+	b.result.Pages++
+	return nil
+}
+
+// removeExcept 删除目录下不再需要的页面
+func (b *staticBuild) removeExcept(path string, keep map[string]bool) error {
+	removed, err := b.g.dir.RemoveExcept(path, keep)
+	b.result.Removed += removed
+	return err
+}
@@ -37,6 +37,8 @@ func CreateTag(form model.TagCreateForm) (int, error) {
 		return 0, err
 	}
 
+	InvalidateSitemap(model.SitemapTypeTag)
+
 	return tag.TagID, nil
 }
 
@@ -90,6 +92,8 @@ func UpdateTag(tagID int, form model.TagUpdateForm) error {
 		return err
 	}
 
+	InvalidateSitemap(model.SitemapTypeTag)
+
 	return nil
 }
 
@@ -179,6 +183,8 @@ func DeleteTag(tagID int) error {
 		return err
 	}
 
+	InvalidateSitemap(model.SitemapTypeTag)
+
 	return nil
 }
 
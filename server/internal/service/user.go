@@ -72,17 +72,31 @@ func RegisterUser(form model.UserCreateForm) (int, error) {
 		// 不返回错误，因为用户已创建成功
 	}
 
+	// 发送邮箱验证邮件
+	if user.Email != "" {
+		sendVerificationEmailAsync(user.UserID)
+	}
+
 	return user.UserID, nil
 }
 
-// LoginUser 用户登录
-func LoginUser(username, password string) (*model.LoginResult, error) {
+// LoginUser 用户登录，连续失败过多时返回 *LoginLimitError
+func LoginUser(username, password, ip string) (*model.LoginResult, error) {
+	// 检查账号和IP是否因登录失败过多被限制
+	if err := CheckLoginAllowed(username, ip); err != nil {
+		var limitErr *LoginLimitError
+		if errors.As(err, &limitErr) {
+			return nil, err
+		}
+		zap.L().Error("检查登录限制失败", zap.String("username", username), zap.Error(err))
+	}
+
 	var user model.User
 
 	// 查询用户
 	if err := model.DB.Where("username = ?", username).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("用户不存在")
+			return nil, loginFailed(username, ip, errors.New("用户不存在"))
 		}
 		return nil, err
 	}
@@ -94,9 +108,24 @@ func LoginUser(username, password string) (*model.LoginResult, error) {
 
 	// 验证密码
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return nil, errors.New("密码错误")
+		return nil, loginFailed(username, ip, errors.New("密码错误"))
 	}
 
+	// 系统要求验证邮箱时，未验证的账号不能登录
+	if err := CheckEmailVerified(user.UserID); err != nil {
+		return nil, err
+	}
+
+	// 需要两步验证时只返回临时令牌，完成第二步后才签发令牌
+	challenge, err := BeginMFA(user.UserID, user.Username)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &model.LoginResult{MFA: challenge}, nil
+	}
+	ResetLoginFailures(username)
+
 	// 查询用户角色
 	var roleIDs []int
 	if err := model.DB.Table("sys_user_roles").
@@ -138,6 +167,18 @@ func LoginUser(username, password string) (*model.LoginResult, error) {
 	}, nil
 }
 
+// loginFailed 记录登录失败，达到失败上限时返回锁定错误，否则返回原错误
+func loginFailed(username, ip string, err error) error {
+	if _, recordErr := RecordLoginFailure(username, ip); recordErr != nil {
+		var limitErr *LoginLimitError
+		if errors.As(recordErr, &limitErr) {
+			return recordErr
+		}
+		zap.L().Error("记录登录失败次数失败", zap.String("username", username), zap.Error(recordErr))
+	}
+	return err
+}
+
 // RefreshToken 刷新访问令牌
 func RefreshToken(refreshToken string) (*model.RefreshTokenResult, error) {
 	// 解析刷新令牌
@@ -237,6 +278,11 @@ func UpdateUserProfile(userID int, form model.UserProfileUpdateForm) error {
 		}
 	}
 
+	var user model.User
+	if err := model.DB.Select("user_id, email").First(&user, userID).Error; err != nil {
+		return err
+	}
+
 	// 更新用户资料
 	updates := map[string]interface{}{
 		"nickname": form.Nickname,
@@ -245,11 +291,19 @@ func UpdateUserProfile(userID int, form model.UserProfileUpdateForm) error {
 		"avatar":   form.Avatar,
 		"gender":   form.Gender,
 	}
+	// 修改邮箱后需要重新验证
+	emailChanged := form.Email != user.Email
+	if emailChanged {
+		updates["email_verified_at"] = nil
+	}
 
 	if err := model.DB.Model(&model.User{}).Where("user_id = ?", userID).Updates(updates).Error; err != nil {
 		return err
 	}
 
+	if emailChanged && form.Email != "" {
+		sendVerificationEmailAsync(userID)
+	}
 	return nil
 }
 
@@ -434,5 +488,10 @@ func AssignRoles(userID int, roleIDs []int) error {
 		}
 	}
 
-	return tx.Commit().Error
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	InvalidateUserPermissionCache(userID)
+	return nil
 }
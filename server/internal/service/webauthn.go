@@ -0,0 +1,429 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/config"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/jwt"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/webauthn"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 通行密钥缓存键
+const (
+	webauthnSessionKey = "blog:auth:webauthn:%s" // 注册或登录过程中的挑战，完成时删除，保证每个挑战只能使用一次
+)
+
+// 通行密钥参数
+const (
+	webauthnSessionExpire  = 300        // 挑战有效期（秒）
+	webauthnTimeout        = 300000     // 浏览器等待用户操作的时间（毫秒）
+	webauthnMaxCredentials = 10         // 每个用户最多注册的通行密钥数量
+	webauthnCeremonyCreate = "register" // 注册
+	webauthnCeremonyGet    = "login"    // 登录
+	defaultWebAuthnRPName  = "Go React Blog"
+)
+
+var (
+	// ErrWebAuthnSessionInvalid 挑战不存在、已过期或已使用
+	ErrWebAuthnSessionInvalid = errors.New("通行密钥验证已超时，请重试")
+	// ErrWebAuthnCredentialInvalid 通行密钥校验失败
+	ErrWebAuthnCredentialInvalid = errors.New("通行密钥验证失败")
+	// ErrWebAuthnCredentialExists 通行密钥已注册过
+	ErrWebAuthnCredentialExists = errors.New("该通行密钥已注册")
+	// ErrWebAuthnCredentialNotFound 通行密钥不存在
+	ErrWebAuthnCredentialNotFound = errors.New("通行密钥不存在")
+	// ErrWebAuthnTooManyCredentials 通行密钥数量达到上限
+	ErrWebAuthnTooManyCredentials = fmt.Errorf("最多只能注册%d个通行密钥", webauthnMaxCredentials)
+)
+
+// webauthnRP 本站作为依赖方的配置
+var (
+	webauthnRP     webauthn.Config
+	webauthnRPName string
+)
+
+// webauthnSession 注册或登录过程中保存的挑战
+type webauthnSession struct {
+	Ceremony  string `json:"ceremony"`
+	Challenge []byte `json:"challenge"`
+	UserID    int    `json:"user_id"` // 注册时为当前用户；登录时为指定的用户，0表示由所选的凭据确定
+}
+
+// InitWebAuthn 设置依赖方信息。始终要求认证器验证用户身份（指纹、面容或PIN），
+// 因此通行密钥本身已是两个因素，登录时不再要求两步验证
+func InitWebAuthn(cfg config.WebAuthnConfig) {
+	if cfg.RPName == "" {
+		cfg.RPName = defaultWebAuthnRPName
+	}
+	webauthnRP = webauthn.Config{
+		RPID:                    cfg.RPID,
+		Origins:                 cfg.Origins,
+		RequireUserVerification: true,
+	}
+	webauthnRPName = cfg.RPName
+}
+
+// BeginWebAuthnRegistration 开始为当前用户注册通行密钥，返回传给浏览器的注册选项
+func BeginWebAuthnRegistration(userID int) (*model.WebAuthnOptions, error) {
+	var user model.User
+	if err := model.DB.Select("user_id, username, nickname").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	credentials, err := ListWebAuthnCredentials(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(credentials) >= webauthnMaxCredentials {
+		return nil, ErrWebAuthnTooManyCredentials
+	}
+
+	session := webauthnSession{Ceremony: webauthnCeremonyCreate, UserID: userID}
+	sessionID, err := saveWebAuthnSession(&session)
+	if err != nil {
+		return nil, err
+	}
+
+	displayName := user.Nickname
+	if displayName == "" {
+		displayName = user.Username
+	}
+	params := make([]webauthn.CredentialParameter, 0, len(webauthn.SupportedAlgorithms))
+	for _, alg := range webauthn.SupportedAlgorithms {
+		params = append(params, webauthn.CredentialParameter{Type: webauthn.CredentialType, Alg: alg})
+	}
+	return &model.WebAuthnOptions{
+		SessionID: sessionID,
+		PublicKey: webauthn.CreationOptions{
+			RP:                 webauthn.RelyingParty{ID: webauthnRP.RPID, Name: webauthnRPName},
+			User:               webauthn.UserEntity{ID: webauthnUserHandle(userID), Name: user.Username, DisplayName: displayName},
+			Challenge:          session.Challenge,
+			PubKeyCredParams:   params,
+			Timeout:            webauthnTimeout,
+			ExcludeCredentials: webauthnDescriptors(credentials),
+			AuthenticatorSelection: webauthn.AuthenticatorSelection{
+				ResidentKey:        "required",
+				RequireResidentKey: true,
+				UserVerification:   webauthnRP.UserVerification(),
+			},
+			Attestation: "none",
+		},
+		ExpiresIn: webauthnSessionExpire,
+	}, nil
+}
+
+// FinishWebAuthnRegistration 校验浏览器返回的新凭据并保存
+func FinishWebAuthnRegistration(userID int, sessionID, name string, credential json.RawMessage) (*model.WebAuthnCredential, error) {
+	session, err := takeWebAuthnSession(sessionID, webauthnCeremonyCreate)
+	if err != nil {
+		return nil, err
+	}
+	if session.UserID != userID {
+		return nil, ErrWebAuthnSessionInvalid
+	}
+
+	var response webauthn.RegistrationResponse
+	if err := json.Unmarshal(credential, &response); err != nil {
+		return nil, ErrWebAuthnCredentialInvalid
+	}
+	verified, err := webauthnRP.VerifyRegistration(session.Challenge, &response)
+	if err != nil {
+		return nil, fmt.Errorf("%w：%v", ErrWebAuthnCredentialInvalid, err)
+	}
+
+	var count int64
+	if err := model.DB.Model(&model.WebAuthnCredential{}).Where("raw_id = ?", verified.ID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrWebAuthnCredentialExists
+	}
+	if err := model.DB.Model(&model.WebAuthnCredential{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count >= webauthnMaxCredentials {
+		return nil, ErrWebAuthnTooManyCredentials
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "通行密钥 " + time.Now().Format("2006-01-02")
+	}
+	record := model.WebAuthnCredential{
+		UserID:         userID,
+		RawID:          verified.ID,
+		PublicKey:      verified.PublicKey,
+		SignCount:      int64(verified.SignCount),
+		AAGUID:         formatAAGUID(verified.AAGUID),
+		Transports:     strings.Join(verified.Transports, ","),
+		BackupEligible: verified.BackupEligible,
+		BackupState:    verified.BackupState,
+		Name:           name,
+	}
+	if err := model.DB.Create(&record).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// BeginWebAuthnLogin 开始通行密钥登录。指定用户名时只允许该用户的通行密钥；
+// 为空时由浏览器列出本站的全部通行密钥供选择。用户不存在时同样返回选项，不暴露用户名是否存在
+func BeginWebAuthnLogin(username string) (*model.WebAuthnOptions, error) {
+	session := webauthnSession{Ceremony: webauthnCeremonyGet}
+	allow := make([]webauthn.CredentialDescriptor, 0)
+
+	if username != "" {
+		var user model.User
+		err := model.DB.Select("user_id").Where("username = ?", username).First(&user).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if err == nil {
+			credentials, err := ListWebAuthnCredentials(user.UserID)
+			if err != nil {
+				return nil, err
+			}
+			if len(credentials) > 0 {
+				session.UserID = user.UserID
+				allow = webauthnDescriptors(credentials)
+			}
+		}
+	}
+
+	sessionID, err := saveWebAuthnSession(&session)
+	if err != nil {
+		return nil, err
+	}
+	return &model.WebAuthnOptions{
+		SessionID: sessionID,
+		PublicKey: webauthn.RequestOptions{
+			Challenge:        session.Challenge,
+			Timeout:          webauthnTimeout,
+			RPID:             webauthnRP.RPID,
+			AllowCredentials: allow,
+			UserVerification: webauthnRP.UserVerification(),
+		},
+		ExpiresIn: webauthnSessionExpire,
+	}, nil
+}
+
+// FinishWebAuthnLogin 校验通行密钥签名，通过后签发与密码登录相同的令牌。
+// 返回的用户用于记录登录日志，凭据无法对应到用户时为nil；签名校验失败计入登录失败次数
+func FinishWebAuthnLogin(sessionID string, credential json.RawMessage, client model.SessionClient) (*model.LoginResult, *model.User, error) {
+	session, err := takeWebAuthnSession(sessionID, webauthnCeremonyGet)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var response webauthn.AssertionResponse
+	if err := json.Unmarshal(credential, &response); err != nil || len(response.RawID) == 0 {
+		return nil, nil, ErrWebAuthnCredentialInvalid
+	}
+
+	var record model.WebAuthnCredential
+	if err := model.DB.Where("raw_id = ?", []byte(response.RawID)).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrWebAuthnCredentialInvalid
+		}
+		return nil, nil, err
+	}
+	if session.UserID != 0 && session.UserID != record.UserID {
+		return nil, nil, ErrWebAuthnCredentialInvalid
+	}
+	if handle := response.Response.UserHandle; len(handle) > 0 && !bytes.Equal(handle, webauthnUserHandle(record.UserID)) {
+		return nil, nil, ErrWebAuthnCredentialInvalid
+	}
+
+	var user model.User
+	if err := model.DB.First(&user, record.UserID).Error; err != nil {
+		return nil, nil, err
+	}
+	if err := CheckLoginAllowed(user.Username, client.IPAddress); err != nil {
+		var limitErr *LoginLimitError
+		if errors.As(err, &limitErr) {
+			return nil, &user, err
+		}
+		zap.L().Error("检查登录限制失败", zap.String("username", user.Username), zap.Error(err))
+	}
+	if user.Status != model.UserStatusNormal {
+		return nil, &user, ErrUserDisabled
+	}
+	if err := CheckEmailVerified(user.UserID); err != nil {
+		return nil, &user, err
+	}
+
+	authData, err := webauthnRP.VerifyAssertion(session.Challenge, &response, record.PublicKey, uint32(record.SignCount))
+	if err != nil {
+		if errors.Is(err, webauthn.ErrSignCountInvalid) {
+			zap.L().Warn("通行密钥签名计数异常，凭据可能已被复制",
+				zap.Int("user_id", user.UserID),
+				zap.Int("credential_id", record.CredentialID),
+			)
+		}
+		return nil, &user, loginFailed(user.Username, client.IPAddress, fmt.Errorf("%w：%v", ErrWebAuthnCredentialInvalid, err))
+	}
+
+	now := time.Now()
+	if err := model.DB.Model(&record).Updates(map[string]interface{}{
+		"sign_count":   int64(authData.SignCount),
+		"backup_state": authData.Has(webauthn.FlagBackupState),
+		"last_used_at": now,
+	}).Error; err != nil {
+		return nil, &user, err
+	}
+
+	// 认证器验证了用户身份（PIN、指纹等）时已相当于两个因素；只验证了持有凭据时仍需两步验证
+	if !authData.Has(webauthn.FlagUserVerified) {
+		challenge, err := BeginMFA(user.UserID, user.Username)
+		if err != nil {
+			return nil, &user, err
+		}
+		if challenge != nil {
+			return &model.LoginResult{MFA: challenge}, &user, nil
+		}
+	}
+
+	ResetLoginFailures(user.Username)
+	tokens, err := IssueTokens(user.UserID, user.Username, client)
+	if err != nil {
+		return nil, &user, err
+	}
+	if err := model.DB.Model(&user).Updates(map[string]interface{}{
+		"last_login":  now,
+		"login_count": gorm.Expr("login_count + 1"),
+	}).Error; err != nil {
+		zap.L().Warn("更新最后登录时间失败", zap.Int("user_id", user.UserID), zap.Error(err))
+	}
+	return tokens, &user, nil
+}
+
+// ListWebAuthnCredentials 获取用户的通行密钥列表
+func ListWebAuthnCredentials(userID int) ([]model.WebAuthnCredential, error) {
+	credentials := make([]model.WebAuthnCredential, 0)
+	if err := model.DB.Where("user_id = ?", userID).Order("credential_id").Find(&credentials).Error; err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+// RenameWebAuthnCredential 重命名用户的通行密钥
+func RenameWebAuthnCredential(userID, credentialID int, name string) error {
+	result := model.DB.Model(&model.WebAuthnCredential{}).
+		Where("credential_id = ? AND user_id = ?", credentialID, userID).
+		Update("name", strings.TrimSpace(name))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWebAuthnCredentialNotFound
+	}
+	return nil
+}
+
+// DeleteWebAuthnCredential 删除用户的通行密钥，删除后该凭据不能再登录
+func DeleteWebAuthnCredential(userID, credentialID int) error {
+	result := model.DB.Where("credential_id = ? AND user_id = ?", credentialID, userID).
+		Delete(&model.WebAuthnCredential{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWebAuthnCredentialNotFound
+	}
+	return nil
+}
+
+// saveWebAuthnSession 生成挑战并保存，返回会话ID
+func saveWebAuthnSession(session *webauthnSession) (string, error) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return "", err
+	}
+	session.Challenge = challenge
+
+	sessionID, err := jwt.NewTokenID()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+	if err := model.RDB.Set(context.Background(), fmt.Sprintf(webauthnSessionKey, sessionID),
+		data, webauthnSessionExpire*time.Second).Err(); err != nil {
+		return "", err
+	}
+	return sessionID, nil
+}
+
+// takeWebAuthnSession 取出并删除挑战，无论校验是否通过挑战都只能使用一次
+func takeWebAuthnSession(sessionID, ceremony string) (*webauthnSession, error) {
+	if sessionID == "" {
+		return nil, ErrWebAuthnSessionInvalid
+	}
+	ctx := context.Background()
+	key := fmt.Sprintf(webauthnSessionKey, sessionID)
+	var get *redis.StringCmd
+	if _, err := model.RDB.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	}); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	data, err := get.Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrWebAuthnSessionInvalid
+		}
+		return nil, err
+	}
+	var session webauthnSession
+	if err := json.Unmarshal(data, &session); err != nil || session.Ceremony != ceremony {
+		return nil, ErrWebAuthnSessionInvalid
+	}
+	return &session, nil
+}
+
+// webauthnUserHandle 注册时写入认证器的用户标识，使用不含个人信息的用户ID
+func webauthnUserHandle(userID int) []byte {
+	return []byte(strconv.Itoa(userID))
+}
+
+// webauthnDescriptors 将已保存的凭据转换为选项中的凭据列表
+func webauthnDescriptors(credentials []model.WebAuthnCredential) []webauthn.CredentialDescriptor {
+	descriptors := make([]webauthn.CredentialDescriptor, 0, len(credentials))
+	for _, cred := range credentials {
+		descriptor := webauthn.CredentialDescriptor{Type: webauthn.CredentialType, ID: cred.RawID}
+		if cred.Transports != "" {
+			descriptor.Transports = strings.Split(cred.Transports, ",")
+		}
+		descriptors = append(descriptors, descriptor)
+	}
+	return descriptors
+}
+
+// formatAAGUID 将认证器型号标识格式化为UUID字符串
+func formatAAGUID(aaguid []byte) string {
+	if len(aaguid) != 16 {
+		return ""
+	}
+	s := hex.EncodeToString(aaguid)
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
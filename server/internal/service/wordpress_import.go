@@ -0,0 +1,706 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"mime"
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/render"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/slug"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/wxr"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 用户名长度，与注册规则一致
+const (
+	usernameMinLen = 4
+	usernameMaxLen = 30
+	nicknameMaxLen = 50
+	emailMaxLen    = 100
+)
+
+// wordPressUploadsPath WordPress 上传目录在网址中的路径
+const wordPressUploadsPath = "/wp-content/uploads/"
+
+// wordPressUploadURL 正文中引用上传目录文件的地址
+var wordPressUploadURL = regexp.MustCompile(`(?i)(?:(?:https?:)?//[^/\s"'<>]+)?/wp-content/uploads/[^\s"'<>()?#]+`)
+
+// WordPress 导入相关错误
+var (
+	ErrWordPressExportInvalid = errors.New("无法解析WordPress导出文件，请上传WXR格式的XML文件")
+	ErrAuthorMapInvalid       = errors.New("作者对应关系格式错误，应为 登录名=用户ID，多个以逗号分隔")
+	ErrAuthorMapUserNotFound  = errors.New("作者对应的用户不存在")
+)
+
+// wordPressImport 导入中的 WordPress 导出文件
+type wordPressImport struct {
+	export    *wxr.Export
+	uploads   fs.FS // WordPress 上传目录，为空时不导入附件
+	userID    int
+	dryRun    bool
+	report    *importReport
+	hosts     map[string]bool   // 站点域名，用于识别站内的上传文件地址
+	authors   map[string]int    // 作者登录名 → 用户ID，预检时新用户为0
+	authorIDs map[int]string    // WordPress 作者ID → 登录名
+	users     map[string]int    // 评论者邮箱或名称 → 用户ID
+	uploaded  map[string]string // 上传目录内路径 → 上传后的地址
+	files     map[int]string    // 附件ID → 上传后的地址
+}
+
+// ParseImportAuthorMap 解析作者对应关系，格式为 登录名=用户ID，多个以逗号分隔
+func ParseImportAuthorMap(s string) (map[string]int, error) {
+	authorMap := make(map[string]int)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		login, id, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, ErrAuthorMapInvalid
+		}
+		userID, err := strconv.Atoi(strings.TrimSpace(id))
+		if err != nil || userID <= 0 || strings.TrimSpace(login) == "" {
+			return nil, ErrAuthorMapInvalid
+		}
+		authorMap[strings.TrimSpace(login)] = userID
+	}
+	return authorMap, nil
+}
+
+// ImportWordPress 从 WordPress 导出文件（WXR）导入文章、分类、标签、评论和附件
+//
+// 作者按 authorMap（登录名 → 用户ID）、邮箱、用户名依次匹配已有用户，都不匹配时创建未激活的用户；
+// 游客评论者按邮箱匹配已有用户，否则同样创建未激活的用户。
+// 分类按上级关系创建分类树，文章正文以HTML保存，评论保留层级、审核状态、IP和用户代理，
+// 垃圾评论、回收站评论和 pingback/trackback 不导入。
+// uploads 为 WordPress 的 wp-content/uploads 目录，附件和正文引用的上传文件从中读取并保存为文件记录，
+// 为空时保留原地址。scopes 为个人访问令牌的权限范围，不受限时传nil。
+func ImportWordPress(r io.Reader, uploads fs.FS, userID int, scopes []string, opts model.ImportOptions, authorMap map[string]int) (*model.ImportResult, error) {
+	export, err := wxr.Parse(r, time.Local)
+	if err != nil {
+		zap.L().Warn("解析WordPress导出文件失败", zap.Error(err))
+		return nil, ErrWordPressExportInvalid
+	}
+
+	// 非草稿文章的状态，没有发布权限时提交审核
+	publishStatus, err := articlePublishStatus(userID, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &wordPressImport{
+		export:    export,
+		uploads:   uploads,
+		userID:    userID,
+		dryRun:    opts.DryRun,
+		report:    newImportReport(opts.DryRun),
+		hosts:     make(map[string]bool),
+		authors:   make(map[string]int),
+		authorIDs: make(map[int]string),
+		users:     make(map[string]int),
+		uploaded:  make(map[string]string),
+		files:     make(map[int]string),
+	}
+	for _, base := range []string{export.BaseSiteURL, export.BaseBlogURL} {
+		if u, err := url.Parse(base); err == nil && u.Host != "" {
+			w.hosts[strings.ToLower(u.Host)] = true
+		}
+	}
+
+	if err := w.importAuthors(authorMap); err != nil {
+		return nil, err
+	}
+	if err := w.importTerms(); err != nil {
+		return nil, err
+	}
+	if err := w.importAttachments(); err != nil {
+		return nil, err
+	}
+
+	seenKeys := make(map[string]bool)
+	for _, item := range export.Items {
+		if item.PostType != "post" {
+			continue
+		}
+		switch item.Status {
+		case "trash", "inherit", "auto-draft":
+			continue
+		}
+
+		res, err := w.importPost(item, opts, publishStatus, seenKeys)
+		if err != nil {
+			res.Status = model.ImportItemFailed
+			res.Message = err.Error()
+			zap.L().Warn("导入WordPress文章失败", zap.Int("post_id", item.PostID), zap.Error(err))
+		}
+		w.report.add(res)
+		w.report.result.Comments += res.Comments
+	}
+	w.report.result.Images = len(w.uploaded)
+
+	return w.report.result, nil
+}
+
+// importAuthors 匹配或创建作者对应的用户
+func (w *wordPressImport) importAuthors(authorMap map[string]int) error {
+	for login, id := range authorMap {
+		var count int64
+		if err := model.DB.Model(&model.User{}).Where("user_id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("%w: %s=%d", ErrAuthorMapUserNotFound, login, id)
+		}
+		w.authors[login] = id
+	}
+
+	for _, author := range w.export.Authors {
+		w.authorIDs[author.ID] = author.Login
+		if _, ok := w.authors[author.Login]; ok {
+			continue
+		}
+		name := strings.TrimSpace(author.DisplayName)
+		if name == "" {
+			name = strings.TrimSpace(author.FirstName + " " + author.LastName)
+		}
+		id, err := w.ensureUser(author.Email, author.Login, name, author.Login)
+		if err != nil {
+			return err
+		}
+		w.authors[author.Login] = id
+	}
+	return nil
+}
+
+// authorUserID 返回文章作者对应的用户ID，作者不在导出文件的作者列表中时按登录名创建
+func (w *wordPressImport) authorUserID(login string) (int, error) {
+	if id, ok := w.authors[login]; ok {
+		return id, nil
+	}
+	if login == "" {
+		return w.userID, nil
+	}
+	id, err := w.ensureUser("", login, login, login)
+	if err != nil {
+		return 0, err
+	}
+	w.authors[login] = id
+	return id, nil
+}
+
+// ensureUser 按邮箱或用户名匹配已有用户，不存在时创建未激活的用户，预检时返回0
+func (w *wordPressImport) ensureUser(email, username, nickname, label string) (int, error) {
+	email = strings.TrimSpace(email)
+	if len(email) > emailMaxLen {
+		email = ""
+	}
+	var user model.User
+	if email != "" {
+		err := model.DB.Select("user_id").Where("LOWER(email) = LOWER(?)", email).First(&user).Error
+		if err == nil {
+			return user.UserID, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, err
+		}
+	}
+	if username != "" {
+		err := model.DB.Select("user_id").Where("username = ?", username).First(&user).Error
+		if err == nil {
+			return user.UserID, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, err
+		}
+	}
+
+	w.report.addUser(label)
+	if w.dryRun {
+		return 0, nil
+	}
+
+	base := username
+	if base == "" {
+		base = nickname
+	}
+	if base == "" {
+		base, _, _ = strings.Cut(email, "@")
+	}
+	name, err := uniqueUsername(base)
+	if err != nil {
+		return 0, err
+	}
+	// 导入的用户没有密码，需要找回密码后才能登录
+	user = model.User{
+		Username: name,
+		Nickname: truncateRunes(nickname, nicknameMaxLen),
+		Email:    email,
+		Status:   model.UserStatusInactive,
+	}
+	omit := []string{"birthday", "last_login", "mobile", "wechat_openid", "wechat_unionid"}
+	if email == "" {
+		omit = append(omit, "email")
+	}
+	if err := model.DB.Omit(omit...).Create(&user).Error; err != nil {
+		return 0, err
+	}
+	return user.UserID, nil
+}
+
+// uniqueUsername 根据名称生成符合规则且不重复的用户名
+func uniqueUsername(name string) (string, error) {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+		default:
+			if b.Len() > 0 && !strings.HasSuffix(b.String(), "_") {
+				b.WriteByte('_')
+			}
+		}
+	}
+	base := strings.Trim(b.String(), "_")
+	if len(base) < usernameMinLen {
+		base = "wp_" + base
+		if len(base) < usernameMinLen {
+			base = "wp_user"
+		}
+	}
+	if len(base) > usernameMaxLen-4 {
+		base = base[:usernameMaxLen-4]
+	}
+
+	candidate := base
+	for i := 2; ; i++ {
+		var count int64
+		if err := model.DB.Model(&model.User{}).Where("username = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = base + strconv.Itoa(i)
+	}
+}
+
+// importTerms 按导出文件中的分类树和标签列表创建分类和标签
+func (w *wordPressImport) importTerms() error {
+	var paths [][]string
+	for _, category := range w.export.Categories {
+		paths = append(paths, w.categoryPath(category.Slug, category.Name))
+	}
+	_, missing, err := ensureCategoryPaths(paths, !w.dryRun)
+	if err != nil {
+		return err
+	}
+	w.report.addCategories(missing)
+
+	var names []string
+	for _, tag := range w.export.Tags {
+		names = append(names, tag.Name)
+	}
+	missing, err = missingTagNames(names)
+	if err != nil {
+		return err
+	}
+	w.report.addTags(missing)
+	if !w.dryRun {
+		if _, err := ensureTags(names); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// categoryPath 根据分类别名沿上级分类生成从顶级到该分类的名称路径
+func (w *wordPressImport) categoryPath(slugName, name string) []string {
+	bySlug := make(map[string]wxr.Category, len(w.export.Categories))
+	for _, category := range w.export.Categories {
+		bySlug[category.Slug] = category
+	}
+
+	category, ok := bySlug[slugName]
+	if !ok {
+		return []string{name}
+	}
+	names := []string{category.Name}
+	seen := map[string]bool{category.Slug: true}
+	for category.Parent != "" && !seen[category.Parent] {
+		parent, ok := bySlug[category.Parent]
+		if !ok {
+			break
+		}
+		seen[parent.Slug] = true
+		names = append([]string{parent.Name}, names...)
+		category = parent
+	}
+	return names
+}
+
+// importAttachments 将附件从上传目录保存为文件记录
+func (w *wordPressImport) importAttachments() error {
+	for _, item := range w.export.Items {
+		if item.PostType != "attachment" || item.AttachmentURL == "" {
+			continue
+		}
+		rel, ok := w.uploadPath(item.AttachmentURL)
+		if !ok {
+			continue
+		}
+		u, err := w.upload(rel)
+		if errors.Is(err, ErrImportFileTooLarge) {
+			w.report.result.Warnings = append(w.report.result.Warnings, err.Error())
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if u == "" {
+			w.report.result.Warnings = append(w.report.result.Warnings, "附件不存在: "+item.AttachmentURL)
+			continue
+		}
+		w.files[item.PostID] = u
+		w.report.result.Attachments++
+	}
+	return nil
+}
+
+// uploadPath 返回站内上传文件地址在上传目录中的相对路径
+func (w *wordPressImport) uploadPath(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+	if u.Host != "" && !w.hosts[strings.ToLower(u.Host)] {
+		return "", false
+	}
+	i := strings.Index(u.Path, wordPressUploadsPath)
+	if i < 0 {
+		return "", false
+	}
+	rel := u.Path[i+len(wordPressUploadsPath):]
+	if !fs.ValidPath(rel) {
+		return "", false
+	}
+	return rel, true
+}
+
+// upload 上传上传目录中的文件并返回新地址，文件不存在时返回空字符串
+func (w *wordPressImport) upload(rel string) (string, error) {
+	if u, ok := w.uploaded[rel]; ok {
+		return u, nil
+	}
+	if w.uploads == nil {
+		return "", nil
+	}
+
+	// 上传目录可以是 uploads 本身，也可以是它的上级目录
+	var name string
+	for _, candidate := range []string{rel, "uploads/" + rel, "wp-content/uploads/" + rel} {
+		if info, err := fs.Stat(w.uploads, candidate); err == nil && !info.IsDir() {
+			if info.Size() > importMaxFileSize {
+				return "", fmt.Errorf("%s: %w", rel, ErrImportFileTooLarge)
+			}
+			name = candidate
+			break
+		}
+	}
+	if name == "" {
+		return "", nil
+	}
+
+	u := rel
+	if !w.dryRun {
+		data, err := fs.ReadFile(w.uploads, name)
+		if err != nil {
+			return "", err
+		}
+		result, err := UploadFileData(path.Base(rel), mime.TypeByExtension(path.Ext(rel)), data, w.userID, true)
+		if err != nil {
+			return "", err
+		}
+		u = result.URL
+	}
+	w.uploaded[rel] = u
+	return u, nil
+}
+
+// rewriteUploads 将正文中引用的站内上传文件改写为上传后的地址
+func (w *wordPressImport) rewriteUploads(content string, item *model.ImportItem) (string, error) {
+	var firstErr error
+	content = wordPressUploadURL.ReplaceAllStringFunc(content, func(ref string) string {
+		rel, ok := w.uploadPath(ref)
+		if !ok || w.uploads == nil {
+			return ref
+		}
+		u, err := w.upload(rel)
+		if errors.Is(err, ErrImportFileTooLarge) {
+			item.Warnings = append(item.Warnings, err.Error())
+			return ref
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return ref
+		}
+		if u == "" {
+			item.Warnings = append(item.Warnings, "文件不存在: "+ref)
+			return ref
+		}
+		item.Images++
+		return u
+	})
+	return content, firstErr
+}
+
+// importPost 导入单篇文章及其评论
+func (w *wordPressImport) importPost(item wxr.Item, opts model.ImportOptions, publishStatus int8, seenKeys map[string]bool) (importFileResult, error) {
+	res := importFileResult{ImportItem: model.ImportItem{File: strconv.Itoa(item.PostID)}}
+	res.Title = truncateRunes(html.UnescapeString(item.Title), titleMaxLen)
+
+	// 非ASCII别名在导出文件中经过URL编码
+	postName := item.PostName
+	if s, err := url.PathUnescape(postName); err == nil {
+		postName = s
+	}
+	key := slug.Make(postName, articleKeyMaxLen)
+	if key == "" {
+		key = slug.Make(res.Title, articleKeyMaxLen)
+	}
+	if res.Title == "" {
+		res.Title = "post-" + strconv.Itoa(item.PostID)
+	}
+	if ok, err := resolveImportKey(key, opts, seenKeys, &res.ImportItem); err != nil || !ok {
+		return res, err
+	}
+
+	// 状态：已发布的文章按导入者权限发布或提交审核，定时、私密文章保存为草稿
+	status := model.ArticleStatusDraft
+	switch item.Status {
+	case "publish":
+		status = publishStatus
+	case "pending":
+		status = model.ArticleStatusPending
+	}
+
+	// 分类和标签
+	var categoryPaths [][]string
+	var tagNames []string
+	for _, term := range item.Terms {
+		switch term.Domain {
+		case "category":
+			categoryPaths = append(categoryPaths, w.categoryPath(term.Slug, term.Name))
+		case "post_tag":
+			tagNames = append(tagNames, term.Name)
+		}
+	}
+	categoryIDs, missingCategories, err := ensureCategoryPaths(categoryPaths, !w.dryRun)
+	if err != nil {
+		return res, err
+	}
+	res.newCategories = missingCategories
+	if len(categoryPaths) == 0 {
+		if categoryIDs, err = categoryIDsByName(nil); err != nil {
+			return res, err
+		}
+	}
+	if res.newTags, err = missingTagNames(tagNames); err != nil {
+		return res, err
+	}
+
+	authorID, err := w.authorUserID(item.Creator)
+	if err != nil {
+		return res, err
+	}
+
+	// 经典编辑器的正文没有段落标签
+	content, err := w.rewriteUploads(wxr.AutoParagraph(wxr.ConvertCaptions(item.Content)), &res.ImportItem)
+	if err != nil {
+		return res, err
+	}
+	thumbnail := ""
+	if id, err := strconv.Atoi(item.Meta["_thumbnail_id"]); err == nil {
+		if thumbnail = w.files[id]; len(thumbnail) > thumbnailMaxLen {
+			thumbnail = ""
+		}
+	}
+
+	comments := importableComments(item.Comments)
+	if w.dryRun {
+		for _, c := range comments {
+			if c.UserID == 0 {
+				if _, err := w.commenterUserID(c); err != nil {
+					return res, err
+				}
+			}
+		}
+		res.Comments = len(comments)
+		res.Status = model.ImportItemReady
+		return res, nil
+	}
+
+	tagIDs, err := ensureTags(tagNames)
+	if err != nil {
+		return res, err
+	}
+	articleID, err := CreateArticle(model.ArticleCreateForm{
+		Title:         res.Title,
+		ArticleKey:    res.ArticleKey,
+		Content:       content,
+		ContentFormat: render.FormatHTML,
+		Summary:       truncateRunes(html.UnescapeString(item.Excerpt), summaryMaxLen),
+		Thumbnail:     thumbnail,
+		Status:        status,
+		ArticleType:   1,
+		CategoryIDs:   categoryIDs,
+		TagIDs:        tagIDs,
+		AllowComment:  item.CommentStatus != "closed",
+		IsTop:         item.IsSticky,
+	}, w.userID)
+	if err != nil {
+		return res, err
+	}
+	res.ArticleID = articleID
+	res.Status = model.ImportItemCreated
+	importArticleCreated(articleID, status, item.Date, authorID, w.userID)
+
+	if res.Comments, err = w.importComments(int64(articleID), authorID, comments); err != nil {
+		res.Warnings = append(res.Warnings, "导入评论失败: "+err.Error())
+		zap.L().Error("导入WordPress评论失败", zap.Int("article_id", articleID), zap.Error(err))
+	}
+
+	return res, nil
+}
+
+// importableComments 过滤需要导入的评论并按ID排序，保证上级评论先导入
+func importableComments(comments []wxr.Comment) []wxr.Comment {
+	list := make([]wxr.Comment, 0, len(comments))
+	for _, c := range comments {
+		if c.Approved != "1" && c.Approved != "0" {
+			continue
+		}
+		if c.Type == "pingback" || c.Type == "trackback" {
+			continue
+		}
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// commenterUserID 返回评论者对应的用户ID，登录用户对应作者，游客按邮箱匹配或创建
+func (w *wordPressImport) commenterUserID(c wxr.Comment) (int, error) {
+	if login, ok := w.authorIDs[c.UserID]; ok && c.UserID > 0 {
+		return w.authorUserID(login)
+	}
+
+	key := strings.ToLower(c.AuthorEmail)
+	if key == "" {
+		key = "name:" + c.Author
+	}
+	if id, ok := w.users[key]; ok {
+		return id, nil
+	}
+	label := c.Author
+	if c.AuthorEmail != "" {
+		label = c.Author + " <" + c.AuthorEmail + ">"
+	}
+	id, err := w.ensureUser(c.AuthorEmail, "", c.Author, label)
+	if err != nil {
+		return 0, err
+	}
+	w.users[key] = id
+	return id, nil
+}
+
+// importComments 导入文章评论，保留回复层级，并更新文章评论数
+func (w *wordPressImport) importComments(articleID int64, authorID int, comments []wxr.Comment) (int, error) {
+	if len(comments) == 0 {
+		return 0, nil
+	}
+
+	// 先确定评论者，避免在事务中创建用户
+	userIDs := make([]int, len(comments))
+	for i, c := range comments {
+		id, err := w.commenterUserID(c)
+		if err != nil {
+			return 0, err
+		}
+		userIDs[i] = id
+	}
+
+	tx := model.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// WordPress 评论ID → 导入后的评论
+	imported := make(map[int]*model.Comment, len(comments))
+	approved := 0
+	for i, c := range comments {
+		comment := model.Comment{
+			ArticleID:    articleID,
+			UserID:       userIDs[i],
+			Content:      c.Content,
+			IPAddress:    c.AuthorIP,
+			UserAgent:    c.Agent,
+			IsApproved:   c.Approved == "1",
+			IsAdminReply: userIDs[i] == authorID,
+		}
+		if !c.Date.IsZero() {
+			comment.CreatedAt = c.Date
+			comment.UpdatedAt = c.Date
+		}
+		// 上级评论未导入（如垃圾评论）时作为顶级评论
+		if parent, ok := imported[c.Parent]; ok && c.Parent > 0 {
+			comment.ParentID = &parent.CommentID
+			if parent.RootID != nil {
+				comment.RootID = parent.RootID
+			} else {
+				comment.RootID = &parent.CommentID
+			}
+		}
+
+		query := tx
+		if net.ParseIP(c.AuthorIP) == nil {
+			query = tx.Omit("ip_address")
+		}
+		if err := query.Create(&comment).Error; err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		imported[c.ID] = &comment
+		if comment.IsApproved {
+			approved++
+		}
+	}
+
+	if err := tx.Model(&model.Article{}).Where("article_id = ?", articleID).
+		Update("comment_count", gorm.Expr("comment_count + ?", approved)).Error; err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return 0, err
+	}
+	return len(comments), nil
+}
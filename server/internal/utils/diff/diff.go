@@ -0,0 +1,190 @@
+package diff
+
+import (
+	"strings"
+)
+
+// 差异行类型
+const (
+	OpEqual  = "equal"  // 未变化
+	OpInsert = "insert" // 新增
+	OpDelete = "delete" // 删除
+)
+
+// Line 差异行
+type Line struct {
+	Op      string `json:"op"`       // 操作类型：equal、insert、delete
+	OldLine int    `json:"old_line"` // 旧文本行号（从1开始，新增行为0）
+	NewLine int    `json:"new_line"` // 新文本行号（从1开始，删除行为0）
+	Text    string `json:"text"`     // 行内容
+}
+
+// Result 差异结果
+type Result struct {
+	Lines   []Line `json:"lines"`   // 差异行列表
+	Added   int    `json:"added"`   // 新增行数
+	Removed int    `json:"removed"` // 删除行数
+}
+
+// SplitLines 按行切分文本，统一处理\r\n换行
+func SplitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.Split(text, "\n")
+}
+
+// Lines 计算两段文本的行级差异（Myers算法）
+func Lines(oldText, newText string) *Result {
+	a := SplitLines(oldText)
+	b := SplitLines(newText)
+	ops := myers(a, b)
+
+	result := &Result{Lines: make([]Line, 0, len(ops))}
+	oldNo, newNo := 0, 0
+	for _, op := range ops {
+		switch op {
+		case OpEqual:
+			result.Lines = append(result.Lines, Line{Op: OpEqual, OldLine: oldNo + 1, NewLine: newNo + 1, Text: a[oldNo]})
+			oldNo++
+			newNo++
+		case OpDelete:
+			result.Lines = append(result.Lines, Line{Op: OpDelete, OldLine: oldNo + 1, Text: a[oldNo]})
+			result.Removed++
+			oldNo++
+		case OpInsert:
+			result.Lines = append(result.Lines, Line{Op: OpInsert, NewLine: newNo + 1, Text: b[newNo]})
+			result.Added++
+			newNo++
+		}
+	}
+
+	return result
+}
+
+// maxEditDistance 最短编辑脚本搜索的最大编辑距离。搜索轨迹占用的内存与编辑距离的平方成正比，
+// 超过时不再寻找最短脚本，中间不同的部分整体按删除旧行、新增新行处理
+const maxEditDistance = 1000
+
+// myers 计算将a转换为b的编辑脚本，先去掉首尾相同的行再搜索最短编辑脚本
+func myers(a, b []string) []string {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]string, 0, len(a)+len(b)-prefix-suffix)
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, OpEqual)
+	}
+
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if mid := shortestEdit(midA, midB); mid != nil {
+		ops = append(ops, mid...)
+	} else {
+		for range midA {
+			ops = append(ops, OpDelete)
+		}
+		for range midB {
+			ops = append(ops, OpInsert)
+		}
+	}
+
+	for i := 0; i < suffix; i++ {
+		ops = append(ops, OpEqual)
+	}
+	return ops
+}
+
+// shortestEdit 使用Myers算法计算最短编辑脚本，编辑距离超过 maxEditDistance 时返回nil
+func shortestEdit(a, b []string) []string {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return []string{}
+	}
+	if max > maxEditDistance {
+		max = maxEditDistance
+	}
+
+	// v[k+offset] 保存对角线k上能到达的最远x
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	// trace[d] 保存第d步开始前对角线 -d-1 到 d+1 的结果，只记录用到的部分
+	var trace [][]int
+
+	for d := 0; d <= max; d++ {
+		snapshot := make([]int, 2*d+3)
+		copy(snapshot, v[offset-d-1:offset+d+2])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[k-1+offset] < v[k+1+offset]) {
+				x = v[k+1+offset] // 向下移动（插入）
+			} else {
+				x = v[k-1+offset] + 1 // 向右移动（删除）
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[k+offset] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b)
+			}
+		}
+	}
+
+	return nil
+}
+
+// backtrack 根据搜索轨迹回溯出编辑操作序列
+func backtrack(trace [][]int, a, b []string) []string {
+	x, y := len(a), len(b)
+	var ops []string
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		offset := d + 1
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[k-1+offset] < v[k+1+offset]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[prevK+offset]
+		prevY := prevX - prevK
+
+		// 对角线上的相同行
+		for x > prevX && y > prevY {
+			ops = append(ops, OpEqual)
+			x--
+			y--
+		}
+
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, OpInsert)
+			} else {
+				ops = append(ops, OpDelete)
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	// 反转为正序
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+
+	return ops
+}
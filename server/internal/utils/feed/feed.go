@@ -0,0 +1,51 @@
+package feed
+
+import (
+	"time"
+)
+
+// generator 订阅源生成器名称
+const generator = "go-react-blog"
+
+// 订阅源内容类型
+const (
+	ContentTypeRSS  = "application/rss+xml; charset=utf-8"
+	ContentTypeAtom = "application/atom+xml; charset=utf-8"
+	ContentTypeJSON = "application/feed+json; charset=utf-8"
+)
+
+// Feed 订阅源，与具体格式无关
+type Feed struct {
+	ID          string    // 订阅源唯一标识
+	Title       string    // 标题
+	Description string    // 描述
+	Link        string    // 对应的网页地址
+	FeedURL     string    // 订阅源自身地址
+	Language    string    // 语言
+	Copyright   string    // 版权信息
+	Icon        string    // 图标地址
+	Updated     time.Time // 最后更新时间
+	Items       []*Item   // 条目
+}
+
+// Item 订阅源条目
+type Item struct {
+	ID          string     // 条目唯一标识，发布后保持不变
+	Title       string     // 标题
+	Link        string     // 网页地址
+	Summary     string     // 摘要（纯文本）
+	ContentHTML string     // 全文HTML，为空时只输出摘要
+	AuthorName  string     // 作者名称
+	AuthorURL   string     // 作者主页
+	Categories  []string   // 分类和标签
+	Published   time.Time  // 发布时间
+	Updated     time.Time  // 更新时间
+	Enclosure   *Enclosure // 附件（封面图）
+}
+
+// Enclosure 附件
+type Enclosure struct {
+	URL    string // 地址
+	Type   string // MIME类型
+	Length int64  // 字节数，未知时为0
+}
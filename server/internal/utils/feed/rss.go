@@ -0,0 +1,129 @@
+package feed
+
+import (
+	"encoding/xml"
+	"strconv"
+	"time"
+)
+
+// rss RSS 2.0 根元素
+type rss struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	NSAtom    string     `xml:"xmlns:atom,attr"`
+	NSContent string     `xml:"xmlns:content,attr"`
+	NSDublin  string     `xml:"xmlns:dc,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string      `xml:"title"`
+	Link          string      `xml:"link"`
+	Description   string      `xml:"description"`
+	Language      string      `xml:"language,omitempty"`
+	Copyright     string      `xml:"copyright,omitempty"`
+	LastBuildDate string      `xml:"lastBuildDate,omitempty"`
+	Generator     string      `xml:"generator"`
+	AtomLink      rssAtomLink `xml:"atom:link"`
+	Image         *rssImage   `xml:"image,omitempty"`
+	Items         []rssItem   `xml:"item"`
+}
+
+type rssAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssImage struct {
+	URL   string `xml:"url"`
+	Title string `xml:"title"`
+	Link  string `xml:"link"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        rssGUID       `xml:"guid"`
+	Description string        `xml:"description"`
+	Content     *rssCDATA     `xml:"content:encoded,omitempty"`
+	Creator     string        `xml:"dc:creator,omitempty"`
+	Categories  []string      `xml:"category"`
+	PubDate     string        `xml:"pubDate"`
+	Enclosure   *rssEnclosure `xml:"enclosure,omitempty"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type rssCDATA struct {
+	Value string `xml:",cdata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length string `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// RSS 生成 RSS 2.0 订阅源，全文通过 content:encoded 输出
+func RSS(f *Feed) ([]byte, error) {
+	channel := rssChannel{
+		Title:       f.Title,
+		Link:        f.Link,
+		Description: f.Description,
+		Language:    f.Language,
+		Copyright:   f.Copyright,
+		Generator:   generator,
+		AtomLink:    rssAtomLink{Href: f.FeedURL, Rel: "self", Type: "application/rss+xml"},
+		Items:       make([]rssItem, 0, len(f.Items)),
+	}
+	if !f.Updated.IsZero() {
+		channel.LastBuildDate = f.Updated.Format(time.RFC1123Z)
+	}
+	if f.Icon != "" {
+		channel.Image = &rssImage{URL: f.Icon, Title: f.Title, Link: f.Link}
+	}
+
+	for _, item := range f.Items {
+		entry := rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{Value: item.ID},
+			Description: item.Summary,
+			Creator:     item.AuthorName,
+			Categories:  item.Categories,
+			PubDate:     item.Published.Format(time.RFC1123Z),
+		}
+		if item.ContentHTML != "" {
+			entry.Content = &rssCDATA{Value: item.ContentHTML}
+		}
+		if item.Enclosure != nil {
+			entry.Enclosure = &rssEnclosure{
+				URL:    item.Enclosure.URL,
+				Length: strconv.FormatInt(item.Enclosure.Length, 10),
+				Type:   item.Enclosure.Type,
+			}
+		}
+		channel.Items = append(channel.Items, entry)
+	}
+
+	return marshalXML(rss{
+		Version:   "2.0",
+		NSAtom:    "http://www.w3.org/2005/Atom",
+		NSContent: "http://purl.org/rss/1.0/modules/content/",
+		NSDublin:  "http://purl.org/dc/elements/1.1/",
+		Channel:   channel,
+	})
+}
+
+// marshalXML 序列化XML并添加声明
+func marshalXML(v interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
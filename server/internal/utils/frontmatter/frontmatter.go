@@ -0,0 +1,200 @@
+package frontmatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalid 前置元数据格式错误
+var ErrInvalid = errors.New("前置元数据格式错误")
+
+// Parse 拆分Markdown文件的前置元数据和正文
+//
+// 支持的格式：
+//   - YAML：以 --- 开始，以 --- 或 ... 结束（Hexo、Hugo、Jekyll）
+//   - TOML：以 +++ 开始和结束（Hugo）
+//   - JSON：以 { 开始的JSON对象（Hugo），或以 ;;; 结束的JSON对象（Hexo）
+//   - 省略开头 --- 的YAML，以 --- 结束（Hexo）
+//
+// 没有前置元数据时返回空的 Matter 和原文。
+func Parse(src []byte) (Matter, []byte, error) {
+	src = bytes.TrimPrefix(src, []byte("\xef\xbb\xbf"))
+	src = bytes.ReplaceAll(src, []byte("\r\n"), []byte("\n"))
+
+	first, rest := splitLine(src)
+	switch strings.TrimSpace(string(first)) {
+	case "---":
+		head, body, ok := splitAt(rest, "---", "...")
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: 缺少结束标记 ---", ErrInvalid)
+		}
+		m, err := parseYAML(head)
+		return m, body, err
+	case "+++":
+		head, body, ok := splitAt(rest, "+++")
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: 缺少结束标记 +++", ErrInvalid)
+		}
+		m, err := parseTOML(head)
+		return m, body, err
+	}
+
+	if bytes.HasPrefix(bytes.TrimLeft(src, " \t\n"), []byte("{")) {
+		// Hexo 的JSON前置元数据以 ;;; 结束
+		if head, body, ok := splitAt(src, ";;;"); ok {
+			m, err := parseJSON(bytes.NewReader(head))
+			return m, body, err
+		}
+		return parseJSONPrefix(src)
+	}
+
+	// Hexo 允许省略开头的 ---，仅当结束标记之前的内容是键值对时才按前置元数据处理
+	if head, body, ok := splitAt(src, "---"); ok && looksLikeYAMLMap(head) {
+		if m, err := parseYAML(head); err == nil {
+			return m, body, nil
+		}
+	}
+
+	return Matter{}, src, nil
+}
+
+// splitLine 拆出第一行
+func splitLine(src []byte) ([]byte, []byte) {
+	if i := bytes.IndexByte(src, '\n'); i >= 0 {
+		return src[:i], src[i+1:]
+	}
+	return src, nil
+}
+
+// splitAt 在第一个内容为结束标记的行处拆分
+func splitAt(src []byte, markers ...string) ([]byte, []byte, bool) {
+	offset := 0
+	for offset < len(src) {
+		line, next := src[offset:], len(src)
+		if i := bytes.IndexByte(line, '\n'); i >= 0 {
+			line, next = line[:i], offset+i+1
+		}
+		trimmed := strings.TrimRight(string(line), " \t")
+		for _, marker := range markers {
+			if trimmed == marker {
+				return src[:offset], src[next:], true
+			}
+		}
+		offset = next
+	}
+	return nil, nil, false
+}
+
+// looksLikeYAMLMap 判断第一行非空内容是否为 key: value 形式
+func looksLikeYAMLMap(head []byte) bool {
+	for _, line := range strings.Split(string(head), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.Index(line, ":")
+		if i <= 0 {
+			return false
+		}
+		key := line[:i]
+		return !strings.ContainsAny(key, " \t[]{}()<>") || strings.HasPrefix(key, `"`)
+	}
+	return false
+}
+
+// parseYAML 解析YAML前置元数据
+func parseYAML(head []byte) (Matter, error) {
+	if len(bytes.TrimSpace(head)) == 0 {
+		return Matter{}, nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(head, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	v, err := yamlValue(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: 顶层必须是键值对", ErrInvalid)
+	}
+	return m, nil
+}
+
+// yamlValue 将YAML节点转换为Go值
+//
+// 日期保留为字符串，由 Matter.Time 按指定时区解析，
+// 避免没有时区的日期被当作UTC。
+func yamlValue(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return map[string]interface{}{}, nil
+		}
+		return yamlValue(node.Content[0])
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	case yaml.MappingNode:
+		m := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			v, err := yamlValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			m[node.Content[i].Value] = v
+		}
+		return m, nil
+	case yaml.SequenceNode:
+		list := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			v, err := yamlValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	}
+
+	if node.ShortTag() == "!!timestamp" {
+		return node.Value, nil
+	}
+	var v interface{}
+	if err := node.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// parseJSON 解析JSON前置元数据
+func parseJSON(r *bytes.Reader) (Matter, error) {
+	m := Matter{}
+	d := json.NewDecoder(r)
+	d.UseNumber()
+	if err := d.Decode(&m); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return m, nil
+}
+
+// parseJSONPrefix 解析位于文件开头的JSON对象，对象之后的内容为正文
+func parseJSONPrefix(src []byte) (Matter, []byte, error) {
+	m := Matter{}
+	d := json.NewDecoder(bytes.NewReader(src))
+	d.UseNumber()
+	if err := d.Decode(&m); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	body := src[d.InputOffset():]
+	_, rest := splitLine(body)
+	if len(bytes.TrimSpace(body[:len(body)-len(rest)])) == 0 {
+		body = rest
+	}
+	return m, body, nil
+}
@@ -0,0 +1,157 @@
+package frontmatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeLayouts 日期字段可能出现的格式，没有时区的按本地时间处理
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 -07:00",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04 -0700",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"2006/01/02",
+}
+
+// Matter 前置元数据，键名区分大小写
+type Matter map[string]interface{}
+
+// String 返回第一个非空字段的字符串值，数字、布尔值和日期转换为字符串
+func (m Matter) String(keys ...string) string {
+	for _, key := range keys {
+		if s := strings.TrimSpace(toString(m[key])); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// Strings 返回字符串列表字段，单个字符串视为只有一个元素的列表，嵌套列表会被展开
+func (m Matter) Strings(key string) []string {
+	var list []string
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		if items, ok := v.([]interface{}); ok {
+			for _, item := range items {
+				walk(item)
+			}
+			return
+		}
+		if s := strings.TrimSpace(toString(v)); s != "" {
+			list = append(list, s)
+		}
+	}
+	walk(m[key])
+	return list
+}
+
+// Paths 返回层级列表字段，每个元素为从上级到下级的名称
+//
+// 字符串元素是单层路径，嵌套列表元素是多层路径，例如
+// [A, [B, C]] 返回 [[A] [B C]]。
+func (m Matter) Paths(key string) [][]string {
+	var paths [][]string
+	v := m[key]
+	items, ok := v.([]interface{})
+	if !ok {
+		items = []interface{}{v}
+	}
+	for _, item := range items {
+		var path []string
+		if sub, ok := item.([]interface{}); ok {
+			for _, name := range sub {
+				if s := strings.TrimSpace(toString(name)); s != "" {
+					path = append(path, s)
+				}
+			}
+		} else if s := strings.TrimSpace(toString(item)); s != "" {
+			path = []string{s}
+		}
+		if len(path) > 0 {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// Bool 返回布尔字段，字段不存在或无法识别时 ok 为假
+func (m Matter) Bool(key string) (value bool, ok bool) {
+	switch v := m[key].(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "yes", "on":
+				return true, true
+			case "no", "off":
+				return false, true
+			}
+			return false, false
+		}
+		return b, true
+	}
+	return false, false
+}
+
+// Time 返回第一个可以解析的日期字段，没有时区的日期按 loc 解析
+func (m Matter) Time(loc *time.Location, keys ...string) (time.Time, bool) {
+	for _, key := range keys {
+		switch v := m[key].(type) {
+		case time.Time:
+			return v, true
+		case string:
+			if t, ok := ParseTime(v, loc); ok {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// ParseTime 按常见格式解析日期，没有时区的日期按 loc 解析
+func ParseTime(s string, loc *time.Location) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// toString 将标量值转换为字符串，列表和对象返回空字符串
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v)
+	}
+	return ""
+}
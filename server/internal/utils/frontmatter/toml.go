@@ -0,0 +1,570 @@
+package frontmatter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// parseTOML 解析TOML前置元数据
+//
+// 支持前置元数据中常用的语法：键值对、点分键、[table] 和 [[array]] 表头、
+// 字符串（含多行字符串）、整数、浮点数、布尔值、日期、数组和内联表。
+// 带时区的日期解析为 time.Time，不带时区的日期保留为字符串。
+func parseTOML(head []byte) (Matter, error) {
+	p := &tomlParser{s: string(head)}
+	m, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// tomlParser TOML解析器
+type tomlParser struct {
+	s   string
+	pos int
+}
+
+// errorf 生成带行号的错误
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	line := strings.Count(p.s[:p.pos], "\n") + 1
+	return fmt.Errorf("%w: 第%d行 %s", ErrInvalid, line, fmt.Sprintf(format, args...))
+}
+
+// eof 是否已到结尾
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+// peek 当前字符，结尾返回0
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+// skipSpaces 跳过行内空白
+func (p *tomlParser) skipSpaces() {
+	for !p.eof() && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// skipComment 跳过注释直到行尾
+func (p *tomlParser) skipComment() {
+	if p.peek() == '#' {
+		for !p.eof() && p.s[p.pos] != '\n' {
+			p.pos++
+		}
+	}
+}
+
+// skipBlank 跳过空白、换行和注释
+func (p *tomlParser) skipBlank() {
+	for !p.eof() {
+		switch p.s[p.pos] {
+		case ' ', '\t', '\r', '\n':
+			p.pos++
+		case '#':
+			p.skipComment()
+		default:
+			return
+		}
+	}
+}
+
+// endOfLine 要求当前行剩余部分只有空白和注释
+func (p *tomlParser) endOfLine() error {
+	p.skipSpaces()
+	p.skipComment()
+	if p.peek() == '\r' {
+		p.pos++
+	}
+	if p.eof() {
+		return nil
+	}
+	if p.s[p.pos] != '\n' {
+		return p.errorf("多余的内容 %q", p.rest(10))
+	}
+	p.pos++
+	return nil
+}
+
+// rest 返回当前位置之后的若干字符，用于错误提示
+func (p *tomlParser) rest(n int) string {
+	end := p.pos + n
+	if end > len(p.s) {
+		end = len(p.s)
+	}
+	return p.s[p.pos:end]
+}
+
+// parse 解析整个文档
+func (p *tomlParser) parse() (Matter, error) {
+	root := Matter{}
+	current := map[string]interface{}(root)
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return root, nil
+		}
+
+		if p.peek() != '[' {
+			keys, err := p.parseKey()
+			if err != nil {
+				return nil, err
+			}
+			p.skipSpaces()
+			if p.peek() != '=' {
+				return nil, p.errorf("缺少 =")
+			}
+			p.pos++
+			p.skipSpaces()
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.set(current, keys, value); err != nil {
+				return nil, err
+			}
+			if err := p.endOfLine(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// 表头
+		arrayTable := strings.HasPrefix(p.s[p.pos:], "[[")
+		if arrayTable {
+			p.pos += 2
+		} else {
+			p.pos++
+		}
+		p.skipSpaces()
+		keys, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		closing := "]"
+		if arrayTable {
+			closing = "]]"
+		}
+		if !strings.HasPrefix(p.s[p.pos:], closing) {
+			return nil, p.errorf("缺少 %s", closing)
+		}
+		p.pos += len(closing)
+
+		parent, err := p.table(root, keys[:len(keys)-1])
+		if err != nil {
+			return nil, err
+		}
+		last := keys[len(keys)-1]
+		if arrayTable {
+			list, _ := parent[last].([]interface{})
+			if _, exists := parent[last]; exists && list == nil {
+				return nil, p.errorf("键 %s 重复定义", last)
+			}
+			current = map[string]interface{}{}
+			parent[last] = append(list, current)
+		} else {
+			if current, err = p.table(parent, []string{last}); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.endOfLine(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// table 按键路径查找或创建表，路径上的表数组取最后一个元素
+func (p *tomlParser) table(m map[string]interface{}, keys []string) (map[string]interface{}, error) {
+	for _, key := range keys {
+		switch v := m[key].(type) {
+		case nil:
+			sub := map[string]interface{}{}
+			m[key] = sub
+			m = sub
+		case map[string]interface{}:
+			m = v
+		case []interface{}:
+			if len(v) == 0 {
+				return nil, p.errorf("键 %s 不是表", key)
+			}
+			sub, ok := v[len(v)-1].(map[string]interface{})
+			if !ok {
+				return nil, p.errorf("键 %s 不是表", key)
+			}
+			m = sub
+		default:
+			return nil, p.errorf("键 %s 不是表", key)
+		}
+	}
+	return m, nil
+}
+
+// set 按点分键设置值
+func (p *tomlParser) set(m map[string]interface{}, keys []string, value interface{}) error {
+	parent, err := p.table(m, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	if _, exists := parent[last]; exists {
+		return p.errorf("键 %s 重复定义", last)
+	}
+	parent[last] = value
+	return nil
+}
+
+// parseKey 解析键，点分键返回各级键名
+func (p *tomlParser) parseKey() ([]string, error) {
+	var keys []string
+	for {
+		p.skipSpaces()
+		var key string
+		switch p.peek() {
+		case '"':
+			s, err := p.parseBasicString()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		case '\'':
+			s, err := p.parseLiteralString()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		default:
+			start := p.pos
+			for !p.eof() && isBareKeyChar(p.s[p.pos]) {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, p.errorf("无效的键 %q", p.rest(10))
+			}
+			key = p.s[start:p.pos]
+		}
+		keys = append(keys, key)
+
+		p.skipSpaces()
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+// isBareKeyChar 是否为裸键允许的字符
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// parseValue 解析值
+func (p *tomlParser) parseValue() (interface{}, error) {
+	switch c := p.peek(); {
+	case c == '"':
+		if strings.HasPrefix(p.s[p.pos:], `"""`) {
+			return p.parseMultilineBasicString()
+		}
+		return p.parseBasicString()
+	case c == '\'':
+		if strings.HasPrefix(p.s[p.pos:], `'''`) {
+			return p.parseMultilineLiteralString()
+		}
+		return p.parseLiteralString()
+	case c == '[':
+		return p.parseArray()
+	case c == '{':
+		return p.parseInlineTable()
+	case c == 0:
+		return nil, p.errorf("缺少值")
+	}
+	return p.parseScalar()
+}
+
+// parseBasicString 解析双引号字符串
+func (p *tomlParser) parseBasicString() (string, error) {
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.eof() || p.s[p.pos] == '\n' {
+			return "", p.errorf("字符串未结束")
+		}
+		c := p.s[p.pos]
+		if c == '"' {
+			p.pos++
+			return b.String(), nil
+		}
+		if c == '\\' {
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+			continue
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+}
+
+// parseMultilineBasicString 解析三个双引号的多行字符串
+func (p *tomlParser) parseMultilineBasicString() (string, error) {
+	p.pos += 3
+	p.skipNewline()
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("多行字符串未结束")
+		}
+		if strings.HasPrefix(p.s[p.pos:], `"""`) {
+			p.pos += 3
+			// 结束标记前最多允许两个引号属于内容
+			for i := 0; i < 2 && p.peek() == '"'; i++ {
+				b.WriteByte('"')
+				p.pos++
+			}
+			return b.String(), nil
+		}
+		c := p.s[p.pos]
+		if c == '\\' {
+			// 行尾的反斜杠会删除换行和下一行开头的空白
+			j := p.pos + 1
+			for j < len(p.s) && (p.s[j] == ' ' || p.s[j] == '\t' || p.s[j] == '\r') {
+				j++
+			}
+			if j < len(p.s) && p.s[j] == '\n' {
+				p.pos = j
+				for !p.eof() && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+					p.pos++
+				}
+				continue
+			}
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+			continue
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+}
+
+// parseLiteralString 解析单引号字符串，不处理转义
+func (p *tomlParser) parseLiteralString() (string, error) {
+	p.pos++
+	end := strings.IndexAny(p.s[p.pos:], "'\n")
+	if end < 0 || p.s[p.pos+end] != '\'' {
+		return "", p.errorf("字符串未结束")
+	}
+	s := p.s[p.pos : p.pos+end]
+	p.pos += end + 1
+	return s, nil
+}
+
+// parseMultilineLiteralString 解析三个单引号的多行字符串
+func (p *tomlParser) parseMultilineLiteralString() (string, error) {
+	p.pos += 3
+	p.skipNewline()
+	end := strings.Index(p.s[p.pos:], `'''`)
+	if end < 0 {
+		return "", p.errorf("多行字符串未结束")
+	}
+	s := p.s[p.pos : p.pos+end]
+	p.pos += end + 3
+	for i := 0; i < 2 && p.peek() == '\''; i++ {
+		s += "'"
+		p.pos++
+	}
+	return s, nil
+}
+
+// skipNewline 跳过多行字符串开始标记之后紧跟的换行
+func (p *tomlParser) skipNewline() {
+	if strings.HasPrefix(p.s[p.pos:], "\r\n") {
+		p.pos += 2
+	} else if p.peek() == '\n' {
+		p.pos++
+	}
+}
+
+// parseEscape 解析反斜杠转义
+func (p *tomlParser) parseEscape(b *strings.Builder) error {
+	if p.pos+1 >= len(p.s) {
+		return p.errorf("无效的转义")
+	}
+	c := p.s[p.pos+1]
+	p.pos += 2
+	switch c {
+	case 'b':
+		b.WriteByte('\b')
+	case 't':
+		b.WriteByte('\t')
+	case 'n':
+		b.WriteByte('\n')
+	case 'f':
+		b.WriteByte('\f')
+	case 'r':
+		b.WriteByte('\r')
+	case 'e':
+		b.WriteByte(0x1b)
+	case '"':
+		b.WriteByte('"')
+	case '\\':
+		b.WriteByte('\\')
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+		if p.pos+n > len(p.s) {
+			return p.errorf("无效的转义")
+		}
+		code, err := strconv.ParseUint(p.s[p.pos:p.pos+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return p.errorf("无效的转义")
+		}
+		b.WriteRune(rune(code))
+		p.pos += n
+	default:
+		return p.errorf("无效的转义 \\%c", c)
+	}
+	return nil
+}
+
+// parseArray 解析数组，允许跨行、注释和末尾逗号
+func (p *tomlParser) parseArray() ([]interface{}, error) {
+	p.pos++
+	list := make([]interface{}, 0)
+	for {
+		p.skipBlank()
+		if p.peek() == ']' {
+			p.pos++
+			return list, nil
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+
+		p.skipBlank()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return list, nil
+		default:
+			return nil, p.errorf("数组缺少 , 或 ]")
+		}
+	}
+}
+
+// parseInlineTable 解析内联表
+func (p *tomlParser) parseInlineTable() (map[string]interface{}, error) {
+	p.pos++
+	m := map[string]interface{}{}
+	p.skipSpaces()
+	if p.peek() == '}' {
+		p.pos++
+		return m, nil
+	}
+	for {
+		keys, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if p.peek() != '=' {
+			return nil, p.errorf("缺少 =")
+		}
+		p.pos++
+		p.skipSpaces()
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.set(m, keys, value); err != nil {
+			return nil, err
+		}
+
+		p.skipSpaces()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return m, nil
+		default:
+			return nil, p.errorf("内联表缺少 , 或 }")
+		}
+	}
+}
+
+// parseScalar 解析布尔值、数字和日期
+func (p *tomlParser) parseScalar() (interface{}, error) {
+	start := p.pos
+	for !p.eof() && isScalarChar(p.s[p.pos]) {
+		p.pos++
+	}
+	// 日期和时间之间可以用空格分隔
+	if p.pos-start == 10 && p.peek() == ' ' && p.pos+1 < len(p.s) && p.s[p.pos+1] >= '0' && p.s[p.pos+1] <= '9' {
+		p.pos++
+		for !p.eof() && isScalarChar(p.s[p.pos]) {
+			p.pos++
+		}
+	}
+	token := p.s[start:p.pos]
+	if token == "" {
+		return nil, p.errorf("无效的值 %q", p.rest(10))
+	}
+
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "inf", "+inf", "-inf", "nan", "+nan", "-nan":
+		f, _ := strconv.ParseFloat(strings.TrimPrefix(token, "+"), 64)
+		return f, nil
+	}
+
+	// 日期：带时区的转换为时间，不带时区的保留原文
+	if len(token) >= 10 && token[4] == '-' && token[7] == '-' {
+		if t, err := time.Parse(time.RFC3339Nano, strings.Replace(token, " ", "T", 1)); err == nil {
+			return t, nil
+		}
+		return token, nil
+	}
+	// 只有时间
+	if len(token) >= 8 && token[2] == ':' {
+		return token, nil
+	}
+
+	if i, err := strconv.ParseInt(token, 0, 64); err == nil && !hasLeadingZero(token) {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(strings.ReplaceAll(token, "_", ""), 64); err == nil {
+		return f, nil
+	}
+	p.pos = start
+	return nil, p.errorf("无效的值 %q", token)
+}
+
+// isScalarChar 是否为数字、布尔值和日期中可能出现的字符
+func isScalarChar(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' ||
+		c == '+' || c == '-' || c == '_' || c == '.' || c == ':'
+}
+
+// hasLeadingZero 十进制整数不允许前导零，避免 0755 被当作八进制
+func hasLeadingZero(token string) bool {
+	token = strings.TrimLeft(token, "+-")
+	return len(token) > 1 && token[0] == '0' && token[1] >= '0' && token[1] <= '9'
+}
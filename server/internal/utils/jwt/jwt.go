@@ -1,6 +1,8 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -8,14 +10,27 @@ import (
 	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
 )
 
-// GenerateToken 生成JWT令牌
-func GenerateToken(userID int, username string, roleIDs []int, secret string, expire int, issuer string) (string, error) {
+// NewTokenID 生成令牌唯一标识，用作 jti 和刷新令牌族ID
+func NewTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// GenerateToken 生成JWT令牌，tokenID 为令牌唯一标识(jti)，familyID 为签发时所属的刷新令牌族
+func GenerateToken(userID int, username string, roleIDs []int, tokenID, familyID string, version int64, keys *KeySet, expire int, issuer string) (string, error) {
 	// 创建JWT声明
 	claims := model.CustomClaims{
-		UserID:   userID,
-		Username: username,
-		RoleIDs:  roleIDs,
+		UserID:    userID,
+		Username:  username,
+		RoleIDs:   roleIDs,
+		FamilyID:  familyID,
+		Version:   version,
+		TokenType: model.TokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expire) * time.Second)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -23,19 +38,20 @@ func GenerateToken(userID int, username string, roleIDs []int, secret string, ex
 		},
 	}
 
-	// 创建令牌
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// 签名令牌
-	return token.SignedString([]byte(secret))
+	// 使用当前签名密钥签名
+	return keys.Sign(claims)
 }
 
-// GenerateRefreshToken 生成刷新令牌
-func GenerateRefreshToken(userID int, secret string, expire int, issuer string) (string, error) {
+// GenerateRefreshToken 生成刷新令牌，每次刷新都会签发新的 tokenID，同一次登录的刷新令牌共用 familyID
+func GenerateRefreshToken(userID int, tokenID, familyID string, version int64, keys *KeySet, expire int, issuer string) (string, error) {
 	// 创建JWT声明
 	claims := model.RefreshClaims{
-		UserID: userID,
+		UserID:    userID,
+		FamilyID:  familyID,
+		Version:   version,
+		TokenType: model.TokenTypeRefresh,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expire) * time.Second)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -43,26 +59,38 @@ func GenerateRefreshToken(userID int, secret string, expire int, issuer string)
 		},
 	}
 
-	// 创建令牌
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	// 使用当前签名密钥签名
+	return keys.Sign(claims)
+}
 
-	// 签名令牌
-	return token.SignedString([]byte(secret))
+// GenerateMFAToken 生成两步验证临时令牌，purpose 为令牌用途
+func GenerateMFAToken(userID int, username, purpose, tokenID string, keys *KeySet, expire int, issuer string) (string, error) {
+	// 创建JWT声明
+	claims := model.MFAClaims{
+		UserID:    userID,
+		Username:  username,
+		Purpose:   purpose,
+		TokenType: model.TokenTypeMFA,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expire) * time.Second)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    issuer,
+		},
+	}
+
+	// 使用当前签名密钥签名
+	return keys.Sign(claims)
 }
 
 // ParseToken 解析JWT令牌
-func ParseToken(tokenString string, secret string) (*model.CustomClaims, error) {
+func ParseToken(tokenString string, keys *KeySet) (*model.CustomClaims, error) {
 	// 创建声明
 	claims := &model.CustomClaims{}
 
-	// 解析令牌
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		// 验证签名算法
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("无效的签名算法")
-		}
-		return []byte(secret), nil
-	})
+	// 解析令牌，按 kid 选择验证密钥
+	token, err := jwt.ParseWithClaims(tokenString, claims, keys.Keyfunc)
 
 	// 处理解析错误
 	if err != nil {
@@ -70,7 +98,7 @@ func ParseToken(tokenString string, secret string) (*model.CustomClaims, error)
 	}
 
 	// 验证令牌有效性
-	if !token.Valid {
+	if !token.Valid || claims.TokenType != model.TokenTypeAccess {
 		return nil, errors.New("无效的令牌")
 	}
 
@@ -78,18 +106,33 @@ func ParseToken(tokenString string, secret string) (*model.CustomClaims, error)
 }
 
 // ParseRefreshToken 解析刷新令牌
-func ParseRefreshToken(tokenString string, secret string) (*model.RefreshClaims, error) {
+func ParseRefreshToken(tokenString string, keys *KeySet) (*model.RefreshClaims, error) {
 	// 创建声明
 	claims := &model.RefreshClaims{}
 
-	// 解析令牌
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		// 验证签名算法
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("无效的签名算法")
-		}
-		return []byte(secret), nil
-	})
+	// 解析令牌，按 kid 选择验证密钥
+	token, err := jwt.ParseWithClaims(tokenString, claims, keys.Keyfunc)
+
+	// 处理解析错误
+	if err != nil {
+		return nil, err
+	}
+
+	// 验证令牌有效性
+	if !token.Valid || claims.TokenType != model.TokenTypeRefresh {
+		return nil, errors.New("无效的令牌")
+	}
+
+	return claims, nil
+}
+
+// ParseMFAToken 解析两步验证临时令牌
+func ParseMFAToken(tokenString string, keys *KeySet) (*model.MFAClaims, error) {
+	// 创建声明
+	claims := &model.MFAClaims{}
+
+	// 解析令牌，按 kid 选择验证密钥
+	token, err := jwt.ParseWithClaims(tokenString, claims, keys.Keyfunc)
 
 	// 处理解析错误
 	if err != nil {
@@ -97,7 +140,7 @@ func ParseRefreshToken(tokenString string, secret string) (*model.RefreshClaims,
 	}
 
 	// 验证令牌有效性
-	if !token.Valid {
+	if !token.Valid || claims.TokenType != model.TokenTypeMFA {
 		return nil, errors.New("无效的令牌")
 	}
 
@@ -0,0 +1,334 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 支持的签名算法
+const (
+	AlgorithmHS256 = "HS256" // 共享密钥，仅用于未配置非对称密钥时兼容旧配置，不在JWKS中公布
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// minRSAKeyBits RSA 密钥的最小长度
+const minRSAKeyBits = 2048
+
+var (
+	// ErrNoSigningKey 当前没有可用于签名的密钥
+	ErrNoSigningKey = errors.New("没有可用的令牌签名密钥")
+	// ErrUnknownKey 令牌的 kid 不存在、密钥已过期或签名算法与密钥不符
+	ErrUnknownKey = errors.New("无效的令牌签名密钥")
+)
+
+// Key 令牌签名密钥
+type Key struct {
+	ID         string
+	Algorithm  string
+	PrivateKey interface{}      // 签名用的私钥，HS256 为共享密钥；为nil时只用于验证
+	PublicKey  crypto.PublicKey // 验证用的公钥，HS256 为共享密钥
+	NotBefore  time.Time        // 开始用于签名的时间，之前只在JWKS中公布，供验证方提前缓存
+	ExpireAt   time.Time        // 停止验证的时间，为零表示不过期
+}
+
+// KeySet 令牌签名密钥集合，支持按计划轮换：新密钥在 NotBefore 之后接替签名，
+// 旧密钥在 ExpireAt 之前的令牌最长有效期内不再签名，只用于验证已签发的令牌
+type KeySet struct {
+	keys        []*Key
+	byID        map[string]*Key
+	maxLifetime time.Duration // 签发的令牌最长有效期
+}
+
+// NewKeySet 创建密钥集合，maxLifetime 为签发的令牌最长有效期，用于确定旧密钥何时停止签名
+func NewKeySet(keys []*Key, maxLifetime time.Duration) (*KeySet, error) {
+	set := &KeySet{byID: make(map[string]*Key, len(keys)), maxLifetime: maxLifetime}
+	for _, key := range keys {
+		if _, exists := set.byID[key.ID]; exists {
+			return nil, fmt.Errorf("令牌签名密钥 kid %q 重复", key.ID)
+		}
+		if jwt.GetSigningMethod(key.Algorithm) == nil {
+			return nil, fmt.Errorf("令牌签名密钥 %s 的算法 %q 不受支持", key.ID, key.Algorithm)
+		}
+		set.keys = append(set.keys, key)
+		set.byID[key.ID] = key
+	}
+	if len(set.keys) == 0 {
+		return nil, ErrNoSigningKey
+	}
+	return set, nil
+}
+
+// NewHMACKeySet 使用共享密钥创建 HS256 密钥集合，签发的令牌不带 kid，与未启用密钥轮换前的令牌兼容
+func NewHMACKeySet(secret string) (*KeySet, error) {
+	if secret == "" {
+		return nil, ErrNoSigningKey
+	}
+	return NewKeySet([]*Key{{
+		Algorithm:  AlgorithmHS256,
+		PrivateKey: []byte(secret),
+		PublicKey:  []byte(secret),
+	}}, 0)
+}
+
+// SigningKey 返回当前用于签名的密钥：已到 NotBefore、且在 ExpireAt 前还能覆盖令牌最长有效期的密钥中最新的一个
+func (s *KeySet) SigningKey(now time.Time) (*Key, error) {
+	var current *Key
+	for _, key := range s.keys {
+		if key.PrivateKey == nil || now.Before(key.NotBefore) {
+			continue
+		}
+		if !key.ExpireAt.IsZero() && now.Add(s.maxLifetime).After(key.ExpireAt) {
+			continue
+		}
+		if current == nil || key.NotBefore.After(current.NotBefore) {
+			current = key
+		}
+	}
+	if current == nil {
+		return nil, ErrNoSigningKey
+	}
+	return current, nil
+}
+
+// Sign 使用当前签名密钥签名，令牌头中写入 kid
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	key, err := s.SigningKey(time.Now())
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
+	return token.SignedString(key.PrivateKey)
+}
+
+// Keyfunc 按令牌头中的 kid 查找验证密钥，签名算法必须与密钥一致，防止算法混淆攻击
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.byID[kid]
+	if !ok || token.Method.Alg() != key.Algorithm {
+		return nil, ErrUnknownKey
+	}
+	if !key.ExpireAt.IsZero() && time.Now().After(key.ExpireAt) {
+		return nil, ErrUnknownKey
+	}
+	return key.PublicKey, nil
+}
+
+// JWKS 返回未过期的非对称密钥的公钥集（RFC 7517），包括尚未开始签名的密钥
+func (s *KeySet) JWKS() JSONWebKeySet {
+	now := time.Now()
+	set := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(s.keys))}
+	for _, key := range s.keys {
+		if key.Algorithm == AlgorithmHS256 || (!key.ExpireAt.IsZero() && now.After(key.ExpireAt)) {
+			continue
+		}
+		if jwk, err := publicJWK(key.PublicKey); err == nil {
+			jwk.Kid = key.ID
+			jwk.Alg = key.Algorithm
+			jwk.Use = "sig"
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+// JSONWebKeySet 公钥集
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JSONWebKey 公钥（RFC 7517）
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// KeyOptions 从文件加载密钥的参数
+type KeyOptions struct {
+	ID             string // 为空时使用公钥的 RFC 7638 指纹
+	Algorithm      string // 为空时根据密钥类型确定
+	PrivateKeyFile string // PEM 格式的私钥，为空时只用于验证
+	PublicKeyFile  string // PEM 格式的公钥，只在没有私钥时使用
+	NotBefore      time.Time
+	ExpireAt       time.Time
+}
+
+// LoadKey 从 PEM 文件加载非对称密钥，支持 RSA（PKCS#1/PKCS#8）、ECDSA P-256 和 Ed25519
+func LoadKey(opts KeyOptions) (*Key, error) {
+	key := &Key{ID: opts.ID, NotBefore: opts.NotBefore, ExpireAt: opts.ExpireAt}
+	switch {
+	case opts.PrivateKeyFile != "":
+		block, err := readPEM(opts.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		private, err := parsePrivateKey(block)
+		if err != nil {
+			return nil, fmt.Errorf("解析私钥 %s 失败：%w", opts.PrivateKeyFile, err)
+		}
+		key.PrivateKey = private
+		key.PublicKey = private.Public()
+	case opts.PublicKeyFile != "":
+		block, err := readPEM(opts.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("解析公钥 %s 失败：%w", opts.PublicKeyFile, err)
+		}
+		key.PublicKey = public
+	default:
+		return nil, errors.New("令牌签名密钥未配置私钥或公钥文件")
+	}
+
+	algorithm, err := keyAlgorithm(key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Algorithm != "" && opts.Algorithm != algorithm {
+		return nil, fmt.Errorf("密钥类型与算法 %s 不符", opts.Algorithm)
+	}
+	key.Algorithm = algorithm
+
+	if key.ID == "" {
+		if key.ID, err = Thumbprint(key.PublicKey); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// Thumbprint 计算公钥的 RFC 7638 指纹，用作默认的 kid
+func Thumbprint(public crypto.PublicKey) (string, error) {
+	jwk, err := publicJWK(public)
+	if err != nil {
+		return "", err
+	}
+	// 指纹只包含必需字段，且按字段名排序
+	var members interface{}
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{jwk.Crv, jwk.Kty, jwk.X, jwk.Y}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// keyAlgorithm 根据公钥类型确定签名算法
+func keyAlgorithm(public crypto.PublicKey) (string, error) {
+	switch k := public.(type) {
+	case *rsa.PublicKey:
+		if k.N.BitLen() < minRSAKeyBits {
+			return "", fmt.Errorf("RSA 密钥长度不能小于 %d 位", minRSAKeyBits)
+		}
+		return AlgorithmRS256, nil
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return "", errors.New("ECDSA 密钥只支持 P-256 曲线")
+		}
+		return AlgorithmES256, nil
+	case ed25519.PublicKey:
+		return AlgorithmEdDSA, nil
+	default:
+		return "", fmt.Errorf("不支持的密钥类型 %T", public)
+	}
+}
+
+// publicJWK 将公钥转换为 JWK
+func publicJWK(public crypto.PublicKey) (JSONWebKey, error) {
+	encode := base64.RawURLEncoding.EncodeToString
+	switch k := public.(type) {
+	case *rsa.PublicKey:
+		return JSONWebKey{Kty: "RSA", N: encode(k.N.Bytes()), E: encode(big.NewInt(int64(k.E)).Bytes())}, nil
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		return JSONWebKey{Kty: "EC", Crv: k.Curve.Params().Name, X: encode(k.X.FillBytes(make([]byte, size))), Y: encode(k.Y.FillBytes(make([]byte, size)))}, nil
+	case ed25519.PublicKey:
+		return JSONWebKey{Kty: "OKP", Crv: "Ed25519", X: encode(k)}, nil
+	default:
+		return JSONWebKey{}, fmt.Errorf("不支持的密钥类型 %T", public)
+	}
+}
+
+// readPEM 读取 PEM 文件中的第一个块
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s 不是 PEM 格式", path)
+	}
+	return block, nil
+}
+
+// parsePrivateKey 解析 PKCS#8、PKCS#1 或 SEC 1 格式的私钥
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	var private interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		private, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("不支持的私钥类型 %T", private)
+	}
+	return signer, nil
+}
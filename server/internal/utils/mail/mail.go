@@ -0,0 +1,160 @@
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 连接参数
+const (
+	dialTimeout = 10 * time.Second // 连接超时
+	sendTimeout = 30 * time.Second // 整个发送过程的超时
+	tlsPort     = 465              // 使用隐式TLS（SMTPS）的端口，其他端口在服务器支持时使用STARTTLS
+	lineLength  = 76               // Base64正文每行长度
+)
+
+// Config SMTP服务器配置
+type Config struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string // 发件人地址
+	FromName string // 发件人名称，为空时只显示地址
+}
+
+// Message 邮件
+type Message struct {
+	To      string
+	Subject string
+	HTML    string // HTML正文
+}
+
+// Send 通过SMTP服务器发送邮件
+func Send(cfg Config, msg Message) error {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return fmt.Errorf("发件人地址无效: %w", err)
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("收件人地址无效: %w", err)
+	}
+	if cfg.Host == "" || cfg.Port <= 0 {
+		return errors.New("未配置SMTP服务器")
+	}
+	data, err := buildMessage(cfg, from, to, msg)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	tlsConfig := &tls.Config{ServerName: cfg.Host}
+	var conn net.Conn
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	if cfg.Port == tlsPort {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(sendTimeout))
+
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if cfg.Port != tlsPort {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return err
+			}
+		}
+	}
+	if cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// buildMessage 生成邮件内容，主题和名称按RFC 2047编码，正文使用Base64编码
+func buildMessage(cfg Config, from, to *mail.Address, msg Message) ([]byte, error) {
+	if strings.ContainsAny(msg.Subject, "\r\n") {
+		return nil, errors.New("邮件主题不能包含换行")
+	}
+	messageID, err := newMessageID(from.Address)
+	if err != nil {
+		return nil, err
+	}
+	sender := &mail.Address{Name: cfg.FromName, Address: from.Address}
+
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		buf.WriteString(key + ": " + value + "\r\n")
+	}
+	header("From", sender.String())
+	header("To", to.String())
+	header("Subject", mime.BEncoding.Encode("UTF-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", messageID)
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/html; charset=UTF-8")
+	header("Content-Transfer-Encoding", "base64")
+	buf.WriteString("\r\n")
+
+	body := base64.StdEncoding.EncodeToString([]byte(msg.HTML))
+	for len(body) > lineLength {
+		buf.WriteString(body[:lineLength] + "\r\n")
+		body = body[lineLength:]
+	}
+	buf.WriteString(body + "\r\n")
+	return buf.Bytes(), nil
+}
+
+// newMessageID 生成邮件ID，域名部分取发件人地址的域名
+func newMessageID(from string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = from[i+1:]
+	}
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">", nil
+}
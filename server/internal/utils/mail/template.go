@@ -0,0 +1,63 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+)
+
+// 邮件模板
+const (
+	TemplateVerifyEmail   = "verify_email.html"   // 邮箱验证
+	TemplateResetPassword = "reset_password.html" // 找回密码
+	TemplateMagicLink     = "magic_link.html"     // 邮件登录链接
+	TemplateLoginLocked   = "login_locked.html"   // 账号因登录失败被锁定
+)
+
+//go:embed templates/*.html
+var builtinTemplates embed.FS
+
+// templates 按名称索引的模板，每个模板都包含公共布局 base.html
+var templates = parseTemplates(TemplateVerifyEmail, TemplateResetPassword, TemplateMagicLink, TemplateLoginLocked)
+
+// TemplateData 模板数据
+type TemplateData struct {
+	SiteName      string
+	SiteURL       string
+	Username      string
+	Link          string // 邮件中需要点击的链接
+	ExpireMinutes int    // 链接有效期（分钟）
+	Failures      int    // 连续登录失败次数
+	LockMinutes   int    // 账号锁定时长（分钟）
+	IPAddress     string // 触发锁定的IP
+}
+
+// Render 渲染邮件模板，返回主题和HTML正文。模板中的 subject 区块作为邮件主题
+func Render(name string, data TemplateData) (string, string, error) {
+	t, ok := templates[name]
+	if !ok {
+		return "", "", fmt.Errorf("邮件模板 %s 不存在", name)
+	}
+
+	var subject, body bytes.Buffer
+	if err := t.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return "", "", err
+	}
+	if err := t.ExecuteTemplate(&body, "base", data); err != nil {
+		return "", "", err
+	}
+	// 主题按HTML转义输出，邮件主题需要还原为纯文本
+	return html.UnescapeString(strings.TrimSpace(subject.String())), body.String(), nil
+}
+
+// parseTemplates 解析内置模板，模板有误属于程序错误，直接panic
+func parseTemplates(names ...string) map[string]*template.Template {
+	result := make(map[string]*template.Template, len(names))
+	for _, name := range names {
+		result[name] = template.Must(template.ParseFS(builtinTemplates, "templates/base.html", "templates/"+name))
+	}
+	return result
+}
@@ -0,0 +1,95 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// GitHub 默认地址
+const (
+	githubAuthURL  = "https://github.com/login/oauth/authorize"
+	githubTokenURL = "https://github.com/login/oauth/access_token"
+	githubAPIURL   = "https://api.github.com/user"
+)
+
+// github GitHub OAuth2 登录，GitHub 不支持 OIDC，邮箱通过接口单独获取
+type github struct {
+	cfg Config
+}
+
+// newGitHub 创建 GitHub 登录服务，未配置的地址和权限使用默认值
+func newGitHub(cfg Config) *github {
+	if cfg.AuthURL == "" {
+		cfg.AuthURL = githubAuthURL
+	}
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = githubTokenURL
+	}
+	if cfg.UserInfoURL == "" {
+		cfg.UserInfoURL = githubAPIURL
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"read:user", "user:email"}
+	}
+	return &github{cfg: cfg}
+}
+
+// AuthCodeURL 实现 Provider 接口
+func (p *github) AuthCodeURL(ctx context.Context, req AuthRequest) (string, error) {
+	params := url.Values{
+		"client_id":    {p.cfg.ClientID},
+		"redirect_uri": {req.RedirectURI},
+		"scope":        {strings.Join(p.cfg.Scopes, " ")},
+		"state":        {req.State},
+	}
+	pkceParams(params, req.CodeChallenge)
+	return buildURL(p.cfg.AuthURL, params), nil
+}
+
+// Exchange 实现 Provider 接口
+func (p *github) Exchange(ctx context.Context, req ExchangeRequest) (*Identity, error) {
+	token, err := exchangeCode(ctx, p.cfg, p.cfg.TokenURL, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var user struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := getJSON(ctx, p.cfg.HTTPClient, p.cfg.UserInfoURL, token.AccessToken, &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, fmt.Errorf("%w：未返回用户ID", ErrProviderResponse)
+	}
+
+	identity := &Identity{
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Username: user.Login,
+		Nickname: user.Name,
+		Avatar:   user.AvatarURL,
+	}
+
+	// 用户资料中的公开邮箱不一定经过验证，以邮箱接口返回的已验证主邮箱为准
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, p.cfg.HTTPClient, p.cfg.UserInfoURL+"/emails", token.AccessToken, &emails); err == nil {
+		for _, email := range emails {
+			if email.Primary && email.Verified {
+				identity.Email = email.Email
+				identity.EmailVerified = true
+				break
+			}
+		}
+	}
+	return identity, nil
+}
@@ -0,0 +1,239 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// 第三方登录类型
+const (
+	TypeGitHub = "github" // GitHub OAuth2
+	TypeGoogle = "google" // Google，使用 OIDC 发现
+	TypeOIDC   = "oidc"   // 任意支持发现文档的 OpenID Connect 服务
+	TypeWechat = "wechat" // 微信开放平台网站应用扫码登录
+)
+
+// maxResponseSize 第三方接口响应的最大字节数
+const maxResponseSize = 1 << 20
+
+var (
+	// ErrUnsupportedType 不支持的第三方登录类型
+	ErrUnsupportedType = errors.New("不支持的第三方登录类型")
+	// ErrProviderResponse 第三方服务返回错误或无法识别的响应
+	ErrProviderResponse = errors.New("第三方登录服务返回错误")
+	// ErrIDTokenInvalid ID令牌签名、签发者、受众或有效期校验失败
+	ErrIDTokenInvalid = errors.New("第三方登录ID令牌无效")
+	// ErrNonceMismatch ID令牌中的 nonce 与发起登录时不一致，可能是重放的令牌
+	ErrNonceMismatch = errors.New("第三方登录ID令牌nonce不匹配")
+)
+
+// Config 第三方登录服务配置
+type Config struct {
+	Type         string
+	ClientID     string
+	ClientSecret string
+	Issuer       string   // OIDC 签发者地址，用于获取发现文档，Google 可不填
+	Scopes       []string // 为空时使用各类型的默认权限
+	AuthURL      string   // 授权地址，为空时使用默认地址
+	TokenURL     string   // 令牌地址，为空时使用默认地址
+	UserInfoURL  string   // 用户信息地址，为空时使用默认地址
+	HTTPClient   *http.Client
+}
+
+// AuthRequest 生成授权地址的参数
+type AuthRequest struct {
+	State         string // 防止跨站请求伪造，回调时原样返回
+	Nonce         string // 写入 ID 令牌，防止令牌重放，仅 OIDC 使用
+	CodeChallenge string // PKCE 挑战，由 CodeChallenge 计算
+	RedirectURI   string
+}
+
+// ExchangeRequest 使用授权码换取用户信息的参数
+type ExchangeRequest struct {
+	Code         string
+	CodeVerifier string // PKCE 校验码，需与生成授权地址时一致
+	Nonce        string
+	RedirectURI  string
+}
+
+// Identity 第三方账号信息
+type Identity struct {
+	Subject       string // 第三方账号在该服务中的唯一标识，微信为 OpenID
+	UnionID       string // 微信 UnionID，同一开放平台下的应用相同
+	Email         string
+	EmailVerified bool // 第三方服务已验证邮箱属于该用户，未验证的邮箱不能用于关联已有账号
+	Username      string
+	Nickname      string
+	Avatar        string
+}
+
+// Provider 第三方登录服务
+type Provider interface {
+	// AuthCodeURL 返回跳转到第三方服务的授权地址
+	AuthCodeURL(ctx context.Context, req AuthRequest) (string, error)
+	// Exchange 使用回调中的授权码换取令牌并获取账号信息
+	Exchange(ctx context.Context, req ExchangeRequest) (*Identity, error)
+}
+
+// New 根据配置创建第三方登录服务
+func New(cfg Config) (Provider, error) {
+	if cfg.ClientID == "" {
+		return nil, errors.New("第三方登录未配置 client_id")
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	switch cfg.Type {
+	case TypeGitHub:
+		return newGitHub(cfg), nil
+	case TypeGoogle:
+		if cfg.Issuer == "" {
+			cfg.Issuer = "https://accounts.google.com"
+		}
+		return newOIDC(cfg)
+	case TypeOIDC:
+		return newOIDC(cfg)
+	case TypeWechat:
+		return newWechat(cfg), nil
+	default:
+		return nil, fmt.Errorf("%w：%s", ErrUnsupportedType, cfg.Type)
+	}
+}
+
+// NewCodeVerifier 生成 PKCE 校验码（RFC 7636）
+func NewCodeVerifier() (string, error) {
+	return randomString(32)
+}
+
+// CodeChallenge 计算 PKCE 校验码的 S256 挑战
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// NewState 生成 state 或 nonce 随机值
+func NewState() (string, error) {
+	return randomString(24)
+}
+
+// VerifyState 检查回调中的 state 与发起授权时保存在浏览器中的 state 是否一致，任一为空时不通过
+func VerifyState(expected, got string) bool {
+	if expected == "" || got == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(got)) == 1
+}
+
+// randomString 生成 n 字节随机数的 base64url 编码
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// buildURL 在地址后追加查询参数
+func buildURL(base string, params url.Values) string {
+	if strings.Contains(base, "?") {
+		return base + "&" + params.Encode()
+	}
+	return base + "?" + params.Encode()
+}
+
+// pkceParams 将 PKCE 挑战写入授权参数
+func pkceParams(params url.Values, challenge string) {
+	if challenge != "" {
+		params.Set("code_challenge", challenge)
+		params.Set("code_challenge_method", "S256")
+	}
+}
+
+// tokenResponse 令牌接口的响应
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// exchangeCode 按 OAuth2 标准使用授权码换取令牌
+func exchangeCode(ctx context.Context, cfg Config, tokenURL string, req ExchangeRequest) (*tokenResponse, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {req.Code},
+		"redirect_uri":  {req.RedirectURI},
+		"client_id":     {cfg.ClientID},
+		"client_secret": {cfg.ClientSecret},
+	}
+	if req.CodeVerifier != "" {
+		form.Set("code_verifier", req.CodeVerifier)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Accept", "application/json")
+
+	var token tokenResponse
+	if err := doJSON(cfg.HTTPClient, httpReq, &token); err != nil && token.Error == "" {
+		return nil, err
+	}
+	if token.Error != "" {
+		return nil, fmt.Errorf("%w：%s %s", ErrProviderResponse, token.Error, token.ErrorDescription)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("%w：未返回访问令牌", ErrProviderResponse)
+	}
+	return &token, nil
+}
+
+// getJSON 携带访问令牌请求接口并解析JSON响应
+func getJSON(ctx context.Context, client *http.Client, endpoint, accessToken string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	return doJSON(client, req, v)
+}
+
+// doJSON 发送请求并解析JSON响应，非2xx状态码时仍尝试解析响应中的错误信息
+func doJSON(client *http.Client, req *http.Request, v interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+	decodeErr := json.Unmarshal(body, v)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w：%s 返回状态码 %d", ErrProviderResponse, req.URL.Host, resp.StatusCode)
+	}
+	if decodeErr != nil {
+		return fmt.Errorf("%w：%v", ErrProviderResponse, decodeErr)
+	}
+	return nil
+}
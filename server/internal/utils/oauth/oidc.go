@@ -0,0 +1,259 @@
+package oauth
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OIDC 缓存参数
+const (
+	oidcMetadataTTL   = time.Hour       // 发现文档缓存时间
+	oidcKeysTTL       = time.Hour       // 签名公钥缓存时间
+	oidcKeysMinReload = time.Minute     // 遇到未知 kid 时重新获取公钥的最小间隔，防止被用于放大请求
+	oidcClockSkew     = 2 * time.Minute // 允许的时钟误差
+	oidcDiscoveryPath = "/.well-known/openid-configuration"
+)
+
+// oidcSigningMethods 接受的 ID 令牌签名算法，不接受 none 和对称签名
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// oidcMetadata 发现文档中使用的字段
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcClaims ID 令牌和用户信息接口中使用的声明
+type oidcClaims struct {
+	jwt.RegisteredClaims
+	Nonce             string   `json:"nonce"`
+	AuthorizedParty   string   `json:"azp"`
+	Email             string   `json:"email"`
+	EmailVerified     flexBool `json:"email_verified"`
+	Name              string   `json:"name"`
+	PreferredUsername string   `json:"preferred_username"`
+	Picture           string   `json:"picture"`
+}
+
+// flexBool 兼容部分服务以字符串 "true" 返回的布尔值
+type flexBool bool
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch value := v.(type) {
+	case bool:
+		*b = flexBool(value)
+	case string:
+		*b = flexBool(strings.EqualFold(value, "true"))
+	}
+	return nil
+}
+
+// oidc OpenID Connect 登录，通过发现文档获取各接口地址，ID 令牌使用服务公布的公钥验证
+type oidc struct {
+	cfg Config
+
+	mu         sync.Mutex
+	metadata   *oidcMetadata
+	metadataAt time.Time
+	keys       map[string]crypto.PublicKey
+	keysAt     time.Time
+}
+
+// newOIDC 创建 OIDC 登录服务，发现文档在首次使用时获取
+func newOIDC(cfg Config) (*oidc, error) {
+	if cfg.Issuer == "" {
+		return nil, errors.New("OIDC 登录未配置 issuer")
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	return &oidc{cfg: cfg}, nil
+}
+
+// AuthCodeURL 实现 Provider 接口
+func (p *oidc) AuthCodeURL(ctx context.Context, req AuthRequest) (string, error) {
+	metadata, err := p.getMetadata(ctx)
+	if err != nil {
+		return "", err
+	}
+	params := url.Values{
+		"response_type": {"code"},
+		"client_id":     {p.cfg.ClientID},
+		"redirect_uri":  {req.RedirectURI},
+		"scope":         {strings.Join(p.cfg.Scopes, " ")},
+		"state":         {req.State},
+	}
+	if req.Nonce != "" {
+		params.Set("nonce", req.Nonce)
+	}
+	pkceParams(params, req.CodeChallenge)
+	return buildURL(metadata.AuthorizationEndpoint, params), nil
+}
+
+// Exchange 实现 Provider 接口，校验 ID 令牌后以其中的声明为准，缺少邮箱等信息时再请求用户信息接口
+func (p *oidc) Exchange(ctx context.Context, req ExchangeRequest) (*Identity, error) {
+	metadata, err := p.getMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := exchangeCode(ctx, p.cfg, metadata.TokenEndpoint, req)
+	if err != nil {
+		return nil, err
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("%w：未返回ID令牌", ErrProviderResponse)
+	}
+
+	claims, err := p.verifyIDToken(ctx, metadata, token.IDToken)
+	if err != nil {
+		return nil, err
+	}
+	if req.Nonce != "" && claims.Nonce != req.Nonce {
+		return nil, ErrNonceMismatch
+	}
+
+	if (claims.Email == "" || claims.Name == "") && metadata.UserInfoEndpoint != "" {
+		var info oidcClaims
+		if err := getJSON(ctx, p.cfg.HTTPClient, metadata.UserInfoEndpoint, token.AccessToken, &info); err == nil && info.Subject == claims.Subject {
+			if claims.Email == "" {
+				claims.Email = info.Email
+				claims.EmailVerified = info.EmailVerified
+			}
+			if claims.Name == "" {
+				claims.Name = info.Name
+			}
+			if claims.PreferredUsername == "" {
+				claims.PreferredUsername = info.PreferredUsername
+			}
+			if claims.Picture == "" {
+				claims.Picture = info.Picture
+			}
+		}
+	}
+
+	return &Identity{
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.Email != "" && bool(claims.EmailVerified),
+		Username:      claims.PreferredUsername,
+		Nickname:      claims.Name,
+		Avatar:        claims.Picture,
+	}, nil
+}
+
+// verifyIDToken 校验 ID 令牌的签名、签发者、受众和有效期
+func (p *oidc) verifyIDToken(ctx context.Context, metadata *oidcMetadata, rawToken string) (*oidcClaims, error) {
+	var claims oidcClaims
+	_, err := jwt.ParseWithClaims(rawToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.getKey(ctx, metadata, kid)
+	},
+		jwt.WithValidMethods(oidcSigningMethods),
+		jwt.WithIssuer(metadata.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(oidcClockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w：%v", ErrIDTokenInvalid, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w：缺少sub", ErrIDTokenInvalid)
+	}
+	// 令牌有多个受众时，azp 必须是本应用
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.cfg.ClientID {
+		return nil, fmt.Errorf("%w：azp不匹配", ErrIDTokenInvalid)
+	}
+	return &claims, nil
+}
+
+// getMetadata 获取并缓存发现文档，文档中的 issuer 必须与配置一致
+func (p *oidc) getMetadata(ctx context.Context) (*oidcMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil && time.Since(p.metadataAt) < oidcMetadataTTL {
+		return p.metadata, nil
+	}
+
+	issuer := strings.TrimSuffix(p.cfg.Issuer, "/")
+	var metadata oidcMetadata
+	if err := getJSON(ctx, p.cfg.HTTPClient, issuer+oidcDiscoveryPath, "", &metadata); err != nil {
+		// 获取失败时继续使用过期的文档，避免第三方服务短暂不可用导致无法登录
+		if p.metadata != nil {
+			return p.metadata, nil
+		}
+		return nil, err
+	}
+	if strings.TrimSuffix(metadata.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("%w：发现文档的issuer %q 与配置不一致", ErrProviderResponse, metadata.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("%w：发现文档缺少必要的地址", ErrProviderResponse)
+	}
+	if p.cfg.AuthURL != "" {
+		metadata.AuthorizationEndpoint = p.cfg.AuthURL
+	}
+	if p.cfg.TokenURL != "" {
+		metadata.TokenEndpoint = p.cfg.TokenURL
+	}
+	if p.cfg.UserInfoURL != "" {
+		metadata.UserInfoEndpoint = p.cfg.UserInfoURL
+	}
+
+	p.metadata = &metadata
+	p.metadataAt = time.Now()
+	return p.metadata, nil
+}
+
+// getKey 按 kid 查找签名公钥，找不到时重新获取公钥集以支持密钥轮换
+func (p *oidc) getKey(ctx context.Context, metadata *oidcMetadata, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key := p.findKey(kid); key != nil && time.Since(p.keysAt) < oidcKeysTTL {
+		return key, nil
+	}
+	if p.keys == nil || time.Since(p.keysAt) >= oidcKeysMinReload {
+		var set jsonWebKeySet
+		if err := getJSON(ctx, p.cfg.HTTPClient, metadata.JWKSURI, "", &set); err != nil {
+			if key := p.findKey(kid); key != nil {
+				return key, nil
+			}
+			return nil, err
+		}
+		p.keys = set.publicKeys()
+		p.keysAt = time.Now()
+	}
+	if key := p.findKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("未找到kid为 %q 的签名公钥", kid)
+}
+
+// findKey 在缓存的公钥中查找，kid 为空时只有唯一一个公钥才能确定
+func (p *oidc) findKey(kid string) crypto.PublicKey {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key
+		}
+	}
+	return p.keys[kid]
+}
@@ -0,0 +1,544 @@
+package oauth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID     = "blog-client"
+	testClientSecret = "blog-secret"
+	testRedirectURI  = "https://blog.example.com/oauth/callback/oidc"
+	testKeyID        = "key-1"
+)
+
+// mockOIDC 模拟的 OIDC 服务，提供发现文档、公钥集、授权、令牌和用户信息接口，
+// 令牌接口按授权时的 code_challenge 校验 PKCE 校验码
+type mockOIDC struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	// issuer 发现文档中的签发者，为空时使用服务地址
+	issuer string
+	// discovery 修改发现文档，用于构造异常文档
+	discovery func(doc map[string]interface{})
+	// claims 修改签发的 ID 令牌声明
+	claims func(claims jwt.MapClaims)
+	// sign 替换 ID 令牌的签名方式，为空时使用公钥集中的 RSA 密钥
+	sign func(claims jwt.MapClaims) string
+
+	mu    sync.Mutex
+	codes map[string]mockAuthorization
+}
+
+// mockAuthorization 授权码对应的授权请求
+type mockAuthorization struct {
+	challenge   string
+	nonce       string
+	redirectURI string
+}
+
+func newMockOIDC(t *testing.T) *mockOIDC {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockOIDC{t: t, key: key, codes: make(map[string]mockAuthorization)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(oidcDiscoveryPath, m.handleDiscovery)
+	mux.HandleFunc("/jwks", m.handleJWKS)
+	mux.HandleFunc("/authorize", m.handleAuthorize)
+	mux.HandleFunc("/token", m.handleToken)
+	mux.HandleFunc("/userinfo", m.handleUserInfo)
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+// provider 创建连接到模拟服务的登录服务
+func (m *mockOIDC) provider() Provider {
+	m.t.Helper()
+	provider, err := New(Config{
+		Type:         TypeOIDC,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		Issuer:       m.server.URL,
+		HTTPClient:   m.server.Client(),
+	})
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	return provider
+}
+
+func (m *mockOIDC) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	issuer := m.issuer
+	if issuer == "" {
+		issuer = m.server.URL
+	}
+	doc := map[string]interface{}{
+		"issuer":                 issuer,
+		"authorization_endpoint": m.server.URL + "/authorize",
+		"token_endpoint":         m.server.URL + "/token",
+		"userinfo_endpoint":      m.server.URL + "/userinfo",
+		"jwks_uri":               m.server.URL + "/jwks",
+	}
+	if m.discovery != nil {
+		m.discovery(doc)
+	}
+	writeJSON(w, http.StatusOK, doc)
+}
+
+func (m *mockOIDC) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	pub := m.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{
+			// 加密用途的公钥应被跳过
+			{"kty": "RSA", "kid": "enc-key", "use": "enc", "n": "AQAB", "e": "AQAB"},
+			{
+				"kty": "RSA",
+				"kid": testKeyID,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			},
+		},
+	})
+}
+
+// handleAuthorize 模拟用户同意授权，生成授权码后跳转回 redirect_uri
+func (m *mockOIDC) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("response_type") != "code" || q.Get("client_id") != testClientID || q.Get("code_challenge_method") != "S256" {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+
+	code, err := NewState()
+	if err != nil {
+		http.Error(w, "server_error", http.StatusInternalServerError)
+		return
+	}
+	m.mu.Lock()
+	m.codes[code] = mockAuthorization{
+		challenge:   q.Get("code_challenge"),
+		nonce:       q.Get("nonce"),
+		redirectURI: q.Get("redirect_uri"),
+	}
+	m.mu.Unlock()
+
+	params := url.Values{"code": {code}, "state": {q.Get("state")}}
+	http.Redirect(w, r, buildURL(q.Get("redirect_uri"), params), http.StatusFound)
+}
+
+func (m *mockOIDC) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("client_id") != testClientID ||
+		r.PostForm.Get("client_secret") != testClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	// 授权码只能使用一次
+	m.mu.Lock()
+	auth, ok := m.codes[r.PostForm.Get("code")]
+	delete(m.codes, r.PostForm.Get("code"))
+	m.mu.Unlock()
+	if !ok || auth.redirectURI != r.PostForm.Get("redirect_uri") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	if CodeChallenge(r.PostForm.Get("code_verifier")) != auth.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "invalid_grant",
+			"error_description": "PKCE verification failed",
+		})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":                m.server.URL,
+		"sub":                "user-1",
+		"aud":                testClientID,
+		"iat":                now.Unix(),
+		"exp":                now.Add(5 * time.Minute).Unix(),
+		"nonce":              auth.nonce,
+		"email":              "alice@example.com",
+		"email_verified":     "true",
+		"preferred_username": "alice",
+	}
+	if m.claims != nil {
+		m.claims(claims)
+	}
+
+	var idToken string
+	if m.sign != nil {
+		idToken = m.sign(claims)
+	} else {
+		idToken = signToken(m.t, jwt.SigningMethodRS256, testKeyID, m.key, claims)
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+	})
+}
+
+// handleUserInfo ID 令牌中缺少姓名时从这里补充
+func (m *mockOIDC) handleUserInfo(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer access-token" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"sub":     "user-1",
+		"name":    "Alice",
+		"picture": "https://example.com/alice.png",
+	})
+}
+
+// login 生成授权地址并模拟用户授权，返回回调地址中的授权码和 state
+func (m *mockOIDC) login(provider Provider, req AuthRequest) (code, state string) {
+	m.t.Helper()
+	authURL, err := provider.AuthCodeURL(context.Background(), req)
+	if err != nil {
+		m.t.Fatalf("生成授权地址失败: %v", err)
+	}
+
+	client := *m.server.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		m.t.Fatalf("授权请求返回状态码 %d，授权地址 %s", resp.StatusCode, authURL)
+	}
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	return callback.Query().Get("code"), callback.Query().Get("state")
+}
+
+// newLoginRequest 生成授权请求参数，返回授权请求和对应的 PKCE 校验码
+func newLoginRequest(t *testing.T) (AuthRequest, string) {
+	t.Helper()
+	state, err1 := NewState()
+	nonce, err2 := NewState()
+	verifier, err3 := NewCodeVerifier()
+	if err := errors.Join(err1, err2, err3); err != nil {
+		t.Fatal(err)
+	}
+	return AuthRequest{
+		State:         state,
+		Nonce:         nonce,
+		CodeChallenge: CodeChallenge(verifier),
+		RedirectURI:   testRedirectURI,
+	}, verifier
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestOIDCLogin(t *testing.T) {
+	m := newMockOIDC(t)
+	provider := m.provider()
+	req, verifier := newLoginRequest(t)
+
+	code, state := m.login(provider, req)
+	if !VerifyState(req.State, state) {
+		t.Fatalf("回调中的 state %q 与发起授权时不一致", state)
+	}
+
+	identity, err := provider.Exchange(context.Background(), ExchangeRequest{
+		Code:         code,
+		CodeVerifier: verifier,
+		Nonce:        req.Nonce,
+		RedirectURI:  testRedirectURI,
+	})
+	if err != nil {
+		t.Fatalf("登录失败: %v", err)
+	}
+
+	want := Identity{
+		Subject:       "user-1",
+		Email:         "alice@example.com",
+		EmailVerified: true,
+		Username:      "alice",
+		Nickname:      "Alice",
+		Avatar:        "https://example.com/alice.png",
+	}
+	if *identity != want {
+		t.Errorf("账号信息 = %+v，期望 %+v", *identity, want)
+	}
+}
+
+func TestOIDCDiscoveryRejects(t *testing.T) {
+	tests := []struct {
+		name      string
+		issuer    string
+		discovery func(doc map[string]interface{})
+	}{
+		{name: "issuer不一致", issuer: "https://evil.example.com"},
+		{name: "缺少令牌地址", discovery: func(doc map[string]interface{}) { delete(doc, "token_endpoint") }},
+		{name: "缺少公钥集地址", discovery: func(doc map[string]interface{}) { delete(doc, "jwks_uri") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMockOIDC(t)
+			m.issuer = tt.issuer
+			m.discovery = tt.discovery
+			req, _ := newLoginRequest(t)
+
+			_, err := m.provider().AuthCodeURL(context.Background(), req)
+			if !errors.Is(err, ErrProviderResponse) {
+				t.Errorf("错误 = %v，期望 %v", err, ErrProviderResponse)
+			}
+		})
+	}
+}
+
+func TestOIDCExchangeRejects(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		claims   func(claims jwt.MapClaims)
+		sign     func(t *testing.T, claims jwt.MapClaims) string
+		exchange func(req *ExchangeRequest)
+		wantErr  error
+	}{
+		{
+			name:     "PKCE校验码错误",
+			exchange: func(req *ExchangeRequest) { req.CodeVerifier = "wrong-verifier" },
+			wantErr:  ErrProviderResponse,
+		},
+		{
+			name:     "缺少PKCE校验码",
+			exchange: func(req *ExchangeRequest) { req.CodeVerifier = "" },
+			wantErr:  ErrProviderResponse,
+		},
+		{
+			name:     "授权码错误",
+			exchange: func(req *ExchangeRequest) { req.Code = "forged-code" },
+			wantErr:  ErrProviderResponse,
+		},
+		{
+			name:     "nonce不一致",
+			exchange: func(req *ExchangeRequest) { req.Nonce = "other-nonce" },
+			wantErr:  ErrNonceMismatch,
+		},
+		{
+			name:    "令牌缺少nonce",
+			claims:  func(claims jwt.MapClaims) { delete(claims, "nonce") },
+			wantErr: ErrNonceMismatch,
+		},
+		{
+			name: "其他密钥签名",
+			sign: func(t *testing.T, claims jwt.MapClaims) string {
+				return signToken(t, jwt.SigningMethodRS256, testKeyID, otherKey, claims)
+			},
+			wantErr: ErrIDTokenInvalid,
+		},
+		{
+			name: "未知kid",
+			sign: func(t *testing.T, claims jwt.MapClaims) string {
+				return signToken(t, jwt.SigningMethodES256, "unknown-key", ecKey, claims)
+			},
+			wantErr: ErrIDTokenInvalid,
+		},
+		{
+			name: "使用client_secret对称签名",
+			sign: func(t *testing.T, claims jwt.MapClaims) string {
+				return signToken(t, jwt.SigningMethodHS256, testKeyID, []byte(testClientSecret), claims)
+			},
+			wantErr: ErrIDTokenInvalid,
+		},
+		{
+			name: "未签名",
+			sign: func(t *testing.T, claims jwt.MapClaims) string {
+				return signToken(t, jwt.SigningMethodNone, testKeyID, jwt.UnsafeAllowNoneSignatureType, claims)
+			},
+			wantErr: ErrIDTokenInvalid,
+		},
+		{
+			name:    "签发者错误",
+			claims:  func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" },
+			wantErr: ErrIDTokenInvalid,
+		},
+		{
+			name:    "受众错误",
+			claims:  func(claims jwt.MapClaims) { claims["aud"] = "other-client" },
+			wantErr: ErrIDTokenInvalid,
+		},
+		{
+			name:    "多个受众且azp不是本应用",
+			claims:  func(claims jwt.MapClaims) { claims["aud"] = []string{testClientID, "other-client"} },
+			wantErr: ErrIDTokenInvalid,
+		},
+		{
+			name: "已过期",
+			claims: func(claims jwt.MapClaims) {
+				claims["iat"] = time.Now().Add(-time.Hour).Unix()
+				claims["exp"] = time.Now().Add(-10 * time.Minute).Unix()
+			},
+			wantErr: ErrIDTokenInvalid,
+		},
+		{
+			name:    "缺少过期时间",
+			claims:  func(claims jwt.MapClaims) { delete(claims, "exp") },
+			wantErr: ErrIDTokenInvalid,
+		},
+		{
+			name:    "缺少sub",
+			claims:  func(claims jwt.MapClaims) { delete(claims, "sub") },
+			wantErr: ErrIDTokenInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMockOIDC(t)
+			m.claims = tt.claims
+			if tt.sign != nil {
+				m.sign = func(claims jwt.MapClaims) string { return tt.sign(t, claims) }
+			}
+			provider := m.provider()
+			req, verifier := newLoginRequest(t)
+			code, _ := m.login(provider, req)
+
+			exchange := ExchangeRequest{
+				Code:         code,
+				CodeVerifier: verifier,
+				Nonce:        req.Nonce,
+				RedirectURI:  testRedirectURI,
+			}
+			if tt.exchange != nil {
+				tt.exchange(&exchange)
+			}
+
+			identity, err := provider.Exchange(context.Background(), exchange)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("错误 = %v，期望 %v", err, tt.wantErr)
+			}
+			if identity != nil {
+				t.Errorf("校验失败时不应返回账号信息: %+v", identity)
+			}
+		})
+	}
+}
+
+func TestOIDCAuthorizedParty(t *testing.T) {
+	m := newMockOIDC(t)
+	m.claims = func(claims jwt.MapClaims) {
+		claims["aud"] = []string{testClientID, "other-client"}
+		claims["azp"] = testClientID
+	}
+	provider := m.provider()
+	req, verifier := newLoginRequest(t)
+	code, _ := m.login(provider, req)
+
+	_, err := provider.Exchange(context.Background(), ExchangeRequest{
+		Code:         code,
+		CodeVerifier: verifier,
+		Nonce:        req.Nonce,
+		RedirectURI:  testRedirectURI,
+	})
+	if err != nil {
+		t.Fatalf("azp为本应用时应登录成功: %v", err)
+	}
+}
+
+func TestVerifyState(t *testing.T) {
+	state, err := NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		expected string
+		got      string
+		want     bool
+	}{
+		{name: "一致", expected: state, got: state, want: true},
+		{name: "他人发起的授权", expected: state, got: other, want: false},
+		{name: "浏览器中没有state", expected: "", got: state, want: false},
+		{name: "回调缺少state", expected: state, got: "", want: false},
+		{name: "都为空", expected: "", got: "", want: false},
+		{name: "前缀相同", expected: state, got: state[:len(state)-1], want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyState(tt.expected, tt.got); got != tt.want {
+				t.Errorf("VerifyState() = %v，期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// RFC 7636 附录 B 的示例
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	if got := CodeChallenge(verifier); got != want {
+		t.Errorf("CodeChallenge() = %s，期望 %s", got, want)
+	}
+}
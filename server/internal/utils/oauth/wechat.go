@@ -0,0 +1,111 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// 微信开放平台默认地址
+const (
+	wechatAuthURL     = "https://open.weixin.qq.com/connect/qrconnect"
+	wechatTokenURL    = "https://api.weixin.qq.com/sns/oauth2/access_token"
+	wechatUserInfoURL = "https://api.weixin.qq.com/sns/userinfo"
+)
+
+// wechat 微信网站应用扫码登录。微信的授权流程不是标准 OAuth2：
+// 参数名为 appid/secret，令牌接口使用 GET 请求，不支持 PKCE 和 OIDC，也不提供邮箱
+type wechat struct {
+	cfg Config
+}
+
+// wechatError 微信接口的错误字段
+type wechatError struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+// err 错误码不为0时返回错误
+func (e wechatError) err() error {
+	if e.ErrCode != 0 {
+		return fmt.Errorf("%w：%d %s", ErrProviderResponse, e.ErrCode, e.ErrMsg)
+	}
+	return nil
+}
+
+// newWechat 创建微信登录服务，未配置的地址和权限使用默认值。
+// 在微信内打开的网页应将 auth_url 配置为 https://open.weixin.qq.com/connect/oauth2/authorize，权限配置为 snsapi_userinfo
+func newWechat(cfg Config) *wechat {
+	if cfg.AuthURL == "" {
+		cfg.AuthURL = wechatAuthURL
+	}
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = wechatTokenURL
+	}
+	if cfg.UserInfoURL == "" {
+		cfg.UserInfoURL = wechatUserInfoURL
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"snsapi_login"}
+	}
+	return &wechat{cfg: cfg}
+}
+
+// AuthCodeURL 实现 Provider 接口，微信要求参数按固定顺序排列并以 #wechat_redirect 结尾
+func (p *wechat) AuthCodeURL(ctx context.Context, req AuthRequest) (string, error) {
+	return p.cfg.AuthURL +
+		"?appid=" + url.QueryEscape(p.cfg.ClientID) +
+		"&redirect_uri=" + url.QueryEscape(req.RedirectURI) +
+		"&response_type=code" +
+		"&scope=" + url.QueryEscape(strings.Join(p.cfg.Scopes, ",")) +
+		"&state=" + url.QueryEscape(req.State) +
+		"#wechat_redirect", nil
+}
+
+// Exchange 实现 Provider 接口
+func (p *wechat) Exchange(ctx context.Context, req ExchangeRequest) (*Identity, error) {
+	var token struct {
+		wechatError
+		AccessToken string `json:"access_token"`
+		OpenID      string `json:"openid"`
+		UnionID     string `json:"unionid"`
+	}
+	tokenURL := buildURL(p.cfg.TokenURL, url.Values{
+		"appid":      {p.cfg.ClientID},
+		"secret":     {p.cfg.ClientSecret},
+		"code":       {req.Code},
+		"grant_type": {"authorization_code"},
+	})
+	if err := getJSON(ctx, p.cfg.HTTPClient, tokenURL, "", &token); err != nil {
+		return nil, err
+	}
+	if err := token.err(); err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" || token.OpenID == "" {
+		return nil, fmt.Errorf("%w：未返回OpenID", ErrProviderResponse)
+	}
+
+	identity := &Identity{Subject: token.OpenID, UnionID: token.UnionID}
+
+	var user struct {
+		wechatError
+		OpenID     string `json:"openid"`
+		Nickname   string `json:"nickname"`
+		HeadImgURL string `json:"headimgurl"`
+		UnionID    string `json:"unionid"`
+	}
+	userInfoURL := buildURL(p.cfg.UserInfoURL, url.Values{
+		"access_token": {token.AccessToken},
+		"openid":       {token.OpenID},
+	})
+	if err := getJSON(ctx, p.cfg.HTTPClient, userInfoURL, "", &user); err == nil && user.err() == nil && user.OpenID == token.OpenID {
+		identity.Nickname = user.Nickname
+		identity.Avatar = user.HeadImgURL
+		if identity.UnionID == "" {
+			identity.UnionID = user.UnionID
+		}
+	}
+	return identity, nil
+}
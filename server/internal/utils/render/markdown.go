@@ -0,0 +1,818 @@
+package render
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Markdown 语法支持范围：标题（ATX/Setext）、段落、引用、有序/无序/任务列表、
+// 围栏/缩进代码块、分隔线、表格、图片、链接、强调、删除线、行内代码和原始HTML。
+// 输出未经过滤，必须再经过 Sanitize 处理。
+
+var (
+	atxHeadingPattern = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	setextPattern     = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	hrPattern         = regexp.MustCompile(`^ {0,3}((\*[ \t]*){3,}|(-[ \t]*){3,}|(_[ \t]*){3,})$`)
+	fencePattern      = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`]*)$")
+	bulletPattern     = regexp.MustCompile(`^( {0,3})([-*+])([ \t]+|$)`)
+	orderedPattern    = regexp.MustCompile(`^( {0,3})(\d{1,9})([.)])([ \t]+|$)`)
+	taskPattern       = regexp.MustCompile(`^\[([ xX])\][ \t]+`)
+	tableDelimPattern = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	htmlBlockPattern  = regexp.MustCompile(`^ {0,3}<(/?[a-zA-Z][a-zA-Z0-9-]*)[\s/>]|^ {0,3}<!--`)
+	inlineTagPattern  = regexp.MustCompile(`^(<[a-zA-Z][a-zA-Z0-9-]*(\s+[a-zA-Z_:][-a-zA-Z0-9_:.]*(\s*=\s*("[^"]*"|'[^']*'|[^\s"'=<>` + "`" + `]+))?)*\s*/?>|</[a-zA-Z][a-zA-Z0-9-]*\s*>|<!--[\s\S]*?-->)`)
+	autolinkPattern   = regexp.MustCompile(`^<((?:https?|mailto):[^\s<>]+)>`)
+)
+
+// Markdown 将Markdown文本转换为HTML
+func Markdown(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+	lines := strings.Split(src, "\n")
+	for i, line := range lines {
+		lines[i] = expandTabs(line)
+	}
+
+	var b strings.Builder
+	renderBlocks(&b, lines, false)
+	return b.String()
+}
+
+// expandTabs 将行首制表符展开为4个空格
+func expandTabs(line string) string {
+	i := 0
+	for i < len(line) && (line[i] == ' ' || line[i] == '\t') {
+		i++
+	}
+	if !strings.Contains(line[:i], "\t") {
+		return line
+	}
+	col := 0
+	for _, c := range line[:i] {
+		if c == '\t' {
+			col += 4 - col%4
+		} else {
+			col++
+		}
+	}
+	return strings.Repeat(" ", col) + line[i:]
+}
+
+// indentOf 计算行首空格数
+func indentOf(line string) int {
+	n := 0
+	for n < len(line) && line[n] == ' ' {
+		n++
+	}
+	return n
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// renderBlocks 解析块级元素，tight为true时段落不包裹<p>（紧凑列表项）
+func renderBlocks(b *strings.Builder, lines []string, tight bool) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case isBlank(line):
+			i++
+
+		case fencePattern.MatchString(line):
+			i = renderFencedCode(b, lines, i)
+
+		case atxHeadingPattern.MatchString(line):
+			m := atxHeadingPattern.FindStringSubmatch(line)
+			level := len(m[1])
+			writeHeading(b, level, m[2])
+			i++
+
+		case hrPattern.MatchString(line):
+			b.WriteString("<hr>\n")
+			i++
+
+		case strings.HasPrefix(strings.TrimLeft(line, " "), ">") && indentOf(line) < 4:
+			i = renderBlockquote(b, lines, i)
+
+		case bulletPattern.MatchString(line) || orderedPattern.MatchString(line):
+			i = renderList(b, lines, i)
+
+		case indentOf(line) >= 4:
+			i = renderIndentedCode(b, lines, i)
+
+		case htmlBlockPattern.MatchString(line):
+			// 原始HTML块原样输出直到空行
+			for i < len(lines) && !isBlank(lines[i]) {
+				b.WriteString(lines[i])
+				b.WriteByte('\n')
+				i++
+			}
+
+		case i+1 < len(lines) && strings.Contains(line, "|") && tableDelimPattern.MatchString(lines[i+1]):
+			i = renderTable(b, lines, i)
+
+		default:
+			i = renderParagraph(b, lines, i, tight)
+		}
+	}
+}
+
+// renderFencedCode 渲染围栏代码块，语言标识输出为 language-xxx 样式类
+func renderFencedCode(b *strings.Builder, lines []string, start int) int {
+	m := fencePattern.FindStringSubmatch(lines[start])
+	indent := len(m[1])
+	fence := m[2]
+	lang := strings.Fields(strings.TrimSpace(m[3]))
+
+	if len(lang) > 0 {
+		b.WriteString(`<pre><code class="language-` + html.EscapeString(lang[0]) + `">`)
+	} else {
+		b.WriteString("<pre><code>")
+	}
+
+	i := start + 1
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if indentOf(line) < 4 && strings.HasPrefix(trimmed, fence[:1]) &&
+			len(strings.TrimRight(trimmed, fence[:1])) == 0 && len(trimmed) >= len(fence) {
+			i++
+			break
+		}
+		// 去除与围栏相同的缩进
+		n := indentOf(line)
+		if n > indent {
+			n = indent
+		}
+		b.WriteString(html.EscapeString(line[n:]))
+		b.WriteByte('\n')
+	}
+
+	b.WriteString("</code></pre>\n")
+	return i
+}
+
+// renderIndentedCode 渲染缩进代码块
+func renderIndentedCode(b *strings.Builder, lines []string, start int) int {
+	var code []string
+	i := start
+	for ; i < len(lines); i++ {
+		if isBlank(lines[i]) {
+			code = append(code, "")
+			continue
+		}
+		if indentOf(lines[i]) < 4 {
+			break
+		}
+		code = append(code, lines[i][4:])
+	}
+
+	// 去除末尾空行
+	for len(code) > 0 && code[len(code)-1] == "" {
+		code = code[:len(code)-1]
+	}
+
+	b.WriteString("<pre><code>")
+	for _, line := range code {
+		b.WriteString(html.EscapeString(line))
+		b.WriteByte('\n')
+	}
+	b.WriteString("</code></pre>\n")
+	return i
+}
+
+// renderBlockquote 渲染引用块，支持惰性续行
+func renderBlockquote(b *strings.Builder, lines []string, start int) int {
+	var inner []string
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimLeft(line, " ")
+		if strings.HasPrefix(trimmed, ">") && indentOf(line) < 4 {
+			trimmed = trimmed[1:]
+			if strings.HasPrefix(trimmed, " ") {
+				trimmed = trimmed[1:]
+			}
+			inner = append(inner, trimmed)
+			continue
+		}
+		// 惰性续行：非空且上一行是段落内容
+		if isBlank(line) || len(inner) == 0 || isBlank(inner[len(inner)-1]) || startsBlock(line) {
+			break
+		}
+		inner = append(inner, line)
+	}
+
+	b.WriteString("<blockquote>\n")
+	renderBlocks(b, inner, false)
+	b.WriteString("</blockquote>\n")
+	return i
+}
+
+// listMarker 列表项标记信息
+type listMarker struct {
+	ordered bool
+	char    string // 无序列表符号或有序列表分隔符
+	start   int    // 有序列表起始序号
+	width   int    // 标记及其后空格占用的宽度，即列表项内容缩进
+}
+
+// parseListMarker 解析列表项标记
+func parseListMarker(line string) (listMarker, bool) {
+	if m := bulletPattern.FindStringSubmatch(line); m != nil && !hrPattern.MatchString(line) {
+		return listMarker{char: m[2], width: markerWidth(len(m[1])+1, m[3])}, true
+	}
+	if m := orderedPattern.FindStringSubmatch(line); m != nil {
+		start, _ := strconv.Atoi(m[2])
+		return listMarker{ordered: true, char: m[3], start: start, width: markerWidth(len(m[1])+len(m[2])+1, m[4])}, true
+	}
+	return listMarker{}, false
+}
+
+// markerWidth 计算列表项内容缩进，标记后超过4个空格时视为缩进代码，只计1个空格
+func markerWidth(prefix int, spaces string) int {
+	if spaces == "" {
+		return prefix + 1
+	}
+	if len(spaces) > 4 {
+		return prefix + 1
+	}
+	return prefix + len(spaces)
+}
+
+// renderList 渲染列表，连续相同类型的列表项属于同一个列表
+func renderList(b *strings.Builder, lines []string, start int) int {
+	first, _ := parseListMarker(lines[start])
+
+	var items [][]string
+	loose := false
+	i := start
+	for i < len(lines) {
+		marker, ok := parseListMarker(lines[i])
+		if !ok || marker.ordered != first.ordered || marker.char != first.char {
+			break
+		}
+
+		// 列表项首行
+		content := ""
+		if marker.width < len(lines[i]) {
+			content = lines[i][marker.width:]
+		}
+		item := []string{content}
+		i++
+
+		// 续行：缩进不小于内容缩进，或段落惰性续行
+		for i < len(lines) {
+			line := lines[i]
+			if isBlank(line) {
+				// 空行后紧跟缩进内容时仍属于当前项
+				j := i
+				for j < len(lines) && isBlank(lines[j]) {
+					j++
+				}
+				if j < len(lines) && indentOf(lines[j]) >= marker.width {
+					for ; i < j; i++ {
+						item = append(item, "")
+					}
+					continue
+				}
+				break
+			}
+			if indentOf(line) >= marker.width {
+				item = append(item, line[marker.width:])
+				i++
+				continue
+			}
+			if _, isItem := parseListMarker(line); isItem || startsBlock(line) || isBlank(item[len(item)-1]) {
+				break
+			}
+			item = append(item, line)
+			i++
+		}
+
+		// 列表项之间或项内有空行时为松散列表
+		for _, l := range item[:len(item)-1] {
+			if isBlank(l) {
+				loose = true
+			}
+		}
+		items = append(items, item)
+
+		// 列表项之间的空行
+		j := i
+		for j < len(lines) && isBlank(lines[j]) {
+			j++
+		}
+		if j > i && j < len(lines) {
+			if next, ok := parseListMarker(lines[j]); ok && next.ordered == first.ordered && next.char == first.char {
+				loose = true
+				i = j
+			}
+		}
+	}
+
+	switch {
+	case !first.ordered:
+		b.WriteString("<ul>\n")
+	case first.start != 1:
+		b.WriteString(`<ol start="` + strconv.Itoa(first.start) + `">` + "\n")
+	default:
+		b.WriteString("<ol>\n")
+	}
+
+	for _, item := range items {
+		b.WriteString("<li>")
+		// 任务列表
+		if m := taskPattern.FindStringSubmatch(item[0]); m != nil {
+			if m[1] == " " {
+				b.WriteString(`<input type="checkbox" disabled> `)
+			} else {
+				b.WriteString(`<input type="checkbox" checked disabled> `)
+			}
+			item[0] = item[0][len(m[0]):]
+		}
+		renderBlocks(b, item, !loose)
+		b.WriteString("</li>\n")
+	}
+
+	if first.ordered {
+		b.WriteString("</ol>\n")
+	} else {
+		b.WriteString("</ul>\n")
+	}
+	return i
+}
+
+// renderTable 渲染GFM表格
+func renderTable(b *strings.Builder, lines []string, start int) int {
+	header := splitTableRow(lines[start])
+	delims := splitTableRow(lines[start+1])
+
+	aligns := make([]string, len(header))
+	for i := range aligns {
+		if i >= len(delims) {
+			break
+		}
+		d := strings.TrimSpace(delims[i])
+		left, right := strings.HasPrefix(d, ":"), strings.HasSuffix(d, ":")
+		switch {
+		case left && right:
+			aligns[i] = "center"
+		case right:
+			aligns[i] = "right"
+		case left:
+			aligns[i] = "left"
+		}
+	}
+
+	writeRow := func(cells []string, tag string) {
+		b.WriteString("<tr>")
+		for i := range header {
+			cell := ""
+			if i < len(cells) {
+				cell = strings.TrimSpace(cells[i])
+			}
+			if aligns[i] != "" {
+				b.WriteString("<" + tag + ` align="` + aligns[i] + `">`)
+			} else {
+				b.WriteString("<" + tag + ">")
+			}
+			b.WriteString(renderInline(cell))
+			b.WriteString("</" + tag + ">")
+		}
+		b.WriteString("</tr>\n")
+	}
+
+	b.WriteString("<table>\n<thead>\n")
+	writeRow(header, "th")
+	b.WriteString("</thead>\n")
+
+	i := start + 2
+	if i < len(lines) && !isBlank(lines[i]) && strings.Contains(lines[i], "|") {
+		b.WriteString("<tbody>\n")
+		for ; i < len(lines) && !isBlank(lines[i]) && strings.Contains(lines[i], "|"); i++ {
+			writeRow(splitTableRow(lines[i]), "td")
+		}
+		b.WriteString("</tbody>\n")
+	}
+	b.WriteString("</table>\n")
+	return i
+}
+
+// splitTableRow 按未转义的竖线切分表格行
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' && i+1 < len(line) && line[i+1] == '|' {
+			cell.WriteByte('|')
+			i++
+			continue
+		}
+		if line[i] == '|' {
+			cells = append(cells, cell.String())
+			cell.Reset()
+			continue
+		}
+		cell.WriteByte(line[i])
+	}
+	return append(cells, cell.String())
+}
+
+// renderParagraph 渲染段落，段落后紧跟 === 或 --- 时为Setext标题
+func renderParagraph(b *strings.Builder, lines []string, start int, tight bool) int {
+	var para []string
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) {
+			break
+		}
+		if i > start {
+			if m := setextPattern.FindStringSubmatch(line); m != nil {
+				level := 1
+				if m[1][0] == '-' {
+					level = 2
+				}
+				writeHeading(b, level, strings.Join(para, "\n"))
+				return i + 1
+			}
+			if startsBlock(line) {
+				break
+			}
+		}
+		para = append(para, strings.TrimLeft(line, " "))
+	}
+
+	text := renderInline(strings.Join(para, "\n"))
+	if tight {
+		b.WriteString(text)
+		b.WriteByte('\n')
+	} else {
+		b.WriteString("<p>" + text + "</p>\n")
+	}
+	return i
+}
+
+// startsBlock 判断该行能否打断段落开始新的块
+func startsBlock(line string) bool {
+	if indentOf(line) >= 4 {
+		return false
+	}
+	if fencePattern.MatchString(line) || atxHeadingPattern.MatchString(line) || hrPattern.MatchString(line) ||
+		strings.HasPrefix(strings.TrimLeft(line, " "), ">") || htmlBlockPattern.MatchString(line) {
+		return true
+	}
+	// 有序列表只有从1开始才能打断段落，空列表项不能打断段落
+	if m := bulletPattern.FindStringSubmatch(line); m != nil {
+		return m[3] != ""
+	}
+	if m := orderedPattern.FindStringSubmatch(line); m != nil {
+		return m[2] == "1" && m[4] != ""
+	}
+	return false
+}
+
+// writeHeading 输出标题，锚点ID在渲染后统一生成
+func writeHeading(b *strings.Builder, level int, text string) {
+	tag := "h" + strconv.Itoa(level)
+	b.WriteString("<" + tag + ">" + renderInline(strings.TrimSpace(text)) + "</" + tag + ">\n")
+}
+
+// renderInline 渲染行内元素
+func renderInline(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		c := text[i]
+		rest := text[i:]
+
+		switch {
+		// 反斜杠转义
+		case c == '\\' && i+1 < len(text) && isPunct(text[i+1]):
+			b.WriteString(html.EscapeString(text[i+1 : i+2]))
+			i += 2
+			continue
+
+		// 反斜杠硬换行
+		case c == '\\' && i+1 < len(text) && text[i+1] == '\n':
+			b.WriteString("<br>\n")
+			i += 2
+			continue
+
+		// 两个以上空格的硬换行
+		case c == ' ' && strings.HasPrefix(strings.TrimLeft(rest, " "), "\n") && len(rest)-len(strings.TrimLeft(rest, " ")) >= 2:
+			b.WriteString("<br>\n")
+			i += len(rest) - len(strings.TrimLeft(rest, " ")) + 1
+			continue
+
+		// 行内代码
+		case c == '`':
+			if code, n := parseCodeSpan(rest); n > 0 {
+				b.WriteString(code)
+				i += n
+				continue
+			}
+
+		// 图片
+		case c == '!' && strings.HasPrefix(rest, "!["):
+			if label, dest, title, n := parseLink(rest[1:]); n > 0 {
+				b.WriteString(`<img src="` + html.EscapeString(dest) + `" alt="` + html.EscapeString(plainText(label)) + `"`)
+				if title != "" {
+					b.WriteString(` title="` + html.EscapeString(title) + `"`)
+				}
+				b.WriteString(">")
+				i += n + 1
+				continue
+			}
+
+		// 链接
+		case c == '[':
+			if label, dest, title, n := parseLink(rest); n > 0 {
+				b.WriteString(`<a href="` + html.EscapeString(dest) + `"`)
+				if title != "" {
+					b.WriteString(` title="` + html.EscapeString(title) + `"`)
+				}
+				b.WriteString(">" + renderInline(label) + "</a>")
+				i += n
+				continue
+			}
+
+		// 自动链接与行内HTML
+		case c == '<':
+			if m := autolinkPattern.FindStringSubmatch(rest); m != nil {
+				url := html.EscapeString(m[1])
+				b.WriteString(`<a href="` + url + `">` + url + "</a>")
+				i += len(m[0])
+				continue
+			}
+			if m := inlineTagPattern.FindString(rest); m != "" {
+				b.WriteString(m)
+				i += len(m)
+				continue
+			}
+
+		// 强调与删除线
+		case c == '*' || c == '_' || c == '~':
+			if out, n := parseEmphasis(text, i); n > 0 {
+				b.WriteString(out)
+				i += n
+				continue
+			}
+		}
+
+		// 普通字符，保留已有的HTML实体
+		if c == '&' {
+			if m := entityPattern.FindString(rest); m != "" {
+				b.WriteString(m)
+				i += len(m)
+				continue
+			}
+		}
+		b.WriteString(html.EscapeString(text[i : i+1]))
+		i++
+	}
+	return b.String()
+}
+
+var entityPattern = regexp.MustCompile(`^&(#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[a-zA-Z][a-zA-Z0-9]{1,31});`)
+
+func isPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+// parseCodeSpan 解析行内代码，返回HTML和消耗的字节数
+func parseCodeSpan(text string) (string, int) {
+	ticks := len(text) - len(strings.TrimLeft(text, "`"))
+	fence := text[:ticks]
+	for j := ticks; j < len(text); {
+		k := strings.Index(text[j:], fence)
+		if k < 0 {
+			break
+		}
+		k += j
+		end := k + ticks
+		// 闭合反引号数量必须一致
+		if end < len(text) && text[end] == '`' {
+			j = end + len(text[end:]) - len(strings.TrimLeft(text[end:], "`"))
+			continue
+		}
+		code := strings.ReplaceAll(text[ticks:k], "\n", " ")
+		if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+			code = code[1 : len(code)-1]
+		}
+		return "<code>" + html.EscapeString(code) + "</code>", end
+	}
+	return "", 0
+}
+
+// parseLink 解析 [文本](地址 "标题")，返回消耗的字节数
+func parseLink(text string) (label, dest, title string, n int) {
+	// 匹配方括号，支持嵌套
+	depth := 0
+	end := -1
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '`':
+			if _, m := parseCodeSpan(text[i:]); m > 0 {
+				i += m - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				end = i
+			}
+		}
+		if end >= 0 {
+			break
+		}
+	}
+	if end < 0 || end+1 >= len(text) || text[end+1] != '(' {
+		return "", "", "", 0
+	}
+	label = text[1:end]
+
+	// 解析地址和标题
+	rest := text[end+2:]
+	closeIdx := -1
+	parens := 0
+	inQuote := byte(0)
+	for i := 0; i < len(rest); i++ {
+		c := rest[i]
+		if c == '\\' {
+			i++
+			continue
+		}
+		if inQuote != 0 {
+			if c == inQuote {
+				inQuote = 0
+			}
+			continue
+		}
+		if (c == '"' || c == '\'') && i > 0 && (rest[i-1] == ' ' || rest[i-1] == '\t') {
+			inQuote = c
+			continue
+		}
+		if c == '(' {
+			parens++
+		} else if c == ')' {
+			if parens == 0 {
+				closeIdx = i
+				break
+			}
+			parens--
+		} else if c == '\n' && strings.TrimSpace(rest[:i]) == "" {
+			return "", "", "", 0
+		}
+	}
+	if closeIdx < 0 {
+		return "", "", "", 0
+	}
+
+	inner := strings.TrimSpace(rest[:closeIdx])
+	if strings.HasPrefix(inner, "<") {
+		if k := strings.Index(inner, ">"); k > 0 {
+			dest = inner[1:k]
+			inner = strings.TrimSpace(inner[k+1:])
+		}
+	} else if k := strings.IndexAny(inner, " \t\n"); k >= 0 {
+		dest = inner[:k]
+		inner = strings.TrimSpace(inner[k:])
+	} else {
+		dest = inner
+		inner = ""
+	}
+	if len(inner) >= 2 && (inner[0] == '"' || inner[0] == '\'' || inner[0] == '(') {
+		title = inner[1 : len(inner)-1]
+	} else if inner != "" {
+		return "", "", "", 0
+	}
+
+	return label, unescapePunct(dest), unescapePunct(title), end + 2 + closeIdx + 1
+}
+
+// unescapePunct 去除标点前的转义反斜杠
+func unescapePunct(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isPunct(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// parseEmphasis 解析 *斜体*、**粗体**、~~删除线~~，返回HTML和消耗的字节数
+func parseEmphasis(text string, pos int) (string, int) {
+	c := text[pos]
+	run := 0
+	for pos+run < len(text) && text[pos+run] == c {
+		run++
+	}
+
+	// 前后字符判断：左侧分隔符后不能紧跟空白，下划线不能位于单词内部
+	if pos+run >= len(text) || isSpace(text[pos+run]) {
+		return "", 0
+	}
+	if c == '_' && pos > 0 && isWordChar(text[pos-1]) {
+		return "", 0
+	}
+
+	var tag string
+	var width int
+	switch {
+	case c == '~' && run == 2:
+		tag, width = "del", 2
+	case c == '~':
+		return "", 0
+	case run >= 3:
+		// ***粗斜体***
+		if inner, n := findEmphasisClose(text, pos+3, c, 3); n > 0 {
+			return "<em><strong>" + renderInline(inner) + "</strong></em>", n - pos
+		}
+		tag, width = "strong", 2
+	case run == 2:
+		tag, width = "strong", 2
+	default:
+		tag, width = "em", 1
+	}
+
+	inner, n := findEmphasisClose(text, pos+width, c, width)
+	if n == 0 {
+		return "", 0
+	}
+	return "<" + tag + ">" + renderInline(inner) + "</" + tag + ">", n - pos
+}
+
+// findEmphasisClose 查找闭合分隔符，返回内部文本及闭合后的位置
+func findEmphasisClose(text string, start int, c byte, width int) (string, int) {
+	for i := start; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+			continue
+		case '`':
+			if _, n := parseCodeSpan(text[i:]); n > 0 {
+				i += n - 1
+				continue
+			}
+		case '[':
+			if _, _, _, n := parseLink(text[i:]); n > 0 {
+				i += n - 1
+				continue
+			}
+		}
+		if text[i] != c {
+			continue
+		}
+
+		run := 1
+		for i+run < len(text) && text[i+run] == c {
+			run++
+		}
+		end := i + run
+
+		// 闭合分隔符长度必须一致，避免 **a* 误匹配；长度不同的分隔符可能是内部嵌套的强调
+		if run != width || i == start || isSpace(text[i-1]) || (c == '_' && end < len(text) && isWordChar(text[end])) {
+			if run == 2 && width == 1 && end < len(text) && !isSpace(text[end]) {
+				if _, n := findEmphasisClose(text, end, c, 2); n > 0 {
+					i = n - 1
+					continue
+				}
+			}
+			i = end - 1
+			continue
+		}
+		return text[start:i], end
+	}
+	return "", 0
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// plainText 去除Markdown标记，用于图片替代文本
+func plainText(text string) string {
+	return strings.NewReplacer("*", "", "_", "", "`", "", "~", "").Replace(text)
+}
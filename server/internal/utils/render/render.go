@@ -0,0 +1,88 @@
+package render
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// 内容格式，与 cms_article_contents.content_format 一致
+const (
+	FormatMarkdown int8 = 1
+	FormatHTML     int8 = 2
+)
+
+// 阅读速度（每分钟）
+const (
+	cjkCharsPerMinute = 400 // 中日韩文字
+	wordsPerMinute    = 200 // 其他语言单词
+)
+
+// Result 渲染结果
+type Result struct {
+	HTML        string     `json:"html"`         // 过滤后的HTML
+	TOC         []*TOCItem `json:"toc"`          // 目录
+	WordCount   int        `json:"word_count"`   // 字数，中日韩文字按字计，其他语言按词计
+	ReadingTime int        `json:"reading_time"` // 预计阅读时长（分钟）
+}
+
+// Render 渲染文章内容：Markdown转换为HTML，两种格式均按白名单过滤，并生成标题锚点、目录和字数统计
+func Render(content string, format int8) *Result {
+	var out string
+	if format == FormatHTML {
+		out = Sanitize(content)
+	} else {
+		out = Sanitize(Markdown(content))
+	}
+
+	out, toc := addHeadingAnchors(out)
+	if toc == nil {
+		toc = []*TOCItem{}
+	}
+
+	cjk, words := countWords(out)
+	result := &Result{
+		HTML:      out,
+		TOC:       toc,
+		WordCount: cjk + words,
+	}
+	if result.WordCount > 0 {
+		minutes := float64(cjk)/cjkCharsPerMinute + float64(words)/wordsPerMinute
+		result.ReadingTime = int(math.Ceil(minutes))
+	}
+	return result
+}
+
+// countWords 统计HTML文本内容中的中日韩文字数和其他语言单词数
+func countWords(src string) (cjk int, words int) {
+	tokenizer := nethtml.NewTokenizer(strings.NewReader(src))
+	for {
+		tt := tokenizer.Next()
+		if tt == nethtml.ErrorToken {
+			return
+		}
+		if tt != nethtml.TextToken {
+			continue
+		}
+
+		inWord := false
+		for _, r := range string(tokenizer.Text()) {
+			switch {
+			case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+				cjk++
+				inWord = false
+			case unicode.IsLetter(r) || unicode.IsDigit(r):
+				if !inWord {
+					words++
+				}
+				inWord = true
+			case r == '\'' || r == '-' || r == '_':
+				// 单词内部的连接符不拆分单词
+			default:
+				inWord = false
+			}
+		}
+	}
+}
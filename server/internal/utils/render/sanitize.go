@@ -0,0 +1,267 @@
+package render
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// allowedTags 允许的标签及各自允许的属性
+var allowedTags = map[string]map[string]bool{
+	"a":          {"href": true, "title": true},
+	"img":        {"src": true, "alt": true, "title": true, "width": true, "height": true},
+	"p":          {},
+	"br":         {},
+	"hr":         {},
+	"h1":         {},
+	"h2":         {},
+	"h3":         {},
+	"h4":         {},
+	"h5":         {},
+	"h6":         {},
+	"strong":     {},
+	"b":          {},
+	"em":         {},
+	"i":          {},
+	"u":          {},
+	"s":          {},
+	"del":        {},
+	"ins":        {},
+	"mark":       {},
+	"sub":        {},
+	"sup":        {},
+	"small":      {},
+	"abbr":       {},
+	"kbd":        {},
+	"code":       {},
+	"pre":        {},
+	"blockquote": {"cite": true},
+	"q":          {"cite": true},
+	"ul":         {},
+	"ol":         {"start": true, "reversed": true},
+	"li":         {},
+	"dl":         {},
+	"dt":         {},
+	"dd":         {},
+	"table":      {},
+	"caption":    {},
+	"thead":      {},
+	"tbody":      {},
+	"tfoot":      {},
+	"tr":         {},
+	"th":         {"align": true, "colspan": true, "rowspan": true},
+	"td":         {"align": true, "colspan": true, "rowspan": true},
+	"div":        {},
+	"span":       {},
+	"figure":     {},
+	"figcaption": {},
+	"details":    {"open": true},
+	"summary":    {},
+	"input":      {"type": true, "checked": true, "disabled": true},
+}
+
+// globalAttrs 所有允许的标签都可以使用的属性
+var globalAttrs = map[string]bool{"id": true, "class": true, "title": true}
+
+// droppedTags 连同内容一起移除的标签
+var droppedTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "frame": true, "frameset": true, "object": true,
+	"embed": true, "applet": true, "noscript": true, "template": true, "textarea": true, "select": true,
+	"title": true, "head": true, "svg": true, "math": true,
+}
+
+// voidTags 无需闭合的标签
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true, "input": true}
+
+var (
+	// 属性值只允许安全字符，防止class/id被用于伪造页面元素
+	classPattern  = regexp.MustCompile(`^[\w\- ]{1,200}$`)
+	idPattern     = regexp.MustCompile(`^[\p{L}\p{N}_\-]{1,100}$`)
+	numberPattern = regexp.MustCompile(`^\d{1,5}$`)
+	dataURIImage  = regexp.MustCompile(`^data:image/(png|gif|jpeg|webp);base64,[A-Za-z0-9+/=]+$`)
+)
+
+// Sanitize 按白名单过滤HTML，移除不允许的标签、属性和危险链接，并补全未闭合的标签
+func Sanitize(src string) string {
+	var b strings.Builder
+	var stack []string
+	skipDepth := 0
+	skipTag := ""
+
+	tokenizer := nethtml.NewTokenizer(strings.NewReader(src))
+	for {
+		tt := tokenizer.Next()
+		if tt == nethtml.ErrorToken {
+			// io.EOF 表示解析完成，其他错误按已解析部分输出
+			break
+		}
+		token := tokenizer.Token()
+		tag := token.Data
+
+		// 处于被移除的标签内部时只跟踪同名标签的嵌套
+		if skipDepth > 0 {
+			switch {
+			case tt == nethtml.StartTagToken && tag == skipTag:
+				skipDepth++
+			case tt == nethtml.EndTagToken && tag == skipTag:
+				skipDepth--
+			}
+			continue
+		}
+
+		switch tt {
+		case nethtml.TextToken:
+			b.WriteString(html.EscapeString(token.Data))
+
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			if droppedTags[tag] {
+				if tt == nethtml.StartTagToken && !voidTags[tag] {
+					skipDepth, skipTag = 1, tag
+				}
+				continue
+			}
+			allowed, ok := allowedTags[tag]
+			if !ok {
+				continue
+			}
+			if tag == "input" && !isCheckbox(token.Attr) {
+				continue
+			}
+
+			b.WriteString("<" + tag)
+			writeAttrs(&b, tag, token.Attr, allowed)
+			b.WriteString(">")
+			if !voidTags[tag] {
+				stack = append(stack, tag)
+			}
+
+		case nethtml.EndTagToken:
+			if _, ok := allowedTags[tag]; !ok || voidTags[tag] {
+				continue
+			}
+			// 闭合到最近的同名标签，中间未闭合的标签一并闭合
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i] != tag {
+					continue
+				}
+				for j := len(stack) - 1; j >= i; j-- {
+					b.WriteString("</" + stack[j] + ">")
+				}
+				stack = stack[:i]
+				break
+			}
+		}
+	}
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		b.WriteString("</" + stack[i] + ">")
+	}
+	return b.String()
+}
+
+// writeAttrs 输出允许的属性
+func writeAttrs(b *strings.Builder, tag string, attrs []nethtml.Attribute, allowed map[string]bool) {
+	seen := make(map[string]bool, len(attrs))
+	external := false
+	for _, attr := range attrs {
+		key := strings.ToLower(attr.Key)
+		if seen[key] || attr.Namespace != "" || !(allowed[key] || globalAttrs[key]) {
+			continue
+		}
+
+		val := attr.Val
+		switch key {
+		case "href", "cite":
+			if !isSafeURL(val) {
+				continue
+			}
+			if key == "href" && isAbsoluteURL(val) {
+				external = true
+			}
+		case "src":
+			if !isSafeURL(val) && !dataURIImage.MatchString(val) {
+				continue
+			}
+		case "class":
+			if !classPattern.MatchString(val) {
+				continue
+			}
+		case "id":
+			if !idPattern.MatchString(val) {
+				continue
+			}
+		case "width", "height", "colspan", "rowspan", "start":
+			if !numberPattern.MatchString(val) {
+				continue
+			}
+		case "align":
+			if val != "left" && val != "center" && val != "right" {
+				continue
+			}
+		case "type":
+			val = "checkbox"
+		case "checked", "disabled", "open", "reversed":
+			val = ""
+		}
+
+		seen[key] = true
+		if val == "" && (key == "checked" || key == "disabled" || key == "open" || key == "reversed") {
+			b.WriteString(" " + key)
+			continue
+		}
+		b.WriteString(" " + key + `="` + html.EscapeString(val) + `"`)
+	}
+
+	// 外部链接禁止传递来源并告知搜索引擎不跟踪
+	if tag == "a" && external {
+		b.WriteString(` rel="nofollow noopener noreferrer" target="_blank"`)
+	}
+	// 复选框只用于展示任务列表
+	if tag == "input" && !seen["disabled"] {
+		b.WriteString(" disabled")
+	}
+}
+
+// isCheckbox 判断input是否为复选框
+func isCheckbox(attrs []nethtml.Attribute) bool {
+	for _, attr := range attrs {
+		if strings.ToLower(attr.Key) == "type" {
+			return strings.EqualFold(strings.TrimSpace(attr.Val), "checkbox")
+		}
+	}
+	return false
+}
+
+// isSafeURL 判断链接是否安全，只允许http、https、mailto协议和相对地址
+func isSafeURL(raw string) bool {
+	// 浏览器解析协议时会忽略空白和控制字符
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, raw)
+	if cleaned == "" {
+		return false
+	}
+
+	colon := strings.IndexByte(cleaned, ':')
+	if colon < 0 {
+		return true
+	}
+	// 冒号出现在路径、查询或片段中时不是协议
+	if sep := strings.IndexAny(cleaned, "/?#"); sep >= 0 && sep < colon {
+		return true
+	}
+
+	scheme := strings.ToLower(cleaned[:colon])
+	return scheme == "http" || scheme == "https" || scheme == "mailto"
+}
+
+// isAbsoluteURL 判断是否为指向其他站点的绝对地址
+func isAbsoluteURL(raw string) bool {
+	lower := strings.ToLower(strings.TrimSpace(raw))
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "//")
+}
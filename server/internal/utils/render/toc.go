@@ -0,0 +1,154 @@
+package render
+
+import (
+	"html"
+	"strconv"
+	"strings"
+	"unicode"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// TOCItem 目录项
+type TOCItem struct {
+	ID       string     `json:"id"`                 // 标题锚点ID
+	Text     string     `json:"text"`               // 标题文本
+	Level    int        `json:"level"`              // 标题级别1-6
+	Children []*TOCItem `json:"children,omitempty"` // 下级标题
+}
+
+// headingLevel 返回标题标签的级别，非标题返回0
+func headingLevel(tag string) int {
+	if len(tag) == 2 && tag[0] == 'h' && tag[1] >= '1' && tag[1] <= '6' {
+		return int(tag[1] - '0')
+	}
+	return 0
+}
+
+// addHeadingAnchors 为标题生成锚点ID并提取目录，输入必须是已过滤的HTML
+//
+// 锚点ID由标题文本生成，重复时依次追加 -1、-2 后缀，内容不变时ID保持稳定；
+// 标题已有ID时沿用原ID。
+func addHeadingAnchors(src string) (string, []*TOCItem) {
+	var b strings.Builder
+	var headings []*TOCItem
+	used := make(map[string]bool)
+
+	var heading *TOCItem
+	var headingAttrs []nethtml.Attribute
+	var inner strings.Builder
+	var text strings.Builder
+
+	tokenizer := nethtml.NewTokenizer(strings.NewReader(src))
+	for {
+		tt := tokenizer.Next()
+		if tt == nethtml.ErrorToken {
+			break
+		}
+		token := tokenizer.Token()
+
+		if heading == nil {
+			if tt == nethtml.StartTagToken {
+				if level := headingLevel(token.Data); level > 0 {
+					heading = &TOCItem{Level: level}
+					headingAttrs = token.Attr
+					inner.Reset()
+					text.Reset()
+					continue
+				}
+			}
+			b.WriteString(token.String())
+			continue
+		}
+
+		// 标题内部：缓存内容直到标题结束
+		if tt == nethtml.EndTagToken && headingLevel(token.Data) == heading.Level {
+			heading.Text = strings.Join(strings.Fields(text.String()), " ")
+
+			id := ""
+			for _, attr := range headingAttrs {
+				if attr.Key == "id" {
+					id = attr.Val
+				}
+			}
+			if id == "" || used[id] {
+				id = uniqueSlug(slugify(heading.Text), used)
+			}
+			used[id] = true
+			heading.ID = id
+
+			tag := token.Data
+			b.WriteString("<" + tag + ` id="` + html.EscapeString(id) + `"`)
+			for _, attr := range headingAttrs {
+				if attr.Key != "id" {
+					b.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+				}
+			}
+			b.WriteString(">" + inner.String() + "</" + tag + ">")
+
+			headings = append(headings, heading)
+			heading = nil
+			continue
+		}
+		if tt == nethtml.TextToken {
+			text.WriteString(token.Data)
+		}
+		inner.WriteString(token.String())
+	}
+
+	return b.String(), buildTOC(headings)
+}
+
+// slugify 将标题文本转换为锚点ID，保留字母、数字（含中文），空白和连字符转为 -
+func slugify(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-':
+			dash = true
+		}
+	}
+	if b.Len() == 0 {
+		return "heading"
+	}
+	return b.String()
+}
+
+// uniqueSlug 在重复时追加序号
+func uniqueSlug(slug string, used map[string]bool) string {
+	if !used[slug] {
+		return slug
+	}
+	for i := 1; ; i++ {
+		candidate := slug + "-" + strconv.Itoa(i)
+		if !used[candidate] {
+			return candidate
+		}
+	}
+}
+
+// buildTOC 将按出现顺序排列的标题构建为目录树，跳级的标题挂在最近的上级标题下
+func buildTOC(headings []*TOCItem) []*TOCItem {
+	var roots []*TOCItem
+	var stack []*TOCItem
+	for _, h := range headings {
+		for len(stack) > 0 && stack[len(stack)-1].Level >= h.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, h)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, h)
+		}
+		stack = append(stack, h)
+	}
+	return roots
+}
@@ -0,0 +1,122 @@
+package segment
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// 摘要截取参数（字符数）
+const (
+	snippetLength = 120 // 摘要长度
+	snippetBefore = 30  // 首个命中位置之前保留的长度
+)
+
+// Highlight 转义文本并用<mark>标签标记命中的词语，匹配时忽略大小写
+func Highlight(text string, words []string) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	return renderMarks(runes, matchWords(runes, words))
+}
+
+// Snippet 截取首个命中位置附近的文本作为摘要并高亮命中词语，没有命中时截取开头
+func Snippet(text string, words []string) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	marks := matchWords(runes, words)
+
+	start := 0
+	if len(marks) > 0 && marks[0][0] > snippetBefore {
+		start = marks[0][0] - snippetBefore
+	}
+	end := start + snippetLength
+	if end > len(runes) {
+		end = len(runes)
+		if start = end - snippetLength; start < 0 {
+			start = 0
+		}
+	}
+
+	// 截取窗口内的命中区间并换算为相对位置
+	var window [][2]int
+	for _, m := range marks {
+		if m[0] >= start && m[1] <= end {
+			window = append(window, [2]int{m[0] - start, m[1] - start})
+		}
+	}
+
+	result := renderMarks(runes[start:end], window)
+	if start > 0 {
+		result = "…" + result
+	}
+	if end < len(runes) {
+		result += "…"
+	}
+	return result
+}
+
+// matchWords 查找词语在文本中的命中区间，返回按位置排序且互不重叠的区间
+func matchWords(runes []rune, words []string) [][2]int {
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	// 长词优先，避免短词截断长词的高亮
+	terms := make([][]rune, 0, len(words))
+	for _, word := range words {
+		if word = strings.TrimSpace(word); word != "" {
+			terms = append(terms, []rune(strings.ToLower(word)))
+		}
+	}
+	sort.Slice(terms, func(i, j int) bool { return len(terms[i]) > len(terms[j]) })
+
+	covered := make([]bool, len(runes))
+	var marks [][2]int
+	for _, term := range terms {
+		for i := 0; i+len(term) <= len(lower); i++ {
+			if !hasRunes(lower[i:], term) || anyCovered(covered[i:i+len(term)]) {
+				continue
+			}
+			for j := i; j < i+len(term); j++ {
+				covered[j] = true
+			}
+			marks = append(marks, [2]int{i, i + len(term)})
+			i += len(term) - 1
+		}
+	}
+	sort.Slice(marks, func(i, j int) bool { return marks[i][0] < marks[j][0] })
+	return marks
+}
+
+// renderMarks 转义文本并在命中区间插入<mark>标签
+func renderMarks(runes []rune, marks [][2]int) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range marks {
+		b.WriteString(html.EscapeString(string(runes[last:m[0]])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[m[0]:m[1]])))
+		b.WriteString("</mark>")
+		last = m[1]
+	}
+	b.WriteString(html.EscapeString(string(runes[last:])))
+	return b.String()
+}
+
+func hasRunes(text, prefix []rune) bool {
+	for i, r := range prefix {
+		if text[i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+func anyCovered(covered []bool) bool {
+	for _, c := range covered {
+		if c {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,229 @@
+package segment
+
+import (
+	"bufio"
+	_ "embed"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// DefaultFreq 用户词典未指定词频时使用的默认词频
+const DefaultFreq = 3000
+
+//go:embed dict.txt
+var builtinDict string
+
+// Segmenter 基于词典的中文分词器
+//
+// 分词时先按标点和空白切分为文本块，块内每个汉字和每段连续的字母数字各作为一个单元，
+// 再根据词典构建切分有向无环图，通过动态规划求出概率最大的切分路径。
+type Segmenter struct {
+	mu    sync.RWMutex
+	freq  map[string]int // 词频，词语前缀以0记录
+	total int            // 词频总和
+}
+
+var (
+	defaultSegmenter *Segmenter
+	defaultOnce      sync.Once
+)
+
+// New 创建空词典的分词器
+func New() *Segmenter {
+	return &Segmenter{freq: make(map[string]int)}
+}
+
+// Default 获取加载内置词典的默认分词器
+func Default() *Segmenter {
+	defaultOnce.Do(func() {
+		defaultSegmenter = New()
+		// 内置词典随程序发布，格式固定，不会读取失败
+		_ = defaultSegmenter.Load(strings.NewReader(builtinDict))
+	})
+	return defaultSegmenter
+}
+
+// Load 从读取器加载词典，每行格式为"词语 [词频]"，#开头的行为注释
+func (s *Segmenter) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		freq := DefaultFreq
+		if len(fields) > 1 {
+			if n, err := strconv.Atoi(fields[1]); err == nil && n > 0 {
+				freq = n
+			}
+		}
+		s.AddWord(fields[0], freq)
+	}
+	return scanner.Err()
+}
+
+// LoadFile 从文件加载词典
+func (s *Segmenter) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return s.Load(f)
+}
+
+// AddWord 添加词语，已存在时更新词频
+func (s *Segmenter) AddWord(word string, freq int) {
+	blocks := splitBlocks(word)
+	if len(blocks) != 1 || freq <= 0 {
+		return
+	}
+	units := blocks[0]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.Join(units, "")
+	s.total += freq - s.freq[key]
+	s.freq[key] = freq
+
+	// 记录前缀，用于构建有向无环图时提前结束查找
+	for i := 1; i < len(units); i++ {
+		prefix := strings.Join(units[:i], "")
+		if _, ok := s.freq[prefix]; !ok {
+			s.freq[prefix] = 0
+		}
+	}
+}
+
+// Cut 精确模式分词，返回的词语中字母统一转为小写
+func (s *Segmenter) Cut(text string) []string {
+	var words []string
+	s.cut(text, func(units []string, start, end int) {
+		words = append(words, strings.Join(units[start:end], ""))
+	})
+	return words
+}
+
+// CutForSearch 搜索引擎模式分词，在精确模式基础上对长词再切出词典中的二字词和三字词，提高召回率
+func (s *Segmenter) CutForSearch(text string) []string {
+	var words []string
+	s.cut(text, func(units []string, start, end int) {
+		if end-start > 2 {
+			for n := 2; n <= 3 && n < end-start; n++ {
+				for i := start; i+n <= end; i++ {
+					if sub := strings.Join(units[i:i+n], ""); s.inDict(sub) {
+						words = append(words, sub)
+					}
+				}
+			}
+		}
+		words = append(words, strings.Join(units[start:end], ""))
+	})
+	return words
+}
+
+// cut 对文本分块并逐块切分，emit接收每个词语在单元切片中的区间
+func (s *Segmenter) cut(text string, emit func(units []string, start, end int)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	logTotal := math.Log(float64(s.total + 1))
+	for _, units := range splitBlocks(text) {
+		route := s.calcRoute(units, logTotal)
+		for i := 0; i < len(units); {
+			end := route[i].end
+			emit(units, i, end)
+			i = end
+		}
+	}
+}
+
+// routeNode 动态规划节点，记录从当前位置到结尾的最大对数概率及当前词的结束位置
+type routeNode struct {
+	logProb float64
+	end     int
+}
+
+// calcRoute 从后向前计算最大概率切分路径
+func (s *Segmenter) calcRoute(units []string, logTotal float64) []routeNode {
+	n := len(units)
+	route := make([]routeNode, n+1)
+	for i := n - 1; i >= 0; i-- {
+		best := routeNode{logProb: math.Inf(-1)}
+		var word strings.Builder
+		for j := i; j < n; j++ {
+			word.WriteString(units[j])
+			freq, ok := s.freq[word.String()]
+			if !ok {
+				break
+			}
+			// 单个单元始终是合法切分，未登录时词频按1计算
+			if freq == 0 && j > i {
+				continue
+			}
+			if freq == 0 {
+				freq = 1
+			}
+			if p := math.Log(float64(freq)) - logTotal + route[j+1].logProb; p > best.logProb {
+				best = routeNode{logProb: p, end: j + 1}
+			}
+		}
+		if best.end == 0 {
+			best = routeNode{logProb: -logTotal + route[i+1].logProb, end: i + 1}
+		}
+		route[i] = best
+	}
+	return route
+}
+
+// inDict 判断是否为词典中的词语（不含前缀）
+func (s *Segmenter) inDict(word string) bool {
+	return s.freq[word] > 0
+}
+
+// splitBlocks 按空白和标点将文本切分为文本块，块内每个汉字、每段连续字母数字各为一个单元
+func splitBlocks(text string) [][]string {
+	var blocks [][]string
+	var units []string
+	var alnum []rune
+
+	flushAlnum := func() {
+		if len(alnum) > 0 {
+			units = append(units, strings.ToLower(string(alnum)))
+			alnum = alnum[:0]
+		}
+	}
+	flushBlock := func() {
+		flushAlnum()
+		if len(units) > 0 {
+			blocks = append(blocks, units)
+			units = nil
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushAlnum()
+			units = append(units, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			alnum = append(alnum, r)
+		case (r == '+' || r == '#') && len(alnum) > 0:
+			// 保留 c++、c# 等写法
+			alnum = append(alnum, r)
+		default:
+			flushBlock()
+		}
+	}
+	flushBlock()
+
+	return blocks
+}
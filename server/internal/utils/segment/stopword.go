@@ -0,0 +1,36 @@
+package segment
+
+// stopWords 停用词，不参与检索匹配
+var stopWords = map[string]struct{}{
+	"的": {}, "了": {}, "是": {}, "在": {}, "和": {}, "与": {}, "及": {}, "或": {},
+	"也": {}, "就": {}, "都": {}, "而": {}, "把": {}, "被": {}, "让": {}, "给": {},
+	"着": {}, "过": {}, "吗": {}, "呢": {}, "吧": {}, "啊": {}, "呀": {}, "嘛": {},
+	"之": {}, "其": {}, "这": {}, "那": {}, "个": {}, "将": {}, "从": {}, "对": {},
+	"我们": {}, "你们": {}, "他们": {}, "这个": {}, "那个": {}, "一个": {}, "什么": {},
+	"如何": {}, "怎么": {}, "怎样": {}, "为什么": {}, "以及": {}, "并且": {}, "或者": {},
+	"a": {}, "an": {}, "the": {}, "and": {}, "or": {}, "of": {}, "to": {}, "in": {},
+	"on": {}, "for": {}, "is": {}, "are": {}, "with": {}, "by": {},
+}
+
+// IsStopWord 判断是否为停用词
+func IsStopWord(word string) bool {
+	_, ok := stopWords[word]
+	return ok
+}
+
+// FilterStopWords 过滤停用词并去重，保持原有顺序
+func FilterStopWords(words []string) []string {
+	seen := make(map[string]struct{}, len(words))
+	result := make([]string, 0, len(words))
+	for _, word := range words {
+		if IsStopWord(word) {
+			continue
+		}
+		if _, ok := seen[word]; ok {
+			continue
+		}
+		seen[word] = struct{}{}
+		result = append(result, word)
+	}
+	return result
+}
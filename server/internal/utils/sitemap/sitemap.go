@@ -0,0 +1,105 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// ContentType 站点地图内容类型
+const ContentType = "application/xml; charset=utf-8"
+
+// MaxURLs 单个站点地图允许的最大地址数（协议限制）
+const MaxURLs = 50000
+
+// 命名空间
+const (
+	nsSitemap = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	nsImage   = "http://www.google.com/schemas/sitemap-image/1.1"
+)
+
+// URL 站点地图中的页面
+type URL struct {
+	Loc     string    // 页面绝对地址
+	LastMod time.Time // 最后修改时间，为零值时不输出
+	Images  []string  // 页面包含的图片绝对地址
+}
+
+// Sitemap 站点地图索引中的子站点地图
+type Sitemap struct {
+	Loc     string    // 子站点地图绝对地址
+	LastMod time.Time // 最后修改时间，为零值时不输出
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	NS      string   `xml:"xmlns,attr"`
+	NSImage string   `xml:"xmlns:image,attr"`
+	URLs    []xmlURL `xml:"url"`
+}
+
+type xmlURL struct {
+	Loc     string     `xml:"loc"`
+	LastMod string     `xml:"lastmod,omitempty"`
+	Images  []xmlImage `xml:"image:image"`
+}
+
+type xmlImage struct {
+	Loc string `xml:"image:loc"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	NS       string       `xml:"xmlns,attr"`
+	Sitemaps []xmlSitemap `xml:"sitemap"`
+}
+
+type xmlSitemap struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// URLSet 生成站点地图，包含图片扩展
+func URLSet(urls []URL) ([]byte, error) {
+	set := urlSet{
+		NS:      nsSitemap,
+		NSImage: nsImage,
+		URLs:    make([]xmlURL, 0, len(urls)),
+	}
+	for _, u := range urls {
+		entry := xmlURL{Loc: u.Loc, LastMod: formatTime(u.LastMod)}
+		for _, image := range u.Images {
+			entry.Images = append(entry.Images, xmlImage{Loc: image})
+		}
+		set.URLs = append(set.URLs, entry)
+	}
+	return marshalXML(set)
+}
+
+// Index 生成站点地图索引
+func Index(sitemaps []Sitemap) ([]byte, error) {
+	index := sitemapIndex{
+		NS:       nsSitemap,
+		Sitemaps: make([]xmlSitemap, 0, len(sitemaps)),
+	}
+	for _, s := range sitemaps {
+		index.Sitemaps = append(index.Sitemaps, xmlSitemap{Loc: s.Loc, LastMod: formatTime(s.LastMod)})
+	}
+	return marshalXML(index)
+}
+
+// formatTime 按W3C Datetime格式输出时间
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// marshalXML 序列化XML并添加声明
+func marshalXML(v interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,34 @@
+package slug
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Make 将文本转换为URL标识：字母转小写，保留字母、数字（含中文）和下划线，
+// 空白和其他符号合并为一个 -，结果最多 maxLen 个字符，maxLen<=0 表示不限制
+func Make(text string, maxLen int) string {
+	var b strings.Builder
+	count := 0
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		if maxLen > 0 && count >= maxLen {
+			break
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			if dash && b.Len() > 0 {
+				if maxLen > 0 && count+2 > maxLen {
+					break
+				}
+				b.WriteByte('-')
+				count++
+			}
+			dash = false
+			b.WriteRune(r)
+			count++
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
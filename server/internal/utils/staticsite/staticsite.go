@@ -0,0 +1,301 @@
+package staticsite
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sunmoonstrand/go-react-blog/server/internal/utils/render"
+)
+
+// 页面模板，站点模板目录中的同名文件会替换内置模板
+const (
+	TemplateArticle  = "article.html"  // 文章页
+	TemplateList     = "list.html"     // 首页、分类、标签、月份归档的文章列表
+	TemplateArchives = "archives.html" // 归档首页
+)
+
+// pageTemplates 页面模板，其余模板（如 base.html）作为公共模板供页面引用
+var pageTemplates = []string{TemplateArticle, TemplateList, TemplateArchives}
+
+//go:embed templates/*.html
+var builtinTemplates embed.FS
+
+// Site 站点信息
+type Site struct {
+	Name        string
+	Description string
+	Keywords    string
+	URL         string // 站点地址，不以 / 结尾
+	Logo        string
+	Language    string
+	Copyright   string
+}
+
+// Link 链接
+type Link struct {
+	Name  string
+	URL   string
+	Count int // 文章数，不需要时为0
+}
+
+// ArticleItem 列表中的文章
+type ArticleItem struct {
+	Title       string
+	URL         string
+	Summary     string
+	Thumbnail   string
+	Author      string
+	PublishTime time.Time
+	IsTop       bool
+	Categories  []Link
+	Tags        []Link
+}
+
+// Article 文章页的文章
+type Article struct {
+	ArticleItem
+	ContentHTML template.HTML // 已过滤的正文HTML
+	TOC         []*render.TOCItem
+	WordCount   int
+	ReadingTime int // 预计阅读时长（分钟）
+	UpdatedAt   time.Time
+	SourceURL   string
+	SourceName  string
+}
+
+// Pagination 分页
+type Pagination struct {
+	Page       int
+	TotalPages int
+	PrevURL    string
+	NextURL    string
+}
+
+// Page 页面数据
+type Page struct {
+	Site        *Site
+	Title       string // 页面标题，首页为空
+	Description string
+	Path        string // 页面路径，如 /article/hello
+	FeedURL     string // 当前范围的订阅源地址
+	Article     *Article
+	Articles    []ArticleItem
+	Pagination  *Pagination
+	Archives    []Link
+	Categories  []Link
+	Tags        []Link
+	GeneratedAt time.Time
+}
+
+// Renderer 页面渲染器
+type Renderer struct {
+	pages map[string]*template.Template
+}
+
+// funcs 模板函数
+var funcs = template.FuncMap{
+	"date": func(t time.Time, layout string) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(layout)
+	},
+}
+
+// NewRenderer 加载内置模板，dir 不为空时用其中的同名 .html 文件替换内置模板，
+// 新增的文件作为公共模板，可在页面模板中通过 {{template "文件名" .}} 引用
+func NewRenderer(dir string) (*Renderer, error) {
+	sources := make(map[string]string)
+	entries, err := fs.ReadDir(builtinTemplates, "templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		data, err := fs.ReadFile(builtinTemplates, "templates/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		sources[entry.Name()] = string(data)
+	}
+
+	if dir != "" {
+		files, err := filepath.Glob(filepath.Join(dir, "*.html"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			sources[filepath.Base(file)] = string(data)
+		}
+	}
+
+	// 公共模板
+	base := template.New("").Funcs(funcs)
+	for name, src := range sources {
+		if isPageTemplate(name) {
+			continue
+		}
+		if _, err := base.New(name).Parse(src); err != nil {
+			return nil, fmt.Errorf("解析模板 %s 失败: %w", name, err)
+		}
+	}
+
+	// 每个页面模板使用独立的模板集，页面之间可以定义同名区块
+	r := &Renderer{pages: make(map[string]*template.Template, len(pageTemplates))}
+	for _, name := range pageTemplates {
+		t, err := base.Clone()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := t.New(name).Parse(sources[name]); err != nil {
+			return nil, fmt.Errorf("解析模板 %s 失败: %w", name, err)
+		}
+		r.pages[name] = t
+	}
+	return r, nil
+}
+
+// Render 使用页面模板渲染页面
+func (r *Renderer) Render(name string, page *Page) ([]byte, error) {
+	t, ok := r.pages[name]
+	if !ok {
+		return nil, fmt.Errorf("页面模板不存在: %s", name)
+	}
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, name, page); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// isPageTemplate 是否为页面模板
+func isPageTemplate(name string) bool {
+	for _, page := range pageTemplates {
+		if name == page {
+			return true
+		}
+	}
+	return false
+}
+
+// Dir 静态站点输出目录
+type Dir string
+
+// ErrInvalidPath 页面路径不在输出目录内
+var ErrInvalidPath = errors.New("无效的页面路径")
+
+// file 返回站内路径对应的文件路径，以 / 结尾的路径对应目录下的 index.html
+func (d Dir) file(p string) (string, error) {
+	dirIndex := p == "" || strings.HasSuffix(p, "/")
+	rel := strings.TrimPrefix(path.Clean("/"+p), "/")
+	if dirIndex {
+		rel = path.Join(rel, "index.html")
+	}
+	if rel == "" || !fs.ValidPath(rel) {
+		return "", ErrInvalidPath
+	}
+	return filepath.Join(string(d), filepath.FromSlash(rel)), nil
+}
+
+// WriteFile 写入页面，先写临时文件再重命名，避免访问到写了一半的文件
+func (d Dir) WriteFile(p string, data []byte) error {
+	name, err := d.file(p)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// RemoveAll 删除页面目录，目录不存在时忽略
+func (d Dir) RemoveAll(p string) error {
+	name, err := d.file(strings.TrimSuffix(p, "/"))
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(name)
+}
+
+// RemovePagesAfter 删除分页目录 <p>/page/<n>/ 中页码大于 total 的分页，返回删除的数量
+func (d Dir) RemovePagesAfter(p string, total int) (int, error) {
+	name, err := d.file(strings.TrimSuffix(p, "/") + "/page")
+	if err != nil {
+		return 0, err
+	}
+	entries, err := os.ReadDir(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, entry := range entries {
+		if n, err := strconv.Atoi(entry.Name()); err != nil || n <= total {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(name, entry.Name())); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// RemoveExcept 删除目录 p 下名称不在 keep 中的文件和子目录，返回删除的数量
+func (d Dir) RemoveExcept(p string, keep map[string]bool) (int, error) {
+	name, err := d.file(strings.TrimSuffix(p, "/"))
+	if err != nil {
+		return 0, err
+	}
+	entries, err := os.ReadDir(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, entry := range entries {
+		if keep[entry.Name()] {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(name, entry.Name())); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
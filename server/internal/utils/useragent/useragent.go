@@ -0,0 +1,112 @@
+package useragent
+
+import (
+	"regexp"
+	"strings"
+)
+
+// 设备类型
+const (
+	DeviceDesktop = "desktop" // 电脑
+	DeviceMobile  = "mobile"  // 手机
+	DeviceTablet  = "tablet"  // 平板
+	DeviceBot     = "bot"     // 爬虫或脚本
+	DeviceUnknown = "unknown" // 无法识别
+)
+
+// Info 从 User-Agent 中识别的设备信息
+type Info struct {
+	Browser        string `json:"browser"`
+	BrowserVersion string `json:"browser_version"`
+	OS             string `json:"os"`
+	DeviceType     string `json:"device_type"`
+}
+
+// String 返回便于展示的设备描述，如 "Chrome 120 / Windows"
+func (i Info) String() string {
+	browser := i.Browser
+	if browser != "" && i.BrowserVersion != "" {
+		browser += " " + i.BrowserVersion
+	}
+	switch {
+	case browser != "" && i.OS != "":
+		return browser + " / " + i.OS
+	case browser != "":
+		return browser
+	case i.OS != "":
+		return i.OS
+	}
+	return "未知设备"
+}
+
+// rule 匹配规则，按顺序匹配第一个命中的规则
+type rule struct {
+	name string
+	re   *regexp.Regexp // 第一个分组为版本号，没有分组时不识别版本
+}
+
+// browserRules 浏览器规则，基于 Chromium 的浏览器也包含 Chrome 和 Safari 标识，需排在前面
+var browserRules = []rule{
+	{"Edge", regexp.MustCompile(`Edg(?:e|A|iOS)?/(\d+)`)},
+	{"Opera", regexp.MustCompile(`(?:OPR|Opera)/(\d+)`)},
+	{"WeChat", regexp.MustCompile(`MicroMessenger/(\d+)`)},
+	{"QQ Browser", regexp.MustCompile(`QQBrowser/(\d+)`)},
+	{"UC Browser", regexp.MustCompile(`UCBrowser/(\d+)`)},
+	{"Samsung Internet", regexp.MustCompile(`SamsungBrowser/(\d+)`)},
+	{"Firefox", regexp.MustCompile(`(?:Firefox|FxiOS)/(\d+)`)},
+	{"Chrome", regexp.MustCompile(`(?:Chrome|CriOS)/(\d+)`)},
+	{"Safari", regexp.MustCompile(`Version/(\d+)[\d.]* (?:Mobile/\S+ )?Safari/`)},
+	{"Internet Explorer", regexp.MustCompile(`(?:MSIE |Trident/.*rv:)(\d+)`)},
+	{"curl", regexp.MustCompile(`^curl/(\d+)`)},
+}
+
+// osRules 操作系统规则
+var osRules = []rule{
+	{"Windows", regexp.MustCompile(`Windows`)},
+	{"iOS", regexp.MustCompile(`iPhone|iPad|iPod`)},
+	{"macOS", regexp.MustCompile(`Mac OS X|Macintosh`)},
+	{"HarmonyOS", regexp.MustCompile(`HarmonyOS|OpenHarmony`)},
+	{"Android", regexp.MustCompile(`Android`)},
+	{"Chrome OS", regexp.MustCompile(`CrOS`)},
+	{"Linux", regexp.MustCompile(`Linux`)},
+}
+
+var botPattern = regexp.MustCompile(`(?i)bot|crawler|spider|slurp|curl|wget|python-requests|go-http-client|okhttp`)
+
+// Parse 识别 User-Agent 中的浏览器、操作系统和设备类型，无法识别的项为空
+func Parse(ua string) Info {
+	info := Info{DeviceType: DeviceUnknown}
+	ua = strings.TrimSpace(ua)
+	if ua == "" {
+		return info
+	}
+
+	for _, r := range browserRules {
+		if m := r.re.FindStringSubmatch(ua); m != nil {
+			info.Browser = r.name
+			if len(m) > 1 {
+				info.BrowserVersion = m[1]
+			}
+			break
+		}
+	}
+	for _, r := range osRules {
+		if r.re.MatchString(ua) {
+			info.OS = r.name
+			break
+		}
+	}
+
+	switch {
+	case botPattern.MatchString(ua):
+		info.DeviceType = DeviceBot
+	case strings.Contains(ua, "iPad") || strings.Contains(ua, "Tablet") ||
+		(info.OS == "Android" && !strings.Contains(ua, "Mobile")):
+		info.DeviceType = DeviceTablet
+	case strings.Contains(ua, "Mobi") || strings.Contains(ua, "iPhone") || strings.Contains(ua, "iPod"):
+		info.DeviceType = DeviceMobile
+	case info.OS != "":
+		info.DeviceType = DeviceDesktop
+	}
+	return info
+}
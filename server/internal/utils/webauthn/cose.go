@@ -0,0 +1,110 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// COSE 签名算法，按优先顺序列出
+const (
+	AlgES256 int64 = -7   // ECDSA P-256 + SHA-256
+	AlgEdDSA int64 = -8   // Ed25519
+	AlgRS256 int64 = -257 // RSASSA-PKCS1-v1_5 + SHA-256
+)
+
+// SupportedAlgorithms 支持的签名算法，用于注册选项 pubKeyCredParams
+var SupportedAlgorithms = []int64{AlgES256, AlgEdDSA, AlgRS256}
+
+// COSE 密钥参数
+const (
+	coseKeyType  int64 = 1  // 密钥类型
+	coseKeyAlg   int64 = 3  // 算法
+	coseCurve    int64 = -1 // EC2/OKP 曲线
+	coseX        int64 = -2 // EC2/OKP 的x坐标
+	coseY        int64 = -3 // EC2 的y坐标
+	coseRSAMod   int64 = -1 // RSA 模数n
+	coseRSAExp   int64 = -2 // RSA 指数e
+	coseKtyOKP   int64 = 1
+	coseKtyEC2   int64 = 2
+	coseKtyRSA   int64 = 3
+	coseCrvP256  int64 = 1
+	coseCrvEd255 int64 = 6
+)
+
+// ErrUnsupportedKey 不支持的公钥类型或算法
+var ErrUnsupportedKey = errors.New("不支持的公钥类型")
+
+// PublicKey 从 COSE 编码解析出的公钥
+type PublicKey struct {
+	Algorithm int64
+	Key       crypto.PublicKey
+}
+
+// ParsePublicKey 解析 COSE_Key 编码的公钥
+func ParsePublicKey(cose []byte) (*PublicKey, error) {
+	v, n, err := decodeCBOR(cose)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(cose) {
+		return nil, errors.New("公钥数据包含多余内容")
+	}
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, ErrUnsupportedKey
+	}
+
+	kty, _ := m[coseKeyType].(int64)
+	alg, _ := m[coseKeyAlg].(int64)
+	switch {
+	case kty == coseKtyEC2 && alg == AlgES256:
+		crv, _ := m[coseCurve].(int64)
+		x, _ := m[coseX].([]byte)
+		y, _ := m[coseY].([]byte)
+		if crv != coseCrvP256 || len(x) != 32 || len(y) != 32 {
+			return nil, ErrUnsupportedKey
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("无效的椭圆曲线公钥")
+		}
+		return &PublicKey{Algorithm: alg, Key: key}, nil
+	case kty == coseKtyOKP && alg == AlgEdDSA:
+		crv, _ := m[coseCurve].(int64)
+		x, _ := m[coseX].([]byte)
+		if crv != coseCrvEd255 || len(x) != ed25519.PublicKeySize {
+			return nil, ErrUnsupportedKey
+		}
+		return &PublicKey{Algorithm: alg, Key: ed25519.PublicKey(x)}, nil
+	case kty == coseKtyRSA && alg == AlgRS256:
+		n, _ := m[coseRSAMod].([]byte)
+		e, _ := m[coseRSAExp].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, ErrUnsupportedKey
+		}
+		exp := new(big.Int).SetBytes(e)
+		return &PublicKey{Algorithm: alg, Key: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}}, nil
+	}
+	return nil, ErrUnsupportedKey
+}
+
+// Verify 校验签名
+func (k *PublicKey) Verify(data, signature []byte) bool {
+	switch key := k.Key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(data)
+		return ecdsa.VerifyASN1(key, digest[:], signature)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, data, signature)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	}
+	return false
+}
@@ -0,0 +1,394 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// 认证器数据标志位
+const (
+	FlagUserPresent    byte = 0x01 // 用户在场（触摸了认证器）
+	FlagUserVerified   byte = 0x04 // 已验证用户（指纹、面容或PIN）
+	FlagBackupEligible byte = 0x08 // 凭据可同步备份（多设备通行密钥）
+	FlagBackupState    byte = 0x10 // 凭据已备份
+	FlagAttestedData   byte = 0x40 // 包含新凭据数据，仅注册时出现
+	FlagExtensions     byte = 0x80 // 包含扩展数据
+)
+
+// 协议常量
+const (
+	CredentialType = "public-key"
+	ChallengeSize  = 32 // 挑战字节数
+
+	ceremonyCreate = "webauthn.create"
+	ceremonyGet    = "webauthn.get"
+
+	// 认证器数据各部分长度
+	rpIDHashSize  = 32
+	authDataMin   = rpIDHashSize + 1 + 4
+	aaguidSize    = 16
+	credIDLenSize = 2
+)
+
+var (
+	// ErrInvalidResponse 浏览器返回的数据格式错误
+	ErrInvalidResponse = errors.New("无效的通行密钥响应")
+	// ErrChallengeMismatch 挑战不匹配，可能是重放或过期的请求
+	ErrChallengeMismatch = errors.New("通行密钥挑战不匹配")
+	// ErrOriginMismatch 请求来源不是允许的站点
+	ErrOriginMismatch = errors.New("通行密钥请求来源不受信任")
+	// ErrRPIDMismatch 凭据不属于本站点
+	ErrRPIDMismatch = errors.New("通行密钥不属于本站点")
+	// ErrUserNotPresent 用户未确认操作
+	ErrUserNotPresent = errors.New("未在认证器上确认")
+	// ErrUserNotVerified 认证器未验证用户身份
+	ErrUserNotVerified = errors.New("认证器未验证用户身份")
+	// ErrSignatureInvalid 签名校验失败
+	ErrSignatureInvalid = errors.New("通行密钥签名无效")
+	// ErrSignCountInvalid 签名计数器未递增，凭据可能被复制
+	ErrSignCountInvalid = errors.New("通行密钥签名计数异常，凭据可能已被复制")
+)
+
+// URLBytes 以不带填充的base64url编码在JSON中传输的二进制数据，解析时兼容带填充和标准base64编码
+type URLBytes []byte
+
+// MarshalJSON 实现 json.Marshaler 接口
+func (b URLBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口
+func (b *URLBytes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	decoded, err := DecodeURLBytes(s)
+	if err != nil {
+		return err
+	}
+	*b = decoded
+	return nil
+}
+
+// DecodeURLBytes 解码base64url字符串，兼容带填充和标准base64编码
+func DecodeURLBytes(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "=")
+	s = strings.NewReplacer("+", "-", "/", "_").Replace(s)
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+// Config 依赖方（本站）配置
+type Config struct {
+	RPID                    string   // 依赖方ID，通常为站点域名
+	Origins                 []string // 允许发起请求的来源，如 https://blog.example.com
+	RequireUserVerification bool     // 是否要求认证器验证用户身份
+}
+
+// RelyingParty 依赖方信息
+type RelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// UserEntity 注册凭据的用户信息
+type UserEntity struct {
+	ID          URLBytes `json:"id"`
+	Name        string   `json:"name"`
+	DisplayName string   `json:"displayName"`
+}
+
+// CredentialParameter 可接受的凭据类型和算法
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+// CredentialDescriptor 凭据标识
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         URLBytes `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+// AuthenticatorSelection 对认证器的要求
+type AuthenticatorSelection struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// CreationOptions 注册选项，前端传给 navigator.credentials.create 的 publicKey 参数
+type CreationOptions struct {
+	RP                     RelyingParty           `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	Challenge              URLBytes               `json:"challenge"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int                    `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions 登录选项，前端传给 navigator.credentials.get 的 publicKey 参数
+type RequestOptions struct {
+	Challenge        URLBytes               `json:"challenge"`
+	Timeout          int                    `json:"timeout"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// RegistrationResponse 注册时浏览器返回的凭据（PublicKeyCredential.toJSON() 的格式）
+type RegistrationResponse struct {
+	ID       string   `json:"id"`
+	RawID    URLBytes `json:"rawId"`
+	Type     string   `json:"type"`
+	Response struct {
+		ClientDataJSON    URLBytes `json:"clientDataJSON"`
+		AttestationObject URLBytes `json:"attestationObject"`
+		Transports        []string `json:"transports"`
+	} `json:"response"`
+}
+
+// AssertionResponse 登录时浏览器返回的断言
+type AssertionResponse struct {
+	ID       string   `json:"id"`
+	RawID    URLBytes `json:"rawId"`
+	Type     string   `json:"type"`
+	Response struct {
+		ClientDataJSON    URLBytes `json:"clientDataJSON"`
+		AuthenticatorData URLBytes `json:"authenticatorData"`
+		Signature         URLBytes `json:"signature"`
+		UserHandle        URLBytes `json:"userHandle"`
+	} `json:"response"`
+}
+
+// Credential 注册成功的凭据
+type Credential struct {
+	ID             []byte
+	PublicKey      []byte // COSE 编码的公钥
+	SignCount      uint32
+	AAGUID         []byte // 认证器型号标识
+	BackupEligible bool
+	BackupState    bool
+	Transports     []string
+}
+
+// AuthenticatorData 认证器数据
+type AuthenticatorData struct {
+	RPIDHash     []byte
+	Flags        byte
+	SignCount    uint32
+	AAGUID       []byte // 以下字段仅在包含新凭据数据时存在
+	CredentialID []byte
+	PublicKey    []byte
+}
+
+// Has 是否设置了标志位
+func (a *AuthenticatorData) Has(flag byte) bool {
+	return a.Flags&flag != 0
+}
+
+// clientData 浏览器生成的客户端数据
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// NewChallenge 生成随机挑战
+func NewChallenge() ([]byte, error) {
+	b := make([]byte, ChallengeSize)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// UserVerification 返回选项中的 userVerification 取值
+func (cfg Config) UserVerification() string {
+	if cfg.RequireUserVerification {
+		return "required"
+	}
+	return "preferred"
+}
+
+// VerifyRegistration 校验注册响应，返回新凭据。
+// 注册选项要求 attestation 为 none，不校验认证器证明，只信任凭据本身的公钥
+func (cfg Config) VerifyRegistration(challenge []byte, resp *RegistrationResponse) (*Credential, error) {
+	if resp == nil || resp.Type != CredentialType {
+		return nil, ErrInvalidResponse
+	}
+	if err := cfg.verifyClientData(resp.Response.ClientDataJSON, ceremonyCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	obj, n, err := decodeCBOR(resp.Response.AttestationObject)
+	if err != nil || n != len(resp.Response.AttestationObject) {
+		return nil, ErrInvalidResponse
+	}
+	attestation, ok := obj.(map[interface{}]interface{})
+	if !ok {
+		return nil, ErrInvalidResponse
+	}
+	rawAuthData, ok := attestation["authData"].([]byte)
+	if !ok {
+		return nil, ErrInvalidResponse
+	}
+
+	authData, err := ParseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.verifyAuthenticatorData(authData); err != nil {
+		return nil, err
+	}
+	if !authData.Has(FlagAttestedData) {
+		return nil, ErrInvalidResponse
+	}
+	if len(resp.RawID) > 0 && !bytes.Equal(resp.RawID, authData.CredentialID) {
+		return nil, ErrInvalidResponse
+	}
+	if _, err := ParsePublicKey(authData.PublicKey); err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:             authData.CredentialID,
+		PublicKey:      authData.PublicKey,
+		SignCount:      authData.SignCount,
+		AAGUID:         authData.AAGUID,
+		BackupEligible: authData.Has(FlagBackupEligible),
+		BackupState:    authData.Has(FlagBackupState),
+		Transports:     resp.Response.Transports,
+	}, nil
+}
+
+// VerifyAssertion 使用已保存的公钥和签名计数校验登录断言，返回认证器数据，调用方需保存新的签名计数
+func (cfg Config) VerifyAssertion(challenge []byte, resp *AssertionResponse, publicKey []byte, signCount uint32) (*AuthenticatorData, error) {
+	if resp == nil || resp.Type != CredentialType {
+		return nil, ErrInvalidResponse
+	}
+	if err := cfg.verifyClientData(resp.Response.ClientDataJSON, ceremonyGet, challenge); err != nil {
+		return nil, err
+	}
+
+	authData, err := ParseAuthenticatorData(resp.Response.AuthenticatorData)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.verifyAuthenticatorData(authData); err != nil {
+		return nil, err
+	}
+
+	key, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	clientDataHash := sha256.Sum256(resp.Response.ClientDataJSON)
+	signed := make([]byte, 0, len(resp.Response.AuthenticatorData)+len(clientDataHash))
+	signed = append(signed, resp.Response.AuthenticatorData...)
+	signed = append(signed, clientDataHash[:]...)
+	if !key.Verify(signed, resp.Response.Signature) {
+		return nil, ErrSignatureInvalid
+	}
+
+	// 不支持计数器的认证器（如同步的通行密钥）始终返回0
+	if (authData.SignCount != 0 || signCount != 0) && authData.SignCount <= signCount {
+		return nil, ErrSignCountInvalid
+	}
+	return authData, nil
+}
+
+// ParseAuthenticatorData 解析认证器数据
+func ParseAuthenticatorData(data []byte) (*AuthenticatorData, error) {
+	if len(data) < authDataMin {
+		return nil, ErrInvalidResponse
+	}
+	a := &AuthenticatorData{
+		RPIDHash:  data[:rpIDHashSize],
+		Flags:     data[rpIDHashSize],
+		SignCount: binary.BigEndian.Uint32(data[rpIDHashSize+1 : authDataMin]),
+	}
+	rest := data[authDataMin:]
+
+	if a.Has(FlagAttestedData) {
+		if len(rest) < aaguidSize+credIDLenSize {
+			return nil, ErrInvalidResponse
+		}
+		a.AAGUID = rest[:aaguidSize]
+		idLen := int(binary.BigEndian.Uint16(rest[aaguidSize : aaguidSize+credIDLenSize]))
+		rest = rest[aaguidSize+credIDLenSize:]
+		if idLen == 0 || len(rest) < idLen {
+			return nil, ErrInvalidResponse
+		}
+		a.CredentialID = rest[:idLen]
+		rest = rest[idLen:]
+
+		_, n, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, ErrInvalidResponse
+		}
+		a.PublicKey = rest[:n]
+		rest = rest[n:]
+	}
+	if a.Has(FlagExtensions) {
+		_, n, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, ErrInvalidResponse
+		}
+		rest = rest[n:]
+	}
+	if len(rest) != 0 {
+		return nil, ErrInvalidResponse
+	}
+	return a, nil
+}
+
+// verifyClientData 校验客户端数据的类型、挑战和来源
+func (cfg Config) verifyClientData(raw []byte, ceremony string, challenge []byte) error {
+	var data clientData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return ErrInvalidResponse
+	}
+	if data.Type != ceremony {
+		return ErrInvalidResponse
+	}
+	got, err := DecodeURLBytes(data.Challenge)
+	if err != nil || len(challenge) == 0 || subtle.ConstantTimeCompare(got, challenge) != 1 {
+		return ErrChallengeMismatch
+	}
+	if data.CrossOrigin {
+		return ErrOriginMismatch
+	}
+	for _, origin := range cfg.Origins {
+		if data.Origin == origin {
+			return nil
+		}
+	}
+	return ErrOriginMismatch
+}
+
+// verifyAuthenticatorData 校验依赖方ID和用户在场、用户验证标志
+func (cfg Config) verifyAuthenticatorData(a *AuthenticatorData) error {
+	rpIDHash := sha256.Sum256([]byte(cfg.RPID))
+	if subtle.ConstantTimeCompare(a.RPIDHash, rpIDHash[:]) != 1 {
+		return ErrRPIDMismatch
+	}
+	if !a.Has(FlagUserPresent) {
+		return ErrUserNotPresent
+	}
+	if cfg.RequireUserVerification && !a.Has(FlagUserVerified) {
+		return ErrUserNotVerified
+	}
+	return nil
+}
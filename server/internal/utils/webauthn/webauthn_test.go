@@ -0,0 +1,417 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+)
+
+const (
+	testRPID   = "blog.example.com"
+	testOrigin = "https://blog.example.com"
+)
+
+var testConfig = Config{RPID: testRPID, Origins: []string{testOrigin}}
+
+// softAuthenticator 软件实现的认证器，按 WebAuthn 规范生成注册和登录数据
+type softAuthenticator struct {
+	alg          int64
+	signer       crypto.Signer
+	credentialID []byte
+	signCount    uint32
+	flags        byte
+	rpID         string
+	origin       string
+}
+
+func newSoftAuthenticator(t *testing.T, alg int64) *softAuthenticator {
+	t.Helper()
+	var signer crypto.Signer
+	var err error
+	switch alg {
+	case AlgES256:
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		t.Fatalf("不支持的算法 %d", alg)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		t.Fatal(err)
+	}
+	return &softAuthenticator{
+		alg:          alg,
+		signer:       signer,
+		credentialID: id,
+		flags:        FlagUserPresent | FlagUserVerified,
+		rpID:         testRPID,
+		origin:       testOrigin,
+	}
+}
+
+// coseKey 公钥的 COSE_Key 编码
+func (a *softAuthenticator) coseKey() []byte {
+	switch key := a.signer.Public().(type) {
+	case *ecdsa.PublicKey:
+		x := make([]byte, 32)
+		y := make([]byte, 32)
+		key.X.FillBytes(x)
+		key.Y.FillBytes(y)
+		return encodeCBOR(map[int64]interface{}{
+			coseKeyType: coseKtyEC2, coseKeyAlg: AlgES256, coseCurve: coseCrvP256, coseX: x, coseY: y,
+		})
+	case ed25519.PublicKey:
+		return encodeCBOR(map[int64]interface{}{
+			coseKeyType: coseKtyOKP, coseKeyAlg: AlgEdDSA, coseCurve: coseCrvEd255, coseX: []byte(key),
+		})
+	}
+	return nil
+}
+
+// authData 生成认证器数据，attested 为 true 时包含新凭据
+func (a *softAuthenticator) authData(attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.rpID))
+	flags := a.flags
+	if attested {
+		flags |= FlagAttestedData
+	}
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	if attested {
+		data = append(data, make([]byte, aaguidSize)...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, a.coseKey()...)
+	}
+	return data
+}
+
+func (a *softAuthenticator) clientData(ceremony string, challenge []byte) []byte {
+	data, _ := json.Marshal(clientData{
+		Type:      ceremony,
+		Challenge: base64.RawURLEncoding.EncodeToString(challenge),
+		Origin:    a.origin,
+	})
+	return data
+}
+
+// register 模拟 navigator.credentials.create，attestation 为 none
+func (a *softAuthenticator) register(challenge []byte) *RegistrationResponse {
+	resp := &RegistrationResponse{ID: base64.RawURLEncoding.EncodeToString(a.credentialID), RawID: a.credentialID, Type: CredentialType}
+	resp.Response.ClientDataJSON = a.clientData(ceremonyCreate, challenge)
+	resp.Response.AttestationObject = encodeCBOR(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": a.authData(true),
+	})
+	return resp
+}
+
+// assert 模拟 navigator.credentials.get，每次签名计数加一
+func (a *softAuthenticator) assert(t *testing.T, challenge []byte) *AssertionResponse {
+	t.Helper()
+	a.signCount++
+	resp := &AssertionResponse{ID: base64.RawURLEncoding.EncodeToString(a.credentialID), RawID: a.credentialID, Type: CredentialType}
+	resp.Response.ClientDataJSON = a.clientData(ceremonyGet, challenge)
+	resp.Response.AuthenticatorData = a.authData(false)
+
+	clientDataHash := sha256.Sum256(resp.Response.ClientDataJSON)
+	signed := append(append([]byte{}, resp.Response.AuthenticatorData...), clientDataHash[:]...)
+	var sig []byte
+	var err error
+	if a.alg == AlgES256 {
+		digest := sha256.Sum256(signed)
+		sig, err = a.signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	} else {
+		sig, err = a.signer.Sign(rand.Reader, signed, crypto.Hash(0))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Response.Signature = sig
+	return resp
+}
+
+// encodeCBOR 测试用的最小CBOR编码，只支持认证器数据中用到的类型，映射按键的编码排序
+func encodeCBOR(v interface{}) []byte {
+	var buf bytes.Buffer
+	writeCBOR(&buf, v)
+	return buf.Bytes()
+}
+
+func writeCBOR(buf *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case int64:
+		if v >= 0 {
+			writeCBORHead(buf, cborUint, uint64(v))
+		} else {
+			writeCBORHead(buf, cborNegInt, uint64(-1-v))
+		}
+	case []byte:
+		writeCBORHead(buf, cborBytes, uint64(len(v)))
+		buf.Write(v)
+	case string:
+		writeCBORHead(buf, cborText, uint64(len(v)))
+		buf.WriteString(v)
+	case map[int64]interface{}:
+		entries := make(map[string][]byte, len(v))
+		for k, val := range v {
+			entries[string(encodeCBOR(k))] = encodeCBOR(val)
+		}
+		writeCBORMap(buf, entries)
+	case map[string]interface{}:
+		entries := make(map[string][]byte, len(v))
+		for k, val := range v {
+			entries[string(encodeCBOR(k))] = encodeCBOR(val)
+		}
+		writeCBORMap(buf, entries)
+	default:
+		panic(fmt.Sprintf("不支持的CBOR类型 %T", v))
+	}
+}
+
+func writeCBORMap(buf *bytes.Buffer, entries map[string][]byte) {
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	writeCBORHead(buf, cborMap, uint64(len(keys)))
+	for _, k := range keys {
+		buf.WriteString(k)
+		buf.Write(entries[k])
+	}
+}
+
+func writeCBORHead(buf *bytes.Buffer, major byte, n uint64) {
+	switch {
+	case n < 24:
+		buf.WriteByte(major<<5 | byte(n))
+	case n <= 0xff:
+		buf.WriteByte(major<<5 | 24)
+		buf.WriteByte(byte(n))
+	case n <= 0xffff:
+		buf.WriteByte(major<<5 | 25)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		buf.WriteByte(major<<5 | 26)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+}
+
+func newTestChallenge(t *testing.T) []byte {
+	t.Helper()
+	challenge, err := NewChallenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return challenge
+}
+
+// registerCredential 注册软件认证器并返回保存的凭据
+func registerCredential(t *testing.T, auth *softAuthenticator) *Credential {
+	t.Helper()
+	challenge := newTestChallenge(t)
+	credential, err := testConfig.VerifyRegistration(challenge, auth.register(challenge))
+	if err != nil {
+		t.Fatalf("注册失败: %v", err)
+	}
+	return credential
+}
+
+func TestRegistrationAndAssertion(t *testing.T) {
+	for _, alg := range []int64{AlgES256, AlgEdDSA} {
+		t.Run(fmt.Sprint(alg), func(t *testing.T) {
+			auth := newSoftAuthenticator(t, alg)
+			credential := registerCredential(t, auth)
+			if !bytes.Equal(credential.ID, auth.credentialID) {
+				t.Fatalf("凭据ID不一致")
+			}
+			key, err := ParsePublicKey(credential.PublicKey)
+			if err != nil || key.Algorithm != alg {
+				t.Fatalf("解析公钥失败: %v", err)
+			}
+
+			signCount := credential.SignCount
+			for i := 0; i < 3; i++ {
+				challenge := newTestChallenge(t)
+				authData, err := testConfig.VerifyAssertion(challenge, auth.assert(t, challenge), credential.PublicKey, signCount)
+				if err != nil {
+					t.Fatalf("第%d次登录失败: %v", i+1, err)
+				}
+				if authData.SignCount != signCount+1 || !authData.Has(FlagUserVerified) {
+					t.Fatalf("认证器数据错误: %+v", authData)
+				}
+				signCount = authData.SignCount
+			}
+		})
+	}
+}
+
+func TestVerifyRegistrationRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    Config
+		modify func(auth *softAuthenticator)
+		want   error
+	}{
+		{"来源不匹配", testConfig, func(a *softAuthenticator) { a.origin = "https://evil.example.com" }, ErrOriginMismatch},
+		{"依赖方ID不匹配", testConfig, func(a *softAuthenticator) { a.rpID = "evil.example.com" }, ErrRPIDMismatch},
+		{"用户不在场", testConfig, func(a *softAuthenticator) { a.flags = 0 }, ErrUserNotPresent},
+		{"要求验证用户", Config{RPID: testRPID, Origins: []string{testOrigin}, RequireUserVerification: true},
+			func(a *softAuthenticator) { a.flags = FlagUserPresent }, ErrUserNotVerified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := newSoftAuthenticator(t, AlgES256)
+			tt.modify(auth)
+			challenge := newTestChallenge(t)
+			if _, err := tt.cfg.VerifyRegistration(challenge, auth.register(challenge)); !errors.Is(err, tt.want) {
+				t.Fatalf("期望 %v，实际 %v", tt.want, err)
+			}
+		})
+	}
+
+	t.Run("挑战不匹配", func(t *testing.T) {
+		auth := newSoftAuthenticator(t, AlgES256)
+		resp := auth.register(newTestChallenge(t))
+		if _, err := testConfig.VerifyRegistration(newTestChallenge(t), resp); !errors.Is(err, ErrChallengeMismatch) {
+			t.Fatalf("期望 %v，实际 %v", ErrChallengeMismatch, err)
+		}
+	})
+}
+
+func TestVerifyAssertionRejects(t *testing.T) {
+	t.Run("挑战不匹配", func(t *testing.T) {
+		auth := newSoftAuthenticator(t, AlgES256)
+		credential := registerCredential(t, auth)
+		resp := auth.assert(t, newTestChallenge(t))
+		if _, err := testConfig.VerifyAssertion(newTestChallenge(t), resp, credential.PublicKey, 0); !errors.Is(err, ErrChallengeMismatch) {
+			t.Fatalf("期望 %v，实际 %v", ErrChallengeMismatch, err)
+		}
+	})
+
+	t.Run("登录类型错误", func(t *testing.T) {
+		auth := newSoftAuthenticator(t, AlgES256)
+		credential := registerCredential(t, auth)
+		challenge := newTestChallenge(t)
+		resp := auth.assert(t, challenge)
+		resp.Response.ClientDataJSON = auth.clientData(ceremonyCreate, challenge)
+		if _, err := testConfig.VerifyAssertion(challenge, resp, credential.PublicKey, 0); !errors.Is(err, ErrInvalidResponse) {
+			t.Fatalf("期望 %v，实际 %v", ErrInvalidResponse, err)
+		}
+	})
+
+	t.Run("签名被篡改", func(t *testing.T) {
+		for _, alg := range []int64{AlgES256, AlgEdDSA} {
+			auth := newSoftAuthenticator(t, alg)
+			credential := registerCredential(t, auth)
+			challenge := newTestChallenge(t)
+			resp := auth.assert(t, challenge)
+			resp.Response.AuthenticatorData[len(resp.Response.AuthenticatorData)-1] ^= 0xff
+			if _, err := testConfig.VerifyAssertion(challenge, resp, credential.PublicKey, 0); !errors.Is(err, ErrSignatureInvalid) {
+				t.Fatalf("算法%d: 期望 %v，实际 %v", alg, ErrSignatureInvalid, err)
+			}
+		}
+	})
+
+	t.Run("其他凭据的公钥", func(t *testing.T) {
+		auth := newSoftAuthenticator(t, AlgES256)
+		registerCredential(t, auth)
+		other := registerCredential(t, newSoftAuthenticator(t, AlgES256))
+		challenge := newTestChallenge(t)
+		if _, err := testConfig.VerifyAssertion(challenge, auth.assert(t, challenge), other.PublicKey, 0); !errors.Is(err, ErrSignatureInvalid) {
+			t.Fatalf("期望 %v，实际 %v", ErrSignatureInvalid, err)
+		}
+	})
+
+	t.Run("来源不匹配", func(t *testing.T) {
+		auth := newSoftAuthenticator(t, AlgES256)
+		credential := registerCredential(t, auth)
+		auth.origin = "https://blog.example.com.evil.net"
+		challenge := newTestChallenge(t)
+		if _, err := testConfig.VerifyAssertion(challenge, auth.assert(t, challenge), credential.PublicKey, 0); !errors.Is(err, ErrOriginMismatch) {
+			t.Fatalf("期望 %v，实际 %v", ErrOriginMismatch, err)
+		}
+	})
+
+	t.Run("依赖方ID不匹配", func(t *testing.T) {
+		auth := newSoftAuthenticator(t, AlgES256)
+		credential := registerCredential(t, auth)
+		auth.rpID = "example.com"
+		challenge := newTestChallenge(t)
+		if _, err := testConfig.VerifyAssertion(challenge, auth.assert(t, challenge), credential.PublicKey, 0); !errors.Is(err, ErrRPIDMismatch) {
+			t.Fatalf("期望 %v，实际 %v", ErrRPIDMismatch, err)
+		}
+	})
+}
+
+func TestVerifyAssertionSignCount(t *testing.T) {
+	auth := newSoftAuthenticator(t, AlgEdDSA)
+	credential := registerCredential(t, auth)
+
+	// 计数器回退或未递增，说明凭据可能被复制
+	for _, stored := range []uint32{10, 11} {
+		challenge := newTestChallenge(t)
+		auth.signCount = 9
+		if _, err := testConfig.VerifyAssertion(challenge, auth.assert(t, challenge), credential.PublicKey, stored); !errors.Is(err, ErrSignCountInvalid) {
+			t.Fatalf("保存的计数为%d: 期望 %v，实际 %v", stored, ErrSignCountInvalid, err)
+		}
+	}
+
+	// 不支持计数器的认证器始终返回0
+	challenge := newTestChallenge(t)
+	auth.signCount = ^uint32(0) // 加一后回绕为0
+	if _, err := testConfig.VerifyAssertion(challenge, auth.assert(t, challenge), credential.PublicKey, 0); err != nil {
+		t.Fatalf("计数器始终为0时应允许登录: %v", err)
+	}
+}
+
+func TestParseAuthenticatorDataRejectsTrailingBytes(t *testing.T) {
+	auth := newSoftAuthenticator(t, AlgES256)
+	data := append(auth.authData(false), 0x00)
+	if _, err := ParseAuthenticatorData(data); !errors.Is(err, ErrInvalidResponse) {
+		t.Fatalf("期望 %v，实际 %v", ErrInvalidResponse, err)
+	}
+	if _, err := ParseAuthenticatorData(data[:authDataMin-1]); !errors.Is(err, ErrInvalidResponse) {
+		t.Fatalf("期望 %v，实际 %v", ErrInvalidResponse, err)
+	}
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	v, n, err := decodeCBOR(encodeCBOR(map[int64]interface{}{1: int64(-7), 2: []byte{1, 2}, -1: "x"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := v.(map[interface{}]interface{})
+	if n == 0 || m[int64(1)] != int64(-7) || !bytes.Equal(m[int64(2)].([]byte), []byte{1, 2}) || m[int64(-1)] != "x" {
+		t.Fatalf("解析结果错误: %#v", v)
+	}
+
+	if _, _, err := decodeCBOR([]byte{0x42, 0x01}); !errors.Is(err, errCBORTruncated) {
+		t.Fatalf("期望 %v，实际 %v", errCBORTruncated, err)
+	}
+
+	// 超过最大嵌套层数
+	nested := bytes.Repeat([]byte{0x81}, cborMaxDepth+2)
+	nested = append(nested, 0x00)
+	if _, _, err := decodeCBOR(nested); err == nil {
+		t.Fatal("嵌套层数过多时应返回错误")
+	}
+}
@@ -0,0 +1,84 @@
+package wxr
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// 以区块元素开始的段落不需要再包裹 <p>
+	blockStart = regexp.MustCompile(`(?i)^<(?:!--|/?(?:table|thead|tfoot|caption|col|colgroup|tbody|tr|td|th|div|dl|dd|dt|ul|ol|li|pre|form|map|area|blockquote|address|math|style|script|p|h[1-6]|hr|fieldset|legend|section|article|aside|hgroup|header|footer|nav|figure|figcaption|details|menu|summary|iframe|video|audio|object|embed)\b)`)
+	preBlock   = regexp.MustCompile(`(?is)<pre\b.*?</pre>`)
+	paragraphs = regexp.MustCompile(`\n[ \t]*\n\s*`)
+
+	// [caption id="" align="" width="" caption=""]<a><img></a> 说明文字[/caption]
+	captionShortcode = regexp.MustCompile(`(?is)\[caption([^\]]*)\](.*?)\[/caption\]`)
+	captionImage     = regexp.MustCompile(`(?is)^\s*((?:<a\b[^>]*>\s*)?<img\b[^>]*>(?:\s*</a>)?)(.*)$`)
+	captionAttr      = regexp.MustCompile(`(?i)\bcaption\s*=\s*"([^"]*)"`)
+)
+
+// AutoParagraph 将经典编辑器正文中的空行转换为段落、单个换行转换为 <br />，
+// 与 WordPress 显示文章时的 wpautop 主要规则一致
+//
+// 区块编辑器（Gutenberg）的正文已包含段落标签，原样返回。
+func AutoParagraph(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	if strings.TrimSpace(s) == "" {
+		return ""
+	}
+	if strings.Contains(s, "<!-- wp:") {
+		return s
+	}
+
+	// <pre> 中的换行保持原样
+	var pres []string
+	s = preBlock.ReplaceAllStringFunc(s, func(pre string) string {
+		pres = append(pres, pre)
+		return "<!-- wxr-pre-" + strconv.Itoa(len(pres)-1) + " -->"
+	})
+
+	var b strings.Builder
+	for _, chunk := range paragraphs.Split(strings.TrimSpace(s), -1) {
+		chunk = strings.TrimSpace(chunk)
+		if chunk == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		if blockStart.MatchString(chunk) {
+			b.WriteString(chunk)
+			continue
+		}
+		b.WriteString("<p>" + strings.ReplaceAll(chunk, "\n", "<br />\n") + "</p>")
+	}
+
+	out := b.String()
+	for i, pre := range pres {
+		out = strings.Replace(out, "<!-- wxr-pre-"+strconv.Itoa(i)+" -->", pre, 1)
+	}
+	return out
+}
+
+// ConvertCaptions 将图片说明短代码 [caption] 转换为 <figure>
+func ConvertCaptions(s string) string {
+	return captionShortcode.ReplaceAllStringFunc(s, func(shortcode string) string {
+		m := captionShortcode.FindStringSubmatch(shortcode)
+		parts := captionImage.FindStringSubmatch(m[2])
+		if parts == nil {
+			return m[2]
+		}
+		text := strings.TrimSpace(parts[2])
+		if text == "" {
+			if attr := captionAttr.FindStringSubmatch(m[1]); attr != nil {
+				text = attr[1]
+			}
+		}
+		out := "<figure>" + strings.TrimSpace(parts[1])
+		if text != "" {
+			out += "<figcaption>" + text + "</figcaption>"
+		}
+		return out + "</figure>"
+	})
+}
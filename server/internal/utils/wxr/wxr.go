@@ -0,0 +1,307 @@
+package wxr
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrInvalid 不是有效的 WordPress 导出文件
+var ErrInvalid = errors.New("不是有效的WordPress导出文件")
+
+// wpDateLayout WordPress 导出文件中的日期格式
+const wpDateLayout = "2006-01-02 15:04:05"
+
+// Export WordPress 导出文件（WXR）
+type Export struct {
+	Title       string     // 站点标题
+	BaseSiteURL string     // 站点地址，附件地址以此开头
+	BaseBlogURL string     // 博客地址
+	Authors     []Author   // 作者
+	Categories  []Category // 分类，上级分类在 Parent 中以别名引用
+	Tags        []Tag      // 标签
+	Items       []Item     // 文章、页面、附件等
+}
+
+// Author 作者
+type Author struct {
+	ID          int    `xml:"author_id"`
+	Login       string `xml:"author_login"`
+	Email       string `xml:"author_email"`
+	DisplayName string `xml:"author_display_name"`
+	FirstName   string `xml:"author_first_name"`
+	LastName    string `xml:"author_last_name"`
+}
+
+// Category 分类
+type Category struct {
+	TermID      int    `xml:"term_id"`
+	Slug        string `xml:"category_nicename"`
+	Parent      string `xml:"category_parent"` // 上级分类别名，顶级分类为空
+	Name        string `xml:"cat_name"`
+	Description string `xml:"category_description"`
+}
+
+// Tag 标签
+type Tag struct {
+	TermID      int    `xml:"term_id"`
+	Slug        string `xml:"tag_slug"`
+	Name        string `xml:"tag_name"`
+	Description string `xml:"tag_description"`
+}
+
+// Term 文章关联的分类或标签
+type Term struct {
+	Domain string `xml:"domain,attr"`   // category 或 post_tag
+	Slug   string `xml:"nicename,attr"` // 别名
+	Name   string `xml:",chardata"`
+}
+
+// Item 文章、页面或附件
+type Item struct {
+	PostID        int
+	PostType      string // post、page、attachment 等
+	Status        string // publish、draft、pending、private、future、trash、inherit
+	Title         string
+	Link          string
+	Creator       string // 作者登录名
+	Content       string
+	Excerpt       string
+	PostName      string // 别名
+	Date          time.Time
+	CommentStatus string // open 或 closed
+	PostParent    int
+	IsSticky      bool
+	AttachmentURL string
+	Terms         []Term
+	Meta          map[string]string
+	Comments      []Comment
+}
+
+// Comment 评论
+type Comment struct {
+	ID          int
+	Author      string
+	AuthorEmail string
+	AuthorURL   string
+	AuthorIP    string
+	Agent       string // 标准导出不包含，部分插件会导出 comment_agent
+	Date        time.Time
+	Content     string
+	Approved    string // 1、0、spam、trash
+	Type        string // 空或 comment、pingback、trackback
+	Parent      int    // 上级评论ID，顶级评论为0
+	UserID      int    // 登录用户的作者ID，游客为0
+}
+
+// rawItem 导出文件中的 item 元素
+type rawItem struct {
+	Title         string       `xml:"title"`
+	Link          string       `xml:"link"`
+	Creator       string       `xml:"creator"`
+	Encoded       []rawEncoded `xml:"encoded"`
+	PostID        int          `xml:"post_id"`
+	PostDate      string       `xml:"post_date"`
+	PostDateGMT   string       `xml:"post_date_gmt"`
+	CommentStatus string       `xml:"comment_status"`
+	PostName      string       `xml:"post_name"`
+	Status        string       `xml:"status"`
+	PostParent    int          `xml:"post_parent"`
+	PostType      string       `xml:"post_type"`
+	IsSticky      int          `xml:"is_sticky"`
+	AttachmentURL string       `xml:"attachment_url"`
+	Categories    []Term       `xml:"category"`
+	Meta          []rawMeta    `xml:"postmeta"`
+	Comments      []rawComment `xml:"comment"`
+}
+
+// rawEncoded content:encoded 和 excerpt:encoded，以命名空间区分
+type rawEncoded struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// rawMeta 自定义字段
+type rawMeta struct {
+	Key   string `xml:"meta_key"`
+	Value string `xml:"meta_value"`
+}
+
+// rawComment 导出文件中的评论
+type rawComment struct {
+	ID          int    `xml:"comment_id"`
+	Author      string `xml:"comment_author"`
+	AuthorEmail string `xml:"comment_author_email"`
+	AuthorURL   string `xml:"comment_author_url"`
+	AuthorIP    string `xml:"comment_author_IP"`
+	Agent       string `xml:"comment_agent"`
+	Date        string `xml:"comment_date"`
+	DateGMT     string `xml:"comment_date_gmt"`
+	Content     string `xml:"comment_content"`
+	Approved    string `xml:"comment_approved"`
+	Type        string `xml:"comment_type"`
+	Parent      int    `xml:"comment_parent"`
+	UserID      int    `xml:"comment_user_id"`
+}
+
+// Parse 解析 WordPress 导出文件，没有时区的日期按 loc 解析
+func Parse(r io.Reader, loc *time.Location) (*Export, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	d := xml.NewDecoder(r)
+	// 导出文件中偶尔出现HTML实体
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+
+	export := &Export{}
+	foundChannel := false
+	var stack []string
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if len(stack) == 0 || stack[len(stack)-1] != "channel" {
+				if t.Name.Local == "channel" {
+					foundChannel = true
+				}
+				stack = append(stack, t.Name.Local)
+				continue
+			}
+			if err := export.decodeChannelElement(d, t, loc); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+			}
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	if !foundChannel {
+		return nil, ErrInvalid
+	}
+	return export, nil
+}
+
+// decodeChannelElement 解析 channel 的直接子元素
+func (e *Export) decodeChannelElement(d *xml.Decoder, start xml.StartElement, loc *time.Location) error {
+	// channel 下的 category 有 RSS 和 wp 两种，只处理 wp 命名空间的分类
+	wp := start.Name.Space != ""
+	switch {
+	case start.Name.Local == "title" && !wp:
+		return d.DecodeElement(&e.Title, &start)
+	case start.Name.Local == "base_site_url":
+		return d.DecodeElement(&e.BaseSiteURL, &start)
+	case start.Name.Local == "base_blog_url":
+		return d.DecodeElement(&e.BaseBlogURL, &start)
+	case start.Name.Local == "author" && wp:
+		var author Author
+		if err := d.DecodeElement(&author, &start); err != nil {
+			return err
+		}
+		e.Authors = append(e.Authors, author)
+		return nil
+	case start.Name.Local == "category" && wp:
+		var category Category
+		if err := d.DecodeElement(&category, &start); err != nil {
+			return err
+		}
+		category.Name = unescape(category.Name)
+		e.Categories = append(e.Categories, category)
+		return nil
+	case start.Name.Local == "tag" && wp:
+		var tag Tag
+		if err := d.DecodeElement(&tag, &start); err != nil {
+			return err
+		}
+		tag.Name = unescape(tag.Name)
+		e.Tags = append(e.Tags, tag)
+		return nil
+	case start.Name.Local == "item":
+		var raw rawItem
+		if err := d.DecodeElement(&raw, &start); err != nil {
+			return err
+		}
+		e.Items = append(e.Items, raw.item(loc))
+		return nil
+	}
+	return d.Skip()
+}
+
+// item 转换为 Item
+func (raw *rawItem) item(loc *time.Location) Item {
+	item := Item{
+		PostID:        raw.PostID,
+		PostType:      strings.TrimSpace(raw.PostType),
+		Status:        strings.TrimSpace(raw.Status),
+		Title:         strings.TrimSpace(raw.Title),
+		Link:          strings.TrimSpace(raw.Link),
+		Creator:       strings.TrimSpace(raw.Creator),
+		PostName:      strings.TrimSpace(raw.PostName),
+		Date:          parseDate(raw.PostDateGMT, raw.PostDate, loc),
+		CommentStatus: strings.TrimSpace(raw.CommentStatus),
+		PostParent:    raw.PostParent,
+		IsSticky:      raw.IsSticky == 1,
+		AttachmentURL: strings.TrimSpace(raw.AttachmentURL),
+		Terms:         raw.Categories,
+		Meta:          make(map[string]string, len(raw.Meta)),
+	}
+	for _, encoded := range raw.Encoded {
+		// 摘要的命名空间为 http://wordpress.org/export/1.x/excerpt/
+		if strings.Contains(encoded.XMLName.Space, "excerpt") {
+			item.Excerpt = encoded.Value
+		} else {
+			item.Content = encoded.Value
+		}
+	}
+	for _, meta := range raw.Meta {
+		item.Meta[meta.Key] = meta.Value
+	}
+	for i := range item.Terms {
+		item.Terms[i].Name = unescape(item.Terms[i].Name)
+	}
+	for _, c := range raw.Comments {
+		item.Comments = append(item.Comments, Comment{
+			ID:          c.ID,
+			Author:      strings.TrimSpace(c.Author),
+			AuthorEmail: strings.TrimSpace(c.AuthorEmail),
+			AuthorURL:   strings.TrimSpace(c.AuthorURL),
+			AuthorIP:    strings.TrimSpace(c.AuthorIP),
+			Agent:       strings.TrimSpace(c.Agent),
+			Date:        parseDate(c.DateGMT, c.Date, loc),
+			Content:     c.Content,
+			Approved:    strings.TrimSpace(c.Approved),
+			Type:        strings.TrimSpace(c.Type),
+			Parent:      c.Parent,
+			UserID:      c.UserID,
+		})
+	}
+	return item
+}
+
+// unescape 分类和标签名称在导出时经过HTML转义
+func unescape(s string) string {
+	return strings.TrimSpace(html.UnescapeString(s))
+}
+
+// parseDate 优先使用UTC日期，草稿的UTC日期为 0000-00-00 00:00:00 时使用本地日期
+func parseDate(gmt, local string, loc *time.Location) time.Time {
+	if t, err := time.Parse(wpDateLayout, strings.TrimSpace(gmt)); err == nil && t.Year() > 1 {
+		return t
+	}
+	if t, err := time.ParseInLocation(wpDateLayout, strings.TrimSpace(local), loc); err == nil && t.Year() > 1 {
+		return t
+	}
+	return time.Time{}
+}
@@ -0,0 +1,228 @@
+package xmlrpc
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateTimeLayouts dateTime.iso8601 可能出现的格式，客户端实现不统一
+var dateTimeLayouts = []string{
+	"20060102T15:04:05",
+	"20060102T15:04:05Z07:00",
+	"20060102T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05Z07:00",
+	"20060102T150405",
+	"20060102T150405Z07:00",
+}
+
+// Decode 解析方法调用，返回方法名和参数
+//
+// 参数类型对应关系：string→string，int/i4/i8→int，boolean→bool，double→float64，
+// dateTime.iso8601→time.Time，base64→[]byte，struct→map[string]interface{}，
+// array→[]interface{}，nil→nil。
+func Decode(r io.Reader) (string, []interface{}, error) {
+	d := xml.NewDecoder(r)
+	var method string
+	params := make([]interface{}, 0)
+
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "methodName":
+			if method, err = readText(d); err != nil {
+				return "", nil, err
+			}
+			method = strings.TrimSpace(method)
+		case "value":
+			v, err := decodeValue(d)
+			if err != nil {
+				return "", nil, err
+			}
+			params = append(params, v)
+		}
+	}
+
+	if method == "" {
+		return "", nil, errors.New("xmlrpc: missing methodName")
+	}
+	return method, params, nil
+}
+
+// decodeValue 解析 <value> 的内容，调用时 <value> 开始标签已读取
+func decodeValue(d *xml.Decoder) (interface{}, error) {
+	var text strings.Builder
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			// 未指定类型时按字符串处理
+			return text.String(), nil
+		case xml.StartElement:
+			v, err := decodeTyped(d, t)
+			if err != nil {
+				return nil, err
+			}
+			if err := skipToEnd(d); err != nil {
+				return nil, err
+			}
+			return v, nil
+		}
+	}
+}
+
+// decodeTyped 解析带类型的值，调用时类型开始标签已读取
+func decodeTyped(d *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	switch start.Name.Local {
+	case "struct":
+		return decodeStruct(d)
+	case "array":
+		return decodeArray(d)
+	case "nil":
+		return nil, skipToEnd(d)
+	}
+
+	text, err := readText(d)
+	if err != nil {
+		return nil, err
+	}
+	switch start.Name.Local {
+	case "string":
+		return text, nil
+	case "int", "i4", "i8":
+		return strconv.Atoi(strings.TrimSpace(text))
+	case "boolean":
+		switch strings.TrimSpace(text) {
+		case "1", "true":
+			return true, nil
+		case "0", "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("xmlrpc: invalid boolean %q", text)
+	case "double":
+		return strconv.ParseFloat(strings.TrimSpace(text), 64)
+	case "dateTime.iso8601":
+		return parseDateTime(strings.TrimSpace(text))
+	case "base64":
+		return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+	}
+	return nil, fmt.Errorf("xmlrpc: unsupported type %q", start.Name.Local)
+}
+
+// decodeStruct 解析结构体成员
+func decodeStruct(d *xml.Decoder) (map[string]interface{}, error) {
+	members := make(map[string]interface{})
+	var name string
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "member":
+				name = ""
+			case "name":
+				if name, err = readText(d); err != nil {
+					return nil, err
+				}
+			case "value":
+				v, err := decodeValue(d)
+				if err != nil {
+					return nil, err
+				}
+				members[name] = v
+			}
+		case xml.EndElement:
+			if t.Name.Local == "struct" {
+				return members, nil
+			}
+		}
+	}
+}
+
+// decodeArray 解析数组元素
+func decodeArray(d *xml.Decoder) ([]interface{}, error) {
+	values := make([]interface{}, 0)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "value" {
+				v, err := decodeValue(d)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, v)
+			}
+		case xml.EndElement:
+			if t.Name.Local == "array" {
+				return values, nil
+			}
+		}
+	}
+}
+
+// readText 读取元素文本直到对应的结束标签
+func readText(d *xml.Decoder) (string, error) {
+	var text strings.Builder
+	depth := 0
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				return text.String(), nil
+			}
+			depth--
+		}
+	}
+}
+
+// skipToEnd 跳过剩余内容直到当前元素结束
+func skipToEnd(d *xml.Decoder) error {
+	_, err := readText(d)
+	return err
+}
+
+// parseDateTime 解析日期时间，未带时区时按UTC处理
+func parseDateTime(s string) (time.Time, error) {
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("xmlrpc: invalid dateTime %q", s)
+}
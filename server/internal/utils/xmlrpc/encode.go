@@ -0,0 +1,159 @@
+package xmlrpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ContentType XML-RPC 响应内容类型
+const ContentType = "text/xml; charset=utf-8"
+
+// 常用错误码，与 WordPress 的 XML-RPC 实现保持一致便于客户端识别
+const (
+	FaultParse          = -32700 // 请求无法解析
+	FaultMethodNotFound = -32601 // 方法不存在
+	FaultBadRequest     = 400    // 参数错误
+	FaultUnauthorized   = 401    // 无权限
+	FaultForbidden      = 403    // 用户名或密码错误
+	FaultNotFound       = 404    // 资源不存在
+	FaultInternal       = 500    // 服务器内部错误
+)
+
+// Fault XML-RPC 错误
+type Fault struct {
+	Code    int
+	Message string
+}
+
+// Error 实现 error 接口
+func (f *Fault) Error() string {
+	return fmt.Sprintf("xmlrpc fault %d: %s", f.Code, f.Message)
+}
+
+// NewFault 创建 XML-RPC 错误
+func NewFault(code int, message string) *Fault {
+	return &Fault{Code: code, Message: message}
+}
+
+// EncodeResponse 生成方法调用成功的响应
+//
+// 支持 string、整数、bool、浮点数、time.Time、[]byte、切片和键为字符串的 map，
+// nil 输出为空字符串。
+func EncodeResponse(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString("<methodResponse><params><param>")
+	if err := encodeValue(&buf, v); err != nil {
+		return nil, err
+	}
+	buf.WriteString("</param></params></methodResponse>")
+	return buf.Bytes(), nil
+}
+
+// EncodeFault 生成错误响应
+func EncodeFault(fault *Fault) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString("<methodResponse><fault>")
+	// 成员类型固定，不会出错
+	_ = encodeValue(&buf, map[string]interface{}{
+		"faultCode":   fault.Code,
+		"faultString": fault.Message,
+	})
+	buf.WriteString("</fault></methodResponse>")
+	return buf.Bytes()
+}
+
+// encodeValue 输出 <value> 元素
+func encodeValue(buf *bytes.Buffer, v interface{}) error {
+	buf.WriteString("<value>")
+	if err := encodeInner(buf, v); err != nil {
+		return err
+	}
+	buf.WriteString("</value>")
+	return nil
+}
+
+// encodeInner 输出带类型的值
+func encodeInner(buf *bytes.Buffer, v interface{}) error {
+	switch val := v.(type) {
+	case nil:
+		buf.WriteString("<string></string>")
+	case string:
+		buf.WriteString("<string>")
+		if err := xml.EscapeText(buf, []byte(val)); err != nil {
+			return err
+		}
+		buf.WriteString("</string>")
+	case bool:
+		if val {
+			buf.WriteString("<boolean>1</boolean>")
+		} else {
+			buf.WriteString("<boolean>0</boolean>")
+		}
+	case time.Time:
+		buf.WriteString("<dateTime.iso8601>" + val.UTC().Format("20060102T15:04:05Z") + "</dateTime.iso8601>")
+	case []byte:
+		buf.WriteString("<base64>" + base64.StdEncoding.EncodeToString(val) + "</base64>")
+	default:
+		return encodeReflect(buf, reflect.ValueOf(v))
+	}
+	return nil
+}
+
+// encodeReflect 处理数字、切片和 map 等需要反射的类型
+func encodeReflect(buf *bytes.Buffer, rv reflect.Value) error {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteString("<int>" + strconv.FormatInt(rv.Int(), 10) + "</int>")
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		buf.WriteString("<int>" + strconv.FormatUint(rv.Uint(), 10) + "</int>")
+	case reflect.Float32, reflect.Float64:
+		buf.WriteString("<double>" + strconv.FormatFloat(rv.Float(), 'f', -1, 64) + "</double>")
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			buf.WriteString("<string></string>")
+			return nil
+		}
+		return encodeInner(buf, rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		buf.WriteString("<array><data>")
+		for i := 0; i < rv.Len(); i++ {
+			if err := encodeValue(buf, rv.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("</data></array>")
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("xmlrpc: unsupported map key type %s", rv.Type().Key())
+		}
+		keys := make([]string, 0, rv.Len())
+		for _, key := range rv.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		buf.WriteString("<struct>")
+		for _, key := range keys {
+			buf.WriteString("<member><name>")
+			if err := xml.EscapeText(buf, []byte(key)); err != nil {
+				return err
+			}
+			buf.WriteString("</name>")
+			if err := encodeValue(buf, rv.MapIndex(reflect.ValueOf(key).Convert(rv.Type().Key())).Interface()); err != nil {
+				return err
+			}
+			buf.WriteString("</member>")
+		}
+		buf.WriteString("</struct>")
+	default:
+		return fmt.Errorf("xmlrpc: unsupported type %s", rv.Type())
+	}
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/sunmoonstrand/go-react-blog/server/internal/logger"
 	"github.com/sunmoonstrand/go-react-blog/server/internal/model"
 	"github.com/sunmoonstrand/go-react-blog/server/internal/router"
+	"github.com/sunmoonstrand/go-react-blog/server/internal/service"
 
 	"go.uber.org/zap"
 )
@@ -69,6 +70,46 @@ func main() {
 	}
 	defer rdb.Close()
 
+	// 设置令牌有效期并加载签名密钥
+	if err := service.InitAuthToken(cfg.Server); err != nil {
+		log.Fatal("加载令牌签名密钥失败", zap.Error(err))
+	}
+
+	// 设置通行密钥依赖方信息
+	service.InitWebAuthn(cfg.WebAuthn)
+
+	// 创建第三方登录服务
+	if err := service.InitOAuth(cfg.OAuth); err != nil {
+		log.Fatal("第三方登录配置错误", zap.Error(err))
+	}
+
+	// 初始化文章搜索分词器
+	if err := service.InitArticleSearch(cfg.Search); err != nil {
+		log.Fatal("加载搜索用户词典失败", zap.Error(err))
+	}
+
+	// 加载静态站点模板
+	if err := service.InitStaticSite(cfg.Static); err != nil {
+		log.Fatal("加载静态站点模板失败", zap.Error(err))
+	}
+
+	// 命令行子命令（如 import）执行完即退出，不启动服务器
+	if len(os.Args) > 1 {
+		if err := runCommand(cfg, os.Args[1], os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			log.Sync()
+			os.Exit(1)
+		}
+		return
+	}
+
+	// 启动后台调度器（文章定时发布/下线）
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
+	if cfg.Scheduler.Enabled {
+		go service.StartArticleScheduler(schedulerCtx, cfg.Scheduler)
+	}
+
 	// 初始化路由
 	r := router.InitRouter(cfg)
 
@@ -98,6 +139,9 @@ func main() {
 	<-quit
 	log.Info("正在关闭服务器...")
 
+	// 停止后台调度器
+	stopScheduler()
+
 	// 设置关闭超时时间
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()